@@ -10,9 +10,10 @@
 //
 // Monitoring commands requires specifying a CommandMonitor when constructing
 // a mongo.Client. A CommandMonitor can be set to monitor started, succeeded,
-// and/or failed events. A CommandStartedEvent can be correlated to its matching
-// CommandSucceededEvent or CommandFailedEvent through the RequestID field. For
-// example, the following code collects the names of started events:
+// failed and/or retry events. A CommandStartedEvent can be correlated to its matching
+// CommandSucceededEvent or CommandFailedEvent through the RequestID field, and its
+// Attempt field separates first attempts from retries. For example, the following
+// code collects the names of started events:
 //
 //	var commandStarted []string
 //	cmdMonitor := &event.CommandMonitor{
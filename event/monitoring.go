@@ -23,6 +23,9 @@ type CommandStartedEvent struct {
 	CommandName  string
 	RequestID    int64
 	ConnectionID string
+	// DriverConnectionID contains the driver's ID for the connection, which is the ConnectionID of the pool events
+	// for the connection.
+	DriverConnectionID uint64
 	// ServerConnectionID contains the connection ID from the server of the operation. If the server does not return
 	// this value (e.g. on MDB < 4.2), it is unset. If the server connection ID would cause an int32 overflow, then
 	// then this field will be nil.
@@ -35,6 +38,12 @@ type CommandStartedEvent struct {
 	// ServiceID contains the ID of the server to which the command was sent if it is running behind a load balancer.
 	// Otherwise, it is unset.
 	ServiceID *primitive.ObjectID
+	// Labels contains the operation labels of the context the command was run with, which are added with
+	// mongo.WithOperationLabels. It is nil if the context has no labels.
+	Labels map[string]string
+	// Attempt is the number of the attempt of the command, starting at 1. Retries of the command have higher attempt
+	// numbers. Each batch of a write that is split into several commands starts again at 1.
+	Attempt int
 }
 
 // CommandFinishedEvent represents a generic command finishing.
@@ -46,6 +55,9 @@ type CommandFinishedEvent struct {
 	DatabaseName  string
 	RequestID     int64
 	ConnectionID  string
+	// DriverConnectionID contains the driver's ID for the connection, which is the ConnectionID of the pool events
+	// for the connection.
+	DriverConnectionID uint64
 	// ServerConnectionID contains the connection ID from the server of the operation. If the server does not return
 	// this value (e.g. on MDB < 4.2), it is unset.If the server connection ID would cause an int32 overflow, then
 	// this field will be nil.
@@ -58,6 +70,9 @@ type CommandFinishedEvent struct {
 	// ServiceID contains the ID of the server to which the command was sent if it is running behind a load balancer.
 	// Otherwise, it is unset.
 	ServiceID *primitive.ObjectID
+	// Labels contains the operation labels of the context the command was run with, which are added with
+	// mongo.WithOperationLabels. It is nil if the context has no labels.
+	Labels map[string]string
 }
 
 // CommandSucceededEvent represents an event generated when a command's execution succeeds.
@@ -70,6 +85,26 @@ type CommandSucceededEvent struct {
 type CommandFailedEvent struct {
 	CommandFinishedEvent
 	Failure string
+	// FailureCode contains the server error code of the failure. It is 0 if the failure is not a server error, e.g.
+	// if it is a network error.
+	FailureCode int32
+}
+
+// CommandRetryEvent represents an event generated when the driver retries a command after a retryable error. The
+// retry is a new attempt that selects a server again and publishes its own CommandStartedEvent.
+type CommandRetryEvent struct {
+	CommandName  string
+	DatabaseName string
+	// RequestID is the request ID of the failed attempt. It is 0 if the attempt failed before the command was sent,
+	// e.g. while checking out a connection.
+	RequestID int64
+	// Attempt is the number of the attempt that is about to start, which is 2 for the first retry.
+	Attempt int
+	// Failure is the error of the failed attempt.
+	Failure error
+	// Labels contains the operation labels of the context the command was run with, which are added with
+	// mongo.WithOperationLabels. It is nil if the context has no labels.
+	Labels map[string]string
 }
 
 // CommandMonitor represents a monitor that is triggered for different events.
@@ -77,16 +112,35 @@ type CommandMonitor struct {
 	Started   func(context.Context, *CommandStartedEvent)
 	Succeeded func(context.Context, *CommandSucceededEvent)
 	Failed    func(context.Context, *CommandFailedEvent)
+	Retry     func(context.Context, *CommandRetryEvent)
+
+	// Redaction is applied to the Command of CommandStartedEvents and the Reply of CommandSucceededEvents before they
+	// are published. If it redacts any values, the error messages returned by the server are also redacted from the
+	// replies and from the Failure of CommandFailedEvents, as they can contain values of the command. If nil, commands,
+	// replies and failures are published as is, except for security-sensitive commands.
+	Redaction Redactor
+}
+
+// Redactor redacts values from the commands and replies published to a CommandMonitor. It is implemented by
+// redact.Policy.
+type Redactor interface {
+	// Enabled returns whether any values are redacted.
+	Enabled() bool
+
+	// Redact returns a copy of the document with the values redacted, or the document as is if no value is redacted.
+	Redact(doc bson.Raw) bson.Raw
 }
 
 // strings for pool command monitoring reasons
 const (
 	ReasonIdle              = "idle"
+	ReasonLifeTimeExpired   = "lifeTimeExpired"
 	ReasonPoolClosed        = "poolClosed"
 	ReasonStale             = "stale"
 	ReasonConnectionErrored = "connectionError"
 	ReasonTimedOut          = "timeout"
 	ReasonError             = "error"
+	ReasonOverloaded        = "overloaded"
 )
 
 // strings for pool command monitoring types
@@ -102,6 +156,15 @@ const (
 	GetFailed          = "ConnectionCheckOutFailed"
 	GetSucceeded       = "ConnectionCheckedOut"
 	ConnectionReturned = "ConnectionCheckedIn"
+
+	// PoolDrained is published when the connections of a pool are marked as stale without pausing the pool, e.g. when
+	// its server is excluded from server selection. Idle connections are closed right away and in-use connections are
+	// closed when they are checked back in.
+	PoolDrained = "ConnectionPoolDrained"
+
+	// ConcurrencyLimitChanged is published when the adaptive concurrency limit of the operations in progress on a
+	// server changes. It is only published if load shedding is enabled.
+	ConcurrencyLimitChanged = "ConcurrencyLimitChanged"
 )
 
 // MonitorPoolOptions contains pool options as formatted in pool events
@@ -124,6 +187,17 @@ type PoolEvent struct {
 	ServiceID    *primitive.ObjectID `json:"serviceId"`
 	Interruption bool                `json:"interruptInUseConnections"`
 	Error        error               `json:"error"`
+	// Priority is the priority of the operation checking out a connection. It is only set if the Type is GetStarted,
+	// GetSucceeded or GetFailed. The Duration of GetSucceeded and GetFailed events is the time the operation waited
+	// for a connection, so it gives the wait times of each priority. It is one of the values of priority.Priority in the
+	// mongo/priority package: -1 for low, 0 for normal and 1 for high.
+	Priority int `json:"priority"`
+	// ConcurrencyLimit is the adaptive concurrency limit of the operations in progress on the server. It is only set if
+	// the Type is ConcurrencyLimitChanged, or GetFailed with the Reason ReasonOverloaded.
+	ConcurrencyLimit uint64 `json:"concurrencyLimit"`
+	// InFlight is the number of operations in progress on the server, holding or waiting for a connection. It is only
+	// set if the Type is ConcurrencyLimitChanged.
+	InFlight uint64 `json:"inFlight"`
 }
 
 // PoolMonitor is a function that allows the user to gain access to events occurring in the pool
@@ -194,6 +268,42 @@ type ServerHeartbeatFailedEvent struct {
 	Awaited       bool   // If this heartbeat was awaitable
 }
 
+// ServerRTTSampledEvent is an event generated when the driver measures the round trip time of a server. The RTT is
+// measured by the heartbeats that are not awaited and, when the streaming protocol is used, by the hello commands of
+// a separate RTT monitoring connection. Unlike the duration of an awaited heartbeat, it doesn't include the time the
+// server waited for a topology change.
+type ServerRTTSampledEvent struct {
+	Address    address.Address
+	TopologyID primitive.ObjectID // A unique identifier for the topology this server is a part of
+	RTT        time.Duration
+}
+
+// CircuitBreakerOpenedEvent is an event generated when the circuit breaker of a server opens, either because the
+// failure rate of its commands reached the threshold or because a probe failed while it was half-open. While the
+// circuit breaker is open, the server is not selected for reads.
+type CircuitBreakerOpenedEvent struct {
+	Address     address.Address
+	TopologyID  primitive.ObjectID // A unique identifier for the topology this server is a part of
+	FailureRate float64            // The failure rate of the commands in the sliding window, or 1 if a probe failed
+	Commands    int                // The number of commands in the sliding window, or 0 if a probe failed
+	CoolDown    time.Duration      // How long the circuit breaker stays open before it becomes half-open
+}
+
+// CircuitBreakerHalfOpenedEvent is an event generated when the circuit breaker of a server becomes half-open after its
+// cool-down, letting a limited number of probe operations be sent to the server.
+type CircuitBreakerHalfOpenedEvent struct {
+	Address    address.Address
+	TopologyID primitive.ObjectID // A unique identifier for the topology this server is a part of
+	Probes     int                // The number of probe operations that must succeed to close the circuit breaker
+}
+
+// CircuitBreakerClosedEvent is an event generated when the circuit breaker of a server closes after its probes
+// succeeded, making the server selectable for reads again.
+type CircuitBreakerClosedEvent struct {
+	Address    address.Address
+	TopologyID primitive.ObjectID // A unique identifier for the topology this server is a part of
+}
+
 // ServerMonitor represents a monitor that is triggered for different server events. The client
 // will monitor changes on the MongoDB deployment it is connected to, and this monitor reports
 // the changes in the client's representation of the deployment. The topology represents the
@@ -210,4 +320,56 @@ type ServerMonitor struct {
 	ServerHeartbeatStarted     func(*ServerHeartbeatStartedEvent)
 	ServerHeartbeatSucceeded   func(*ServerHeartbeatSucceededEvent)
 	ServerHeartbeatFailed      func(*ServerHeartbeatFailedEvent)
+	ServerRTTSampled           func(*ServerRTTSampledEvent)
+	// The circuit breaker callbacks are only called if the circuit breaker of servers is enabled.
+	CircuitBreakerOpened     func(*CircuitBreakerOpenedEvent)
+	CircuitBreakerHalfOpened func(*CircuitBreakerHalfOpenedEvent)
+	CircuitBreakerClosed     func(*CircuitBreakerClosedEvent)
+}
+
+// ServerSelectionStartedEvent is an event generated when the driver starts selecting a server for an operation.
+type ServerSelectionStartedEvent struct {
+	Selector            description.ServerSelector
+	Operation           string // The name of the operation the server is selected for
+	OperationID         int32  // The driver-generated ID of the operation the server is selected for
+	TopologyDescription description.Topology
+}
+
+// ServerSelectionSucceededEvent is an event generated when the driver selects a server for an operation.
+type ServerSelectionSucceededEvent struct {
+	Selector            description.ServerSelector
+	Operation           string
+	OperationID         int32
+	Address             address.Address // The address of the selected server
+	Duration            time.Duration
+	TopologyDescription description.Topology
+}
+
+// ServerSelectionFailedEvent is an event generated when the driver fails to select a server for an operation.
+type ServerSelectionFailedEvent struct {
+	Selector            description.ServerSelector
+	Operation           string
+	OperationID         int32
+	Duration            time.Duration
+	Failure             error
+	TopologyDescription description.Topology
+}
+
+// ServerSelectionWaitingEvent is an event generated when the topology has no server suitable for an operation and the
+// driver waits for the topology to change. It can be published several times for the same operation.
+type ServerSelectionWaitingEvent struct {
+	Selector            description.ServerSelector
+	Operation           string
+	OperationID         int32
+	RemainingTime       time.Duration // The time left until server selection times out, or 0 if there is no timeout
+	TopologyDescription description.Topology
+}
+
+// ServerSelectionMonitor represents a monitor that is triggered when the driver selects a server for an operation.
+// The callbacks receive the context of the operation, so they can be correlated with the caller.
+type ServerSelectionMonitor struct {
+	Started   func(context.Context, *ServerSelectionStartedEvent)
+	Succeeded func(context.Context, *ServerSelectionSucceededEvent)
+	Failed    func(context.Context, *ServerSelectionFailedEvent)
+	Waiting   func(context.Context, *ServerSelectionWaitingEvent)
 }
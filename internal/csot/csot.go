@@ -54,6 +54,11 @@ func (zrm *ZeroRTTMonitor) P90() time.Duration {
 	return 0
 }
 
+// P95 implements the RTT monitor interface.
+func (zrm *ZeroRTTMonitor) P95() time.Duration {
+	return 0
+}
+
 // Stats implements the RTT monitor interface.
 func (zrm *ZeroRTTMonitor) Stats() string {
 	return ""
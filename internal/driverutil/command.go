@@ -0,0 +1,38 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverutil
+
+import (
+	"strings"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+)
+
+// CommandCollection returns the collection a command operates on. Most
+// commands have the collection name as the value of the command name, but
+// getMore has it in the "collection" field.
+func CommandCollection(cmd bson.Raw) string {
+	elem, err := cmd.IndexErr(0)
+	if err != nil {
+		return ""
+	}
+	if collection, ok := elem.Value().StringValueOK(); ok {
+		return collection
+	}
+	collection, _ := cmd.Lookup("collection").StringValueOK()
+	return collection
+}
+
+// ConnectionAddress returns the address of the server from the ID of a
+// connection, which is the address followed by the connection number in
+// brackets.
+func ConnectionAddress(connectionID string) string {
+	if idx := strings.LastIndex(connectionID, "[-"); idx >= 0 {
+		return connectionID[:idx]
+	}
+	return connectionID
+}
@@ -0,0 +1,28 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverutil
+
+import "context"
+
+type closeConnectionKey struct{}
+
+// WithCloseConnection returns a context that makes the connection the command is sent with close itself while reading
+// the reply, which is how fault injection simulates a network error in the middle of a read.
+func WithCloseConnection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, closeConnectionKey{}, true)
+}
+
+// CloseConnectionFromContext returns true if the connection must be closed while reading the reply of a command sent
+// with the context.
+func CloseConnectionFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	closeConn, _ := ctx.Value(closeConnectionKey{}).(bool)
+	return closeConn
+}
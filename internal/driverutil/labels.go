@@ -0,0 +1,55 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverutil
+
+import "context"
+
+// LabelsCommentPolicy specifies how operation labels are added to the comment
+// of a command.
+type LabelsCommentPolicy int
+
+const (
+	// LabelsCommentMerge adds the labels to the comment. If the command has a
+	// document comment, labels that are not fields of it are added to it. If
+	// the command has a comment of another type, the comment is replaced with
+	// a document containing the labels and the original comment in the
+	// "comment" field.
+	LabelsCommentMerge LabelsCommentPolicy = iota
+
+	// LabelsCommentPreserve only adds the labels to commands without a
+	// comment.
+	LabelsCommentPreserve
+
+	// LabelsCommentReplace replaces the comment with the labels.
+	LabelsCommentReplace
+
+	// LabelsCommentOmit does not add the labels to the comment.
+	LabelsCommentOmit
+)
+
+type labelsKey struct{}
+
+// OperationLabels are the labels of the operations run with a context.
+type OperationLabels struct {
+	Labels        map[string]string
+	CommentPolicy LabelsCommentPolicy
+}
+
+// WithOperationLabels returns a context with the given operation labels.
+func WithOperationLabels(ctx context.Context, labels OperationLabels) context.Context {
+	return context.WithValue(ctx, labelsKey{}, labels)
+}
+
+// OperationLabelsFromContext returns the operation labels of a context.
+func OperationLabelsFromContext(ctx context.Context) (OperationLabels, bool) {
+	if ctx == nil {
+		return OperationLabels{}, false
+	}
+
+	labels, ok := ctx.Value(labelsKey{}).(OperationLabels)
+	return labels, ok && len(labels.Labels) > 0
+}
@@ -0,0 +1,34 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverutil
+
+import (
+	"context"
+
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context with the given operation priority.
+func WithPriority(ctx context.Context, p priority.Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the operation priority of a context, or
+// priority.Normal if the context doesn't have one.
+func PriorityFromContext(ctx context.Context) priority.Priority {
+	if ctx == nil {
+		return priority.Normal
+	}
+
+	p, ok := ctx.Value(priorityKey{}).(priority.Priority)
+	if !ok {
+		return priority.Normal
+	}
+	return p
+}
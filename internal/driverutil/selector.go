@@ -0,0 +1,41 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driverutil
+
+import (
+	"context"
+
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+)
+
+type serverSelectorKey struct{}
+
+// serverSelector wraps the server selector of a context so that a nil
+// selector can be distinguished from a missing one.
+type serverSelector struct {
+	selector description.ServerSelector
+}
+
+// WithServerSelector returns a context with the given application server
+// selector, which overrides the server selector of the client for the
+// operations run with the context. A nil selector disables the server selector
+// of the client.
+func WithServerSelector(ctx context.Context, selector description.ServerSelector) context.Context {
+	return context.WithValue(ctx, serverSelectorKey{}, serverSelector{selector: selector})
+}
+
+// ServerSelectorFromContext returns the application server selector of a
+// context. The returned selector is nil if the context disables the server
+// selector of the client.
+func ServerSelectorFromContext(ctx context.Context) (description.ServerSelector, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	ss, ok := ctx.Value(serverSelectorKey{}).(serverSelector)
+	return ss.selector, ok
+}
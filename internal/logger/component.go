@@ -20,6 +20,7 @@ const (
 	ConnectionPoolCreated            = "Connection pool created"
 	ConnectionPoolReady              = "Connection pool ready"
 	ConnectionPoolCleared            = "Connection pool cleared"
+	ConnectionPoolDrained            = "Connection pool drained"
 	ConnectionPoolClosed             = "Connection pool closed"
 	ConnectionCreated                = "Connection created"
 	ConnectionReady                  = "Connection ready"
@@ -32,6 +33,7 @@ const (
 	ServerSelectionStarted           = "Server selection started"
 	ServerSelectionSucceeded         = "Server selection succeeded"
 	ServerSelectionWaiting           = "Waiting for suitable server to become available"
+	SlowOperation                    = "Slow operation"
 	TopologyClosed                   = "Stopped topology monitoring"
 	TopologyDescriptionChanged       = "Topology description changed"
 	TopologyOpening                  = "Starting topology monitoring"
@@ -46,17 +48,22 @@ const (
 	KeyAwaited             = "awaited"
 	KeyCommand             = "command"
 	KeyCommandName         = "commandName"
+	KeyComment             = "comment"
+	KeyConnectionID        = "connectionId"
 	KeyDatabaseName        = "databaseName"
 	KeyDriverConnectionID  = "driverConnectionId"
 	KeyDurationMS          = "durationMS"
 	KeyError               = "error"
 	KeyFailure             = "failure"
+	KeyLabels              = "labels"
 	KeyMaxConnecting       = "maxConnecting"
 	KeyMaxIdleTimeMS       = "maxIdleTimeMS"
 	KeyMaxPoolSize         = "maxPoolSize"
 	KeyMessage             = "message"
 	KeyMinPoolSize         = "minPoolSize"
+	KeyNamespace           = "namespace"
 	KeyNewDescription      = "newDescription"
+	KeyNReturned           = "nreturned"
 	KeyOperation           = "operation"
 	KeyOperationID         = "operationId"
 	KeyPreviousDescription = "previousDescription"
@@ -69,6 +76,9 @@ const (
 	KeyServerHost          = "serverHost"
 	KeyServerPort          = "serverPort"
 	KeyServiceID           = "serviceId"
+	KeyShape               = "shape"
+	KeyShapeHash           = "shapeHash"
+	KeyThresholdMS         = "thresholdMS"
 	KeyTimestamp           = "timestamp"
 	KeyTopologyDescription = "topologyDescription"
 	KeyTopologyID          = "topologyId"
@@ -85,11 +95,13 @@ func (kvs *KeyValues) Add(key string, value interface{}) {
 const (
 	ReasonConnClosedStale              = "Connection became stale because the pool was cleared"
 	ReasonConnClosedIdle               = "Connection has been available but unused for longer than the configured max idle time"
+	ReasonConnClosedLifeTime           = "Connection has existed for longer than the configured max lifetime"
 	ReasonConnClosedError              = "An error occurred while using the connection"
 	ReasonConnClosedPoolClosed         = "Connection pool was closed"
 	ReasonConnCheckoutFailedTimout     = "Wait queue timeout elapsed without a connection becoming available"
 	ReasonConnCheckoutFailedError      = "An error occurred while trying to establish a new connection"
 	ReasonConnCheckoutFailedPoolClosed = "Connection pool was closed"
+	ReasonConnCheckoutFailedOverloaded = "The concurrency limit of the server was reached"
 )
 
 // Component is an enumeration representing the "components" which can be
@@ -155,6 +167,7 @@ type Command struct {
 	ServerHost         string              // Hostname or IP address for the server
 	ServerPort         string              // Port for the server
 	ServiceID          *primitive.ObjectID // ID for the command  in load balancer mode
+	Labels             map[string]string   // Operation labels of the command's context
 }
 
 // SerializeCommand takes a command and a variable number of key-value pairs and
@@ -192,6 +205,11 @@ func SerializeCommand(cmd Command, extraKeysAndValues ...interface{}) KeyValues
 		keysAndValues.Add(KeyServiceID, cmd.ServiceID.Hex())
 	}
 
+	// Add the "labels" if the command has operation labels.
+	if len(cmd.Labels) > 0 {
+		keysAndValues.Add(KeyLabels, cmd.Labels)
+	}
+
 	return keysAndValues
 }
 
@@ -13,6 +13,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/hongyuyang/mongo-go-driver/mongo/redact"
 )
 
 // DefaultMaxDocumentLength is the default maximum number of bytes that can be
@@ -38,11 +40,18 @@ type LogSink interface {
 	Error(err error, msg string, keysAndValues ...interface{})
 }
 
+// componentSink is a LogSink that receives the component of each message
+// printed by a Logger.
+type componentSink interface {
+	infoComponent(level int, component Component, msg string, keysAndValues ...interface{})
+}
+
 // Logger represents the configuration for the internal logger.
 type Logger struct {
 	ComponentLevels   map[Component]Level // Log levels for each component.
 	Sink              LogSink             // LogSink for log printing.
 	MaxDocumentLength uint                // Command truncation width.
+	Redaction         *redact.Policy      // Redaction applied to logged commands and replies.
 	logFile           *os.File            // File to write logs to.
 }
 
@@ -120,6 +129,14 @@ func (logger *Logger) Print(level Level, component Component, msg string, keysAn
 		return
 	}
 
+	// Sinks that group messages by component, like the slog sink, are told the
+	// component of the message.
+	if sink, ok := logger.Sink.(componentSink); ok {
+		sink.infoComponent(int(level)-DiffToInfo, component, msg, keysAndValues...)
+
+		return
+	}
+
 	logger.Sink.Info(int(level)-DiffToInfo, msg, keysAndValues...)
 }
 
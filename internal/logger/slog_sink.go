@@ -0,0 +1,172 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
+)
+
+// componentGroups are the names of the attribute groups that contain the
+// key-value pairs of the messages of each component.
+var componentGroups = map[Component]string{
+	ComponentCommand:         "command",
+	ComponentTopology:        "topology",
+	ComponentServerSelection: "serverSelection",
+	ComponentConnection:      "connection",
+}
+
+// documentKeys are the keys whose values are extended JSON documents. They are
+// logged as nested groups.
+var documentKeys = map[string]bool{
+	KeyCommand: true,
+	KeyReply:   true,
+	KeyShape:   true,
+}
+
+// SlogSink is a LogSink that writes messages to a slog.Logger.
+type SlogSink struct {
+	logger *slog.Logger
+}
+
+// Compile-time check to ensure SlogSink implements the LogSink interface.
+var _ LogSink = &SlogSink{}
+
+// NewSlogSink will create a SlogSink that writes messages to the provided
+// slog.Logger. If the logger is nil, slog.Default() is used.
+func NewSlogSink(logger *slog.Logger) *SlogSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SlogSink{logger: logger}
+}
+
+// SlogLevel returns the slog.Level of a level passed to LogSink.Info. Info
+// messages are logged at slog.LevelInfo and debug messages at slog.LevelDebug.
+func SlogLevel(level int) slog.Level {
+	if Level(level+DiffToInfo) <= LevelInfo {
+		return slog.LevelInfo
+	}
+
+	return slog.LevelDebug - slog.Level(level+DiffToInfo-int(LevelDebug))
+}
+
+// Info will write a message without a component group to the slog.Logger.
+func (sink *SlogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	sink.log(SlogLevel(level), "", msg, keysAndValues)
+}
+
+// Error will write an error message to the slog.Logger at slog.LevelError.
+func (sink *SlogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	sink.log(slog.LevelError, "", msg, append(keysAndValues, KeyError, err))
+}
+
+func (sink *SlogSink) infoComponent(level int, component Component, msg string, keysAndValues ...interface{}) {
+	sink.log(SlogLevel(level), componentGroups[component], msg, keysAndValues)
+}
+
+// log writes a message with the key-value pairs converted to attributes. If
+// group is not empty, the attributes are nested in a group with that name.
+func (sink *SlogSink) log(level slog.Level, group string, msg string, keysAndValues []interface{}) {
+	ctx := context.Background()
+	if !sink.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok || key == KeyMessage {
+			// The message is the message of the record.
+			continue
+		}
+
+		attrs = append(attrs, slogAttr(key, keysAndValues[i+1]))
+	}
+
+	if group != "" {
+		attrs = []slog.Attr{{Key: group, Value: slog.GroupValue(attrs...)}}
+	}
+
+	sink.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// slogAttr converts a key-value pair to an attribute.
+func slogAttr(key string, value interface{}) slog.Attr {
+	if str, ok := value.(string); ok && documentKeys[key] {
+		return slog.Any(key, extJSONDocument(str))
+	}
+
+	return slog.Any(key, value)
+}
+
+// extJSONDocument is an extended JSON document that is converted to a group
+// when it is logged, so documents that are not logged are never parsed.
+type extJSONDocument string
+
+// Compile-time check to ensure extJSONDocument implements the slog.LogValuer
+// interface.
+var _ slog.LogValuer = extJSONDocument("")
+
+// LogValue will return the document as a group. If the document cannot be
+// parsed, e.g. because it was truncated, it is returned as a string.
+func (doc extJSONDocument) LogValue() slog.Value {
+	// Wrap the value in a document so that arrays, like aggregation
+	// pipelines, can be parsed as well.
+	var wrapper bson.D
+	err := bson.UnmarshalExtJSON([]byte(`{"v":`+string(doc)+`}`), false, &wrapper)
+	if err != nil || len(wrapper) != 1 {
+		return slog.StringValue(string(doc))
+	}
+
+	return slogValue(wrapper[0].Value)
+}
+
+// slogValue converts a value unmarshaled from extended JSON to a slog.Value.
+// Documents and arrays are converted to groups. Handlers omit empty groups, so
+// empty documents and arrays are converted to empty maps and slices.
+func slogValue(val interface{}) slog.Value {
+	switch v := val.(type) {
+	case bson.D:
+		if len(v) == 0 {
+			return slog.AnyValue(map[string]interface{}{})
+		}
+
+		attrs := make([]slog.Attr, 0, len(v))
+		for _, elem := range v {
+			attrs = append(attrs, slog.Attr{Key: elem.Key, Value: slogValue(elem.Value)})
+		}
+
+		return slog.GroupValue(attrs...)
+	case bson.A:
+		if len(v) == 0 {
+			return slog.AnyValue([]interface{}{})
+		}
+
+		attrs := make([]slog.Attr, 0, len(v))
+		for i, elem := range v {
+			attrs = append(attrs, slog.Attr{Key: strconv.Itoa(i), Value: slogValue(elem)})
+		}
+
+		return slog.GroupValue(attrs...)
+	case primitive.DateTime:
+		return slog.TimeValue(v.Time())
+	case primitive.ObjectID:
+		return slog.StringValue(v.Hex())
+	case nil:
+		return slog.AnyValue(nil)
+	default:
+		return slog.AnyValue(v)
+	}
+}
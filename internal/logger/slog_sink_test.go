@@ -0,0 +1,105 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+)
+
+func TestSlogSink(t *testing.T) {
+	// newLogger returns a Logger that writes to a slog JSON handler enabled at
+	// the given level.
+	newLogger := func(t *testing.T, level slog.Level) (*Logger, *bytes.Buffer) {
+		t.Helper()
+
+		buf := &bytes.Buffer{}
+		handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level})
+		logger, err := New(NewSlogSink(slog.New(handler)), 0, map[Component]Level{
+			ComponentAll: LevelDebug,
+		})
+		assert.Nil(t, err, "New error: %v", err)
+
+		return logger, buf
+	}
+	decode := func(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+		t.Helper()
+
+		record := map[string]interface{}{}
+		err := json.Unmarshal(buf.Bytes(), &record)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+
+		return record
+	}
+
+	t.Run("levels", func(t *testing.T) {
+		assert.Equal(t, slog.LevelInfo, SlogLevel(int(LevelInfo)-DiffToInfo), "expected info level")
+		assert.Equal(t, slog.LevelDebug, SlogLevel(int(LevelDebug)-DiffToInfo), "expected debug level")
+	})
+	t.Run("component groups", func(t *testing.T) {
+		logger, buf := newLogger(t, slog.LevelDebug)
+		logger.Print(LevelDebug, ComponentCommand, CommandStarted, SerializeCommand(Command{
+			DriverConnectionID: 1,
+			Message:            CommandStarted,
+			Name:               "find",
+			DatabaseName:       "db",
+			RequestID:          7,
+			ServerHost:         "localhost",
+			ServerPort:         "27017",
+		}, KeyCommand, `{"find": "coll", "filter": {"x": {"$numberInt": "1"}}, "pipeline": [{"$match": {}}]}`)...)
+
+		record := decode(t, buf)
+		assert.Equal(t, "DEBUG", record["level"], "expected debug level, got %v", record["level"])
+		assert.Equal(t, CommandStarted, record["msg"], "expected message %q, got %v", CommandStarted, record["msg"])
+
+		command, ok := record["command"].(map[string]interface{})
+		assert.True(t, ok, "expected command group, got %v", record["command"])
+		assert.Equal(t, "find", command[KeyCommandName], "expected command name, got %v", command[KeyCommandName])
+		assert.Equal(t, float64(7), command[KeyRequestID], "expected typed request ID, got %v", command[KeyRequestID])
+		assert.Equal(t, float64(27017), command[KeyServerPort], "expected typed port, got %v", command[KeyServerPort])
+		_, ok = command[KeyMessage]
+		assert.False(t, ok, "expected message to be omitted from attributes")
+
+		want := map[string]interface{}{
+			"find":     "coll",
+			"filter":   map[string]interface{}{"x": float64(1)},
+			"pipeline": map[string]interface{}{"0": map[string]interface{}{"$match": map[string]interface{}{}}},
+		}
+		assert.Equal(t, want, command[KeyCommand], "expected nested command %v, got %v", want, command[KeyCommand])
+	})
+	t.Run("truncated documents", func(t *testing.T) {
+		logger, buf := newLogger(t, slog.LevelDebug)
+		logger.Print(LevelDebug, ComponentCommand, CommandSucceeded, KeyReply, `{"ok": {"$numberDou...`)
+
+		reply := decode(t, buf)["command"].(map[string]interface{})[KeyReply]
+		assert.Equal(t, `{"ok": {"$numberDou...`, reply, "expected truncated reply as string, got %v", reply)
+	})
+	t.Run("disabled levels", func(t *testing.T) {
+		logger, buf := newLogger(t, slog.LevelInfo)
+		logger.Print(LevelDebug, ComponentConnection, ConnectionCreated)
+		assert.Equal(t, 0, buf.Len(), "expected no output, got %q", buf.String())
+
+		logger.Print(LevelInfo, ComponentConnection, ConnectionCreated, KeyServerHost, "localhost")
+		connection := decode(t, buf)["connection"].(map[string]interface{})
+		assert.Equal(t, "localhost", connection[KeyServerHost], "expected connection group, got %v", connection)
+	})
+	t.Run("errors", func(t *testing.T) {
+		logger, buf := newLogger(t, slog.LevelInfo)
+		logger.Error(errors.New("boom"), "failed")
+
+		record := decode(t, buf)
+		assert.Equal(t, "ERROR", record["level"], "expected error level, got %v", record["level"])
+		assert.Equal(t, "boom", record[KeyError], "expected error attribute, got %v", record[KeyError])
+	})
+}
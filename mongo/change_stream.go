@@ -87,6 +87,7 @@ type ChangeStream struct {
 	selector        description.ServerSelector
 	operationTime   *primitive.Timestamp
 	wireVersion     *description.VersionRange
+	timeout         *time.Duration
 }
 
 type changeStreamConfig struct {
@@ -99,6 +100,7 @@ type changeStreamConfig struct {
 	collectionName string
 	databaseName   string
 	crypt          driver.Crypt
+	timeout        *time.Duration
 }
 
 func newChangeStream(ctx context.Context, config changeStreamConfig, pipeline interface{},
@@ -122,6 +124,7 @@ func newChangeStream(ctx context.Context, config changeStreamConfig, pipeline in
 			description.LatencySelector(config.client.localThreshold),
 		}),
 		cursorOptions: cursorOpts,
+		timeout:       config.timeout,
 	}
 
 	cs.sess = sessionFromContext(ctx)
@@ -137,7 +140,7 @@ func newChangeStream(ctx context.Context, config changeStreamConfig, pipeline in
 		ReadPreference(config.readPreference).ReadConcern(config.readConcern).
 		Deployment(cs.client.deployment).ClusterClock(cs.client.clock).
 		CommandMonitor(cs.client.monitor).Session(cs.sess).ServerSelector(cs.selector).Retry(driver.RetryNone).
-		ServerAPI(cs.client.serverAPI).Crypt(config.crypt).Timeout(cs.client.timeout)
+		ServerAPI(cs.client.serverAPI).Crypt(config.crypt).Timeout(cs.timeout)
 
 	if cs.options.Collation != nil {
 		cs.aggregate.Collation(bsoncore.Document(cs.options.Collation.ToDocument()))
@@ -277,11 +280,11 @@ func (cs *ChangeStream) executeOperation(ctx context.Context, resuming bool) err
 		cs.aggregate.Pipeline(plArr)
 	}
 
-	// If cs.client.timeout is set and context is not already a Timeout context,
-	// honor cs.client.timeout in new Timeout context for change stream
+	// If cs.timeout is set and context is not already a Timeout context,
+	// honor cs.timeout in new Timeout context for change stream
 	// operation execution and potential retry.
-	if cs.client.timeout != nil && !csot.IsTimeoutContext(ctx) {
-		newCtx, cancelFunc := csot.MakeTimeoutContext(ctx, *cs.client.timeout)
+	if cs.timeout != nil && !csot.IsTimeoutContext(ctx) {
+		newCtx, cancelFunc := csot.MakeTimeoutContext(ctx, *cs.timeout)
 		// Redefine ctx to be the new timeout-derived context.
 		ctx = newCtx
 		// Cancel the timeout-derived context at the end of executeOperation to avoid a context leak.
@@ -20,6 +20,7 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
 	"github.com/hongyuyang/mongo-go-driver/internal/uuid"
 	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
 	"github.com/hongyuyang/mongo-go-driver/mongo/options"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
@@ -70,6 +71,7 @@ type Client struct {
 	timeout        *time.Duration
 	httpClient     *http.Client
 	logger         *logger.Logger
+	faultInjector  *faultinject.Injector
 
 	// client-side encryption fields
 	keyVaultClientFLE  *Client
@@ -186,6 +188,8 @@ func NewClient(opts ...*options.ClientOptions) (*Client, error) {
 	// Timeout
 	client.timeout = clientOpt.Timeout
 	client.httpClient = clientOpt.HTTPClient
+	// FaultInjector
+	client.faultInjector = clientOpt.FaultInjector
 	// WriteConcern
 	if clientOpt.WriteConcern != nil {
 		client.writeConcern = clientOpt.WriteConcern
@@ -232,6 +236,11 @@ func NewClient(opts ...*options.ClientOptions) (*Client, error) {
 		return nil, fmt.Errorf("invalid logger options: %w", err)
 	}
 
+	// SlowOperationThreshold
+	if monitor := newSlowOperationMonitor(clientOpt, client.logger); monitor != nil {
+		client.monitor = monitor.wrap(client.monitor)
+	}
+
 	return client, nil
 }
 
@@ -377,8 +386,8 @@ func (c *Client) Ping(ctx context.Context, rp *readpref.ReadPref) error {
 // StartSession is safe to call from multiple goroutines concurrently. However, Sessions returned by StartSession are
 // not safe for concurrent use by multiple goroutines.
 //
-// If the DefaultReadConcern, DefaultWriteConcern, or DefaultReadPreference options are not set, the client's read
-// concern, write concern, or read preference will be used, respectively.
+// If the DefaultReadConcern, DefaultWriteConcern, DefaultReadPreference, or DefaultTimeout options are not set, the
+// client's read concern, write concern, read preference, or timeout will be used, respectively.
 func (c *Client) StartSession(opts ...*options.SessionOptions) (Session, error) {
 	if c.sessionPool == nil {
 		return nil, ErrClientDisconnected
@@ -389,6 +398,7 @@ func (c *Client) StartSession(opts ...*options.SessionOptions) (Session, error)
 		DefaultReadConcern:    c.readConcern,
 		DefaultReadPreference: c.readPreference,
 		DefaultWriteConcern:   c.writeConcern,
+		DefaultTimeout:        c.timeout,
 	}
 	if sopts.CausalConsistency != nil {
 		coreOpts.CausalConsistency = sopts.CausalConsistency
@@ -405,6 +415,9 @@ func (c *Client) StartSession(opts ...*options.SessionOptions) (Session, error)
 	if sopts.DefaultMaxCommitTime != nil {
 		coreOpts.DefaultMaxCommitTime = sopts.DefaultMaxCommitTime
 	}
+	if sopts.DefaultTimeout != nil {
+		coreOpts.DefaultTimeout = sopts.DefaultTimeout
+	}
 	if sopts.Snapshot != nil {
 		coreOpts.Snapshot = sopts.Snapshot
 	}
@@ -814,6 +827,7 @@ func (c *Client) Watch(ctx context.Context, pipeline interface{},
 		registry:       c.registry,
 		streamType:     ClientStream,
 		crypt:          c.cryptFLE,
+		timeout:        c.timeout,
 	}
 
 	return newChangeStream(ctx, csConfig, pipeline, opts...)
@@ -828,6 +842,18 @@ func (c *Client) NumberSessionsInProgress() int {
 	return int(c.sessionPool.CheckedOut())
 }
 
+// FaultInjector returns the fault injector of the client, which adds faults to its commands and heartbeats according
+// to its rules. Rules can be added and removed while the client is running. The injector is the one set with
+// options.ClientOptions.SetFaultInjector, or nil if none was set. To configure fault injection only at runtime, create
+// the client with an injector without rules, which adds no faults until rules are added:
+//
+//	client, err := mongo.Connect(ctx, options.Client().SetFaultInjector(faultinject.New()))
+//	...
+//	client.FaultInjector().Add(rule)
+func (c *Client) FaultInjector() *faultinject.Injector {
+	return c.faultInjector
+}
+
 // Timeout returns the timeout set for this client.
 func (c *Client) Timeout() *time.Duration {
 	return c.timeout
@@ -863,5 +889,11 @@ func newLogger(opts *options.LoggerOptions) (*logger.Logger, error) {
 		componentLevels[logger.Component(component)] = logger.Level(level)
 	}
 
-	return logger.New(opts.Sink, opts.MaxDocumentLength, componentLevels)
+	log, err := logger.New(opts.Sink, opts.MaxDocumentLength, componentLevels)
+	if err != nil {
+		return nil, err
+	}
+	log.Redaction = opts.Redaction
+
+	return log, nil
 }
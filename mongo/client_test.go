@@ -18,6 +18,7 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
 	"github.com/hongyuyang/mongo-go-driver/internal/integtest"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
 	"github.com/hongyuyang/mongo-go-driver/mongo/options"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
@@ -299,6 +300,14 @@ func TestClient(t *testing.T) {
 		client := setupClient(options.Client().SetServerMonitor(monitor))
 		assert.Equal(t, monitor, client.serverMonitor, "expected sdam monitor %v, got %v", monitor, client.serverMonitor)
 	})
+	t.Run("fault injector", func(t *testing.T) {
+		client := setupClient()
+		assert.Nil(t, client.FaultInjector(), "expected no fault injector by default, got %v", client.FaultInjector())
+
+		injector := faultinject.New()
+		client = setupClient(options.Client().SetFaultInjector(injector))
+		assert.Equal(t, injector, client.FaultInjector(), "expected fault injector %v, got %v", injector, client.FaultInjector())
+	})
 	t.Run("GetURI", func(t *testing.T) {
 		t.Run("ApplyURI not called", func(t *testing.T) {
 			opts := options.Client().SetHosts([]string{"localhost:27017"})
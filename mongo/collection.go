@@ -42,6 +42,7 @@ type Collection struct {
 	writeSelector  description.ServerSelector
 	bsonOpts       *options.BSONOptions
 	registry       *bsoncodec.Registry
+	timeout        *time.Duration
 }
 
 // aggregateParams is used to store information to configure an Aggregate operation.
@@ -59,6 +60,7 @@ type aggregateParams struct {
 	readSelector   description.ServerSelector
 	writeSelector  description.ServerSelector
 	readPreference *readpref.ReadPref
+	timeout        *time.Duration
 	opts           []*options.AggregateOptions
 }
 
@@ -96,6 +98,11 @@ func newCollection(db *Database, name string, opts ...*options.CollectionOptions
 		reg = collOpt.Registry
 	}
 
+	timeout := db.timeout
+	if collOpt.Timeout != nil {
+		timeout = collOpt.Timeout
+	}
+
 	readSelector := description.CompositeSelector([]description.ServerSelector{
 		description.ReadPrefSelector(rp),
 		description.LatencySelector(db.client.localThreshold),
@@ -117,6 +124,7 @@ func newCollection(db *Database, name string, opts ...*options.CollectionOptions
 		writeSelector:  writeSelector,
 		bsonOpts:       bsonOpts,
 		registry:       reg,
+		timeout:        timeout,
 	}
 
 	return coll
@@ -133,6 +141,7 @@ func (coll *Collection) copy() *Collection {
 		readSelector:   coll.readSelector,
 		writeSelector:  coll.writeSelector,
 		registry:       coll.registry,
+		timeout:        coll.timeout,
 	}
 }
 
@@ -159,6 +168,10 @@ func (coll *Collection) Clone(opts ...*options.CollectionOptions) (*Collection,
 		copyColl.registry = optsColl.Registry
 	}
 
+	if optsColl.Timeout != nil {
+		copyColl.timeout = optsColl.Timeout
+	}
+
 	copyColl.readSelector = description.CompositeSelector([]description.ServerSelector{
 		description.ReadPrefSelector(copyColl.readPreference),
 		description.LatencySelector(copyColl.client.localThreshold),
@@ -177,6 +190,11 @@ func (coll *Collection) Database() *Database {
 	return coll.db
 }
 
+// Timeout returns the timeout used to configure the Collection object.
+func (coll *Collection) Timeout() *time.Duration {
+	return coll.timeout
+}
+
 // BulkWrite performs a bulk write operation (https://www.mongodb.com/docs/manual/core/bulk-write-operations/).
 //
 // The models parameter must be a slice of operations to be executed in this bulk write. It cannot be nil or empty.
@@ -291,7 +309,7 @@ func (coll *Collection) insert(ctx context.Context, documents []interface{},
 		ServerSelector(selector).ClusterClock(coll.client.clock).
 		Database(coll.db.name).Collection(coll.name).
 		Deployment(coll.client.deployment).Crypt(coll.client.cryptFLE).Ordered(true).
-		ServerAPI(coll.client.serverAPI).Timeout(coll.client.timeout).Logger(coll.client.logger)
+		ServerAPI(coll.client.serverAPI).Timeout(coll.timeout).Logger(coll.client.logger)
 	imo := options.MergeInsertManyOptions(opts...)
 	if imo.BypassDocumentValidation != nil && *imo.BypassDocumentValidation {
 		op = op.BypassDocumentValidation(*imo.BypassDocumentValidation)
@@ -471,7 +489,7 @@ func (coll *Collection) delete(ctx context.Context, filter interface{}, deleteOn
 		ServerSelector(selector).ClusterClock(coll.client.clock).
 		Database(coll.db.name).Collection(coll.name).
 		Deployment(coll.client.deployment).Crypt(coll.client.cryptFLE).Ordered(true).
-		ServerAPI(coll.client.serverAPI).Timeout(coll.client.timeout).Logger(coll.client.logger)
+		ServerAPI(coll.client.serverAPI).Timeout(coll.timeout).Logger(coll.client.logger)
 	if do.Comment != nil {
 		comment, err := marshalValue(do.Comment, coll.bsonOpts, coll.registry)
 		if err != nil {
@@ -588,7 +606,7 @@ func (coll *Collection) updateOrReplace(ctx context.Context, filter bsoncore.Doc
 		Database(coll.db.name).Collection(coll.name).
 		Deployment(coll.client.deployment).Crypt(coll.client.cryptFLE).Hint(uo.Hint != nil).
 		ArrayFilters(uo.ArrayFilters != nil).Ordered(true).ServerAPI(coll.client.serverAPI).
-		Timeout(coll.client.timeout).Logger(coll.client.logger)
+		Timeout(coll.timeout).Logger(coll.client.logger)
 	if uo.Let != nil {
 		let, err := marshal(uo.Let, coll.bsonOpts, coll.registry)
 		if err != nil {
@@ -791,6 +809,7 @@ func (coll *Collection) Aggregate(ctx context.Context, pipeline interface{},
 		readSelector:   coll.readSelector,
 		writeSelector:  coll.writeSelector,
 		readPreference: coll.readPreference,
+		timeout:        coll.timeout,
 		opts:           opts,
 	}
 	return aggregate(a)
@@ -842,6 +861,11 @@ func aggregate(a aggregateParams) (cur *Cursor, err error) {
 
 	ao := options.MergeAggregateOptions(a.opts...)
 
+	timeout := a.timeout
+	if ao.Timeout != nil {
+		timeout = ao.Timeout
+	}
+
 	cursorOpts := a.client.createBaseCursorOptions()
 
 	cursorOpts.MarshalValueEncoderFn = newEncoderFn(a.bsonOpts, a.registry)
@@ -860,7 +884,7 @@ func aggregate(a aggregateParams) (cur *Cursor, err error) {
 		Crypt(a.client.cryptFLE).
 		ServerAPI(a.client.serverAPI).
 		HasOutputStage(hasOutputStage).
-		Timeout(a.client.timeout).
+		Timeout(timeout).
 		MaxTime(ao.MaxTime)
 
 	// Omit "maxTimeMS" from operations that return a user-managed cursor to
@@ -936,6 +960,7 @@ func aggregate(a aggregateParams) (cur *Cursor, err error) {
 	}
 	op = op.Retry(retry)
 
+	start := time.Now()
 	err = op.Execute(a.ctx)
 	if err != nil {
 		if wce, ok := err.(driver.WriteCommandError); ok && wce.WriteConcernError != nil {
@@ -949,7 +974,11 @@ func aggregate(a aggregateParams) (cur *Cursor, err error) {
 		return nil, replaceErrors(err)
 	}
 	cursor, err := newCursorWithSession(bc, a.client.bsonOpts, a.registry, sess)
-	return cursor, replaceErrors(err)
+	if err != nil {
+		return nil, replaceErrors(err)
+	}
+	cursor.setTimeout(timeout, ao.TimeoutMode, start)
+	return cursor, nil
 }
 
 // CountDocuments returns the number of documents in the collection. For a fast count of the documents in the
@@ -992,7 +1021,7 @@ func (coll *Collection) CountDocuments(ctx context.Context, filter interface{},
 	op := operation.NewAggregate(pipelineArr).Session(sess).ReadConcern(rc).ReadPreference(coll.readPreference).
 		CommandMonitor(coll.client.monitor).ServerSelector(selector).ClusterClock(coll.client.clock).Database(coll.db.name).
 		Collection(coll.name).Deployment(coll.client.deployment).Crypt(coll.client.cryptFLE).ServerAPI(coll.client.serverAPI).
-		Timeout(coll.client.timeout).MaxTime(countOpts.MaxTime)
+		Timeout(coll.timeout).MaxTime(countOpts.MaxTime)
 	if countOpts.Collation != nil {
 		op.Collation(bsoncore.Document(countOpts.Collation.ToDocument()))
 	}
@@ -1077,7 +1106,7 @@ func (coll *Collection) EstimatedDocumentCount(ctx context.Context,
 		Database(coll.db.name).Collection(coll.name).CommandMonitor(coll.client.monitor).
 		Deployment(coll.client.deployment).ReadConcern(rc).ReadPreference(coll.readPreference).
 		ServerSelector(selector).Crypt(coll.client.cryptFLE).ServerAPI(coll.client.serverAPI).
-		Timeout(coll.client.timeout).MaxTime(co.MaxTime)
+		Timeout(coll.timeout).MaxTime(co.MaxTime)
 
 	if co.Comment != nil {
 		comment, err := marshalValue(co.Comment, coll.bsonOpts, coll.registry)
@@ -1144,7 +1173,7 @@ func (coll *Collection) Distinct(ctx context.Context, fieldName string, filter i
 		Database(coll.db.name).Collection(coll.name).CommandMonitor(coll.client.monitor).
 		Deployment(coll.client.deployment).ReadConcern(rc).ReadPreference(coll.readPreference).
 		ServerSelector(selector).Crypt(coll.client.cryptFLE).ServerAPI(coll.client.serverAPI).
-		Timeout(coll.client.timeout).MaxTime(option.MaxTime)
+		Timeout(coll.timeout).MaxTime(option.MaxTime)
 
 	if option.Collation != nil {
 		op.Collation(bsoncore.Document(option.Collation.ToDocument()))
@@ -1249,13 +1278,22 @@ func (coll *Collection) find(
 
 	fo := options.MergeFindOptions(opts...)
 
+	timeout := coll.timeout
+	if fo.Timeout != nil {
+		timeout = fo.Timeout
+	}
+	timeoutMode, err := cursorTimeoutMode(fo.CursorType, fo.TimeoutMode)
+	if err != nil {
+		return nil, err
+	}
+
 	selector := makeReadPrefSelector(sess, coll.readSelector, coll.client.localThreshold)
 	op := operation.NewFind(f).
 		Session(sess).ReadConcern(rc).ReadPreference(coll.readPreference).
 		CommandMonitor(coll.client.monitor).ServerSelector(selector).
 		ClusterClock(coll.client.clock).Database(coll.db.name).Collection(coll.name).
 		Deployment(coll.client.deployment).Crypt(coll.client.cryptFLE).ServerAPI(coll.client.serverAPI).
-		Timeout(coll.client.timeout).MaxTime(fo.MaxTime).Logger(coll.client.logger).
+		Timeout(timeout).MaxTime(fo.MaxTime).Logger(coll.client.logger).
 		OmitCSOTMaxTimeMS(omitCSOTMaxTimeMS)
 
 	cursorOpts := coll.client.createBaseCursorOptions()
@@ -1377,6 +1415,7 @@ func (coll *Collection) find(
 	}
 	op = op.Retry(retry)
 
+	start := time.Now()
 	if err = op.Execute(ctx); err != nil {
 		return nil, replaceErrors(err)
 	}
@@ -1385,7 +1424,33 @@ func (coll *Collection) find(
 	if err != nil {
 		return nil, replaceErrors(err)
 	}
-	return newCursorWithSession(bc, coll.bsonOpts, coll.registry, sess)
+	cursor, err := newCursorWithSession(bc, coll.bsonOpts, coll.registry, sess)
+	if err != nil {
+		return nil, err
+	}
+	cursor.setTimeout(timeout, timeoutMode, start)
+	return cursor, nil
+}
+
+// errTailableAwaitCursorLifetime is returned by Find for a tailable awaitData cursor with TimeoutModeCursorLifetime.
+var errTailableAwaitCursorLifetime = errors.New(
+	"TimeoutModeCursorLifetime cannot be used with a TailableAwait cursor")
+
+// cursorTimeoutMode returns the timeout mode of a find cursor of the given type. Tailable cursors default to
+// TimeoutModeIteration, as their lifetime is unbounded, and tailable awaitData cursors cannot use
+// TimeoutModeCursorLifetime.
+func cursorTimeoutMode(cursorType *options.CursorType, mode *options.TimeoutMode) (*options.TimeoutMode, error) {
+	if cursorType == nil || *cursorType == options.NonTailable {
+		return mode, nil
+	}
+	if mode == nil {
+		iteration := options.TimeoutModeIteration
+		return &iteration, nil
+	}
+	if *cursorType == options.TailableAwait && *mode == options.TimeoutModeCursorLifetime {
+		return nil, errTailableAwaitCursorLifetime
+	}
+	return mode, nil
 }
 
 // FindOne executes a find command and returns a SingleResult for one document in the collection.
@@ -1428,6 +1493,7 @@ func (coll *Collection) FindOne(ctx context.Context, filter interface{},
 			Skip:                opt.Skip,
 			Snapshot:            opt.Snapshot,
 			Sort:                opt.Sort,
+			Timeout:             opt.Timeout,
 		})
 	}
 	// Unconditionally send a limit to make sure only one document is returned and the cursor is not kept open
@@ -1519,7 +1585,7 @@ func (coll *Collection) FindOneAndDelete(ctx context.Context, filter interface{}
 		return &SingleResult{err: err}
 	}
 	fod := options.MergeFindOneAndDeleteOptions(opts...)
-	op := operation.NewFindAndModify(f).Remove(true).ServerAPI(coll.client.serverAPI).Timeout(coll.client.timeout).
+	op := operation.NewFindAndModify(f).Remove(true).ServerAPI(coll.client.serverAPI).Timeout(coll.timeout).
 		MaxTime(fod.MaxTime)
 	if fod.Collation != nil {
 		op = op.Collation(bsoncore.Document(fod.Collation.ToDocument()))
@@ -1600,7 +1666,7 @@ func (coll *Collection) FindOneAndReplace(ctx context.Context, filter interface{
 
 	fo := options.MergeFindOneAndReplaceOptions(opts...)
 	op := operation.NewFindAndModify(f).Update(bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: r}).
-		ServerAPI(coll.client.serverAPI).Timeout(coll.client.timeout).MaxTime(fo.MaxTime)
+		ServerAPI(coll.client.serverAPI).Timeout(coll.timeout).MaxTime(fo.MaxTime)
 	if fo.BypassDocumentValidation != nil && *fo.BypassDocumentValidation {
 		op = op.BypassDocumentValidation(*fo.BypassDocumentValidation)
 	}
@@ -1686,7 +1752,7 @@ func (coll *Collection) FindOneAndUpdate(ctx context.Context, filter interface{}
 	}
 
 	fo := options.MergeFindOneAndUpdateOptions(opts...)
-	op := operation.NewFindAndModify(f).ServerAPI(coll.client.serverAPI).Timeout(coll.client.timeout).
+	op := operation.NewFindAndModify(f).ServerAPI(coll.client.serverAPI).Timeout(coll.timeout).
 		MaxTime(fo.MaxTime)
 
 	u, err := marshalUpdateValue(update, coll.bsonOpts, coll.registry, true)
@@ -1790,6 +1856,7 @@ func (coll *Collection) Watch(ctx context.Context, pipeline interface{},
 		collectionName: coll.Name(),
 		databaseName:   coll.db.Name(),
 		crypt:          coll.client.cryptFLE,
+		timeout:        coll.timeout,
 	}
 	return newChangeStream(ctx, csConfig, pipeline, opts...)
 }
@@ -1893,7 +1960,7 @@ func (coll *Collection) drop(ctx context.Context) error {
 		ServerSelector(selector).ClusterClock(coll.client.clock).
 		Database(coll.db.name).Collection(coll.name).
 		Deployment(coll.client.deployment).Crypt(coll.client.cryptFLE).
-		ServerAPI(coll.client.serverAPI).Timeout(coll.client.timeout)
+		ServerAPI(coll.client.serverAPI).Timeout(coll.timeout)
 	err = op.Execute(ctx)
 
 	// ignore namespace not found errors
@@ -9,9 +9,11 @@ package mongo
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/bson"
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/require"
 	"github.com/hongyuyang/mongo-go-driver/mongo/options"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
@@ -75,6 +77,55 @@ func TestCollection(t *testing.T) {
 		}
 		compareColls(t, expected, coll)
 	})
+	t.Run("inherit timeout", func(t *testing.T) {
+		client := setupClient(options.Client().SetTimeout(time.Second))
+		db := client.Database("foo")
+		assert.Equal(t, time.Second, *db.Timeout(), "expected database to inherit client timeout, got %v", *db.Timeout())
+
+		db = client.Database("foo", options.Database().SetTimeout(2*time.Second))
+		coll := db.Collection("bar")
+		assert.Equal(t, 2*time.Second, *coll.Timeout(), "expected collection to inherit database timeout, got %v",
+			*coll.Timeout())
+
+		coll = db.Collection("bar", options.Collection().SetTimeout(0))
+		assert.Equal(t, time.Duration(0), *coll.Timeout(), "expected collection timeout 0, got %v", *coll.Timeout())
+
+		clone, err := coll.Clone(options.Collection().SetTimeout(3 * time.Second))
+		require.NoError(t, err, "Clone error")
+		assert.Equal(t, 3*time.Second, *clone.Timeout(), "expected cloned collection timeout, got %v", *clone.Timeout())
+		assert.Equal(t, time.Duration(0), *coll.Timeout(), "expected original collection timeout 0, got %v",
+			*coll.Timeout())
+	})
+	t.Run("tailable cursor timeout mode", func(t *testing.T) {
+		lifetime := options.TimeoutModeCursorLifetime
+		iteration := options.TimeoutModeIteration
+		testCases := []struct {
+			name       string
+			cursorType options.CursorType
+			mode       *options.TimeoutMode
+			want       *options.TimeoutMode
+			err        error
+		}{
+			{"non-tailable default", options.NonTailable, nil, nil, nil},
+			{"tailable default", options.Tailable, nil, &iteration, nil},
+			{"tailable await default", options.TailableAwait, nil, &iteration, nil},
+			{"tailable cursor lifetime", options.Tailable, &lifetime, &lifetime, nil},
+			{"tailable await cursor lifetime", options.TailableAwait, &lifetime, nil, errTailableAwaitCursorLifetime},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, err := cursorTimeoutMode(&tc.cursorType, tc.mode)
+				assert.Equal(t, tc.err, err, "expected error %v, got %v", tc.err, err)
+				assert.Equal(t, tc.want, got, "expected timeout mode %v, got %v", tc.want, got)
+			})
+		}
+
+		coll := setupColl("foo", options.Collection().SetTimeout(time.Second))
+		opts := options.Find().SetCursorType(options.TailableAwait).SetTimeoutMode(lifetime)
+		_, err := coll.Find(bgCtx, bson.D{}, opts)
+		assert.Equal(t, errTailableAwaitCursorLifetime, err, "expected error %v, got %v",
+			errTailableAwaitCursorLifetime, err)
+	})
 	t.Run("replace topology error", func(t *testing.T) {
 		coll := setupColl("foo")
 		doc := bson.D{}
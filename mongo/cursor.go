@@ -17,6 +17,7 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/bson"
 	"github.com/hongyuyang/mongo-go-driver/bson/bsoncodec"
 	"github.com/hongyuyang/mongo-go-driver/bson/bsonrw"
+	"github.com/hongyuyang/mongo-go-driver/internal/csot"
 	"github.com/hongyuyang/mongo-go-driver/mongo/options"
 	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
@@ -38,6 +39,12 @@ type Cursor struct {
 	registry      *bsoncodec.Registry
 	clientSession *session.Client
 
+	// timeout is the timeout of the operation that created the cursor. With TimeoutModeCursorLifetime, it applies to
+	// the whole lifetime of the cursor, which ends at deadline. With TimeoutModeIteration, it applies to each call.
+	timeout     *time.Duration
+	timeoutMode options.TimeoutMode
+	deadline    time.Time
+
 	err error
 }
 
@@ -77,6 +84,39 @@ func newCursorWithSession(
 	return c, nil
 }
 
+// setTimeout configures the cursor to honor the timeout of the operation that created it, which started at start. A
+// nil or zero timeout means no timeout.
+func (c *Cursor) setTimeout(timeout *time.Duration, mode *options.TimeoutMode, start time.Time) {
+	if timeout == nil || *timeout == 0 {
+		return
+	}
+
+	c.timeout = timeout
+	c.timeoutMode = options.TimeoutModeCursorLifetime
+	if mode != nil {
+		c.timeoutMode = *mode
+	}
+	c.deadline = start.Add(*timeout)
+}
+
+// timeoutContext returns the context used to iterate the cursor. If the cursor has a timeout and ctx is not already a
+// Timeout context, the returned context expires at the end of the cursor lifetime or after the timeout, depending on
+// the timeout mode. If the cursor lifetime has already ended, the returned context is expired, so iterating the cursor
+// returns an error for which IsTimeout returns true. The returned cancel function must be called to avoid a context
+// leak.
+func (c *Cursor) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout == nil || csot.IsTimeoutContext(ctx) {
+		return ctx, func() {}
+	}
+	if c.timeoutMode == options.TimeoutModeIteration {
+		return csot.MakeTimeoutContext(ctx, *c.timeout)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, c.deadline)
+	ctx, _ = csot.MakeTimeoutContext(ctx, 0)
+	return ctx, cancel
+}
+
 func newEmptyCursor() *Cursor {
 	return &Cursor{bc: driver.NewEmptyBatchCursor()}
 }
@@ -172,6 +212,9 @@ func (c *Cursor) next(ctx context.Context, nonBlocking bool) bool {
 		return false
 	}
 
+	ctx, cancel := c.timeoutContext(ctx)
+	defer cancel()
+
 	// call the Next method in a loop until at least one document is returned in the next batch or
 	// the context times out.
 	for {
@@ -280,8 +323,17 @@ func (c *Cursor) Err() error { return c.err }
 
 // Close closes this cursor. Next and TryNext must not be called after Close has been called. Close is idempotent. After
 // the first call, any subsequent calls will not change the state.
+//
+// If the operation that created the cursor has a timeout, it applies to Close separately from the iteration of the
+// cursor, so that the cursor can be closed after its lifetime has ended.
 func (c *Cursor) Close(ctx context.Context) error {
 	defer c.closeImplicitSession()
+
+	if c.timeout != nil && !csot.IsTimeoutContext(ctx) {
+		var cancel context.CancelFunc
+		ctx, cancel = csot.MakeTimeoutContext(ctx, *c.timeout)
+		defer cancel()
+	}
 	return replaceErrors(c.bc.Close(ctx))
 }
 
@@ -314,6 +366,9 @@ func (c *Cursor) All(ctx context.Context, results interface{}) error {
 	// completes even if the context passed to All has errored.
 	defer c.Close(context.Background())
 
+	ctx, cancel := c.timeoutContext(ctx)
+	defer cancel()
+
 	batch := c.batch // exhaust the current batch before iterating the batch cursor
 	for {
 		sliceVal, index, err = c.addFromBatch(sliceVal, elementType, batch, index)
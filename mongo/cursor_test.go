@@ -207,6 +207,82 @@ func TestCursor(t *testing.T) {
 			assert.Equal(t, want, got, "expected and actual All results are different")
 		})
 	})
+	t.Run("timeout", func(t *testing.T) {
+		timeout := 50 * time.Millisecond
+		expired := time.Now().Add(-time.Second)
+
+		t.Run("cursor lifetime", func(t *testing.T) {
+			tbc := &contextBatchCursor{testBatchCursor: newTestBatchCursor(2, 1)}
+			cursor, err := newCursor(tbc, nil, nil)
+			require.NoError(t, err, "newCursor error")
+			cursor.setTimeout(&timeout, nil, expired)
+
+			assert.True(t, cursor.Next(context.Background()), "expected Next to return the first batch")
+			assert.False(t, cursor.Next(context.Background()), "expected Next to fail after the cursor lifetime")
+			assert.True(t, IsTimeout(cursor.Err()), "expected a timeout error, got %v", cursor.Err())
+
+			err = cursor.Close(context.Background())
+			require.NoError(t, err, "Close error")
+			assert.NoError(t, tbc.closeErr, "expected Close to use a fresh timeout, got %v", tbc.closeErr)
+		})
+		t.Run("iteration", func(t *testing.T) {
+			tbc := &contextBatchCursor{testBatchCursor: newTestBatchCursor(2, 1)}
+			cursor, err := newCursor(tbc, nil, nil)
+			require.NoError(t, err, "newCursor error")
+			mode := options.TimeoutModeIteration
+			cursor.setTimeout(&timeout, &mode, expired)
+
+			for i := 0; i < 2; i++ {
+				assert.True(t, cursor.Next(context.Background()), "expected Next to return batch %d", i)
+				deadline, ok := tbc.ctx.Deadline()
+				assert.True(t, ok, "expected iteration context to have a deadline")
+				assert.True(t, time.Until(deadline) > 0, "expected a fresh deadline, got %v", deadline)
+			}
+			assert.NoError(t, cursor.Err(), "expected no error, got %v", cursor.Err())
+		})
+		t.Run("no timeout", func(t *testing.T) {
+			tbc := &contextBatchCursor{testBatchCursor: newTestBatchCursor(2, 1)}
+			cursor, err := newCursor(tbc, nil, nil)
+			require.NoError(t, err, "newCursor error")
+			zero := time.Duration(0)
+			cursor.setTimeout(&zero, nil, expired)
+
+			assert.True(t, cursor.Next(context.Background()), "expected Next to return the first batch")
+			assert.True(t, cursor.Next(context.Background()), "expected Next to return the second batch")
+			_, ok := tbc.ctx.Deadline()
+			assert.False(t, ok, "expected no deadline without a timeout")
+		})
+	})
+}
+
+// contextBatchCursor is a testBatchCursor that records the contexts it is called with. Like driver.BatchCursor, it
+// returns the first batch without a round trip and fails to get the following batches if the context is done.
+type contextBatchCursor struct {
+	*testBatchCursor
+	ctx      context.Context
+	calls    int
+	err      error
+	closeErr error
+}
+
+func (cbc *contextBatchCursor) Next(ctx context.Context) bool {
+	cbc.ctx = ctx
+	cbc.calls++
+	if cbc.calls > 1 {
+		if cbc.err = ctx.Err(); cbc.err != nil {
+			return false
+		}
+	}
+	return cbc.testBatchCursor.Next(ctx)
+}
+
+func (cbc *contextBatchCursor) Err() error {
+	return cbc.err
+}
+
+func (cbc *contextBatchCursor) Close(ctx context.Context) error {
+	cbc.closeErr = ctx.Err()
+	return cbc.testBatchCursor.Close(ctx)
 }
 
 func TestNewCursorFromDocuments(t *testing.T) {
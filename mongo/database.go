@@ -41,6 +41,7 @@ type Database struct {
 	writeSelector  description.ServerSelector
 	bsonOpts       *options.BSONOptions
 	registry       *bsoncodec.Registry
+	timeout        *time.Duration
 }
 
 func newDatabase(client *Client, name string, opts ...*options.DatabaseOptions) *Database {
@@ -71,6 +72,11 @@ func newDatabase(client *Client, name string, opts ...*options.DatabaseOptions)
 		reg = dbOpt.Registry
 	}
 
+	timeout := client.timeout
+	if dbOpt.Timeout != nil {
+		timeout = dbOpt.Timeout
+	}
+
 	db := &Database{
 		client:         client,
 		name:           name,
@@ -79,6 +85,7 @@ func newDatabase(client *Client, name string, opts ...*options.DatabaseOptions)
 		writeConcern:   wc,
 		bsonOpts:       bsonOpts,
 		registry:       reg,
+		timeout:        timeout,
 	}
 
 	db.readSelector = description.CompositeSelector([]description.ServerSelector{
@@ -135,6 +142,7 @@ func (db *Database) Aggregate(ctx context.Context, pipeline interface{},
 		readSelector:   db.readSelector,
 		writeSelector:  db.writeSelector,
 		readPreference: db.readPreference,
+		timeout:        db.timeout,
 		opts:           opts,
 	}
 	return aggregate(a)
@@ -189,7 +197,7 @@ func (db *Database) processRunCommand(ctx context.Context, cmd interface{},
 		ServerSelector(readSelect).ClusterClock(db.client.clock).
 		Database(db.name).Deployment(db.client.deployment).
 		Crypt(db.client.cryptFLE).ReadPreference(ro.ReadPreference).ServerAPI(db.client.serverAPI).
-		Timeout(db.client.timeout).Logger(db.client.logger), sess, nil
+		Timeout(db.timeout).Logger(db.client.logger), sess, nil
 }
 
 // RunCommand executes the given command against the database.
@@ -402,7 +410,7 @@ func (db *Database) ListCollections(ctx context.Context, filter interface{}, opt
 		Session(sess).ReadPreference(db.readPreference).CommandMonitor(db.client.monitor).
 		ServerSelector(selector).ClusterClock(db.client.clock).
 		Database(db.name).Deployment(db.client.deployment).Crypt(db.client.cryptFLE).
-		ServerAPI(db.client.serverAPI).Timeout(db.client.timeout)
+		ServerAPI(db.client.serverAPI).Timeout(db.timeout)
 
 	cursorOpts := db.client.createBaseCursorOptions()
 
@@ -498,6 +506,11 @@ func (db *Database) WriteConcern() *writeconcern.WriteConcern {
 	return db.writeConcern
 }
 
+// Timeout returns the timeout used to configure the Database object.
+func (db *Database) Timeout() *time.Duration {
+	return db.timeout
+}
+
 // Watch returns a change stream for all changes to the corresponding database. See
 // https://www.mongodb.com/docs/manual/changeStreams/ for more information about change streams.
 //
@@ -522,6 +535,7 @@ func (db *Database) Watch(ctx context.Context, pipeline interface{},
 		streamType:     DatabaseStream,
 		databaseName:   db.Name(),
 		crypt:          db.client.cryptFLE,
+		timeout:        db.timeout,
 	}
 	return newChangeStream(ctx, csConfig, pipeline, opts...)
 }
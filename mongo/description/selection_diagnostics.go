@@ -0,0 +1,146 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package description
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+)
+
+// ServerRejection describes why server selection rejected a server.
+type ServerRejection struct {
+	Addr   address.Address
+	Reason string
+}
+
+// String implements the Stringer interface.
+func (r ServerRejection) String() string {
+	return fmt.Sprintf("%s: %s", r.Addr, r.Reason)
+}
+
+// selectionRecorder records the reasons servers are rejected during server selection. The methods of a nil
+// selectionRecorder are no-ops, so selectors only pay for the recording when diagnosing a selection.
+type selectionRecorder struct {
+	addrs   []address.Address
+	reasons map[address.Address][]string
+}
+
+// reject records a reason the given server was rejected. Servers rejected by several steps of a selection, such as
+// a secondary that is neither a primary nor fresh enough for a PrimaryPreferred read, keep all of their reasons.
+func (r *selectionRecorder) reject(s Server, format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+
+	reason := fmt.Sprintf(format, args...)
+	reasons, ok := r.reasons[s.Addr]
+	if !ok {
+		r.addrs = append(r.addrs, s.Addr)
+	}
+	for _, existing := range reasons {
+		if existing == reason {
+			return
+		}
+	}
+	r.reasons[s.Addr] = append(reasons, reason)
+}
+
+// rejectAll records the same reason for all of the given servers.
+func (r *selectionRecorder) rejectAll(servers []Server, reason string) {
+	for _, s := range servers {
+		r.reject(s, "%s", reason)
+	}
+}
+
+// rejectKinds records a wrong kind reason for the servers that are not of one of the given kinds.
+func (r *selectionRecorder) rejectKinds(servers []Server, kinds ...ServerKind) {
+	if r == nil {
+		return
+	}
+
+	want := make([]string, len(kinds))
+	for i, kind := range kinds {
+		want[i] = kind.String()
+	}
+
+	for _, s := range servers {
+		matched := false
+		for _, kind := range kinds {
+			if s.Kind == kind {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			r.reject(s, "wrong kind %s, want %s", s.Kind, strings.Join(want, " or "))
+		}
+	}
+}
+
+// rejectRemoved records a reason for the candidates that the selector removed without recording one itself, which
+// is the case for selectors defined outside of this package.
+func (r *selectionRecorder) rejectRemoved(selector ServerSelector, candidates, selected []Server) {
+	if r == nil {
+		return
+	}
+
+	reason := "rejected by server selector"
+	if stringer, ok := selector.(fmt.Stringer); ok && stringer.String() != "" {
+		reason += " " + stringer.String()
+	}
+
+	for _, c := range candidates {
+		if _, ok := r.reasons[c.Addr]; ok || containsServer(selected, c.Addr) {
+			continue
+		}
+		r.reject(c, "%s", reason)
+	}
+}
+
+// rejections returns the recorded rejections in the order the servers were first rejected, skipping the servers
+// that were eventually selected.
+func (r *selectionRecorder) rejections(selected []Server) []ServerRejection {
+	var rejections []ServerRejection
+	for _, addr := range r.addrs {
+		if containsServer(selected, addr) {
+			continue
+		}
+		rejections = append(rejections, ServerRejection{
+			Addr:   addr,
+			Reason: strings.Join(r.reasons[addr], "; "),
+		})
+	}
+	return rejections
+}
+
+func containsServer(servers []Server, addr address.Address) bool {
+	for _, s := range servers {
+		if s.Addr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// DiagnoseSelection runs the selector against the candidates in the same way as SelectServer and additionally returns
+// the reasons each rejected candidate was not selected. Candidates rejected by selectors that are not defined in
+// this package are reported as rejected by that selector.
+func DiagnoseSelection(selector ServerSelector, t Topology, candidates []Server) ([]Server, []ServerRejection, error) {
+	rec := &selectionRecorder{reasons: make(map[address.Address][]string)}
+	t.recorder = rec
+
+	selected, err := selector.SelectServer(t, candidates)
+	if err != nil {
+		rec.rejectAll(candidates, err.Error())
+		return nil, rec.rejections(nil), err
+	}
+	rec.rejectRemoved(selector, candidates, selected)
+
+	return selected, rec.rejections(selected), nil
+}
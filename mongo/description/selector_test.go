@@ -872,3 +872,205 @@ func TestSelector_Max_staleness_is_too_low(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestSelector_CircuitOpen(t *testing.T) {
+	t.Parallel()
+
+	primary := readPrefTestPrimary
+	primary.CircuitOpen = true
+	secondary := readPrefTestSecondary1
+	secondary.CircuitOpen = true
+	c := Topology{
+		Kind:    ReplicaSetWithPrimary,
+		Servers: []Server{primary, secondary, readPrefTestSecondary2},
+	}
+
+	t.Run("reads skip servers whose circuit breaker is open", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := ReadPrefSelector(readpref.Nearest()).SelectServer(c, c.Servers)
+
+		require.NoError(t, err)
+		require.Equal(t, []Server{readPrefTestSecondary2}, result)
+	})
+	t.Run("reads select no server if all circuit breakers are open", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := ReadPrefSelector(readpref.PrimaryPreferred()).SelectServer(c, []Server{primary, secondary})
+
+		require.NoError(t, err)
+		require.Len(t, result, 0)
+	})
+	t.Run("primary reads select the primary if its circuit breaker is open", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := ReadPrefSelector(readpref.Primary()).SelectServer(c, c.Servers)
+
+		require.NoError(t, err)
+		require.Equal(t, []Server{primary}, result)
+	})
+	t.Run("reads to a single server select it if its circuit breaker is open", func(t *testing.T) {
+		t.Parallel()
+
+		single := Topology{Kind: Single, Servers: []Server{secondary}}
+		result, err := ReadPrefSelector(readpref.Secondary()).SelectServer(single, single.Servers)
+
+		require.NoError(t, err)
+		require.Equal(t, []Server{secondary}, result)
+	})
+	t.Run("writes select servers whose circuit breaker is open", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := WriteSelector().SelectServer(c, c.Servers)
+
+		require.NoError(t, err)
+		require.Equal(t, []Server{primary}, result)
+	})
+	t.Run("aggregates with an output stage select servers whose circuit breaker is open", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := OutputAggregateSelector(readpref.Primary()).SelectServer(c, c.Servers)
+
+		require.NoError(t, err)
+		require.Equal(t, []Server{primary}, result)
+	})
+}
+
+func TestDiagnoseSelection(t *testing.T) {
+	t.Parallel()
+
+	primary := Server{
+		Addr:              address.Address("localhost:27017"),
+		HeartbeatInterval: 10 * time.Second,
+		LastWriteTime:     time.Date(2017, 2, 11, 14, 0, 0, 0, time.UTC),
+		LastUpdateTime:    time.Date(2017, 2, 11, 14, 0, 2, 0, time.UTC),
+		Kind:              RSPrimary,
+		WireVersion:       &VersionRange{Min: 6, Max: 21},
+	}
+	fresh := Server{
+		Addr:              address.Address("localhost:27018"),
+		HeartbeatInterval: 10 * time.Second,
+		LastWriteTime:     time.Date(2017, 2, 11, 14, 0, 0, 0, time.UTC),
+		LastUpdateTime:    time.Date(2017, 2, 11, 14, 0, 2, 0, time.UTC),
+		Kind:              RSSecondary,
+		Tags:              tag.Set{tag.Tag{Name: "dc", Value: "east"}},
+		WireVersion:       &VersionRange{Min: 6, Max: 21},
+	}
+	stale := Server{
+		Addr:              address.Address("localhost:27019"),
+		HeartbeatInterval: 10 * time.Second,
+		LastWriteTime:     time.Date(2017, 2, 11, 13, 58, 0, 0, time.UTC),
+		LastUpdateTime:    time.Date(2017, 2, 11, 14, 0, 2, 0, time.UTC),
+		Kind:              RSSecondary,
+		Tags:              tag.Set{tag.Tag{Name: "dc", Value: "west"}},
+		WireVersion:       &VersionRange{Min: 6, Max: 21},
+	}
+	topo := Topology{
+		Kind:    ReplicaSetWithPrimary,
+		Servers: []Server{primary, fresh, stale},
+	}
+	var selectNone ServerSelectorFunc = func(Topology, []Server) ([]Server, error) {
+		return nil, nil
+	}
+
+	testCases := []struct {
+		name     string
+		selector ServerSelector
+		selected []Server
+		want     []ServerRejection
+	}{
+		{
+			name:     "wrong kind",
+			selector: ReadPrefSelector(readpref.Primary()),
+			selected: []Server{primary},
+			want: []ServerRejection{
+				{Addr: fresh.Addr, Reason: "wrong kind RSSecondary, want RSPrimary"},
+				{Addr: stale.Addr, Reason: "wrong kind RSSecondary, want RSPrimary"},
+			},
+		},
+		{
+			name:     "max staleness",
+			selector: ReadPrefSelector(readpref.Secondary(readpref.WithMaxStaleness(90 * time.Second))),
+			selected: []Server{fresh},
+			want: []ServerRejection{
+				{Addr: primary.Addr, Reason: "wrong kind RSPrimary, want RSSecondary"},
+				{Addr: stale.Addr, Reason: "stale by 2m10s, max staleness 1m30s"},
+			},
+		},
+		{
+			name: "tag mismatch",
+			selector: ReadPrefSelector(readpref.Nearest(
+				readpref.WithTagSets(tag.NewTagSetsFromMaps([]map[string]string{{"dc": "east"}})...),
+			)),
+			selected: []Server{fresh},
+			want: []ServerRejection{
+				{Addr: primary.Addr, Reason: "tag mismatch: tags [] do not match tag set [dc=east]"},
+				{Addr: stale.Addr, Reason: "tag mismatch: tags [dc=west] do not match tag set [dc=east]"},
+			},
+		},
+		{
+			name: "secondary preferred falls back to primary",
+			selector: ReadPrefSelector(readpref.SecondaryPreferred(
+				readpref.WithTagSets(tag.NewTagSetsFromMaps([]map[string]string{{"dc": "north"}})...),
+			)),
+			selected: []Server{primary},
+			want: []ServerRejection{
+				{Addr: fresh.Addr, Reason: "tag mismatch: tags [dc=east] do not match any tag set"},
+				{Addr: stale.Addr, Reason: "tag mismatch: tags [dc=west] do not match any tag set"},
+			},
+		},
+		{
+			name:     "custom selector",
+			selector: CompositeSelector([]ServerSelector{ReadPrefSelector(readpref.Secondary()), selectNone}),
+			selected: nil,
+			want: []ServerRejection{
+				{Addr: primary.Addr, Reason: "wrong kind RSPrimary, want RSSecondary"},
+				{Addr: fresh.Addr, Reason: "rejected by server selector"},
+				{Addr: stale.Addr, Reason: "rejected by server selector"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			selected, rejections, err := DiagnoseSelection(tc.selector, topo, topo.Servers)
+			require.NoError(t, err)
+			assert.Equal(t, tc.selected, selected, "unexpected selected servers")
+			assert.Equal(t, tc.want, rejections, "unexpected rejections")
+
+			want, err := tc.selector.SelectServer(topo, topo.Servers)
+			require.NoError(t, err)
+			assert.Equal(t, want, selected, "expected the same servers as SelectServer")
+		})
+	}
+
+	t.Run("latency window", func(t *testing.T) {
+		t.Parallel()
+
+		near := Server{Addr: address.Address("localhost:27017"), Kind: Mongos, AverageRTT: 5 * time.Millisecond, AverageRTTSet: true}
+		far := Server{Addr: address.Address("localhost:27018"), Kind: Mongos, AverageRTT: 50 * time.Millisecond, AverageRTTSet: true}
+		unmeasured := Server{Addr: address.Address("localhost:27019"), Kind: Mongos}
+		sharded := Topology{Kind: Sharded, Servers: []Server{near, far, unmeasured}}
+
+		selected, rejections, err := DiagnoseSelection(LatencySelector(15*time.Millisecond), sharded, sharded.Servers)
+		require.NoError(t, err)
+		assert.Equal(t, []Server{near}, selected, "unexpected selected servers")
+		assert.Equal(t, []ServerRejection{
+			{Addr: far.Addr, Reason: "outside latency window: average RTT 50ms exceeds 20ms"},
+			{Addr: unmeasured.Addr, Reason: "outside latency window: no average RTT"},
+		}, rejections, "unexpected rejections")
+	})
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		selector := ReadPrefSelector(readpref.Secondary(readpref.WithMaxStaleness(30 * time.Second)))
+		_, rejections, err := DiagnoseSelection(selector, topo, topo.Servers)
+		require.Error(t, err)
+		assert.Equal(t, 3, len(rejections), "expected all servers to be rejected, got %v", rejections)
+		assert.Equal(t, err.Error(), rejections[0].Reason, "expected the error to be the rejection reason")
+	})
+}
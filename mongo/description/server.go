@@ -38,7 +38,9 @@ type Server struct {
 	AverageRTTSet     bool
 	Compression       []string // compression methods returned by server
 	CanonicalAddr     address.Address
+	CircuitOpen       bool // removed from read selection by the circuit breaker of the server
 	ElectionID        primitive.ObjectID
+	Excluded          bool // excluded from server selection by the application
 	HeartbeatInterval time.Duration
 	HelloOK           bool
 	Hosts             []string
@@ -50,6 +52,8 @@ type Server struct {
 	MaxDocumentSize   uint32
 	MaxMessageSize    uint32
 	Members           []address.Address
+	MinRTT            time.Duration // minimum RTT observed by the RTT monitor, 0 if not enough samples
+	P90RTT            time.Duration // 90th percentile RTT observed by the RTT monitor, 0 if not enough samples
 	Passives          []string
 	Passive           bool
 	Primary           address.Address
@@ -389,6 +393,14 @@ func (s Server) String() string {
 	if s.LastError != nil {
 		str += fmt.Sprintf(", Last error: %s", s.LastError)
 	}
+
+	if s.Excluded {
+		str += ", Excluded"
+	}
+
+	if s.CircuitOpen {
+		str += ", Circuit open"
+	}
 	return str
 }
 
@@ -443,6 +455,14 @@ func (s Server) Equal(other Server) bool {
 		return false
 	}
 
+	if s.Excluded != other.Excluded {
+		return false
+	}
+
+	if s.CircuitOpen != other.CircuitOpen {
+		return false
+	}
+
 	if s.LastError != nil || other.LastError != nil {
 		if s.LastError == nil || other.LastError == nil {
 			return false
@@ -88,12 +88,13 @@ func CompositeSelector(selectors []ServerSelector) ServerSelector {
 }
 
 func (cs *compositeSelector) SelectServer(t Topology, candidates []Server) ([]Server, error) {
-	var err error
 	for _, sel := range cs.selectors {
-		candidates, err = sel.SelectServer(t, candidates)
+		selected, err := sel.SelectServer(t, candidates)
 		if err != nil {
 			return nil, err
 		}
+		t.recorder.rejectRemoved(sel, candidates, selected)
+		candidates = selected
 	}
 	return candidates, nil
 }
@@ -145,10 +146,13 @@ func (selector *latencySelector) SelectServer(t Topology, candidates []Server) (
 
 		viableIndexes := make([]int, 0, len(candidates))
 		for i, candidate := range candidates {
-			if candidate.AverageRTTSet {
-				if candidate.AverageRTT <= max {
-					viableIndexes = append(viableIndexes, i)
-				}
+			switch {
+			case !candidate.AverageRTTSet:
+				t.recorder.reject(candidate, "outside latency window: no average RTT")
+			case candidate.AverageRTT > max:
+				t.recorder.reject(candidate, "outside latency window: average RTT %s exceeds %s", candidate.AverageRTT, max)
+			default:
+				viableIndexes = append(viableIndexes, i)
 			}
 		}
 		if len(viableIndexes) == len(candidates) {
@@ -197,6 +201,8 @@ func (writeServerSelector) SelectServer(t Topology, candidates []Server) ([]Serv
 			switch candidate.Kind {
 			case Mongos, RSPrimary, Standalone:
 				result = append(result, candidate)
+			default:
+				t.recorder.reject(candidate, "not writable: kind %s", candidate.Kind)
 			}
 		}
 		return result, nil
@@ -235,12 +241,23 @@ func (selector readPrefServerSelector) SelectServer(t Topology, candidates []Ser
 		return candidates, nil
 	}
 
+	// Servers whose circuit breaker is open are not selected for the reads that can be sent to other servers.
+	// Aggregates with an output stage are writes and primary reads to a replica set can only be sent to the primary, so
+	// they still select them.
+	switch {
+	case selector.isOutputAggregate, t.Kind == Single:
+	case t.Kind != Sharded && selector.rp.Mode() == readpref.PrimaryMode:
+	default:
+		candidates = selectClosedCircuits(candidates, t.recorder)
+	}
+
 	switch t.Kind {
 	case Single:
 		return candidates, nil
 	case ReplicaSetNoPrimary, ReplicaSetWithPrimary:
 		return selectForReplicaSet(selector.rp, selector.isOutputAggregate, t, candidates)
 	case Sharded:
+		t.recorder.rejectKinds(candidates, Mongos)
 		return selectByKind(candidates, Mongos), nil
 	}
 
@@ -266,43 +283,52 @@ func selectForReplicaSet(rp *readpref.ReadPref, isOutputAggregate bool, t Topolo
 	if isOutputAggregate {
 		for _, s := range candidates {
 			if s.WireVersion.Max < 13 {
+				t.recorder.rejectKinds(candidates, RSPrimary)
 				return selectByKind(candidates, RSPrimary), nil
 			}
 		}
 	}
 
+	// Kind rejections are only recorded once it is known which kinds the mode ends up selecting, so that a secondary
+	// read by a PrimaryPreferred operation without a primary isn't reported as not being a primary.
 	switch rp.Mode() {
 	case readpref.PrimaryMode:
+		t.recorder.rejectKinds(candidates, RSPrimary)
 		return selectByKind(candidates, RSPrimary), nil
 	case readpref.PrimaryPreferredMode:
 		selected := selectByKind(candidates, RSPrimary)
 
 		if len(selected) == 0 {
-			selected = selectSecondaries(rp, candidates)
-			return selectByTagSet(selected, rp.TagSets()), nil
+			t.recorder.rejectKinds(candidates, RSSecondary)
+			selected = selectSecondaries(rp, candidates, t.recorder)
+			return selectByTagSet(selected, rp.TagSets(), t.recorder), nil
 		}
 
+		t.recorder.rejectKinds(candidates, RSPrimary)
 		return selected, nil
 	case readpref.SecondaryPreferredMode:
-		selected := selectSecondaries(rp, candidates)
-		selected = selectByTagSet(selected, rp.TagSets())
+		t.recorder.rejectKinds(candidates, RSSecondary)
+		selected := selectSecondaries(rp, candidates, t.recorder)
+		selected = selectByTagSet(selected, rp.TagSets(), t.recorder)
 		if len(selected) > 0 {
 			return selected, nil
 		}
 		return selectByKind(candidates, RSPrimary), nil
 	case readpref.SecondaryMode:
-		selected := selectSecondaries(rp, candidates)
-		return selectByTagSet(selected, rp.TagSets()), nil
+		t.recorder.rejectKinds(candidates, RSSecondary)
+		selected := selectSecondaries(rp, candidates, t.recorder)
+		return selectByTagSet(selected, rp.TagSets(), t.recorder), nil
 	case readpref.NearestMode:
+		t.recorder.rejectKinds(candidates, RSPrimary, RSSecondary)
 		selected := selectByKind(candidates, RSPrimary)
-		selected = append(selected, selectSecondaries(rp, candidates)...)
-		return selectByTagSet(selected, rp.TagSets()), nil
+		selected = append(selected, selectSecondaries(rp, candidates, t.recorder)...)
+		return selectByTagSet(selected, rp.TagSets(), t.recorder), nil
 	}
 
 	return nil, fmt.Errorf("unsupported mode: %d", rp.Mode())
 }
 
-func selectSecondaries(rp *readpref.ReadPref, candidates []Server) []Server {
+func selectSecondaries(rp *readpref.ReadPref, candidates []Server, rec *selectionRecorder) []Server {
 	secondaries := selectByKind(candidates, RSSecondary)
 	if len(secondaries) == 0 {
 		return secondaries
@@ -322,6 +348,8 @@ func selectSecondaries(rp *readpref.ReadPref, candidates []Server) []Server {
 				estimatedStaleness := baseTime.Sub(secondary.LastWriteTime) + secondary.HeartbeatInterval
 				if estimatedStaleness <= maxStaleness {
 					selected = append(selected, secondary)
+				} else {
+					rec.reject(secondary, "stale by %s, max staleness %s", estimatedStaleness, maxStaleness)
 				}
 			}
 
@@ -335,6 +363,8 @@ func selectSecondaries(rp *readpref.ReadPref, candidates []Server) []Server {
 			estimatedStaleness := secondary.LastUpdateTime.Sub(secondary.LastWriteTime) - primary.LastUpdateTime.Sub(primary.LastWriteTime) + secondary.HeartbeatInterval
 			if estimatedStaleness <= maxStaleness {
 				selected = append(selected, secondary)
+			} else {
+				rec.reject(secondary, "stale by %s, max staleness %s", estimatedStaleness, maxStaleness)
 			}
 		}
 		return selected
@@ -343,7 +373,7 @@ func selectSecondaries(rp *readpref.ReadPref, candidates []Server) []Server {
 	return secondaries
 }
 
-func selectByTagSet(candidates []Server, tagSets []tag.Set) []Server {
+func selectByTagSet(candidates []Server, tagSets []tag.Set, rec *selectionRecorder) []Server {
 	if len(tagSets) == 0 {
 		return candidates
 	}
@@ -364,13 +394,46 @@ func selectByTagSet(candidates []Server, tagSets []tag.Set) []Server {
 		}
 
 		if len(results) > 0 {
+			if rec != nil {
+				for _, s := range candidates {
+					if !containsServer(results, s.Addr) {
+						rec.reject(s, "tag mismatch: tags [%s] do not match tag set [%s]", s.Tags, ts)
+					}
+				}
+			}
 			return results
 		}
 	}
 
+	for _, s := range candidates {
+		rec.reject(s, "tag mismatch: tags [%s] do not match any tag set", s.Tags)
+	}
 	return []Server{}
 }
 
+// selectClosedCircuits returns the candidates whose circuit breaker is not open.
+func selectClosedCircuits(candidates []Server, rec *selectionRecorder) []Server {
+	open := 0
+	for _, s := range candidates {
+		if s.CircuitOpen {
+			open++
+		}
+	}
+	if open == 0 {
+		return candidates
+	}
+
+	closed := make([]Server, 0, len(candidates)-open)
+	for _, s := range candidates {
+		if s.CircuitOpen {
+			rec.reject(s, "circuit breaker open")
+			continue
+		}
+		closed = append(closed, s)
+	}
+	return closed
+}
+
 func selectByKind(candidates []Server, kind ServerKind) []Server {
 	// Record the indices of viable candidates first and then append those to the returned slice
 	// to avoid appending costly Server structs directly as an optimization.
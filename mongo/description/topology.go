@@ -21,6 +21,9 @@ type Topology struct {
 	SessionTimeoutMinutes    uint32
 	SessionTimeoutMinutesPtr *int64
 	CompatibilityErr         error
+
+	// recorder records the reasons servers are rejected while diagnosing a server selection.
+	recorder *selectionRecorder
 }
 
 // String implements the Stringer interface.
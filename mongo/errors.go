@@ -27,6 +27,12 @@ var ErrUnacknowledgedWrite = errors.New("unacknowledged write")
 // ErrClientDisconnected is returned when disconnected Client is used to run an operation.
 var ErrClientDisconnected = errors.New("client is disconnected")
 
+// ErrOverloaded is wrapped by the errors of operations rejected without being sent because the number of operations in
+// progress on the server reached its concurrency limit. It is only returned if load shedding is enabled with
+// options.ClientOptions.SetLoadShedding. The errors are CommandErrors labeled "SystemOverloadedError" and
+// "RetryableError", like the errors of commands rejected by an overloaded server.
+var ErrOverloaded = driver.ErrOverloaded
+
 // ErrNilDocument is returned when a nil document is passed to a CRUD method.
 var ErrNilDocument = errors.New("document is nil")
 
@@ -0,0 +1,278 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package faultinject injects faults into the commands and heartbeats of a client to test how an application behaves
+// when a deployment is slow or failing, without the server-side failCommand fail point.
+//
+// An Injector holds a list of rules. Each command or heartbeat is checked against the rules in the order they were
+// added, and the first matching rule is applied:
+//
+//	injector := faultinject.New(faultinject.Rule{
+//		CommandNames: []string{"find"},
+//		Namespace:    "db.coll",
+//		Error:        &faultinject.ServerError{Code: 91, Labels: []string{"RetryableWriteError"}},
+//		Rate:         0.1,
+//	})
+//	client, err := mongo.Connect(ctx, options.Client().SetFaultInjector(injector))
+//
+// Rules can also be changed while the client is running through Client.FaultInjector. Clients created without an
+// Injector have no fault injection and Client.FaultInjector returns nil, so create the client with New() to add all
+// rules at runtime.
+//
+// Fault injection is meant for testing and staging environments. An Injector without rules adds no faults.
+package faultinject // import "github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/randutil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+)
+
+// ErrHeartbeatFailure is the error of the heartbeats failed by a rule.
+var ErrHeartbeatFailure = errors.New("heartbeat failed by fault injection")
+
+// ServerError is a synthetic server error returned for a command instead of sending it to the server. The driver
+// handles it like an error returned by the server, so it is retried and changes the server state like a real error
+// with the same code and labels.
+type ServerError struct {
+	Code    int32
+	Name    string
+	Message string
+	Labels  []string
+}
+
+// Rule describes the commands or heartbeats a fault is injected into and the fault to inject. The zero value of a
+// matching field matches everything.
+type Rule struct {
+	// CommandNames are the names of the commands the rule matches.
+	CommandNames []string
+
+	// Namespace is the database name or "database.collection" namespace of the commands the rule matches.
+	Namespace string
+
+	// Addresses are the addresses of the servers the rule matches.
+	Addresses []address.Address
+
+	// Rate is the fraction of the matching commands or heartbeats the rule is applied to, between 0 and 1. The rule is
+	// applied to all of them if Rate is 0.
+	Rate float64
+
+	// Times is the number of times the rule is applied before it is removed. The rule is never removed if Times is 0.
+	Times int
+
+	// Latency is added before the command is sent.
+	Latency time.Duration
+
+	// Error is returned for the command instead of sending it.
+	Error *ServerError
+
+	// CloseConnection closes the connection after the command is sent, while the driver reads the reply. This is
+	// handled by the driver as a network error.
+	CloseConnection bool
+
+	// FailHeartbeats makes the rule match the heartbeats of the servers instead of commands, and fail them with
+	// ErrHeartbeatFailure as if the monitoring connection was broken. CommandNames and Namespace are ignored.
+	FailHeartbeats bool
+}
+
+// Command describes a command that a fault may be injected into.
+type Command struct {
+	Name       string
+	Database   string
+	Collection string
+	Address    address.Address
+}
+
+// Fault is the fault injected into a command.
+type Fault struct {
+	Latency         time.Duration
+	Error           *ServerError
+	CloseConnection bool
+}
+
+// Injector injects faults into the commands and heartbeats of a client according to a list of rules. It is safe for
+// concurrent use.
+type Injector struct {
+	mu     sync.Mutex
+	rules  []*rule
+	nextID int
+	// active is the number of rules, which lets the driver skip matching when there are none.
+	active int32
+}
+
+type rule struct {
+	Rule
+	id      int
+	applied int
+}
+
+// New creates an Injector with the given rules.
+func New(rules ...Rule) *Injector {
+	i := &Injector{}
+	for _, r := range rules {
+		i.Add(r)
+	}
+	return i
+}
+
+// Add adds a rule after the existing rules and returns its ID, which can be passed to Remove.
+func (i *Injector) Add(r Rule) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.nextID++
+	i.rules = append(i.rules, &rule{Rule: r, id: i.nextID})
+	atomic.StoreInt32(&i.active, int32(len(i.rules)))
+	return i.nextID
+}
+
+// Remove removes the rule with the given ID. It returns false if there is no such rule, e.g. because it was applied
+// the number of Times it was limited to.
+func (i *Injector) Remove(id int) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for idx, r := range i.rules {
+		if r.id == id {
+			i.removeLocked(idx)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all rules.
+func (i *Injector) Clear() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.rules = nil
+	atomic.StoreInt32(&i.active, 0)
+}
+
+// Rules returns the current rules.
+func (i *Injector) Rules() []Rule {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	rules := make([]Rule, len(i.rules))
+	for idx, r := range i.rules {
+		rules[idx] = r.Rule
+	}
+	return rules
+}
+
+// CommandFault returns the fault to inject into a command. The zero Fault is returned if no rule applies.
+func (i *Injector) CommandFault(cmd Command) Fault {
+	r := i.apply(func(r *rule) bool {
+		return !r.FailHeartbeats && r.matchesCommand(cmd)
+	})
+	if r == nil {
+		return Fault{}
+	}
+
+	return Fault{
+		Latency:         r.Latency,
+		Error:           r.Error,
+		CloseConnection: r.CloseConnection,
+	}
+}
+
+// HeartbeatFault returns ErrHeartbeatFailure if the heartbeat of the server with the given address must fail, or nil
+// otherwise.
+func (i *Injector) HeartbeatFault(addr address.Address) error {
+	r := i.apply(func(r *rule) bool {
+		return r.FailHeartbeats && r.matchesAddress(addr)
+	})
+	if r == nil {
+		return nil
+	}
+	return ErrHeartbeatFailure
+}
+
+// Active returns whether the injector has any rules. It is safe to call on a nil Injector, which has none.
+func (i *Injector) Active() bool {
+	return i != nil && atomic.LoadInt32(&i.active) > 0
+}
+
+// apply returns the first rule that matches and is selected by its rate, and records that it was applied.
+func (i *Injector) apply(matches func(*rule) bool) *Rule {
+	if !i.Active() {
+		return nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for idx, r := range i.rules {
+		if !matches(r) {
+			continue
+		}
+		if r.Rate > 0 && r.Rate < 1 && random.Float64() >= r.Rate {
+			continue
+		}
+
+		r.applied++
+		applied := r.Rule
+		if r.Times > 0 && r.applied >= r.Times {
+			i.removeLocked(idx)
+		}
+		return &applied
+	}
+	return nil
+}
+
+func (i *Injector) removeLocked(idx int) {
+	i.rules = append(i.rules[:idx:idx], i.rules[idx+1:]...)
+	atomic.StoreInt32(&i.active, int32(len(i.rules)))
+}
+
+func (r *rule) matchesCommand(cmd Command) bool {
+	if len(r.CommandNames) > 0 {
+		matched := false
+		for _, name := range r.CommandNames {
+			if name == cmd.Name {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if r.Namespace != "" {
+		db, coll := r.Namespace, ""
+		if idx := strings.IndexByte(r.Namespace, '.'); idx >= 0 {
+			db, coll = r.Namespace[:idx], r.Namespace[idx+1:]
+		}
+		if db != cmd.Database || (coll != "" && coll != cmd.Collection) {
+			return false
+		}
+	}
+
+	return r.matchesAddress(cmd.Address)
+}
+
+func (r *rule) matchesAddress(addr address.Address) bool {
+	if len(r.Addresses) == 0 {
+		return true
+	}
+
+	for _, a := range r.Addresses {
+		if a.Canonicalize() == addr.Canonicalize() {
+			return true
+		}
+	}
+	return false
+}
+
+var random = randutil.NewLockedRand()
@@ -0,0 +1,123 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package faultinject
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+)
+
+func TestInjector(t *testing.T) {
+	t.Parallel()
+
+	find := Command{Name: "find", Database: "db", Collection: "coll", Address: "a:27017"}
+
+	t.Run("matching", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name    string
+			rule    Rule
+			matches bool
+		}{
+			{"empty rule", Rule{}, true},
+			{"command name", Rule{CommandNames: []string{"insert", "find"}}, true},
+			{"other command name", Rule{CommandNames: []string{"insert"}}, false},
+			{"database", Rule{Namespace: "db"}, true},
+			{"other database", Rule{Namespace: "other"}, false},
+			{"namespace", Rule{Namespace: "db.coll"}, true},
+			{"other collection", Rule{Namespace: "db.other"}, false},
+			{"address", Rule{Addresses: []address.Address{"A"}}, true},
+			{"other address", Rule{Addresses: []address.Address{"b"}}, false},
+			{"heartbeat rule", Rule{FailHeartbeats: true}, false},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				tc.rule.Latency = time.Second
+				fault := New(tc.rule).CommandFault(find)
+				assert.Equal(t, tc.matches, fault.Latency == time.Second,
+					"expected the rule to match: %v, got fault %+v", tc.matches, fault)
+			})
+		}
+	})
+	t.Run("first matching rule wins", func(t *testing.T) {
+		t.Parallel()
+
+		err := &ServerError{Code: 91}
+		i := New(
+			Rule{CommandNames: []string{"insert"}, Latency: time.Second},
+			Rule{CommandNames: []string{"find"}, Error: err},
+			Rule{CloseConnection: true},
+		)
+		fault := i.CommandFault(find)
+		assert.Equal(t, Fault{Error: err}, fault, "expected the second rule to be applied")
+	})
+	t.Run("times", func(t *testing.T) {
+		t.Parallel()
+
+		i := New(Rule{Times: 2, CloseConnection: true})
+		assert.True(t, i.CommandFault(find).CloseConnection, "expected the rule to be applied the first time")
+		assert.True(t, i.CommandFault(find).CloseConnection, "expected the rule to be applied the second time")
+		assert.False(t, i.CommandFault(find).CloseConnection, "expected the rule to be removed")
+		assert.Equal(t, 0, len(i.Rules()), "expected no rules, got %v", i.Rules())
+	})
+	t.Run("rate", func(t *testing.T) {
+		t.Parallel()
+
+		i := New(Rule{Rate: 0.5, CloseConnection: true})
+		var applied int
+		for n := 0; n < 1000; n++ {
+			if i.CommandFault(find).CloseConnection {
+				applied++
+			}
+		}
+		assert.True(t, applied > 300 && applied < 700, "expected the rule to be applied about 500 times, got %d",
+			applied)
+	})
+	t.Run("add and remove", func(t *testing.T) {
+		t.Parallel()
+
+		i := New()
+		assert.False(t, i.Active(), "expected an injector without rules not to be active")
+		id := i.Add(Rule{CloseConnection: true})
+		assert.True(t, i.Active(), "expected an injector with a rule to be active")
+		assert.True(t, i.CommandFault(find).CloseConnection, "expected the added rule to be applied")
+		assert.True(t, i.Remove(id), "expected the rule to be removed")
+		assert.False(t, i.Remove(id), "expected the rule to be removed already")
+		assert.False(t, i.CommandFault(find).CloseConnection, "expected no rule to be applied")
+		assert.False(t, i.Active(), "expected the injector not to be active after the rule was removed")
+
+		i.Add(Rule{CloseConnection: true})
+		i.Clear()
+		assert.Equal(t, Fault{}, i.CommandFault(find), "expected no rule to be applied after Clear")
+		assert.False(t, i.Active(), "expected the injector not to be active after Clear")
+	})
+	t.Run("heartbeats", func(t *testing.T) {
+		t.Parallel()
+
+		i := New(
+			Rule{CloseConnection: true},
+			Rule{FailHeartbeats: true, Addresses: []address.Address{"a"}},
+		)
+		assert.Equal(t, ErrHeartbeatFailure, i.HeartbeatFault("a:27017"), "expected the heartbeat to fail")
+		assert.Nil(t, i.HeartbeatFault("b:27017"), "expected the heartbeat of another server not to fail")
+	})
+	t.Run("nil injector", func(t *testing.T) {
+		t.Parallel()
+
+		var i *Injector
+		assert.False(t, i.Active(), "expected a nil injector not to be active")
+		assert.Equal(t, Fault{}, i.CommandFault(find), "expected no fault")
+		assert.Nil(t, i.HeartbeatFault("a:27017"), "expected no heartbeat fault")
+	})
+}
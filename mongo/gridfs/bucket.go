@@ -11,6 +11,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"time"
 
@@ -33,6 +34,14 @@ const DefaultChunkSize int32 = 255 * 1024 // 255 KiB
 // ErrFileNotFound occurs if a user asks to download a file with a file ID that isn't found in the files collection.
 var ErrFileNotFound = errors.New("file with given parameters not found")
 
+// ErrUploadComplete occurs if a user asks to resume an upload for a file ID that already has a files collection
+// document.
+var ErrUploadComplete = errors.New("upload is already complete")
+
+// ErrChunkSizeMismatch occurs if a user asks to resume an upload with a chunk size that doesn't match the size of the
+// chunks that were already written.
+var ErrChunkSizeMismatch = errors.New("chunk size does not match the chunks of the unfinished upload")
+
 // ErrMissingChunkSize occurs when downloading a file if the files collection document is missing the "chunkSize" field.
 var ErrMissingChunkSize = errors.New("files collection document does not contain a 'chunkSize' field")
 
@@ -54,12 +63,17 @@ type Bucket struct {
 
 	readDeadline  time.Time
 	writeDeadline time.Time
+
+	transformers map[string]ChunkTransformer // chunk transformers available for downloads, keyed by ID
 }
 
 // Upload contains options to upload a file to a bucket.
 type Upload struct {
-	chunkSize int32
-	metadata  bson.D
+	chunkSize   int32
+	metadata    bson.D
+	concurrency int                       // maximum number of chunk batches inserted concurrently
+	checksum    options.ChecksumAlgorithm // empty if no checksum is computed
+	transformer ChunkTransformer          // nil if chunks are uploaded unchanged
 }
 
 // NewBucket creates a GridFS bucket.
@@ -90,6 +104,14 @@ func NewBucket(db *mongo.Database, opts ...*options.BucketOptions) (*Bucket, err
 		b.rp = bo.ReadPreference
 	}
 
+	b.transformers = map[string]ChunkTransformer{
+		ZstdTransformerID:   NewZstdTransformer(0),
+		SnappyTransformerID: NewSnappyTransformer(),
+	}
+	for _, transformer := range bo.ChunkTransformers {
+		b.transformers[transformer.ID()] = transformer
+	}
+
 	var collOpts = options.Collection().SetWriteConcern(b.wc).SetReadConcern(b.rc).SetReadPreference(b.rp)
 
 	b.chunksColl = db.Collection(b.name+".chunks", collOpts)
@@ -100,13 +122,15 @@ func NewBucket(db *mongo.Database, opts ...*options.BucketOptions) (*Bucket, err
 	return b, nil
 }
 
-// SetWriteDeadline sets the write deadline for this bucket.
+// SetWriteDeadline sets the write deadline for this bucket. The deadline is only used by the methods that don't take a
+// context. The methods that take a context ignore it.
 func (b *Bucket) SetWriteDeadline(t time.Time) error {
 	b.writeDeadline = t
 	return nil
 }
 
-// SetReadDeadline sets the read deadline for this bucket
+// SetReadDeadline sets the read deadline for this bucket. The deadline is only used by the methods that don't take a
+// context. The methods that take a context ignore it.
 func (b *Bucket) SetReadDeadline(t time.Time) error {
 	b.readDeadline = t
 	return nil
@@ -117,13 +141,50 @@ func (b *Bucket) OpenUploadStream(filename string, opts ...*options.UploadOption
 	return b.OpenUploadStreamWithID(primitive.NewObjectID(), filename, opts...)
 }
 
+// OpenUploadStreamContext creates a file ID new upload stream for a file given the filename. The stream uses the
+// provided context for all of its operations.
+//
+// See OpenUploadStreamWithIDContext for how the context is used.
+func (b *Bucket) OpenUploadStreamContext(ctx context.Context, filename string, opts ...*options.UploadOptions) (*UploadStream, error) {
+	return b.OpenUploadStreamWithIDContext(ctx, primitive.NewObjectID(), filename, opts...)
+}
+
 // OpenUploadStreamWithID creates a new upload stream for a file given the file ID and filename.
+//
+// Use SetWriteDeadline on the bucket to set a deadline for opening the stream and SetWriteDeadline on the stream to set
+// a deadline for its operations.
 func (b *Bucket) OpenUploadStreamWithID(fileID interface{}, filename string, opts ...*options.UploadOptions) (*UploadStream, error) {
 	ctx, cancel := deadlineContext(b.writeDeadline)
 	if cancel != nil {
 		defer cancel()
 	}
 
+	return b.openUploadStream(ctx, fileID, filename, opts...)
+}
+
+// OpenUploadStreamWithIDContext creates a new upload stream for a file given the file ID and filename. The stream uses
+// the provided context for all of its operations, including chunk batches that are inserted concurrently, so canceling
+// the context cancels the upload. Write, Close and Abort use this context, and WriteContext, CloseContext and
+// AbortContext can be used to run single operations with a different context.
+//
+// If Timeout is set on the Client and the context is not already a Timeout context, the Timeout applies to the whole
+// lifetime of the stream, from opening it until it is closed or aborted. The deadlines set by SetWriteDeadline are
+// ignored.
+func (b *Bucket) OpenUploadStreamWithIDContext(ctx context.Context, fileID interface{}, filename string, opts ...*options.UploadOptions) (*UploadStream, error) {
+	ctx, cancel := timeoutContext(ctx, b.db)
+
+	us, err := b.openUploadStream(ctx, fileID, filename, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	us.ctx = ctx
+	us.cancel = cancel
+	return us, nil
+}
+
+func (b *Bucket) openUploadStream(ctx context.Context, fileID interface{}, filename string, opts ...*options.UploadOptions) (*UploadStream, error) {
 	if err := b.checkFirstWrite(ctx); err != nil {
 		return nil, err
 	}
@@ -146,58 +207,300 @@ func (b *Bucket) UploadFromStream(filename string, source io.Reader, opts ...*op
 	return fileID, err
 }
 
+// UploadFromStreamContext creates a fileID and uploads a file given a source stream, running all of the underlying
+// operations with the provided context.
+//
+// Use the context parameter to time-out or cancel the upload. The deadline set by SetWriteDeadline is ignored.
+func (b *Bucket) UploadFromStreamContext(ctx context.Context, filename string, source io.Reader, opts ...*options.UploadOptions) (primitive.ObjectID, error) {
+	fileID := primitive.NewObjectID()
+	err := b.UploadFromStreamWithIDContext(ctx, fileID, filename, source, opts...)
+	return fileID, err
+}
+
 // UploadFromStreamWithID uploads a file given a source stream.
 //
 // If this upload requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
 // write operations operations on this bucket that also require a custom deadline.
 func (b *Bucket) UploadFromStreamWithID(fileID interface{}, filename string, source io.Reader, opts ...*options.UploadOptions) error {
-	us, err := b.OpenUploadStreamWithID(fileID, filename, opts...)
+	ctx, cancel := deadlineContext(b.writeDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return b.UploadFromStreamWithIDContext(ctx, fileID, filename, source, opts...)
+}
+
+// UploadFromStreamWithIDContext uploads a file given a source stream, running all of the underlying operations with the
+// provided context.
+//
+// Use the context parameter to time-out or cancel the upload. If Timeout is set on the Client and the context is not
+// already a Timeout context, the Timeout applies to the whole upload. The deadline set by SetWriteDeadline is ignored.
+func (b *Bucket) UploadFromStreamWithIDContext(ctx context.Context, fileID interface{}, filename string, source io.Reader, opts ...*options.UploadOptions) error {
+	us, err := b.OpenUploadStreamWithIDContext(ctx, fileID, filename, opts...)
 	if err != nil {
 		return err
 	}
 
-	err = us.SetWriteDeadline(b.writeDeadline)
+	return b.uploadFromStream(us, source, true)
+}
+
+// ResumeUpload continues an unfinished upload of the file with the given file ID from a source stream. An upload is
+// unfinished if chunks were written for the file ID but its files collection document was not, for example because the
+// process that uploaded the file crashed. ErrUploadComplete is returned if the file already has a files collection
+// document.
+//
+// The source must provide the complete file content. ResumeUpload finds the longest run of consecutive chunks starting
+// at chunk 0, deletes all chunks after it, seeks the source past the data of the kept chunks and uploads the rest of the
+// file. The last chunk of the run is uploaded again because it may be a partial final chunk. The filename and options
+// are used to write the files collection document, and the chunk size must match the chunk size of the original
+// upload. ErrChunkSizeMismatch is returned if the first chunk has a different size. The size of chunks uploaded with a
+// chunk transformer cannot be checked, so an upload with a ChunkTransformer must be resumed with the same options as
+// the original upload. If reading from the source fails, the chunks that were written are kept so the upload can be
+// resumed again.
+//
+// If this upload requires a custom write deadline to be set on the bucket, it cannot be done concurrently with other
+// write operations operations on this bucket that also require a custom deadline.
+func (b *Bucket) ResumeUpload(fileID interface{}, filename string, source io.ReadSeeker, opts ...*options.UploadOptions) error {
+	ctx, cancel := deadlineContext(b.writeDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return b.ResumeUploadContext(ctx, fileID, filename, source, opts...)
+}
+
+// ResumeUploadContext continues an unfinished upload of the file with the given file ID from a source stream, running
+// all of the underlying operations with the provided context. See ResumeUpload for how the upload is resumed.
+//
+// Use the context parameter to time-out or cancel the upload. If Timeout is set on the Client and the context is not
+// already a Timeout context, the Timeout applies to the whole upload. The deadline set by SetWriteDeadline is ignored.
+func (b *Bucket) ResumeUploadContext(ctx context.Context, fileID interface{}, filename string, source io.ReadSeeker, opts ...*options.UploadOptions) error {
+	ctx, cancel := timeoutContext(ctx, b.db)
+	defer cancel()
+
+	if err := b.checkFirstWrite(ctx); err != nil {
+		return err
+	}
+
+	upload, err := b.parseUploadOptions(opts...)
 	if err != nil {
-		_ = us.Close()
 		return err
 	}
 
-	for {
-		n, err := source.Read(b.readBuf)
-		if err != nil && err != io.EOF {
-			_ = us.Abort() // upload considered aborted if source stream returns an error
+	resumeChunk, err := b.resumeChunkIndex(ctx, fileID, upload)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.chunksColl.DeleteMany(ctx, bson.D{
+		{"files_id", fileID},
+		{"n", bson.D{{"$gte", resumeChunk}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	us := newUploadStream(upload, fileID, filename, b.chunksColl, b.filesColl)
+	us.ctx = ctx
+	us.chunkIndex = int(resumeChunk)
+	us.fileLen = int64(resumeChunk) * int64(upload.chunkSize)
+
+	if us.checksumHash == nil {
+		if _, err := source.Seek(us.fileLen, io.SeekStart); err != nil {
 			return err
 		}
-
-		if n > 0 {
-			_, err := us.Write(b.readBuf[:n])
-			if err != nil {
-				return err
-			}
+	} else {
+		// The checksum covers the whole file, so the content of the stored chunks is read from the source and hashed.
+		// This leaves the source positioned at the first byte that must be uploaded.
+		if _, err := source.Seek(0, io.SeekStart); err != nil {
+			return err
 		}
+		if _, err := io.CopyN(us.checksumHash, source, us.fileLen); err != nil {
+			return err
+		}
+	}
 
-		if n == 0 || err == io.EOF {
+	return b.uploadFromStream(us, source, false)
+}
+
+// resumeChunkIndex returns the index of the first chunk that must be uploaded to resume the upload of a file with the
+// given upload options.
+func (b *Bucket) resumeChunkIndex(ctx context.Context, fileID interface{}, upload *Upload) (int32, error) {
+	// Use the primary to see the latest writes of the interrupted upload.
+	filesColl, err := b.filesColl.Clone(options.Collection().SetReadPreference(readpref.Primary()))
+	if err != nil {
+		return 0, err
+	}
+	chunksColl, err := b.chunksColl.Clone(options.Collection().SetReadPreference(readpref.Primary()))
+	if err != nil {
+		return 0, err
+	}
+
+	err = filesColl.FindOne(ctx, bson.D{{"_id", fileID}}, options.FindOne().SetProjection(bson.D{{"_id", 1}})).Err()
+	if err == nil {
+		return 0, ErrUploadComplete
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{"n", 1}}).
+		SetProjection(bson.D{{"_id", 0}, {"n", 1}})
+	cursor, err := chunksColl.Find(ctx, bson.D{{"files_id", fileID}}, findOpts)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	var next int32
+	for cursor.Next(ctx) {
+		n, ok := cursor.Current.Lookup("n").AsInt64OK()
+		if !ok || n != int64(next) {
 			break
 		}
+		next++
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, err
 	}
 
-	return us.Close()
+	// Only the final chunk of a file can be smaller than the chunk size, so all chunks of the run except the last one
+	// are known to be complete.
+	if next > 0 {
+		next--
+	}
+
+	// The kept chunks are complete, so the resumed upload must use their size to continue at the right offset.
+	// Transformed chunks have encoded sizes that cannot be compared with the chunk size.
+	if next > 0 && upload.transformer == nil {
+		findOneOpts := options.FindOne().SetProjection(bson.D{{"_id", 0}, {"data", 1}})
+		chunk, err := chunksColl.FindOne(ctx, bson.D{{"files_id", fileID}, {"n", 0}}, findOneOpts).Raw()
+		if err != nil {
+			return 0, err
+		}
+		_, data, ok := chunk.Lookup("data").BinaryOK()
+		if !ok || len(data) != int(upload.chunkSize) {
+			return 0, ErrChunkSizeMismatch
+		}
+	}
+	return next, nil
 }
 
 // OpenDownloadStream creates a stream from which the contents of the file can be read.
+//
+// Use SetReadDeadline on the bucket to set a deadline for opening the stream and SetReadDeadline on the stream to set a
+// deadline for its operations.
 func (b *Bucket) OpenDownloadStream(fileID interface{}) (*DownloadStream, error) {
-	return b.openDownloadStream(bson.D{
+	ctx, cancel := deadlineContext(b.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return b.openDownloadStream(ctx, bson.D{
 		{"_id", fileID},
 	})
 }
 
+// OpenDownloadStreamContext creates a stream from which the contents of the file can be read. The stream uses the
+// provided context for all of its operations, so canceling the context cancels in-flight chunk finds. Read, Skip,
+// ReadAt and Close use this context, and ReadContext, SkipContext, ReadAtContext and CloseContext can be used to run
+// single operations with a different context.
+//
+// If Timeout is set on the Client and the context is not already a Timeout context, the Timeout applies to the whole
+// lifetime of the stream, from opening it until it is closed. The deadlines set by SetReadDeadline are ignored.
+func (b *Bucket) OpenDownloadStreamContext(ctx context.Context, fileID interface{}) (*DownloadStream, error) {
+	return b.openDownloadStreamContext(ctx, func(ctx context.Context) (*DownloadStream, error) {
+		return b.openDownloadStream(ctx, bson.D{{"_id", fileID}})
+	})
+}
+
+// OpenDownloadStreamRange creates a stream from which length bytes of the file, starting at byte offset offset, can be
+// read. Only the chunks that contain the requested range are fetched from the server. If the range extends past the
+// end of the file, the stream ends at the end of the file. ErrInvalidRange is returned if offset or length is negative
+// or if offset is past the end of the file.
+//
+// The returned stream can still Seek and ReadAt anywhere in the file, but Read and Skip stop at the end of the range.
+func (b *Bucket) OpenDownloadStreamRange(fileID interface{}, offset, length int64) (*DownloadStream, error) {
+	ctx, cancel := deadlineContext(b.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return b.openDownloadStreamRange(ctx, fileID, offset, length)
+}
+
+// OpenDownloadStreamRangeContext creates a stream from which length bytes of the file, starting at byte offset offset,
+// can be read. See OpenDownloadStreamRange for how the range is read and OpenDownloadStreamContext for how the context
+// is used.
+func (b *Bucket) OpenDownloadStreamRangeContext(ctx context.Context, fileID interface{}, offset, length int64) (*DownloadStream, error) {
+	return b.openDownloadStreamContext(ctx, func(ctx context.Context) (*DownloadStream, error) {
+		return b.openDownloadStreamRange(ctx, fileID, offset, length)
+	})
+}
+
+func (b *Bucket) openDownloadStreamRange(ctx context.Context, fileID interface{}, offset, length int64) (*DownloadStream, error) {
+	if offset < 0 || length < 0 {
+		return nil, ErrInvalidRange
+	}
+
+	foundFile, doc, err := b.findDownloadFile(ctx, bson.D{{"_id", fileID}})
+	if err != nil {
+		return nil, err
+	}
+	if offset > foundFile.Length {
+		return nil, ErrInvalidRange
+	}
+	transform, err := b.fileTransform(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunksCursor *mongo.Cursor
+	if offset < foundFile.Length && length > 0 {
+		chunksCursor, err = findChunksFrom(ctx, b.chunksColl, foundFile.ID, int32(offset/int64(foundFile.ChunkSize)), 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ds := newDownloadStream(chunksCursor, b.chunksColl, foundFile.ChunkSize, foundFile, doc, transform)
+	if chunksCursor != nil {
+		ds.expectedChunk = int32(offset / int64(foundFile.ChunkSize))
+	}
+	ds.offset = offset
+	// Clamp the length before computing the end of the range so that it can't overflow.
+	if length > foundFile.Length-offset {
+		length = foundFile.Length - offset
+	}
+	ds.end = offset + length
+	return ds, nil
+}
+
 // DownloadToStream downloads the file with the specified fileID and writes it to the provided io.Writer.
 // Returns the number of bytes written to the stream and an error, or nil if there was no error.
 //
 // If this download requires a custom read deadline to be set on the bucket, it cannot be done concurrently with other
 // read operations operations on this bucket that also require a custom deadline.
 func (b *Bucket) DownloadToStream(fileID interface{}, stream io.Writer) (int64, error) {
-	ds, err := b.OpenDownloadStream(fileID)
+	ctx, cancel := deadlineContext(b.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return b.DownloadToStreamContext(ctx, fileID, stream)
+}
+
+// DownloadToStreamContext downloads the file with the specified fileID and writes it to the provided io.Writer, running
+// all of the underlying operations with the provided context. Returns the number of bytes written to the stream and an
+// error, or nil if there was no error.
+//
+// Use the context parameter to time-out or cancel the download. If Timeout is set on the Client and the context is not
+// already a Timeout context, the Timeout applies to the whole download. The deadline set by SetReadDeadline is ignored.
+func (b *Bucket) DownloadToStreamContext(ctx context.Context, fileID interface{}, stream io.Writer) (int64, error) {
+	ds, err := b.OpenDownloadStreamContext(ctx, fileID)
 	if err != nil {
 		return 0, err
 	}
@@ -206,23 +509,24 @@ func (b *Bucket) DownloadToStream(fileID interface{}, stream io.Writer) (int64,
 }
 
 // OpenDownloadStreamByName opens a download stream for the file with the given filename.
+//
+// Use SetReadDeadline on the bucket to set a deadline for opening the stream and SetReadDeadline on the stream to set a
+// deadline for its operations.
 func (b *Bucket) OpenDownloadStreamByName(filename string, opts ...*options.NameOptions) (*DownloadStream, error) {
-	var numSkip int32 = -1
-	var sortOrder int32 = 1
-
-	nameOpts := options.MergeNameOptions(opts...)
-	if nameOpts.Revision != nil {
-		numSkip = *nameOpts.Revision
-	}
-
-	if numSkip < 0 {
-		sortOrder = -1
-		numSkip = (-1 * numSkip) - 1
+	ctx, cancel := deadlineContext(b.readDeadline)
+	if cancel != nil {
+		defer cancel()
 	}
 
-	findOpts := options.Find().SetSkip(int64(numSkip)).SetSort(bson.D{{"uploadDate", sortOrder}})
+	return b.openDownloadStream(ctx, bson.D{{"filename", filename}}, revisionFindOptions(opts...))
+}
 
-	return b.openDownloadStream(bson.D{{"filename", filename}}, findOpts)
+// OpenDownloadStreamByNameContext opens a download stream for the file with the given filename. See
+// OpenDownloadStreamContext for how the context is used.
+func (b *Bucket) OpenDownloadStreamByNameContext(ctx context.Context, filename string, opts ...*options.NameOptions) (*DownloadStream, error) {
+	return b.openDownloadStreamContext(ctx, func(ctx context.Context) (*DownloadStream, error) {
+		return b.openDownloadStream(ctx, bson.D{{"filename", filename}}, revisionFindOptions(opts...))
+	})
 }
 
 // DownloadToStreamByName downloads the file with the given name to the given io.Writer.
@@ -230,7 +534,21 @@ func (b *Bucket) OpenDownloadStreamByName(filename string, opts ...*options.Name
 // If this download requires a custom read deadline to be set on the bucket, it cannot be done concurrently with other
 // read operations operations on this bucket that also require a custom deadline.
 func (b *Bucket) DownloadToStreamByName(filename string, stream io.Writer, opts ...*options.NameOptions) (int64, error) {
-	ds, err := b.OpenDownloadStreamByName(filename, opts...)
+	ctx, cancel := deadlineContext(b.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return b.DownloadToStreamByNameContext(ctx, filename, stream, opts...)
+}
+
+// DownloadToStreamByNameContext downloads the file with the given name to the given io.Writer, running all of the
+// underlying operations with the provided context.
+//
+// Use the context parameter to time-out or cancel the download. If Timeout is set on the Client and the context is not
+// already a Timeout context, the Timeout applies to the whole download. The deadline set by SetReadDeadline is ignored.
+func (b *Bucket) DownloadToStreamByNameContext(ctx context.Context, filename string, stream io.Writer, opts ...*options.NameOptions) (int64, error) {
+	ds, err := b.OpenDownloadStreamByNameContext(ctx, filename, opts...)
 	if err != nil {
 		return 0, err
 	}
@@ -260,13 +578,8 @@ func (b *Bucket) DeleteContext(ctx context.Context, fileID interface{}) error {
 	// If Timeout is set on the Client and context is not already a Timeout
 	// context, honor Timeout in new Timeout context for operation execution to
 	// be shared by both delete operations.
-	if b.db.Client().Timeout() != nil && !csot.IsTimeoutContext(ctx) {
-		newCtx, cancelFunc := csot.MakeTimeoutContext(ctx, *b.db.Client().Timeout())
-		// Redefine ctx to be the new timeout-derived context.
-		ctx = newCtx
-		// Cancel the timeout-derived context at the end of Execute to avoid a context leak.
-		defer cancelFunc()
-	}
+	ctx, cancel := timeoutContext(ctx, b.db)
+	defer cancel()
 
 	// Delete document in files collection and then chunks to minimize race conditions.
 	res, err := b.filesColl.DeleteOne(ctx, bson.D{{"_id", fileID}})
@@ -281,6 +594,97 @@ func (b *Bucket) DeleteContext(ctx context.Context, fileID interface{}) error {
 	return b.deleteChunks(ctx, fileID)
 }
 
+// Verify checks the integrity of the stored file with the given file ID by reading all of its chunks. It returns an
+// error wrapping ErrWrongIndex if chunks are missing, duplicated or out of order, an error wrapping ErrWrongSize if a
+// chunk has the wrong number of bytes for the file's chunk size and length, and an ErrChecksumMismatch if the file was
+// uploaded with a checksum that doesn't match its content. Chunks of files uploaded with a chunk transformer are decoded
+// before they are checked. Verify returns nil if the file is intact.
+//
+// Use the context parameter to time-out or cancel the verification.
+func (b *Bucket) Verify(ctx context.Context, fileID interface{}) error {
+	// If Timeout is set on the Client and context is not already a Timeout
+	// context, honor Timeout in new Timeout context for operation execution to
+	// be shared by all find operations.
+	ctx, cancel := timeoutContext(ctx, b.db)
+	defer cancel()
+
+	file, doc, err := b.findDownloadFile(ctx, bson.D{{"_id", fileID}})
+	if err != nil {
+		return err
+	}
+
+	transform, err := b.fileTransform(doc)
+	if err != nil {
+		return err
+	}
+
+	var checksum hash.Hash
+	algorithm, digest, hasChecksum := fileChecksum(doc)
+	if hasChecksum {
+		if checksum, err = newChecksumHash(algorithm); err != nil {
+			return err
+		}
+	}
+
+	var numChunks int64
+	if file.Length > 0 {
+		numChunks = (file.Length + int64(file.ChunkSize) - 1) / int64(file.ChunkSize)
+	}
+
+	cursor, err := b.findChunks(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	var expected int64
+	for cursor.Next(ctx) {
+		n, ok := cursor.Current.Lookup("n").AsInt64OK()
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: chunk %d has a non-numeric index", ErrWrongIndex, expected)
+		case n < expected:
+			return fmt.Errorf("%w: duplicate chunk %d", ErrWrongIndex, n)
+		case n >= numChunks:
+			return fmt.Errorf("%w: unexpected chunk %d in a file with %d chunks", ErrWrongIndex, n, numChunks)
+		case n > expected:
+			return fmt.Errorf("%w: missing chunk %d", ErrWrongIndex, expected)
+		}
+
+		_, data, ok := cursor.Current.Lookup("data").BinaryOK()
+		if !ok {
+			return fmt.Errorf("%w: chunk %d has no binary data", ErrWrongSize, n)
+		}
+		expectedSize := expectedChunkSize(file.Length, file.ChunkSize, n)
+		if transform != nil {
+			if data, err = transform.decode(file.ID, int32(n), data, expectedSize); err != nil {
+				return err
+			}
+		}
+		if size := int64(len(data)); size != expectedSize {
+			return fmt.Errorf("%w: chunk %d has %d bytes, expected %d", ErrWrongSize, n, size, expectedSize)
+		}
+
+		if checksum != nil {
+			_, _ = checksum.Write(data)
+		}
+		expected++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if expected != numChunks {
+		return fmt.Errorf("%w: missing chunk %d", ErrWrongIndex, expected)
+	}
+
+	if checksum != nil {
+		return checkChecksum(algorithm, digest, checksum)
+	}
+	return nil
+}
+
 // Find returns the files collection documents that match the given filter.
 //
 // If this download requires a custom read deadline to be set on the bucket, it cannot be done concurrently with other
@@ -387,13 +791,8 @@ func (b *Bucket) DropContext(ctx context.Context) error {
 	// If Timeout is set on the Client and context is not already a Timeout
 	// context, honor Timeout in new Timeout context for operation execution to
 	// be shared by both drop operations.
-	if b.db.Client().Timeout() != nil && !csot.IsTimeoutContext(ctx) {
-		newCtx, cancelFunc := csot.MakeTimeoutContext(ctx, *b.db.Client().Timeout())
-		// Redefine ctx to be the new timeout-derived context.
-		ctx = newCtx
-		// Cancel the timeout-derived context at the end of Execute to avoid a context leak.
-		defer cancelFunc()
-	}
+	ctx, cancel := timeoutContext(ctx, b.db)
+	defer cancel()
 
 	err := b.filesColl.Drop(ctx)
 	if err != nil {
@@ -413,43 +812,111 @@ func (b *Bucket) GetChunksCollection() *mongo.Collection {
 	return b.chunksColl
 }
 
-func (b *Bucket) openDownloadStream(filter interface{}, opts ...*options.FindOptions) (*DownloadStream, error) {
-	ctx, cancel := deadlineContext(b.readDeadline)
-	if cancel != nil {
-		defer cancel()
+// openDownloadStreamContext opens a download stream with open and sets the context that the stream uses for its
+// operations.
+func (b *Bucket) openDownloadStreamContext(ctx context.Context, open func(context.Context) (*DownloadStream, error)) (*DownloadStream, error) {
+	ctx, cancel := timeoutContext(ctx, b.db)
+
+	ds, err := open(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
 
-	cursor, err := b.findFile(ctx, filter, opts...)
+	ds.ctx = ctx
+	ds.cancel = cancel
+	return ds, nil
+}
+
+func (b *Bucket) openDownloadStream(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*DownloadStream, error) {
+	foundFile, doc, err := b.findDownloadFile(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	return b.openFileDownloadStream(ctx, foundFile, doc)
+}
+
+// openFileDownloadStream creates a download stream for a file whose files collection document has already been found.
+func (b *Bucket) openFileDownloadStream(ctx context.Context, file *File, doc bson.Raw) (*DownloadStream, error) {
+	transform, err := b.fileTransform(doc)
+	if err != nil {
+		return nil, err
+	}
+	if file.Length == 0 {
+		return newDownloadStream(nil, b.chunksColl, file.ChunkSize, file, doc, transform), nil
+	}
+
+	chunksCursor, err := b.findChunks(ctx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+	// The chunk size can be overridden for individual files, so the expected chunk size should be the "chunkSize"
+	// field from the files collection document, not the bucket's chunk size.
+	return newDownloadStream(chunksCursor, b.chunksColl, file.ChunkSize, file, doc, transform), nil
+}
+
+// findDownloadFile finds and decodes the files collection document for a download. The raw files collection document
+// is returned along with the decoded File.
+func (b *Bucket) findDownloadFile(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*File, bson.Raw, error) {
+	cursor, err := b.findFile(ctx, filter, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
 	// Unmarshal the data into a File instance, which can be passed to newDownloadStream. The _id value has to be
 	// parsed out separately because "_id" will not match the File.ID field and we want to avoid exposing BSON tags
 	// in the File type. After parsing it, use RawValue.Unmarshal to ensure File.ID is set to the appropriate value.
 	var foundFile File
 	if err = cursor.Decode(&foundFile); err != nil {
-		return nil, fmt.Errorf("error decoding files collection document: %w", err)
+		return nil, nil, fmt.Errorf("error decoding files collection document: %w", err)
 	}
 
-	if foundFile.Length == 0 {
-		return newDownloadStream(nil, foundFile.ChunkSize, &foundFile), nil
+	// For a file with non-zero length, chunkSize must exist so we know what size to expect when downloading chunks.
+	if foundFile.Length != 0 {
+		if _, err := cursor.Current.LookupErr("chunkSize"); err != nil {
+			return nil, nil, ErrMissingChunkSize
+		}
 	}
 
-	// For a file with non-zero length, chunkSize must exist so we know what size to expect when downloading chunks.
-	if _, err := cursor.Current.LookupErr("chunkSize"); err != nil {
-		return nil, ErrMissingChunkSize
+	// The cursor reuses its buffers, so copy the document before the cursor is closed.
+	return &foundFile, append(bson.Raw(nil), cursor.Current...), nil
+}
+
+// revisionFindOptions returns the find options that select the requested revision of a file from the documents in the
+// files collection that have the same filename.
+func revisionFindOptions(opts ...*options.NameOptions) *options.FindOptions {
+	var numSkip int32 = -1
+	var sortOrder int32 = 1
+
+	nameOpts := options.MergeNameOptions(opts...)
+	if nameOpts.Revision != nil {
+		numSkip = *nameOpts.Revision
 	}
 
-	chunksCursor, err := b.findChunks(ctx, foundFile.ID)
-	if err != nil {
-		return nil, err
+	if numSkip < 0 {
+		sortOrder = -1
+		numSkip = (-1 * numSkip) - 1
 	}
-	// The chunk size can be overridden for individual files, so the expected chunk size should be the "chunkSize"
-	// field from the files collection document, not the bucket's chunk size.
-	return newDownloadStream(chunksCursor, foundFile.ChunkSize, &foundFile), nil
+
+	return options.Find().SetSkip(int64(numSkip)).SetSort(bson.D{{"uploadDate", sortOrder}})
+}
+
+// timeoutContext returns a context that honors the database's Timeout, which defaults to the Timeout of the Client. If
+// the Timeout is set and ctx is not already a Timeout context, the returned context times out after Timeout so that all
+// operations run with it share a single Timeout. The returned cancel function must be called to avoid a context leak.
+func timeoutContext(ctx context.Context, db *mongo.Database) (context.Context, context.CancelFunc) {
+	if db.Timeout() != nil && !csot.IsTimeoutContext(ctx) {
+		return csot.MakeTimeoutContext(ctx, *db.Timeout())
+	}
+	return ctx, func() {}
 }
 
+// deadlineContext returns the context used by the legacy methods that don't take a context. The context has the read
+// or write deadline set on the bucket or stream, if there is one.
 func deadlineContext(deadline time.Time) (context.Context, context.CancelFunc) {
 	if deadline.Equal(time.Time{}) {
 		return context.Background(), nil
@@ -458,13 +925,38 @@ func deadlineContext(deadline time.Time) (context.Context, context.CancelFunc) {
 	return context.WithDeadline(context.Background(), deadline)
 }
 
-func (b *Bucket) downloadToStream(ds *DownloadStream, stream io.Writer) (int64, error) {
-	err := ds.SetReadDeadline(b.readDeadline)
-	if err != nil {
-		_ = ds.Close()
-		return 0, err
+// uploadFromStream writes the contents of source to the upload stream and closes it. If abortOnSourceError is true, the
+// upload is aborted if reading from source fails.
+func (b *Bucket) uploadFromStream(us *UploadStream, source io.Reader, abortOnSourceError bool) error {
+	defer us.releaseContext()
+
+	for {
+		n, err := source.Read(b.readBuf)
+		if err != nil && err != io.EOF {
+			if abortOnSourceError {
+				_ = us.Abort() // upload considered aborted if source stream returns an error
+			} else {
+				_ = us.waitForBatches()
+			}
+			return err
+		}
+
+		if n > 0 {
+			_, err := us.Write(b.readBuf[:n])
+			if err != nil {
+				return err
+			}
+		}
+
+		if n == 0 || err == io.EOF {
+			break
+		}
 	}
 
+	return us.Close()
+}
+
+func (b *Bucket) downloadToStream(ds *DownloadStream, stream io.Writer) (int64, error) {
 	copied, err := io.Copy(stream, ds)
 	if err != nil {
 		_ = ds.Close()
@@ -494,9 +986,23 @@ func (b *Bucket) findFile(ctx context.Context, filter interface{}, opts ...*opti
 }
 
 func (b *Bucket) findChunks(ctx context.Context, fileID interface{}) (*mongo.Cursor, error) {
-	chunksCursor, err := b.chunksColl.Find(ctx,
-		bson.D{{"files_id", fileID}},
-		options.Find().SetSort(bson.D{{"n", 1}})) // sort by chunk index
+	return findChunksFrom(ctx, b.chunksColl, fileID, 0, 0)
+}
+
+// findChunksFrom returns a cursor over the chunks of a file, sorted by chunk index, starting at chunk index start. If
+// limit is greater than 0, at most limit chunks are returned.
+func findChunksFrom(ctx context.Context, chunks *mongo.Collection, fileID interface{}, start int32, limit int64) (*mongo.Cursor, error) {
+	filter := bson.D{{"files_id", fileID}}
+	if start > 0 {
+		filter = append(filter, bson.E{"n", bson.D{{"$gte", start}}})
+	}
+
+	findOpts := options.Find().SetSort(bson.D{{"n", 1}}) // sort by chunk index
+	if limit > 0 {
+		findOpts.SetLimit(limit)
+	}
+
+	chunksCursor, err := chunks.Find(ctx, filter, findOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -639,13 +1145,27 @@ func (b *Bucket) checkFirstWrite(ctx context.Context) error {
 
 func (b *Bucket) parseUploadOptions(opts ...*options.UploadOptions) (*Upload, error) {
 	upload := &Upload{
-		chunkSize: b.chunkSize, // upload chunk size defaults to bucket's value
+		chunkSize:   b.chunkSize, // upload chunk size defaults to bucket's value
+		concurrency: 1,
 	}
 
 	uo := options.MergeUploadOptions(opts...)
 	if uo.ChunkSizeBytes != nil {
 		upload.chunkSize = *uo.ChunkSizeBytes
 	}
+	if uo.MaxConcurrentBatches != nil {
+		if *uo.MaxConcurrentBatches < 1 {
+			return nil, ErrInvalidConcurrency
+		}
+		upload.concurrency = *uo.MaxConcurrentBatches
+	}
+	if uo.Checksum != nil {
+		if _, err := newChecksumHash(*uo.Checksum); err != nil {
+			return nil, err
+		}
+		upload.checksum = *uo.Checksum
+	}
+	upload.transformer = uo.ChunkTransformer
 	if uo.Registry == nil {
 		uo.Registry = bson.DefaultRegistry
 	}
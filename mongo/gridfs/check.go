@@ -0,0 +1,450 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/mongo"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
+)
+
+// DefaultRepairGracePeriod is the default minimum age of orphaned chunks deleted by Bucket.Repair.
+const DefaultRepairGracePeriod = 24 * time.Hour
+
+// DefaultRepairBatchSize is the default maximum number of chunks deleted by a single delete operation in Bucket.Repair.
+const DefaultRepairBatchSize int32 = 1000
+
+// ProblemType describes the kind of integrity problem found by Bucket.Check.
+type ProblemType int
+
+const (
+	// OrphanedChunks indicates chunks whose file ID has no files collection document, for example because an upload
+	// was aborted or crashed.
+	OrphanedChunks ProblemType = iota
+
+	// MissingChunks indicates a file that has fewer chunks than its length and chunk size require, for example because
+	// a delete failed after the chunks were deleted.
+	MissingChunks
+
+	// InconsistentFile indicates a file whose chunks don't match the length and chunk size in its files collection
+	// document, for example because chunks have the wrong size or there are more chunks than expected.
+	InconsistentFile
+)
+
+// String implements the fmt.Stringer interface.
+func (pt ProblemType) String() string {
+	switch pt {
+	case OrphanedChunks:
+		return "orphaned chunks"
+	case MissingChunks:
+		return "missing chunks"
+	case InconsistentFile:
+		return "inconsistent file"
+	default:
+		return fmt.Sprintf("unknown problem type %d", int(pt))
+	}
+}
+
+// Problem is an integrity problem found by Bucket.Check.
+type Problem struct {
+	// Type is the kind of problem.
+	Type ProblemType
+
+	// FileID is the file ID of the orphaned chunks or the _id of the affected file.
+	FileID interface{}
+
+	// ChunkCount is the number of chunks stored for FileID.
+	ChunkCount int64
+
+	// Message describes the problem.
+	Message string
+}
+
+// CheckReport streams the problems found by Bucket.Check. The orphaned chunks are reported first, followed by the
+// problems of files in the files collection. A CheckReport must be closed when it is no longer needed.
+type CheckReport struct {
+	bucket  *Bucket
+	orphans *mongo.Cursor
+	files   *mongo.Cursor
+	current Problem
+	err     error
+}
+
+// Check scans the bucket for integrity problems and returns a CheckReport that streams them. Check reports orphaned
+// chunks, files with missing chunks, and files whose chunks are inconsistent with their length and chunk size. The
+// scan groups the whole chunks collection by file, so it can be expensive for large buckets. Check requires MongoDB 4.4
+// or later.
+func (b *Bucket) Check(ctx context.Context) (*CheckReport, error) {
+	orphans, err := b.aggregateOrphanedChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckReport{bucket: b, orphans: orphans}, nil
+}
+
+// Next advances the report to the next problem and returns true if there is one. It returns false when there are no
+// more problems or if an error occurred, in which case Err returns the error.
+func (r *CheckReport) Next(ctx context.Context) bool {
+	if r.err != nil {
+		return false
+	}
+
+	if r.orphans != nil {
+		if r.orphans.Next(ctx) {
+			r.current, r.err = orphanProblem(r.orphans.Current)
+			return r.err == nil
+		}
+		if r.err = r.orphans.Err(); r.err != nil {
+			return false
+		}
+		_ = r.orphans.Close(ctx)
+		r.orphans = nil
+
+		if r.files, r.err = r.bucket.aggregateFileChunks(ctx); r.err != nil {
+			return false
+		}
+	}
+
+	if r.files == nil {
+		return false
+	}
+	for r.files.Next(ctx) {
+		var found bool
+		r.current, found, r.err = fileProblem(r.files.Current)
+		if r.err != nil {
+			return false
+		}
+		if found {
+			return true
+		}
+	}
+	r.err = r.files.Err()
+	_ = r.files.Close(ctx)
+	r.files = nil
+	return false
+}
+
+// Problem returns the current problem.
+func (r *CheckReport) Problem() Problem {
+	return r.current
+}
+
+// Err returns the last error seen by the report, or nil if no error has occurred.
+func (r *CheckReport) Err() error {
+	return r.err
+}
+
+// Close closes the report and any open cursors.
+func (r *CheckReport) Close(ctx context.Context) error {
+	var err error
+	if r.orphans != nil {
+		err = r.orphans.Close(ctx)
+		r.orphans = nil
+	}
+	if r.files != nil {
+		if closeErr := r.files.Close(ctx); err == nil {
+			err = closeErr
+		}
+		r.files = nil
+	}
+	return err
+}
+
+// RepairResult is the result of a Bucket.Repair operation.
+type RepairResult struct {
+	// DeletedFileIDs are the file IDs whose orphaned chunks were deleted, or would be deleted in dry-run mode.
+	DeletedFileIDs []interface{}
+
+	// DeletedChunks is the number of orphaned chunks that were deleted, or would be deleted in dry-run mode.
+	DeletedChunks int64
+
+	// SkippedFileIDs are the file IDs whose orphaned chunks were kept because they are newer than the grace period.
+	SkippedFileIDs []interface{}
+}
+
+// Repair deletes orphaned chunks, which are chunks whose file ID has no files collection document. Orphaned chunks are
+// only deleted if they are older than the grace period, and they are deleted in batches of a bounded size. If the
+// DryRun option is set, Repair reports what would be deleted without deleting anything. Files with missing or
+// inconsistent chunks are not modified; use Check to find them.
+//
+// Use the context parameter to time-out or cancel the repair. Repair requires MongoDB 4.4 or later.
+func (b *Bucket) Repair(ctx context.Context, opts ...*options.GridFSRepairOptions) (*RepairResult, error) {
+	gracePeriod := DefaultRepairGracePeriod
+	batchSize := DefaultRepairBatchSize
+	var dryRun bool
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.GracePeriod != nil {
+			gracePeriod = *opt.GracePeriod
+		}
+		if opt.BatchSize != nil {
+			batchSize = *opt.BatchSize
+		}
+		if opt.DryRun != nil {
+			dryRun = *opt.DryRun
+		}
+	}
+	if batchSize <= 0 {
+		return nil, errors.New("repair batch size must be greater than 0")
+	}
+
+	ctx, cancel := timeoutContext(ctx, b.db)
+	defer cancel()
+
+	// Use the primary to see the latest writes of in-progress uploads.
+	filesColl, err := b.filesColl.Clone(options.Collection().SetReadPreference(readpref.Primary()))
+	if err != nil {
+		return nil, err
+	}
+
+	orphans, err := b.aggregateOrphanedChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = orphans.Close(ctx)
+	}()
+
+	result := &RepairResult{}
+	cutoff := time.Now().Add(-gracePeriod)
+	for orphans.Next(ctx) {
+		problem, err := orphanProblem(orphans.Current)
+		if err != nil {
+			return result, err
+		}
+
+		newest, isObjectID := orphans.Current.Lookup("newest").ObjectIDOK()
+		if gracePeriod > 0 && (!isObjectID || newest.Timestamp().After(cutoff)) {
+			result.SkippedFileIDs = append(result.SkippedFileIDs, problem.FileID)
+			continue
+		}
+
+		if dryRun {
+			result.DeletedFileIDs = append(result.DeletedFileIDs, problem.FileID)
+			result.DeletedChunks += problem.ChunkCount
+			continue
+		}
+
+		// An upload may have completed since the aggregation ran, so check for the files collection document again
+		// right before deleting the chunks.
+		err = filesColl.FindOne(ctx, bson.D{{"_id", problem.FileID}}, options.FindOne().SetProjection(bson.D{{"_id", 1}})).Err()
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return result, err
+		}
+
+		deleted, err := b.deleteChunksInBatches(ctx, problem.FileID, batchSize)
+		result.DeletedChunks += deleted
+		if err != nil {
+			return result, err
+		}
+		result.DeletedFileIDs = append(result.DeletedFileIDs, problem.FileID)
+	}
+
+	return result, orphans.Err()
+}
+
+// deleteChunksInBatches deletes all chunks of a file, deleting at most batchSize chunks per delete operation, and
+// returns the number of deleted chunks.
+func (b *Bucket) deleteChunksInBatches(ctx context.Context, fileID interface{}, batchSize int32) (int64, error) {
+	var deleted int64
+	for {
+		findOpts := options.Find().SetProjection(bson.D{{"_id", 1}}).SetLimit(int64(batchSize))
+		cursor, err := b.chunksColl.Find(ctx, bson.D{{"files_id", fileID}}, findOpts)
+		if err != nil {
+			return deleted, err
+		}
+
+		var chunks []struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &chunks); err != nil {
+			return deleted, err
+		}
+		if len(chunks) == 0 {
+			return deleted, nil
+		}
+
+		ids := make(bson.A, 0, len(chunks))
+		for _, chunk := range chunks {
+			ids = append(ids, chunk.ID)
+		}
+		res, err := b.chunksColl.DeleteMany(ctx, bson.D{{"_id", bson.D{{"$in", ids}}}})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += res.DeletedCount
+	}
+}
+
+// aggregateOrphanedChunks returns a cursor with one document per file ID in the chunks collection that has no files
+// collection document. The documents contain the file ID, the number of chunks and the _id of the newest chunk.
+func (b *Bucket) aggregateOrphanedChunks(ctx context.Context) (*mongo.Cursor, error) {
+	pipeline := mongo.Pipeline{
+		{{"$group", bson.D{
+			{"_id", "$files_id"},
+			{"count", bson.D{{"$sum", 1}}},
+			{"newest", bson.D{{"$max", "$_id"}}},
+		}}},
+		{{"$lookup", bson.D{
+			{"from", b.filesColl.Name()},
+			{"localField", "_id"},
+			{"foreignField", "_id"},
+			{"as", "file"},
+		}}},
+		{{"$match", bson.D{{"file", bson.D{{"$size", 0}}}}}},
+		{{"$project", bson.D{{"file", 0}}}},
+	}
+
+	return b.chunksColl.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+}
+
+// aggregateFileChunks returns a cursor with one document per file in the files collection. Each document contains
+// the file's length and chunk size, and a summary of its chunks: the number of chunks, the lowest and highest chunk
+// index, the total number of bytes, the size of the largest chunk and the size of the chunk with the highest index.
+func (b *Bucket) aggregateFileChunks(ctx context.Context) (*mongo.Cursor, error) {
+	size := bson.D{{"$binarySize", "$data"}}
+	pipeline := mongo.Pipeline{
+		// Summarize the chunks of each file without keeping their data. The chunks are sorted by index so the last
+		// chunk of each group is the chunk with the highest index.
+		{{"$sort", bson.D{{"files_id", 1}, {"n", 1}}}},
+		{{"$group", bson.D{
+			{"_id", "$files_id"},
+			{"count", bson.D{{"$sum", 1}}},
+			{"minN", bson.D{{"$min", "$n"}}},
+			{"maxN", bson.D{{"$max", "$n"}}},
+			{"bytes", bson.D{{"$sum", size}}},
+			{"maxSize", bson.D{{"$max", size}}},
+			{"lastSize", bson.D{{"$last", size}}},
+		}}},
+		{{"$project", bson.D{
+			{"chunks", bson.D{
+				{"count", "$count"},
+				{"minN", "$minN"},
+				{"maxN", "$maxN"},
+				{"bytes", "$bytes"},
+				{"maxSize", "$maxSize"},
+				{"lastSize", "$lastSize"},
+			}},
+		}}},
+		// Join the summaries with the files collection documents, including the files that have no chunks.
+		{{"$unionWith", bson.D{
+			{"coll", b.filesColl.Name()},
+			{"pipeline", bson.A{
+				bson.D{{"$project", bson.D{{"length", 1}, {"chunkSize", 1}, {"transform.id", 1}, {"isFile", true}}}},
+			}},
+		}}},
+		{{"$group", bson.D{{"_id", "$_id"}, {"doc", bson.D{{"$mergeObjects", "$$ROOT"}}}}}},
+		{{"$replaceRoot", bson.D{{"newRoot", "$doc"}}}},
+		{{"$match", bson.D{{"isFile", true}}}},
+	}
+
+	return b.chunksColl.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+}
+
+// orphanProblem creates a Problem from a document returned by aggregateOrphanedChunks.
+func orphanProblem(doc bson.Raw) (Problem, error) {
+	var fileID interface{}
+	if err := doc.Lookup("_id").Unmarshal(&fileID); err != nil {
+		return Problem{}, err
+	}
+
+	count, _ := doc.Lookup("count").AsInt64OK()
+	return Problem{
+		Type:       OrphanedChunks,
+		FileID:     fileID,
+		ChunkCount: count,
+		Message:    fmt.Sprintf("%d chunks have no files collection document", count),
+	}, nil
+}
+
+// fileProblem checks a document returned by aggregateFileChunks and returns the file's problem, if it has one.
+func fileProblem(doc bson.Raw) (Problem, bool, error) {
+	var fileID interface{}
+	if err := doc.Lookup("_id").Unmarshal(&fileID); err != nil {
+		return Problem{}, false, err
+	}
+
+	length, _ := doc.Lookup("length").AsInt64OK()
+	chunkSize, hasChunkSize := doc.Lookup("chunkSize").AsInt64OK()
+	summary, _ := doc.Lookup("chunks").DocumentOK()
+	count, _ := summary.Lookup("count").AsInt64OK()
+
+	problem := Problem{FileID: fileID, ChunkCount: count}
+	if length == 0 {
+		if count == 0 {
+			return problem, false, nil
+		}
+		problem.Type = InconsistentFile
+		problem.Message = fmt.Sprintf("empty file has %d chunks", count)
+		return problem, true, nil
+	}
+	if !hasChunkSize || chunkSize <= 0 {
+		problem.Type = InconsistentFile
+		problem.Message = "files collection document has no valid chunkSize"
+		return problem, true, nil
+	}
+
+	expected := (length + chunkSize - 1) / chunkSize
+	if count == 0 {
+		problem.Type = MissingChunks
+		problem.Message = fmt.Sprintf("file has no chunks, expected %d", expected)
+		return problem, true, nil
+	}
+
+	minN, _ := summary.Lookup("minN").AsInt64OK()
+	maxN, _ := summary.Lookup("maxN").AsInt64OK()
+	bytes, _ := summary.Lookup("bytes").AsInt64OK()
+	maxSize, _ := summary.Lookup("maxSize").AsInt64OK()
+	lastSize, _ := summary.Lookup("lastSize").AsInt64OK()
+	expectedLastSize := length - (expected-1)*chunkSize
+
+	switch {
+	case count > expected:
+		problem.Type = InconsistentFile
+		problem.Message = fmt.Sprintf("file has %d chunks, expected %d", count, expected)
+	case count < expected || minN != 0 || maxN != expected-1:
+		problem.Type = MissingChunks
+		problem.Message = fmt.Sprintf("file has %d chunks with indexes %d to %d, expected %d chunks", count, minN, maxN,
+			expected)
+	case hasTransform(doc):
+		// The stored chunks are encoded, so their sizes can't be compared with the chunk size and length. Use
+		// Bucket.Verify to decode and check the chunks.
+		return problem, false, nil
+	case maxSize > chunkSize:
+		problem.Type = InconsistentFile
+		problem.Message = fmt.Sprintf("a chunk has %d bytes, more than the chunk size %d", maxSize, chunkSize)
+	case lastSize != expectedLastSize:
+		problem.Type = InconsistentFile
+		problem.Message = fmt.Sprintf("last chunk has %d bytes, expected %d", lastSize, expectedLastSize)
+	case bytes != length:
+		// No chunk is larger than the chunk size and the last chunk has the right size, so one of the other chunks is
+		// smaller than the chunk size.
+		problem.Type = InconsistentFile
+		problem.Message = fmt.Sprintf("chunks contain %d bytes, but the file length is %d", bytes, length)
+	default:
+		return problem, false, nil
+	}
+	return problem, true, nil
+}
+
+// hasTransform reports whether the file described by a files collection document was uploaded with a chunk transformer.
+func hasTransform(doc bson.Raw) bool {
+	_, err := doc.LookupErr("transform")
+	return err == nil
+}
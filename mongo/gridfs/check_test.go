@@ -0,0 +1,60 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"testing"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+)
+
+func TestFileProblem(t *testing.T) {
+	// chunks creates the chunk summary of a file with an 11 byte length and a 4 byte chunk size.
+	chunks := func(count, minN, maxN, bytes, maxSize, lastSize int32) bson.D {
+		return bson.D{
+			{"count", count},
+			{"minN", minN},
+			{"maxN", maxN},
+			{"bytes", bytes},
+			{"maxSize", maxSize},
+			{"lastSize", lastSize},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		chunks bson.D
+		found  bool
+		typ    ProblemType
+	}{
+		{"valid", chunks(3, 0, 2, 11, 4, 3), false, 0},
+		{"no chunks", nil, true, MissingChunks},
+		{"missing chunk", chunks(2, 0, 2, 7, 4, 3), true, MissingChunks},
+		{"extra chunk", chunks(4, 0, 3, 14, 4, 3), true, InconsistentFile},
+		{"large chunk", chunks(3, 0, 2, 11, 5, 2), true, InconsistentFile},
+		{"wrong last chunk", chunks(3, 0, 2, 11, 4, 4), true, InconsistentFile},
+		{"short chunk", chunks(3, 0, 2, 9, 4, 3), true, InconsistentFile},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := bson.D{{"_id", "file"}, {"length", int64(11)}, {"chunkSize", int32(4)}}
+			if tc.chunks != nil {
+				file = append(file, bson.E{"chunks", tc.chunks})
+			}
+			doc, err := bson.Marshal(file)
+			assert.Nil(t, err, "Marshal error: %v", err)
+
+			problem, found, err := fileProblem(doc)
+			assert.Nil(t, err, "fileProblem error: %v", err)
+			assert.Equal(t, tc.found, found, "expected found %v, got %v (%v)", tc.found, found, problem.Message)
+			if tc.found {
+				assert.Equal(t, tc.typ, problem.Type, "expected problem type %v, got %v", tc.typ, problem.Type)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+)
+
+// ErrUnsupportedChecksum occurs when uploading or verifying a file with a checksum algorithm that is not supported.
+var ErrUnsupportedChecksum = errors.New("unsupported checksum algorithm")
+
+// ErrChecksumMismatch is returned when the checksum of the downloaded content of a file doesn't match the checksum
+// stored in its files collection document.
+type ErrChecksumMismatch struct {
+	// Algorithm is the checksum algorithm.
+	Algorithm options.ChecksumAlgorithm
+
+	// Expected is the hex-encoded checksum stored in the files collection document.
+	Expected string
+
+	// Actual is the hex-encoded checksum of the downloaded content.
+	Actual string
+}
+
+// Error implements the error interface.
+func (e ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("%s checksum mismatch: expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+}
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// newChecksumHash returns a hash that computes checksums with the given algorithm.
+func newChecksumHash(algorithm options.ChecksumAlgorithm) (hash.Hash, error) {
+	switch algorithm {
+	case options.ChecksumSHA256:
+		return sha256.New(), nil
+	case options.ChecksumCRC32C:
+		return crc32.New(crc32cTable), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedChecksum, algorithm)
+	}
+}
+
+// checksumDoc returns the value of the "checksum" field for a files collection document.
+func checksumDoc(algorithm options.ChecksumAlgorithm, h hash.Hash) bson.D {
+	return bson.D{
+		{"algorithm", string(algorithm)},
+		{"digest", hex.EncodeToString(h.Sum(nil))},
+	}
+}
+
+// fileChecksum returns the checksum algorithm and the hex-encoded digest stored in a files collection document. ok is
+// false if the document does not have a checksum.
+func fileChecksum(doc bson.Raw) (algorithm options.ChecksumAlgorithm, digest string, ok bool) {
+	alg, algOK := doc.Lookup("checksum", "algorithm").StringValueOK()
+	digest, digestOK := doc.Lookup("checksum", "digest").StringValueOK()
+	if !algOK || !digestOK {
+		return "", "", false
+	}
+	return options.ChecksumAlgorithm(alg), digest, true
+}
+
+// checkChecksum compares the digest computed by h with the expected hex-encoded digest.
+func checkChecksum(algorithm options.ChecksumAlgorithm, expected string, h hash.Hash) error {
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return ErrChecksumMismatch{
+			Algorithm: algorithm,
+			Expected:  expected,
+			Actual:    actual,
+		}
+	}
+	return nil
+}
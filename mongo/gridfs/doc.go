@@ -24,6 +24,17 @@
 //  2. UploadFromStream/UploadFromStreamWithID - These methods take an io.Reader, which represents the file to
 //     upload. They internally create a new UploadStream and close it once the operation is complete.
 //
+// Chunk batches are inserted sequentially by default. The MaxConcurrentBatches upload option allows several batches to
+// be inserted concurrently. If an upload is interrupted before the files collection document is written, ResumeUpload
+// can continue it from the chunks that are already stored. The Checksum upload option stores a checksum of the file
+// content in the files collection document, which can be checked with DownloadStream.SetVerifyChecksum or
+// Bucket.Verify.
+//
+// The ChunkTransformer upload option encodes the data of each chunk before it is uploaded. NewZstdTransformer and
+// NewSnappyTransformer compress chunks and NewAESGCMTransformer encrypts them. The transformer is recorded in the files
+// collection document and chunks are decoded when the file is downloaded. Transformers other than the compression
+// transformers must be made available for downloads with the ChunkTransformers bucket option.
+//
 // # Downloading a File
 //
 // Similar to uploads, files can be downloaded in two ways:
@@ -34,4 +45,27 @@
 //
 //  2. DownloadToStream/DownloadToStreamByName - These methods take an io.Writer, which represents the download
 //     destination. They internally create a new DownloadStream and close it once the operation is complete.
+//
+// DownloadStream also implements io.Seeker and io.ReaderAt, which can be used to read byte ranges of a file without
+// downloading the chunks before them. OpenDownloadStreamRange opens a stream that reads a single byte range.
+//
+// # Contexts and Timeouts
+//
+// Every Bucket operation has a variant that takes a context, such as UploadFromStreamContext and
+// DownloadToStreamContext. If Timeout is set on the Database of the bucket or on its Client, it applies to the whole
+// operation rather than to each chunk insert or find. Streams opened with OpenUploadStreamContext or
+// OpenDownloadStreamContext use their context for all of their operations, and the stream methods also have variants
+// that take a context, such as WriteContext and ReadContext. The methods that don't take a context use the deadlines
+// set with SetReadDeadline and SetWriteDeadline.
+//
+// # File Systems and HTTP
+//
+// FS exposes a bucket as an io/fs file system in which filenames are used as slash-separated paths. Handler serves the
+// files in a bucket over HTTP with support for range and conditional requests.
+//
+// # Checking and Repairing a Bucket
+//
+// Bucket.Check reports orphaned chunks left behind by aborted or crashed uploads, files with missing chunks, and files
+// whose chunks don't match their length and chunk size. Bucket.Repair deletes orphaned chunks that are older than a
+// grace period.
 package gridfs
@@ -9,12 +9,15 @@ package gridfs
 import (
 	"context"
 	"errors"
+	"hash"
 	"io"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/bson"
 	"github.com/hongyuyang/mongo-go-driver/mongo"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
 )
 
 // ErrWrongIndex is used when the chunk retrieved from the server does not have the expected index.
@@ -23,9 +26,18 @@ var ErrWrongIndex = errors.New("chunk index does not match expected index")
 // ErrWrongSize is used when the chunk retrieved from the server does not have the expected size.
 var ErrWrongSize = errors.New("chunk size does not match expected size")
 
+// ErrInvalidRange is used when a download range or seek position is outside of the file.
+var ErrInvalidRange = errors.New("invalid download range")
+
 var errNoMoreChunks = errors.New("no more chunks remaining")
 
-// DownloadStream is a io.Reader that can be used to download a file from a GridFS bucket.
+var errInvalidWhence = errors.New("invalid whence")
+
+// chunkCacheSize is the maximum number of chunks kept in a DownloadStream's chunk cache for ReadAt.
+const chunkCacheSize = 4
+
+// DownloadStream is a io.Reader that can be used to download a file from a GridFS bucket. DownloadStream also
+// implements io.Seeker and io.ReaderAt, so byte ranges of a file can be read without downloading the preceding chunks.
 type DownloadStream struct {
 	numChunks     int32
 	chunkSize     int32
@@ -39,6 +51,23 @@ type DownloadStream struct {
 	readDeadline  time.Time
 	fileLen       int64
 
+	chunksColl *mongo.Collection
+	fileID     interface{}
+	offset     int64           // position in the file of the next byte returned by Read
+	end        int64           // position in the file at which Read returns io.EOF
+	reposition bool            // the cursor must be reopened at offset before the next read
+	cache      *chunkCache     // chunks fetched by ReadAt
+	transform  *chunkTransform // decodes the chunks, nil if the file was uploaded without a chunk transformer
+
+	ctx    context.Context    // context for all operations, nil if the stream was opened without a context
+	cancel context.CancelFunc // cancels the Timeout context created when the stream was opened, may be nil
+
+	checksumAlgorithm options.ChecksumAlgorithm // empty if the file has no checksum
+	checksumDigest    string                    // hex-encoded checksum from the files collection document
+	verifier          hash.Hash                 // hashes the chunks that are read if checksum verification is enabled
+	verifiedLen       int64                     // number of bytes hashed by verifier
+	checksumErr       error                     // result of the checksum verification, returned at EOF
+
 	// The pointer returned by GetFile. This should not be used in the actual DownloadStream code outside of the
 	// newDownloadStream constructor because the values can be mutated by the user after calling GetFile. Instead,
 	// any values needed in the code should be stored separately and copied over in the constructor.
@@ -101,34 +130,50 @@ func (f *File) UnmarshalBSON(data []byte) error {
 	return nil
 }
 
-func newDownloadStream(cursor *mongo.Cursor, chunkSize int32, file *File) *DownloadStream {
+func newDownloadStream(cursor *mongo.Cursor, chunks *mongo.Collection, chunkSize int32, file *File, doc bson.Raw, transform *chunkTransform) *DownloadStream {
 	numChunks := int32(math.Ceil(float64(file.Length) / float64(chunkSize)))
+	checksumAlgorithm, checksumDigest, _ := fileChecksum(doc)
 
 	return &DownloadStream{
-		numChunks: numChunks,
-		chunkSize: chunkSize,
-		cursor:    cursor,
-		buffer:    make([]byte, chunkSize),
-		done:      cursor == nil,
-		fileLen:   file.Length,
-		file:      file,
+		numChunks:  numChunks,
+		chunkSize:  chunkSize,
+		cursor:     cursor,
+		buffer:     make([]byte, chunkSize),
+		fileLen:    file.Length,
+		chunksColl: chunks,
+		fileID:     file.ID,
+		end:        file.Length,
+		reposition: cursor == nil, // without a cursor, the chunks are found on the first read
+		cache:      &chunkCache{},
+		transform:  transform,
+		file:       file,
+
+		checksumAlgorithm: checksumAlgorithm,
+		checksumDigest:    checksumDigest,
 	}
 }
 
 // Close closes this download stream.
 func (ds *DownloadStream) Close() error {
+	return ds.CloseContext(context.Background())
+}
+
+// CloseContext closes this download stream and closes the chunks cursor with the provided context.
+func (ds *DownloadStream) CloseContext(ctx context.Context) error {
 	if ds.closed {
 		return ErrStreamClosed
 	}
 
 	ds.closed = true
+	defer ds.releaseContext()
 	if ds.cursor != nil {
-		return ds.cursor.Close(context.Background())
+		return ds.cursor.Close(ctx)
 	}
 	return nil
 }
 
-// SetReadDeadline sets the read deadline for this download stream.
+// SetReadDeadline sets the read deadline for this download stream. The deadline is ignored if the stream was opened
+// with a context.
 func (ds *DownloadStream) SetReadDeadline(t time.Time) error {
 	if ds.closed {
 		return ErrStreamClosed
@@ -138,19 +183,59 @@ func (ds *DownloadStream) SetReadDeadline(t time.Time) error {
 	return nil
 }
 
+// SetVerifyChecksum enables or disables checksum verification for this download stream. If verification is enabled and
+// the file was uploaded with a checksum, the checksum of the file content is computed while the file is read, and
+// Read returns an ErrChecksumMismatch instead of io.EOF if it doesn't match the checksum stored for the file.
+//
+// The checksum is only verified if every chunk of the file is read in order, so verification must be enabled before
+// the first Read and is skipped if the stream seeks to another chunk or was opened for a range of the file. Files
+// without a checksum are not verified. ErrUnsupportedChecksum is returned if the file's checksum algorithm is not
+// supported.
+func (ds *DownloadStream) SetVerifyChecksum(verify bool) error {
+	if ds.closed {
+		return ErrStreamClosed
+	}
+
+	ds.verifier = nil
+	ds.verifiedLen = 0
+	if !verify || ds.checksumAlgorithm == "" {
+		return nil
+	}
+
+	verifier, err := newChecksumHash(ds.checksumAlgorithm)
+	if err != nil {
+		return err
+	}
+	ds.verifier = verifier
+	return nil
+}
+
 // Read reads the file from the server and writes it to a destination byte slice.
 func (ds *DownloadStream) Read(p []byte) (int, error) {
+	ctx, cancel := ds.readContext()
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return ds.ReadContext(ctx, p)
+}
+
+// ReadContext reads the file from the server and writes it to a destination byte slice, running any chunk finds with
+// the provided context.
+//
+// Use the context parameter to time-out or cancel the read. The deadline set by SetReadDeadline is ignored.
+func (ds *DownloadStream) ReadContext(ctx context.Context, p []byte) (int, error) {
 	if ds.closed {
 		return 0, ErrStreamClosed
 	}
 
-	if ds.done {
-		return 0, io.EOF
+	if ds.done || ds.offset >= ds.end {
+		return 0, ds.eof()
 	}
 
-	ctx, cancel := deadlineContext(ds.readDeadline)
-	if cancel != nil {
-		defer cancel()
+	// Never read past the end of the requested range.
+	if remaining := ds.end - ds.offset; int64(len(p)) > remaining {
+		p = p[:remaining]
 	}
 
 	bytesCopied := 0
@@ -163,7 +248,7 @@ func (ds *DownloadStream) Read(p []byte) (int, error) {
 				if errors.Is(err, errNoMoreChunks) {
 					if bytesCopied == 0 {
 						ds.done = true
-						return 0, io.EOF
+						return 0, ds.eof()
 					}
 					return bytesCopied, nil
 				}
@@ -175,6 +260,7 @@ func (ds *DownloadStream) Read(p []byte) (int, error) {
 
 		bytesCopied += copied
 		ds.bufferStart += copied
+		ds.offset += int64(copied)
 	}
 
 	return len(p), nil
@@ -182,17 +268,29 @@ func (ds *DownloadStream) Read(p []byte) (int, error) {
 
 // Skip skips a given number of bytes in the file.
 func (ds *DownloadStream) Skip(skip int64) (int64, error) {
+	ctx, cancel := ds.readContext()
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return ds.SkipContext(ctx, skip)
+}
+
+// SkipContext skips a given number of bytes in the file, running any chunk finds with the provided context.
+//
+// Use the context parameter to time-out or cancel the operation. The deadline set by SetReadDeadline is ignored.
+func (ds *DownloadStream) SkipContext(ctx context.Context, skip int64) (int64, error) {
 	if ds.closed {
 		return 0, ErrStreamClosed
 	}
 
-	if ds.done {
+	if ds.done || ds.offset >= ds.end {
 		return 0, nil
 	}
 
-	ctx, cancel := deadlineContext(ds.readDeadline)
-	if cancel != nil {
-		defer cancel()
+	// Never skip past the end of the requested range.
+	if remaining := ds.end - ds.offset; skip > remaining {
+		skip = remaining
 	}
 
 	var skipped int64
@@ -219,17 +317,274 @@ func (ds *DownloadStream) Skip(skip int64) (int64, error) {
 
 		skipped += toSkip
 		ds.bufferStart += int(toSkip)
+		ds.offset += toSkip
 	}
 
 	return skip, nil
 }
 
+// Seek sets the position in the file of the next Read or Skip and implements the io.Seeker interface. Positions are
+// always relative to the whole file, even if the stream was opened with Bucket.OpenDownloadStreamRange. Seeking does
+// not contact the server: the chunks starting at the new position are fetched by the next call to Read or Skip. It is
+// not an error to seek past the end of the file, but subsequent reads will return io.EOF.
+func (ds *DownloadStream) Seek(offset int64, whence int) (int64, error) {
+	if ds.closed {
+		return 0, ErrStreamClosed
+	}
+
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = ds.offset + offset
+	case io.SeekEnd:
+		pos = ds.fileLen + offset
+	default:
+		return 0, errInvalidWhence
+	}
+	if pos < 0 {
+		return 0, ErrInvalidRange
+	}
+	if pos == ds.offset {
+		return pos, nil
+	}
+
+	// If the new position is inside the chunk that is currently buffered, the buffer can be reused. Otherwise, the
+	// cursor is reopened at the chunk containing the new position on the next read.
+	if ds.bufferEnd > 0 {
+		bufferOffset := int64(ds.expectedChunk-1) * int64(ds.chunkSize)
+		if pos >= bufferOffset && pos < bufferOffset+int64(ds.bufferEnd) {
+			ds.bufferStart = int(pos - bufferOffset)
+			ds.offset = pos
+			return pos, nil
+		}
+	}
+
+	ds.bufferStart = 0
+	ds.bufferEnd = 0
+	ds.offset = pos
+	// The open cursor can be kept if the next chunk it returns contains the new position.
+	if ds.cursor == nil || ds.done || pos/int64(ds.chunkSize) != int64(ds.expectedChunk) {
+		ds.reposition = true
+		ds.done = false
+	}
+	return pos, nil
+}
+
+// ReadAt reads len(p) bytes starting at byte offset off in the file and implements the io.ReaderAt interface. ReadAt
+// does not use or change the position used by Read, Skip and Seek, and it is safe to call ReadAt concurrently from
+// multiple goroutines. The chunks covering the requested range are fetched from the chunks collection with a
+// targeted find and the most recently used chunks are cached.
+func (ds *DownloadStream) ReadAt(p []byte, off int64) (int, error) {
+	ctx, cancel := ds.readContext()
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return ds.ReadAtContext(ctx, p, off)
+}
+
+// ReadAtContext reads len(p) bytes starting at byte offset off in the file, running any chunk finds with the provided
+// context. See ReadAt for details.
+//
+// Use the context parameter to time-out or cancel the read. The deadline set by SetReadDeadline is ignored.
+func (ds *DownloadStream) ReadAtContext(ctx context.Context, p []byte, off int64) (int, error) {
+	if ds.closed {
+		return 0, ErrStreamClosed
+	}
+	if off < 0 {
+		return 0, ErrInvalidRange
+	}
+	if off >= ds.fileLen {
+		return 0, io.EOF
+	}
+
+	end := off + int64(len(p))
+	if end > ds.fileLen {
+		end = ds.fileLen
+	}
+
+	chunkSize := int64(ds.chunkSize)
+	lastChunk := int32((end - 1) / chunkSize)
+
+	bytesCopied := 0
+	for pos := off; pos < end; pos = off + int64(bytesCopied) {
+		n := int32(pos / chunkSize)
+		data, err := ds.loadChunk(ctx, n, lastChunk)
+		if err != nil {
+			return bytesCopied, err
+		}
+
+		bytesCopied += copy(p[bytesCopied:end-off], data[pos-int64(n)*chunkSize:])
+	}
+
+	if bytesCopied < len(p) {
+		return bytesCopied, io.EOF
+	}
+	return bytesCopied, nil
+}
+
+// eof returns the error that Read returns at the end of the stream: an ErrChecksumMismatch if checksum verification
+// is enabled and failed, or io.EOF otherwise.
+func (ds *DownloadStream) eof() error {
+	if ds.verifier != nil && ds.verifiedLen == ds.fileLen && ds.offset >= ds.fileLen {
+		ds.checksumErr = checkChecksum(ds.checksumAlgorithm, ds.checksumDigest, ds.verifier)
+		ds.verifier = nil
+	}
+
+	if ds.checksumErr != nil {
+		return ds.checksumErr
+	}
+	return io.EOF
+}
+
+// readContext returns the context for an operation that was called without a context: the context the stream was
+// opened with, or a context with the stream's read deadline.
+func (ds *DownloadStream) readContext() (context.Context, context.CancelFunc) {
+	if ds.ctx != nil {
+		return ds.ctx, nil
+	}
+	return deadlineContext(ds.readDeadline)
+}
+
+// releaseContext cancels the Timeout context created when the stream was opened, if there is one.
+func (ds *DownloadStream) releaseContext() {
+	if ds.cancel != nil {
+		ds.cancel()
+		ds.cancel = nil
+	}
+}
+
 // GetFile returns a File object representing the file being downloaded.
 func (ds *DownloadStream) GetFile() *File {
 	return ds.file
 }
 
+// loadChunk returns the data of chunk n from the chunk cache. On a cache miss, chunk n and the chunks after it up to
+// lastChunk are fetched from the server with a single find and added to the cache.
+func (ds *DownloadStream) loadChunk(ctx context.Context, n, lastChunk int32) ([]byte, error) {
+	if data, ok := ds.cache.get(n); ok {
+		return data, nil
+	}
+
+	// Don't fetch more chunks than the cache can hold, because the chunks fetched first would be evicted before they
+	// are used.
+	limit := int64(lastChunk-n) + 1
+	if limit > chunkCacheSize {
+		limit = chunkCacheSize
+	}
+
+	cursor, err := findChunksFrom(ctx, ds.chunksColl, ds.fileID, n, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	var first []byte
+	for expected := n; expected < n+int32(limit); expected++ {
+		if !cursor.Next(ctx) {
+			if err := cursor.Err(); err != nil {
+				return nil, err
+			}
+			// The chunks collection is missing chunks that are needed to read the requested range.
+			return nil, ErrWrongIndex
+		}
+
+		data, err := ds.chunkData(cursor.Current, expected)
+		if err != nil {
+			return nil, err
+		}
+
+		// The cursor reuses its buffers, so the chunk data must be copied before it is cached.
+		data = append([]byte(nil), data...)
+		ds.cache.add(expected, data)
+		if first == nil {
+			first = data
+		}
+	}
+
+	return first, nil
+}
+
+// chunkData validates that the chunks collection document has the expected chunk index and the expected number of
+// data bytes for that index and returns the chunk's data. If the file was uploaded with a chunk transformer, the data is
+// decoded before its size is validated.
+func (ds *DownloadStream) chunkData(doc bson.Raw, expected int32) ([]byte, error) {
+	chunkIndex, err := doc.LookupErr("n")
+	if err != nil {
+		return nil, err
+	}
+
+	var chunkIndexInt32 int32
+	if chunkIndexInt64, ok := chunkIndex.Int64OK(); ok {
+		chunkIndexInt32 = int32(chunkIndexInt64)
+	} else {
+		chunkIndexInt32 = chunkIndex.Int32()
+	}
+
+	if chunkIndexInt32 != expected {
+		return nil, ErrWrongIndex
+	}
+
+	data, err := doc.LookupErr("data")
+	if err != nil {
+		return nil, err
+	}
+
+	_, dataBytes := data.Binary()
+
+	expectedSize := expectedChunkSize(ds.fileLen, ds.chunkSize, int64(expected))
+	if ds.transform != nil {
+		if dataBytes, err = ds.transform.decode(ds.fileID, expected, dataBytes, expectedSize); err != nil {
+			return nil, err
+		}
+	}
+
+	if int64(len(dataBytes)) != expectedSize {
+		return nil, ErrWrongSize
+	}
+
+	return dataBytes, nil
+}
+
+// expectedChunkSize returns the number of bytes that chunk n of a file with the given length and chunk size must have.
+func expectedChunkSize(fileLen int64, chunkSize int32, n int64) int64 {
+	// final chunk can be fewer than chunkSize bytes, all intermediate chunks must have size chunkSize
+	if remaining := fileLen - int64(chunkSize)*n; remaining < int64(chunkSize) {
+		return remaining
+	}
+	return int64(chunkSize)
+}
+
+// reopenCursor replaces the chunks cursor with one that starts at the chunk containing ds.offset.
+func (ds *DownloadStream) reopenCursor(ctx context.Context) error {
+	if ds.cursor != nil {
+		_ = ds.cursor.Close(ctx)
+		ds.cursor = nil
+	}
+
+	n := int32(ds.offset / int64(ds.chunkSize))
+	cursor, err := findChunksFrom(ctx, ds.chunksColl, ds.fileID, n, 0)
+	if err != nil {
+		return err
+	}
+
+	ds.cursor = cursor
+	ds.expectedChunk = n
+	ds.reposition = false
+	return nil
+}
+
 func (ds *DownloadStream) fillBuffer(ctx context.Context) error {
+	if ds.reposition {
+		if err := ds.reopenCursor(ctx); err != nil {
+			return err
+		}
+	}
+
 	if !ds.cursor.Next(ctx) {
 		ds.done = true
 		// Check for cursor error, otherwise there are no more chunks.
@@ -245,47 +600,68 @@ func (ds *DownloadStream) fillBuffer(ctx context.Context) error {
 		return errNoMoreChunks
 	}
 
-	chunkIndex, err := ds.cursor.Current.LookupErr("n")
+	dataBytes, err := ds.chunkData(ds.cursor.Current, ds.expectedChunk)
 	if err != nil {
 		return err
 	}
 
-	var chunkIndexInt32 int32
-	if chunkIndexInt64, ok := chunkIndex.Int64OK(); ok {
-		chunkIndexInt32 = int32(chunkIndexInt64)
-	} else {
-		chunkIndexInt32 = chunkIndex.Int32()
-	}
-
-	if chunkIndexInt32 != ds.expectedChunk {
-		return ErrWrongIndex
-	}
-
+	// The first chunk read after a seek may start before the current position, so skip the bytes in front of it.
+	chunkOffset := int64(ds.expectedChunk) * int64(ds.chunkSize)
 	ds.expectedChunk++
-	data, err := ds.cursor.Current.LookupErr("data")
-	if err != nil {
-		return err
+
+	if ds.verifier != nil {
+		if chunkOffset == ds.verifiedLen {
+			_, _ = ds.verifier.Write(dataBytes)
+			ds.verifiedLen += int64(len(dataBytes))
+		} else {
+			// A chunk was skipped by seeking, so the checksum can't be verified.
+			ds.verifier = nil
+		}
 	}
 
-	_, dataBytes := data.Binary()
 	copied := copy(ds.buffer, dataBytes)
+	ds.bufferStart = int(ds.offset - chunkOffset)
+	ds.bufferEnd = copied
+
+	return nil
+}
 
-	bytesLen := int32(len(dataBytes))
-	if ds.expectedChunk == ds.numChunks {
-		// final chunk can be fewer than ds.chunkSize bytes
-		bytesDownloaded := int64(ds.chunkSize) * (int64(ds.expectedChunk) - int64(1))
-		bytesRemaining := ds.fileLen - bytesDownloaded
+// chunkCache is a small least-recently-used cache of chunk data keyed by chunk index.
+type chunkCache struct {
+	mu     sync.Mutex
+	chunks []cachedChunk // ordered from least to most recently used
+}
+
+type cachedChunk struct {
+	n    int32
+	data []byte
+}
 
-		if int64(bytesLen) != bytesRemaining {
-			return ErrWrongSize
+func (c *chunkCache) get(n int32) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, chunk := range c.chunks {
+		if chunk.n == n {
+			c.chunks = append(append(c.chunks[:i], c.chunks[i+1:]...), chunk)
+			return chunk.data, true
 		}
-	} else if bytesLen != ds.chunkSize {
-		// all intermediate chunks must have size ds.chunkSize
-		return ErrWrongSize
 	}
+	return nil, false
+}
 
-	ds.bufferStart = 0
-	ds.bufferEnd = copied
+func (c *chunkCache) add(n int32, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return nil
+	for i, chunk := range c.chunks {
+		if chunk.n == n {
+			c.chunks = append(c.chunks[:i], c.chunks[i+1:]...)
+			break
+		}
+	}
+	if len(c.chunks) == chunkCacheSize {
+		c.chunks = c.chunks[1:]
+	}
+	c.chunks = append(c.chunks, cachedChunk{n: n, data: data})
 }
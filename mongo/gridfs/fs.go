@@ -0,0 +1,374 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+)
+
+var errIsDirectory = errors.New("is a directory")
+
+// FS returns a read-only file system backed by the files collection of a GridFS bucket. The returned value implements
+// fs.FS, fs.ReadDirFS and fs.StatFS.
+//
+// Filenames are used as slash-separated paths. A directory exists implicitly for every path prefix of a filename, so a
+// file named "videos/2024/intro.mp4" is listed in the "videos" and "videos/2024" directories. Files whose names are not
+// valid fs paths (for example, names that start with a slash) cannot be opened through the file system.
+//
+// If there are multiple revisions of a file, the NameOptions.Revision option selects the revision that is exposed. The
+// default is the most recent revision. Files opened from the file system are DownloadStreams, so they also implement
+// io.Seeker and io.ReaderAt and can be served with http.FS.
+//
+// The file system uses the bucket's read deadline for all operations. Files opened from the file system use the read
+// deadline the bucket had when they were opened, which can be changed with DownloadStream.SetReadDeadline.
+func FS(bucket *Bucket, opts ...*options.NameOptions) fs.ReadDirFS {
+	return &bucketFS{
+		bucket:   bucket,
+		nameOpts: opts,
+	}
+}
+
+type bucketFS struct {
+	bucket   *Bucket
+	nameOpts []*options.NameOptions
+}
+
+var _ fs.ReadDirFS = (*bucketFS)(nil)
+var _ fs.StatFS = (*bucketFS)(nil)
+
+// Open implements the fs.FS interface.
+func (fsys *bucketFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	ctx, cancel := deadlineContext(fsys.bucket.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	file, doc, err := fsys.findFile(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if file != nil {
+		ds, err := fsys.bucket.openFileDownloadStream(ctx, file, doc)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		ds.readDeadline = fsys.bucket.readDeadline
+		return &fsFile{DownloadStream: ds, info: &fileInfo{name: path.Base(name), file: file}}, nil
+	}
+
+	isDir, err := fsys.isDir(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if !isDir {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &fsDir{fsys: fsys, path: name, info: &fileInfo{name: path.Base(name)}}, nil
+}
+
+// ReadDir implements the fs.ReadDirFS interface.
+func (fsys *bucketFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	ctx, cancel := deadlineContext(fsys.bucket.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	entries, err := fsys.readDir(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	if len(entries) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+// Stat implements the fs.StatFS interface. Stat only reads the files collection.
+func (fsys *bucketFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	ctx, cancel := deadlineContext(fsys.bucket.readDeadline)
+	if cancel != nil {
+		defer cancel()
+	}
+
+	file, _, err := fsys.findFile(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	if file != nil {
+		return &fileInfo{name: path.Base(name), file: file}, nil
+	}
+
+	isDir, err := fsys.isDir(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	if !isDir {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return &fileInfo{name: path.Base(name)}, nil
+}
+
+// findFile returns the selected revision of the file with the given name and its files collection document, or nil if
+// there is no such file.
+func (fsys *bucketFS) findFile(ctx context.Context, name string) (*File, bson.Raw, error) {
+	if name == "." {
+		return nil, nil, nil
+	}
+
+	file, doc, err := fsys.bucket.findDownloadFile(ctx, bson.D{{"filename", name}}, revisionFindOptions(fsys.nameOpts...))
+	if errors.Is(err, ErrFileNotFound) {
+		return nil, nil, nil
+	}
+	return file, doc, err
+}
+
+// isDir reports whether there are any files inside of the directory with the given name.
+func (fsys *bucketFS) isDir(ctx context.Context, name string) (bool, error) {
+	if name == "." {
+		return true, nil
+	}
+
+	cursor, err := fsys.bucket.FindContext(ctx, dirFilter(name), options.GridFSFind().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	if cursor.Next(ctx) {
+		return true, nil
+	}
+	return false, cursor.Err()
+}
+
+// readDir lists the directory with the given name, sorted by filename. Each file in the directory is represented by its
+// selected revision and each subdirectory by a single entry.
+func (fsys *bucketFS) readDir(ctx context.Context, name string) ([]fs.DirEntry, error) {
+	var prefix string
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	// Only the fields of the files collection documents that are used by fileInfo are returned.
+	findOpts := options.Find().
+		SetSort(bson.D{{"filename", 1}, {"uploadDate", 1}}).
+		SetProjection(bson.D{{"filename", 1}, {"length", 1}, {"uploadDate", 1}})
+	cursor, err := fsys.bucket.filesColl.Find(ctx, dirFilter(name), findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	revisions := make(map[string][]*File)
+	dirs := make(map[string]bool)
+	for cursor.Next(ctx) {
+		var file File
+		if err := cursor.Decode(&file); err != nil {
+			return nil, err
+		}
+
+		elem := strings.TrimPrefix(file.Name, prefix)
+		if i := strings.IndexByte(elem, '/'); i >= 0 {
+			if elem = elem[:i]; fs.ValidPath(elem) {
+				dirs[elem] = true
+			}
+			continue
+		}
+		if fs.ValidPath(elem) && elem != "." {
+			revisions[elem] = append(revisions[elem], &file)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(revisions)+len(dirs))
+	for elem, files := range revisions {
+		if file := selectRevision(files, fsys.nameOpts...); file != nil {
+			entries = append(entries, &fileInfo{name: elem, file: file})
+			// A file shadows a directory with the same name, because Open returns the file.
+			delete(dirs, elem)
+		}
+	}
+	for elem := range dirs {
+		entries = append(entries, &fileInfo{name: elem})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// dirFilter returns a files collection filter that matches all files inside of the directory with the given name.
+func dirFilter(name string) bson.D {
+	if name == "." {
+		return bson.D{}
+	}
+	return bson.D{{"filename", primitive.Regex{Pattern: "^" + regexp.QuoteMeta(name+"/")}}}
+}
+
+// selectRevision returns the revision of a file requested by the NameOptions, or nil if the file does not have that
+// revision. The revisions must be sorted by upload date.
+func selectRevision(revisions []*File, opts ...*options.NameOptions) *File {
+	revision := int(*options.MergeNameOptions(opts...).Revision)
+	if revision < 0 {
+		revision += len(revisions)
+	}
+	if revision < 0 || revision >= len(revisions) {
+		return nil
+	}
+	return revisions[revision]
+}
+
+// fileInfo describes a file or a directory in a bucketFS. It implements both fs.FileInfo and fs.DirEntry.
+type fileInfo struct {
+	name string
+	file *File // nil for directories
+}
+
+var _ fs.FileInfo = (*fileInfo)(nil)
+var _ fs.DirEntry = (*fileInfo)(nil)
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *fileInfo) Size() int64 {
+	if fi.file == nil {
+		return 0
+	}
+	return fi.file.Length
+}
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.file == nil {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	if fi.file == nil {
+		return time.Time{}
+	}
+	return fi.file.UploadDate
+}
+
+func (fi *fileInfo) IsDir() bool {
+	return fi.file == nil
+}
+
+// Sys returns the *File for files and nil for directories. Only the ID, Length, UploadDate and Name of the File of an
+// entry returned by ReadDir are set.
+func (fi *fileInfo) Sys() interface{} {
+	if fi.file == nil {
+		return nil
+	}
+	return fi.file
+}
+
+func (fi *fileInfo) Type() fs.FileMode {
+	return fi.Mode().Type()
+}
+
+func (fi *fileInfo) Info() (fs.FileInfo, error) {
+	return fi, nil
+}
+
+// fsFile is a file opened from a bucketFS.
+type fsFile struct {
+	*DownloadStream
+	info *fileInfo
+}
+
+var _ fs.File = (*fsFile)(nil)
+var _ io.ReadSeeker = (*fsFile)(nil)
+var _ io.ReaderAt = (*fsFile)(nil)
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// fsDir is a directory opened from a bucketFS. Its entries are listed on the first call to ReadDir.
+type fsDir struct {
+	fsys    *bucketFS
+	path    string
+	info    *fileInfo
+	entries []fs.DirEntry
+	listed  bool
+	offset  int
+}
+
+var _ fs.ReadDirFile = (*fsDir)(nil)
+
+func (d *fsDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errIsDirectory}
+}
+
+func (d *fsDir) Close() error {
+	return nil
+}
+
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		ctx, cancel := deadlineContext(d.fsys.bucket.readDeadline)
+		if cancel != nil {
+			defer cancel()
+		}
+
+		entries, err := d.fsys.readDir(ctx, d.path)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.path, Err: err}
+		}
+		d.entries = entries
+		d.listed = true
+	}
+
+	entries := d.entries[d.offset:]
+	if n > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+		if len(entries) > n {
+			entries = entries[:n]
+		}
+	}
+	d.offset += len(entries)
+	return entries, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+)
+
+// Handler is an http.Handler that serves the files in a GridFS bucket. The path of the request URL, without the leading
+// slash, is used as the filename. Use http.StripPrefix to serve a bucket under a URL prefix.
+//
+// Responses are written with http.ServeContent, so Range, If-Range, If-Match, If-None-Match, If-Modified-Since and
+// If-Unmodified-Since requests are handled. The response headers are set as follows:
+//
+//   - Content-Length is the length of the file or of the requested range.
+//   - ETag is the legacy "md5" field of the files collection document if it is present and the file's _id otherwise.
+//     Files in GridFS are never modified, so the ETag is a strong validator.
+//   - Last-Modified is the upload date of the file.
+//   - Content-Type is the "contentType" field of the file's metadata, or the deprecated top-level "contentType" field.
+//     If neither is present, the content type is determined from the filename extension or the file's content.
+//
+// Only GET and HEAD requests are allowed. Chunks are only read from the server if the response has a body.
+type Handler struct {
+	bucket   *Bucket
+	nameOpts []*options.NameOptions
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler creates a Handler that serves the files in the given bucket. If there are multiple revisions of a file, the
+// NameOptions.Revision option selects the revision that is served. The default is the most recent revision.
+func NewHandler(bucket *Bucket, opts ...*options.NameOptions) *Handler {
+	return &Handler{
+		bucket:   bucket,
+		nameOpts: opts,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// All operations use the request's context, so they are canceled if the client goes away.
+	ctx, cancel := timeoutContext(r.Context(), h.bucket.db)
+	defer cancel()
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	file, doc, err := h.bucket.findDownloadFile(ctx, bson.D{{"filename", name}}, revisionFindOptions(h.nameOpts...))
+	if errors.Is(err, ErrFileNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	transform, err := h.bucket.fileTransform(doc)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Create the download stream without a chunks cursor, so chunks are only found once http.ServeContent reads the
+	// requested range. Conditional and HEAD requests don't read any chunks.
+	ds := newDownloadStream(nil, h.bucket.chunksColl, file.ChunkSize, file, doc, transform)
+	ds.ctx = ctx
+	defer func() {
+		_ = ds.Close()
+	}()
+
+	header := w.Header()
+	header.Set("ETag", fileETag(doc))
+	if contentType := fileContentType(doc); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	http.ServeContent(w, r, name, file.UploadDate, ds)
+}
+
+// fileETag returns a strong entity tag for the file described by a files collection document.
+func fileETag(doc bson.Raw) string {
+	if md5, ok := doc.Lookup("md5").StringValueOK(); ok && md5 != "" && !strings.ContainsRune(md5, '"') {
+		return `"` + md5 + `"`
+	}
+
+	// Use the hex-encoded BSON value of the _id so that IDs of any type produce a valid entity tag.
+	return `"` + hex.EncodeToString(doc.Lookup("_id").Value) + `"`
+}
+
+// fileContentType returns the content type stored for the file described by a files collection document, or an empty
+// string if no content type is stored.
+func fileContentType(doc bson.Raw) string {
+	if contentType, ok := doc.Lookup("metadata", "contentType").StringValueOK(); ok {
+		return contentType
+	}
+	if contentType, ok := doc.Lookup("contentType").StringValueOK(); ok {
+		return contentType
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/wiremessage"
+)
+
+// ChunkTransformer encodes the data of each chunk before it is uploaded and decodes it after it is downloaded. Use
+// UploadOptions.SetChunkTransformer to upload a file with a transformer and BucketOptions.SetChunkTransformers to make
+// transformers available for downloads.
+type ChunkTransformer = options.ChunkTransformer
+
+// ErrUnknownTransformer occurs when downloading a file that was uploaded with a chunk transformer whose ID is not
+// available in the bucket.
+var ErrUnknownTransformer = errors.New("unknown chunk transformer")
+
+// IDs of the chunk transformers provided by this package.
+const (
+	ZstdTransformerID   = "zstd"
+	SnappyTransformerID = "snappy"
+	AESGCMTransformerID = "aes-gcm"
+)
+
+// NewZstdTransformer returns a ChunkTransformer that compresses chunks with zstd at the given compression level. The
+// level has the same meaning as the zstdCompressionLevel connection string option.
+func NewZstdTransformer(level int) ChunkTransformer {
+	return &compressionTransformer{
+		id:   ZstdTransformerID,
+		opts: driver.CompressionOpts{Compressor: wiremessage.CompressorZstd, ZstdLevel: level},
+	}
+}
+
+// NewSnappyTransformer returns a ChunkTransformer that compresses chunks with snappy.
+func NewSnappyTransformer() ChunkTransformer {
+	return &compressionTransformer{
+		id:   SnappyTransformerID,
+		opts: driver.CompressionOpts{Compressor: wiremessage.CompressorSnappy},
+	}
+}
+
+// compressionTransformer compresses chunks with the codecs used for wire message compression.
+type compressionTransformer struct {
+	id   string
+	opts driver.CompressionOpts
+}
+
+var _ ChunkTransformer = (*compressionTransformer)(nil)
+
+func (ct *compressionTransformer) ID() string {
+	return ct.id
+}
+
+func (ct *compressionTransformer) Params() bson.D {
+	if ct.opts.Compressor == wiremessage.CompressorZstd {
+		return bson.D{{"level", int32(ct.opts.ZstdLevel)}}
+	}
+	return nil
+}
+
+func (ct *compressionTransformer) Encode(_ options.ChunkInfo, data []byte) ([]byte, error) {
+	return driver.CompressPayload(data, ct.opts)
+}
+
+func (ct *compressionTransformer) Decode(chunk options.ChunkInfo, data []byte, _ bson.Raw) ([]byte, error) {
+	opts := ct.opts
+	opts.UncompressedSize = int32(chunk.Size)
+	return driver.DecompressPayload(data, opts)
+}
+
+// KeyProvider returns the AES key with the given ID. The key must be 16, 24 or 32 bytes long to select AES-128, AES-192
+// or AES-256.
+type KeyProvider func(keyID string) ([]byte, error)
+
+// NewAESGCMTransformer returns a ChunkTransformer that encrypts chunks with AES-GCM. Chunks are encrypted with the key
+// returned by the key provider for keyID, and the key ID is stored in the files collection document so the key
+// provider is asked for the same key when the file is downloaded. The keyID can be empty if the transformer is only used
+// for downloads.
+//
+// Each chunk is encrypted with a random nonce, which is stored in front of the encrypted data. The file ID and the chunk
+// index are authenticated, so chunks can't be swapped between files or reordered without failing decryption. Keys are
+// cached by ID after they are first returned by the key provider.
+func NewAESGCMTransformer(keyID string, keys KeyProvider) ChunkTransformer {
+	return &aesGCMTransformer{
+		keyID: keyID,
+		keys:  keys,
+		aeads: make(map[string]cipher.AEAD),
+	}
+}
+
+// aesGCMTransformer encrypts chunks with AES-GCM.
+type aesGCMTransformer struct {
+	keyID string
+	keys  KeyProvider
+
+	mu    sync.Mutex
+	aeads map[string]cipher.AEAD
+}
+
+var _ ChunkTransformer = (*aesGCMTransformer)(nil)
+
+func (at *aesGCMTransformer) ID() string {
+	return AESGCMTransformerID
+}
+
+func (at *aesGCMTransformer) Params() bson.D {
+	return bson.D{{"keyId", at.keyID}}
+}
+
+func (at *aesGCMTransformer) Encode(chunk options.ChunkInfo, data []byte) ([]byte, error) {
+	aead, err := at.aead(at.keyID)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := chunkAdditionalData(chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, ad), nil
+}
+
+func (at *aesGCMTransformer) Decode(chunk options.ChunkInfo, data []byte, params bson.Raw) ([]byte, error) {
+	keyID, ok := params.Lookup("keyId").StringValueOK()
+	if !ok {
+		return nil, errors.New("aes-gcm transformer parameters do not contain a key ID")
+	}
+	aead, err := at.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := chunkAdditionalData(chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted chunk %d is too short", chunk.N)
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting chunk %d: %w", chunk.N, err)
+	}
+	return plaintext, nil
+}
+
+// aead returns the AES-GCM cipher for the key with the given ID.
+func (at *aesGCMTransformer) aead(keyID string) (cipher.AEAD, error) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	if aead, ok := at.aeads[keyID]; ok {
+		return aead, nil
+	}
+
+	key, err := at.keys(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting key %q: %w", keyID, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	at.aeads[keyID] = aead
+	return aead, nil
+}
+
+// chunkAdditionalData returns the additional authenticated data of a chunk, which is the BSON value of the file ID
+// followed by the chunk index.
+func chunkAdditionalData(chunk options.ChunkInfo) ([]byte, error) {
+	_, fileID, err := bson.MarshalValue(chunk.FileID)
+	if err != nil {
+		return nil, err
+	}
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(chunk.N))
+	return append(fileID, n[:]...), nil
+}
+
+// chunkTransform is the transformer of a file and the parameters stored in its files collection document.
+type chunkTransform struct {
+	transformer ChunkTransformer
+	params      bson.Raw
+}
+
+// transformDoc returns the value of the "transform" field for a files collection document.
+func transformDoc(transformer ChunkTransformer) bson.D {
+	doc := bson.D{{"id", transformer.ID()}}
+	if params := transformer.Params(); params != nil {
+		doc = append(doc, bson.E{"params", params})
+	}
+	return doc
+}
+
+// fileTransform returns the transform of the file described by a files collection document, or nil if the file was
+// uploaded without a chunk transformer. An error wrapping ErrUnknownTransformer is returned if the bucket does not have
+// a transformer with the stored ID.
+func (b *Bucket) fileTransform(doc bson.Raw) (*chunkTransform, error) {
+	id, ok := doc.Lookup("transform", "id").StringValueOK()
+	if !ok {
+		return nil, nil
+	}
+
+	transformer, ok := b.transformers[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTransformer, id)
+	}
+	params, _ := doc.Lookup("transform", "params").DocumentOK()
+	return &chunkTransform{transformer: transformer, params: params}, nil
+}
+
+// decode decodes the data of chunk n of a file. size is the number of bytes the decoded chunk must have.
+func (ct *chunkTransform) decode(fileID interface{}, n int32, data []byte, size int64) ([]byte, error) {
+	chunk := options.ChunkInfo{FileID: fileID, N: n, Size: int(size)}
+	return ct.transformer.Decode(chunk, data, ct.params)
+}
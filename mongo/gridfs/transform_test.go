@@ -0,0 +1,100 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package gridfs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+)
+
+func TestChunkTransformers(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible log line\n"), 100)
+	chunk := options.ChunkInfo{FileID: "file", N: 3, Size: len(data)}
+
+	keys := func(keyID string) ([]byte, error) {
+		switch keyID {
+		case "key1":
+			return bytes.Repeat([]byte{1}, 32), nil
+		case "key2":
+			return bytes.Repeat([]byte{2}, 16), nil
+		}
+		return nil, errors.New("no such key")
+	}
+
+	// paramsRaw marshals the parameters of a transformer like they are stored in the files collection document.
+	paramsRaw := func(t *testing.T, transformer ChunkTransformer) bson.Raw {
+		t.Helper()
+
+		raw, err := bson.Marshal(bson.D{{"transform", transformDoc(transformer)}})
+		assert.Nil(t, err, "Marshal error: %v", err)
+		params, _ := bson.Raw(raw).Lookup("transform", "params").DocumentOK()
+		return params
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		testCases := []struct {
+			name        string
+			transformer ChunkTransformer
+			compresses  bool
+		}{
+			{"zstd", NewZstdTransformer(6), true},
+			{"snappy", NewSnappyTransformer(), true},
+			{"aes-gcm", NewAESGCMTransformer("key1", keys), false},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				encoded, err := tc.transformer.Encode(chunk, data)
+				assert.Nil(t, err, "Encode error: %v", err)
+				assert.False(t, bytes.Equal(data, encoded), "expected encoded data to differ from data")
+				if tc.compresses {
+					assert.True(t, len(encoded) < len(data), "expected %d encoded bytes to be less than %d bytes",
+						len(encoded), len(data))
+				}
+
+				decoded, err := tc.transformer.Decode(chunk, encoded, paramsRaw(t, tc.transformer))
+				assert.Nil(t, err, "Decode error: %v", err)
+				assert.Equal(t, data, decoded, "expected decoded data to match data")
+			})
+		}
+	})
+	t.Run("aes-gcm uses key ID from params", func(t *testing.T) {
+		encoder := NewAESGCMTransformer("key2", keys)
+		encoded, err := encoder.Encode(chunk, data)
+		assert.Nil(t, err, "Encode error: %v", err)
+
+		// A transformer that is only used for downloads decodes with the key stored in the parameters.
+		decoder := NewAESGCMTransformer("", keys)
+		decoded, err := decoder.Decode(chunk, encoded, paramsRaw(t, encoder))
+		assert.Nil(t, err, "Decode error: %v", err)
+		assert.Equal(t, data, decoded, "expected decoded data to match data")
+	})
+	t.Run("aes-gcm authenticates chunk position", func(t *testing.T) {
+		transformer := NewAESGCMTransformer("key1", keys)
+		encoded, err := transformer.Encode(chunk, data)
+		assert.Nil(t, err, "Encode error: %v", err)
+
+		params := paramsRaw(t, transformer)
+		moved := chunk
+		moved.N++
+		_, err = transformer.Decode(moved, encoded, params)
+		assert.NotNil(t, err, "expected error decoding chunk with a different index, got nil")
+
+		otherFile := chunk
+		otherFile.FileID = "other"
+		_, err = transformer.Decode(otherFile, encoded, params)
+		assert.NotNil(t, err, "expected error decoding chunk of a different file, got nil")
+	})
+	t.Run("aes-gcm key provider error", func(t *testing.T) {
+		_, err := NewAESGCMTransformer("missing", keys).Encode(chunk, data)
+		assert.NotNil(t, err, "expected error for missing key, got nil")
+	})
+}
@@ -8,6 +8,8 @@ package gridfs
 
 import (
 	"errors"
+	"hash"
+	"sync"
 
 	"context"
 	"time"
@@ -17,6 +19,7 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/bson"
 	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
 	"github.com/hongyuyang/mongo-go-driver/mongo"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
 )
 
 // UploadBufferSize is the size in bytes of one stream batch. Chunks will be written to the db after the sum of chunk
@@ -26,6 +29,9 @@ const UploadBufferSize = 16 * 1024 * 1024 // 16 MiB
 // ErrStreamClosed is an error returned if an operation is attempted on a closed/aborted stream.
 var ErrStreamClosed = errors.New("stream is closed or aborted")
 
+// ErrInvalidConcurrency is returned if the MaxConcurrentBatches upload option is less than 1.
+var ErrInvalidConcurrency = errors.New("the maximum number of concurrent batches must be at least 1")
+
 // UploadStream is used to upload a file in chunks. This type implements the io.Writer interface and a file can be
 // uploaded using the Write method. After an upload is complete, the Close method must be called to write file
 // metadata.
@@ -42,11 +48,22 @@ type UploadStream struct {
 	bufferIndex   int
 	fileLen       int64
 	writeDeadline time.Time
+	checksumHash  hash.Hash // nil if no checksum is computed
+
+	ctx    context.Context    // context for all operations, nil if the stream was opened without a context
+	cancel context.CancelFunc // cancels the Timeout context created when the stream was opened, may be nil
+
+	// State for inserting chunk batches concurrently. batches is nil if batches are inserted sequentially.
+	batches     chan struct{} // limits the number of batches being inserted
+	freeBuffers chan []byte   // buffers of inserted batches that can be reused
+	wg          sync.WaitGroup
+	errMu       sync.Mutex
+	batchErr    error // first error returned by a concurrent batch insert
 }
 
 // NewUploadStream creates a new upload stream.
 func newUploadStream(upload *Upload, fileID interface{}, filename string, chunks, files *mongo.Collection) *UploadStream {
-	return &UploadStream{
+	us := &UploadStream{
 		Upload: upload,
 		FileID: fileID,
 
@@ -55,25 +72,51 @@ func newUploadStream(upload *Upload, fileID interface{}, filename string, chunks
 		filesColl:  files,
 		buffer:     make([]byte, UploadBufferSize),
 	}
+
+	if upload.checksum != "" {
+		// The algorithm was validated when the upload options were parsed.
+		us.checksumHash, _ = newChecksumHash(upload.checksum)
+	}
+	if upload.concurrency > 1 {
+		us.batches = make(chan struct{}, upload.concurrency)
+		us.freeBuffers = make(chan []byte, upload.concurrency)
+	}
+	return us
 }
 
 // Close writes file metadata to the files collection and cleans up any resources associated with the UploadStream.
 func (us *UploadStream) Close() error {
-	if us.closed {
-		return ErrStreamClosed
-	}
-
-	ctx, cancel := deadlineContext(us.writeDeadline)
+	ctx, cancel := us.writeContext()
 	if cancel != nil {
 		defer cancel()
 	}
 
+	return us.CloseContext(ctx)
+}
+
+// CloseContext writes file metadata to the files collection and cleans up any resources associated with the
+// UploadStream, running the underlying operations with the provided context.
+//
+// Use the context parameter to time-out or cancel the operation. The deadline set by SetWriteDeadline is ignored. The
+// context the stream was opened with is released even if CloseContext fails, so use AbortContext with another context
+// to delete the chunks of a stream that failed to close.
+func (us *UploadStream) CloseContext(ctx context.Context) error {
+	if us.closed {
+		return ErrStreamClosed
+	}
+	defer us.releaseContext()
+
 	if us.bufferIndex != 0 {
 		if err := us.uploadChunks(ctx, true); err != nil {
 			return err
 		}
 	}
 
+	// The files collection document must only be written once all chunks are stored.
+	if err := us.waitForBatches(); err != nil {
+		return err
+	}
+
 	if err := us.createFilesCollDoc(ctx); err != nil {
 		return err
 	}
@@ -82,7 +125,8 @@ func (us *UploadStream) Close() error {
 	return nil
 }
 
-// SetWriteDeadline sets the write deadline for this stream.
+// SetWriteDeadline sets the write deadline for this stream. The deadline is ignored if the stream was opened with a
+// context.
 func (us *UploadStream) SetWriteDeadline(t time.Time) error {
 	if us.closed {
 		return ErrStreamClosed
@@ -95,15 +139,22 @@ func (us *UploadStream) SetWriteDeadline(t time.Time) error {
 // Write transfers the contents of a byte slice into this upload stream. If the stream's underlying buffer fills up,
 // the buffer will be uploaded as chunks to the server. Implements the io.Writer interface.
 func (us *UploadStream) Write(p []byte) (int, error) {
-	if us.closed {
-		return 0, ErrStreamClosed
+	ctx, cancel := us.writeContext()
+	if cancel != nil {
+		defer cancel()
 	}
 
-	var ctx context.Context
+	return us.WriteContext(ctx, p)
+}
 
-	ctx, cancel := deadlineContext(us.writeDeadline)
-	if cancel != nil {
-		defer cancel()
+// WriteContext transfers the contents of a byte slice into this upload stream, running any chunk inserts with the
+// provided context. Chunk batches that are inserted concurrently outlive the call and use the context the stream was
+// opened with, or the stream's write deadline if it was opened without a context.
+//
+// Use the context parameter to time-out or cancel the write. The deadline set by SetWriteDeadline is ignored.
+func (us *UploadStream) WriteContext(ctx context.Context, p []byte) (int, error) {
+	if us.closed {
+		return 0, ErrStreamClosed
 	}
 
 	origLen := len(p)
@@ -128,14 +179,27 @@ func (us *UploadStream) Write(p []byte) (int, error) {
 
 // Abort closes the stream and deletes all file chunks that have already been written.
 func (us *UploadStream) Abort() error {
+	ctx, cancel := us.writeContext()
+	if cancel != nil {
+		defer cancel()
+	}
+
+	return us.AbortContext(ctx)
+}
+
+// AbortContext closes the stream and deletes all file chunks that have already been written, running the delete with
+// the provided context.
+//
+// Use the context parameter to time-out or cancel the operation. The deadline set by SetWriteDeadline is ignored.
+func (us *UploadStream) AbortContext(ctx context.Context) error {
 	if us.closed {
 		return ErrStreamClosed
 	}
+	defer us.releaseContext()
 
-	ctx, cancel := deadlineContext(us.writeDeadline)
-	if cancel != nil {
-		defer cancel()
-	}
+	// Wait for concurrent batch inserts so that no chunks are inserted after they are deleted. Insert errors don't
+	// matter because the chunks are deleted anyway.
+	_ = us.waitForBatches()
 
 	_, err := us.chunksColl.DeleteMany(ctx, bson.D{{"files_id", us.FileID}})
 	if err != nil {
@@ -146,6 +210,23 @@ func (us *UploadStream) Abort() error {
 	return nil
 }
 
+// writeContext returns the context for an operation that was called without a context: the context the stream was
+// opened with, or a context with the stream's write deadline.
+func (us *UploadStream) writeContext() (context.Context, context.CancelFunc) {
+	if us.ctx != nil {
+		return us.ctx, nil
+	}
+	return deadlineContext(us.writeDeadline)
+}
+
+// releaseContext cancels the Timeout context created when the stream was opened, if there is one.
+func (us *UploadStream) releaseContext() {
+	if us.cancel != nil {
+		us.cancel()
+		us.cancel = nil
+	}
+}
+
 // uploadChunks uploads the current buffer as a series of chunks to the bucket
 // if uploadPartial is true, any data at the end of the buffer that is smaller than a chunk will be uploaded as a partial
 // chunk. if it is false, the data will be moved to the front of the buffer.
@@ -170,6 +251,19 @@ func (us *UploadStream) uploadChunks(ctx context.Context, uploadPartial bool) er
 			endIndex = us.bufferIndex
 		}
 		chunkData := us.buffer[i:endIndex]
+		if us.checksumHash != nil {
+			_, _ = us.checksumHash.Write(chunkData)
+		}
+		// The file length and the checksum refer to the data before it is encoded.
+		fileLen := int64(len(chunkData))
+		if us.transformer != nil {
+			chunk := options.ChunkInfo{FileID: us.FileID, N: int32(us.chunkIndex), Size: len(chunkData)}
+			encoded, err := us.transformer.Encode(chunk, chunkData)
+			if err != nil {
+				return err
+			}
+			chunkData = encoded
+		}
 		docs[us.chunkIndex-begChunkIndex] = bson.D{
 			{"_id", primitive.NewObjectID()},
 			{"files_id", us.FileID},
@@ -177,7 +271,12 @@ func (us *UploadStream) uploadChunks(ctx context.Context, uploadPartial bool) er
 			{"data", primitive.Binary{Subtype: 0x00, Data: chunkData}},
 		}
 		us.chunkIndex++
-		us.fileLen += int64(len(chunkData))
+		us.fileLen += fileLen
+	}
+
+	bytesUploaded := numChunks * int(us.chunkSize)
+	if us.batches != nil {
+		return us.insertBatchAsync(ctx, docs, bytesUploaded)
 	}
 
 	_, err := us.chunksColl.InsertMany(ctx, docs)
@@ -186,7 +285,6 @@ func (us *UploadStream) uploadChunks(ctx context.Context, uploadPartial bool) er
 	}
 
 	// copy any remaining bytes to beginning of buffer and set buffer index
-	bytesUploaded := numChunks * int(us.chunkSize)
 	if bytesUploaded != UploadBufferSize && !uploadPartial {
 		copy(us.buffer[0:], us.buffer[bytesUploaded:us.bufferIndex])
 	}
@@ -194,6 +292,84 @@ func (us *UploadStream) uploadChunks(ctx context.Context, uploadPartial bool) er
 	return nil
 }
 
+// insertBatchAsync inserts a batch of chunk documents in a separate goroutine. It blocks while the maximum number of
+// batches are already being inserted and returns the error of any previous batch insert that failed. The chunk
+// documents reference the current buffer, so the stream continues with a new buffer that holds the bytes that were not
+// uploaded.
+func (us *UploadStream) insertBatchAsync(ctx context.Context, docs []interface{}, bytesUploaded int) error {
+	if err := us.getBatchErr(); err != nil {
+		return err
+	}
+
+	select {
+	case us.batches <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	buffer := us.buffer
+	select {
+	case us.buffer = <-us.freeBuffers:
+	default:
+		us.buffer = make([]byte, UploadBufferSize)
+	}
+	if bytesUploaded < us.bufferIndex {
+		us.bufferIndex = copy(us.buffer, buffer[bytesUploaded:us.bufferIndex])
+	} else {
+		us.bufferIndex = 0
+	}
+
+	// The insert outlives the context of the current Write, so it uses the context of the stream or, if the stream was
+	// opened without a context, its own context with the write deadline.
+	streamCtx, deadline := us.ctx, us.writeDeadline
+	us.wg.Add(1)
+	go func() {
+		defer us.wg.Done()
+
+		ctx := streamCtx
+		if ctx == nil {
+			var cancel context.CancelFunc
+			ctx, cancel = deadlineContext(deadline)
+			if cancel != nil {
+				defer cancel()
+			}
+		}
+
+		if _, err := us.chunksColl.InsertMany(ctx, docs); err != nil {
+			us.setBatchErr(err)
+		}
+
+		select {
+		case us.freeBuffers <- buffer:
+		default:
+		}
+		<-us.batches
+	}()
+	return nil
+}
+
+// waitForBatches waits for all concurrent batch inserts to finish and returns the first error returned by one of them.
+func (us *UploadStream) waitForBatches() error {
+	us.wg.Wait()
+	return us.getBatchErr()
+}
+
+func (us *UploadStream) getBatchErr() error {
+	us.errMu.Lock()
+	defer us.errMu.Unlock()
+
+	return us.batchErr
+}
+
+func (us *UploadStream) setBatchErr(err error) {
+	us.errMu.Lock()
+	defer us.errMu.Unlock()
+
+	if us.batchErr == nil {
+		us.batchErr = err
+	}
+}
+
 func (us *UploadStream) createFilesCollDoc(ctx context.Context) error {
 	doc := bson.D{
 		{"_id", us.FileID},
@@ -206,6 +382,12 @@ func (us *UploadStream) createFilesCollDoc(ctx context.Context) error {
 	if us.metadata != nil {
 		doc = append(doc, bson.E{"metadata", us.metadata})
 	}
+	if us.checksumHash != nil {
+		doc = append(doc, bson.E{"checksum", checksumDoc(us.checksum, us.checksumHash)})
+	}
+	if us.transformer != nil {
+		doc = append(doc, bson.E{"transform", transformDoc(us.transformer)})
+	}
 
 	_, err := us.filesColl.InsertOne(ctx, doc)
 	if err != nil {
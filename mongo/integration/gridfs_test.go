@@ -9,10 +9,16 @@ package integration
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
+	"io/fs"
+	"math"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"runtime"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/bson"
@@ -423,6 +429,711 @@ func TestGridFS(x *testing.T) {
 		})
 	})
 
+	mt.RunOpts("seekable download", noClientOpts, func(mt *mtest.T) {
+		data := []byte("abc.def.ghi")
+		var chunkSize int32 = 4
+
+		uploadFile := func(mt *mtest.T) (*gridfs.Bucket, interface{}) {
+			bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(chunkSize))
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+
+			fileID, err := bucket.UploadFromStream("foo", bytes.NewReader(data))
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+			return bucket, fileID
+		}
+
+		mt.Run("Seek", func(mt *mtest.T) {
+			testCases := []struct {
+				name     string
+				read     int
+				offset   int64
+				whence   int
+				expected string
+			}{
+				{"start of file", 5, 0, io.SeekStart, "abc.def.ghi"},
+				{"middle of chunk", 0, 5, io.SeekStart, "ef.ghi"},
+				{"backwards into earlier chunk", 9, -8, io.SeekCurrent, "bc.def.ghi"},
+				{"within buffered chunk", 5, 1, io.SeekCurrent, "f.ghi"},
+				{"relative to end", 0, -3, io.SeekEnd, "ghi"},
+				{"end of file", 2, 0, io.SeekEnd, ""},
+				{"past end of file", 0, 20, io.SeekStart, ""},
+			}
+			for _, tc := range testCases {
+				mt.Run(tc.name, func(mt *mtest.T) {
+					bucket, fileID := uploadFile(mt)
+
+					ds, err := bucket.OpenDownloadStream(fileID)
+					assert.Nil(mt, err, "OpenDownloadStream error: %v", err)
+					defer func() { _ = ds.Close() }()
+
+					_, err = io.ReadFull(ds, make([]byte, tc.read))
+					assert.Nil(mt, err, "ReadFull error: %v", err)
+
+					_, err = ds.Seek(tc.offset, tc.whence)
+					assert.Nil(mt, err, "Seek error: %v", err)
+
+					got, err := io.ReadAll(ds)
+					assert.Nil(mt, err, "ReadAll error: %v", err)
+					assert.Equal(mt, tc.expected, string(got), "expected data %q, got %q", tc.expected, got)
+				})
+			}
+		})
+		mt.Run("Seek to negative position", func(mt *mtest.T) {
+			bucket, fileID := uploadFile(mt)
+
+			ds, err := bucket.OpenDownloadStream(fileID)
+			assert.Nil(mt, err, "OpenDownloadStream error: %v", err)
+			defer func() { _ = ds.Close() }()
+
+			_, err = ds.Seek(-1, io.SeekStart)
+			assert.Equal(mt, gridfs.ErrInvalidRange, err, "expected error %v, got %v", gridfs.ErrInvalidRange, err)
+		})
+		mt.Run("ReadAt", func(mt *mtest.T) {
+			testCases := []struct {
+				name        string
+				offset      int64
+				length      int
+				expected    string
+				expectedErr error
+			}{
+				{"whole file", 0, 11, "abc.def.ghi", nil},
+				{"across chunks", 3, 6, ".def.g", nil},
+				{"last chunk", 8, 3, "ghi", nil},
+				{"past end of file", 9, 4, "hi", io.EOF},
+				{"at end of file", 11, 1, "", io.EOF},
+			}
+			for _, tc := range testCases {
+				mt.Run(tc.name, func(mt *mtest.T) {
+					bucket, fileID := uploadFile(mt)
+
+					ds, err := bucket.OpenDownloadStream(fileID)
+					assert.Nil(mt, err, "OpenDownloadStream error: %v", err)
+					defer func() { _ = ds.Close() }()
+
+					p := make([]byte, tc.length)
+					n, err := ds.ReadAt(p, tc.offset)
+					assert.Equal(mt, tc.expectedErr, err, "expected error %v, got %v", tc.expectedErr, err)
+					assert.Equal(mt, tc.expected, string(p[:n]), "expected data %q, got %q", tc.expected, p[:n])
+
+					// ReadAt must not move the position used by Read.
+					got, err := io.ReadAll(ds)
+					assert.Nil(mt, err, "ReadAll error: %v", err)
+					assert.Equal(mt, data, got, "expected data %q, got %q", data, got)
+				})
+			}
+		})
+		mt.Run("OpenDownloadStreamRange", func(mt *mtest.T) {
+			testCases := []struct {
+				name        string
+				offset      int64
+				length      int64
+				expected    string
+				expectedErr error
+			}{
+				{"first chunk", 0, 4, "abc.", nil},
+				{"across chunks", 2, 7, "c.def.g", nil},
+				{"extends past end of file", 8, 10, "ghi", nil},
+				{"length overflows", 8, math.MaxInt64, "ghi", nil},
+				{"empty", 5, 0, "", nil},
+				{"offset at end of file", 11, 5, "", nil},
+				{"offset past end of file", 12, 1, "", gridfs.ErrInvalidRange},
+				{"negative length", 0, -1, "", gridfs.ErrInvalidRange},
+			}
+			for _, tc := range testCases {
+				mt.Run(tc.name, func(mt *mtest.T) {
+					bucket, fileID := uploadFile(mt)
+
+					ds, err := bucket.OpenDownloadStreamRange(fileID, tc.offset, tc.length)
+					assert.Equal(mt, tc.expectedErr, err, "expected error %v, got %v", tc.expectedErr, err)
+					if err != nil {
+						return
+					}
+					defer func() { _ = ds.Close() }()
+
+					got, err := io.ReadAll(ds)
+					assert.Nil(mt, err, "ReadAll error: %v", err)
+					assert.Equal(mt, tc.expected, string(got), "expected data %q, got %q", tc.expected, got)
+				})
+			}
+		})
+	})
+
+	mt.RunOpts("fs adapter", noClientOpts, func(mt *mtest.T) {
+		files := map[string]string{
+			"readme.txt":          "top level",
+			"videos/intro.mp4":    "intro video",
+			"videos/2024/a.mp4":   "first",
+			"videos/2024/b.mp4":   "second",
+			"docs/guide/part1.md": "part one",
+		}
+
+		uploadFiles := func(mt *mtest.T) *gridfs.Bucket {
+			bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(4))
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+
+			for name, content := range files {
+				_, err = bucket.UploadFromStream(name, bytes.NewReader([]byte(content)))
+				assert.Nil(mt, err, "UploadFromStream error: %v", err)
+			}
+			return bucket
+		}
+
+		mt.Run("fstest", func(mt *mtest.T) {
+			bucket := uploadFiles(mt)
+
+			expected := make([]string, 0, len(files))
+			for name := range files {
+				expected = append(expected, name)
+			}
+			err := fstest.TestFS(gridfs.FS(bucket), expected...)
+			assert.Nil(mt, err, "TestFS error: %v", err)
+		})
+		mt.Run("ReadDir", func(mt *mtest.T) {
+			bucket := uploadFiles(mt)
+
+			entries, err := gridfs.FS(bucket).ReadDir("videos")
+			assert.Nil(mt, err, "ReadDir error: %v", err)
+
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			expected := []string{"2024", "intro.mp4"}
+			assert.Equal(mt, expected, names, "expected entries %v, got %v", expected, names)
+			assert.True(mt, entries[0].IsDir(), "expected %q to be a directory", entries[0].Name())
+			assert.False(mt, entries[1].IsDir(), "expected %q to be a file", entries[1].Name())
+		})
+		mt.Run("revisions", func(mt *mtest.T) {
+			bucket := uploadFiles(mt)
+			_, err := bucket.UploadFromStream("readme.txt", bytes.NewReader([]byte("new revision")))
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+			testCases := []struct {
+				name     string
+				opts     *options.NameOptions
+				expected string
+			}{
+				{"latest by default", nil, "new revision"},
+				{"original", options.GridFSName().SetRevision(0), "top level"},
+				{"second most recent", options.GridFSName().SetRevision(-2), "top level"},
+			}
+			for _, tc := range testCases {
+				mt.Run(tc.name, func(mt *mtest.T) {
+					got, err := fs.ReadFile(gridfs.FS(bucket, tc.opts), "readme.txt")
+					assert.Nil(mt, err, "ReadFile error: %v", err)
+					assert.Equal(mt, tc.expected, string(got), "expected content %q, got %q", tc.expected, got)
+				})
+			}
+
+			_, err = fs.Stat(gridfs.FS(bucket, options.GridFSName().SetRevision(2)), "readme.txt")
+			assert.True(mt, errors.Is(err, fs.ErrNotExist), "expected error %v, got %v", fs.ErrNotExist, err)
+		})
+		mt.Run("not found", func(mt *mtest.T) {
+			bucket := uploadFiles(mt)
+
+			_, err := gridfs.FS(bucket).Open("videos/missing.mp4")
+			assert.True(mt, errors.Is(err, fs.ErrNotExist), "expected error %v, got %v", fs.ErrNotExist, err)
+		})
+	})
+
+	mt.RunOpts("http handler", noClientOpts, func(mt *mtest.T) {
+		content := "abc.def.ghi"
+
+		uploadFile := func(mt *mtest.T) (*gridfs.Bucket, primitive.ObjectID) {
+			bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(4))
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+
+			uploadOpts := options.GridFSUpload().SetMetadata(bson.D{{"contentType", "text/custom"}})
+			fileID, err := bucket.UploadFromStream("dir/file.txt", bytes.NewReader([]byte(content)), uploadOpts)
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+			return bucket, fileID
+		}
+		serve := func(handler http.Handler, method string, header http.Header) *http.Response {
+			req := httptest.NewRequest(method, "/dir/file.txt", nil)
+			for key, values := range header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec.Result()
+		}
+
+		mt.Run("full content", func(mt *mtest.T) {
+			bucket, fileID := uploadFile(mt)
+
+			res := serve(gridfs.NewHandler(bucket), http.MethodGet, nil)
+			body, err := io.ReadAll(res.Body)
+			assert.Nil(mt, err, "ReadAll error: %v", err)
+
+			assert.Equal(mt, http.StatusOK, res.StatusCode, "expected status %v, got %v", http.StatusOK, res.StatusCode)
+			assert.Equal(mt, content, string(body), "expected body %q, got %q", content, body)
+			assert.Equal(mt, "11", res.Header.Get("Content-Length"), "unexpected Content-Length %q",
+				res.Header.Get("Content-Length"))
+			assert.Equal(mt, "text/custom", res.Header.Get("Content-Type"), "unexpected Content-Type %q",
+				res.Header.Get("Content-Type"))
+			expectedETag := `"` + fileID.Hex() + `"`
+			assert.Equal(mt, expectedETag, res.Header.Get("ETag"), "expected ETag %q, got %q", expectedETag,
+				res.Header.Get("ETag"))
+			assert.NotEqual(mt, "", res.Header.Get("Last-Modified"), "expected Last-Modified to be set")
+		})
+		mt.Run("range", func(mt *mtest.T) {
+			bucket, _ := uploadFile(mt)
+
+			res := serve(gridfs.NewHandler(bucket), http.MethodGet, http.Header{"Range": {"bytes=5-8"}})
+			body, err := io.ReadAll(res.Body)
+			assert.Nil(mt, err, "ReadAll error: %v", err)
+
+			assert.Equal(mt, http.StatusPartialContent, res.StatusCode, "expected status %v, got %v",
+				http.StatusPartialContent, res.StatusCode)
+			assert.Equal(mt, "ef.g", string(body), "expected body %q, got %q", "ef.g", body)
+			assert.Equal(mt, "bytes 5-8/11", res.Header.Get("Content-Range"), "unexpected Content-Range %q",
+				res.Header.Get("Content-Range"))
+		})
+		mt.Run("conditional", func(mt *mtest.T) {
+			bucket, fileID := uploadFile(mt)
+			handler := gridfs.NewHandler(bucket)
+
+			res := serve(handler, http.MethodGet, http.Header{"If-None-Match": {`"` + fileID.Hex() + `"`}})
+			assert.Equal(mt, http.StatusNotModified, res.StatusCode, "expected status %v, got %v",
+				http.StatusNotModified, res.StatusCode)
+
+			modified := serve(handler, http.MethodHead, nil).Header.Get("Last-Modified")
+			res = serve(handler, http.MethodGet, http.Header{"If-Modified-Since": {modified}})
+			assert.Equal(mt, http.StatusNotModified, res.StatusCode, "expected status %v, got %v",
+				http.StatusNotModified, res.StatusCode)
+		})
+		mt.Run("not found", func(mt *mtest.T) {
+			bucket, err := gridfs.NewBucket(mt.DB)
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+
+			res := serve(gridfs.NewHandler(bucket), http.MethodGet, nil)
+			assert.Equal(mt, http.StatusNotFound, res.StatusCode, "expected status %v, got %v",
+				http.StatusNotFound, res.StatusCode)
+		})
+		mt.Run("method not allowed", func(mt *mtest.T) {
+			bucket, _ := uploadFile(mt)
+
+			res := serve(gridfs.NewHandler(bucket), http.MethodPost, nil)
+			assert.Equal(mt, http.StatusMethodNotAllowed, res.StatusCode, "expected status %v, got %v",
+				http.StatusMethodNotAllowed, res.StatusCode)
+		})
+	})
+
+	mt.RunOpts("concurrent upload", noClientOpts, func(mt *mtest.T) {
+		// Upload a file that spans several upload batches and check that it round trips.
+		fileData := make([]byte, 3*gridfs.UploadBufferSize+1000)
+		_, err := rand.Read(fileData)
+		assert.Nil(mt, err, "Read error: %v", err)
+
+		bucket, err := gridfs.NewBucket(mt.DB)
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+		defer func() { _ = bucket.Drop() }()
+
+		uploadOpts := options.GridFSUpload().SetMaxConcurrentBatches(3)
+		fileID, err := bucket.UploadFromStream("concurrent", bytes.NewReader(fileData), uploadOpts)
+		assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+		var downloaded bytes.Buffer
+		_, err = bucket.DownloadToStream(fileID, &downloaded)
+		assert.Nil(mt, err, "DownloadToStream error: %v", err)
+		assert.True(mt, bytes.Equal(fileData, downloaded.Bytes()), "downloaded data does not match uploaded data")
+
+		_, err = bucket.OpenUploadStream("invalid", options.GridFSUpload().SetMaxConcurrentBatches(0))
+		assert.Equal(mt, gridfs.ErrInvalidConcurrency, err, "expected error %v, got %v", gridfs.ErrInvalidConcurrency, err)
+	})
+
+	mt.RunOpts("resume upload", noClientOpts, func(mt *mtest.T) {
+		data := []byte("abc.def.ghi.jkl")
+		var chunkSize int32 = 4
+
+		// Simulate an interrupted upload: chunks 0-2 were written, chunk 3 is missing, and chunk 4 was written by a
+		// concurrent batch. The files collection document was never written.
+		fileID := primitive.NewObjectID()
+		insertChunk := func(n int32, chunkData []byte) {
+			chunk := bson.D{
+				{"_id", primitive.NewObjectID()},
+				{"files_id", fileID},
+				{"n", n},
+				{"data", primitive.Binary{Data: chunkData}},
+			}
+			_, err := mt.DB.Collection("fs.chunks").InsertOne(context.Background(), chunk)
+			assert.Nil(mt, err, "InsertOne error: %v", err)
+		}
+
+		bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(chunkSize))
+		assert.Nil(mt, err, "NewBucket error: %v", err)
+		defer func() { _ = bucket.Drop() }()
+
+		insertChunk(0, data[0:4])
+		insertChunk(1, data[4:8])
+		insertChunk(2, data[8:12])
+		insertChunk(4, []byte("xxxx"))
+
+		err = bucket.ResumeUpload(fileID, "resumed", bytes.NewReader(data), options.GridFSUpload().SetChunkSizeBytes(5))
+		assert.Equal(mt, gridfs.ErrChunkSizeMismatch, err, "expected error %v, got %v", gridfs.ErrChunkSizeMismatch, err)
+
+		err = bucket.ResumeUpload(fileID, "resumed", bytes.NewReader(data))
+		assert.Nil(mt, err, "ResumeUpload error: %v", err)
+
+		var downloaded bytes.Buffer
+		_, err = bucket.DownloadToStream(fileID, &downloaded)
+		assert.Nil(mt, err, "DownloadToStream error: %v", err)
+		assert.Equal(mt, data, downloaded.Bytes(), "expected data %q, got %q", data, downloaded.Bytes())
+
+		err = bucket.ResumeUpload(fileID, "resumed", bytes.NewReader(data))
+		assert.Equal(mt, gridfs.ErrUploadComplete, err, "expected error %v, got %v", gridfs.ErrUploadComplete, err)
+	})
+
+	mt.RunOpts("checksums", noClientOpts, func(mt *mtest.T) {
+		data := []byte("abc.def.ghi")
+		var chunkSize int32 = 4
+
+		uploadFile := func(mt *mtest.T, algorithm options.ChecksumAlgorithm) (*gridfs.Bucket, interface{}) {
+			bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(chunkSize))
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+
+			uploadOpts := options.GridFSUpload().SetChecksum(algorithm)
+			fileID, err := bucket.UploadFromStream("checksum", bytes.NewReader(data), uploadOpts)
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+			return bucket, fileID
+		}
+		corruptChunk := func(mt *mtest.T, fileID interface{}, n int32, chunkData []byte) {
+			_, err := mt.DB.Collection("fs.chunks").UpdateOne(context.Background(),
+				bson.D{{"files_id", fileID}, {"n", n}},
+				bson.D{{"$set", bson.D{{"data", primitive.Binary{Data: chunkData}}}}})
+			assert.Nil(mt, err, "UpdateOne error: %v", err)
+		}
+
+		mt.Run("stored in files collection", func(mt *mtest.T) {
+			testCases := []struct {
+				algorithm options.ChecksumAlgorithm
+				expected  string
+			}{
+				{options.ChecksumSHA256, "6559e90b5dd57405bdf180f29b509053a3d36c4abf3de535ab249b54d4327234"},
+				{options.ChecksumCRC32C, "a06d6780"},
+			}
+			for _, tc := range testCases {
+				mt.Run(string(tc.algorithm), func(mt *mtest.T) {
+					_, fileID := uploadFile(mt, tc.algorithm)
+
+					filesDoc, err := mt.DB.Collection("fs.files").FindOne(context.Background(), bson.D{{"_id", fileID}}).Raw()
+					assert.Nil(mt, err, "FindOne error: %v", err)
+
+					algorithm := filesDoc.Lookup("checksum", "algorithm").StringValue()
+					assert.Equal(mt, string(tc.algorithm), algorithm, "expected algorithm %q, got %q", tc.algorithm, algorithm)
+					digest := filesDoc.Lookup("checksum", "digest").StringValue()
+					assert.Equal(mt, tc.expected, digest, "expected digest %q, got %q", tc.expected, digest)
+				})
+			}
+		})
+		mt.Run("verified download", func(mt *mtest.T) {
+			bucket, fileID := uploadFile(mt, options.ChecksumSHA256)
+
+			ds, err := bucket.OpenDownloadStream(fileID)
+			assert.Nil(mt, err, "OpenDownloadStream error: %v", err)
+			err = ds.SetVerifyChecksum(true)
+			assert.Nil(mt, err, "SetVerifyChecksum error: %v", err)
+
+			got, err := io.ReadAll(ds)
+			assert.Nil(mt, err, "ReadAll error: %v", err)
+			assert.Equal(mt, data, got, "expected data %q, got %q", data, got)
+			_ = ds.Close()
+
+			err = bucket.Verify(context.Background(), fileID)
+			assert.Nil(mt, err, "Verify error: %v", err)
+		})
+		mt.Run("corrupted chunk", func(mt *mtest.T) {
+			bucket, fileID := uploadFile(mt, options.ChecksumCRC32C)
+			corruptChunk(mt, fileID, 1, []byte("DEF."))
+
+			ds, err := bucket.OpenDownloadStream(fileID)
+			assert.Nil(mt, err, "OpenDownloadStream error: %v", err)
+			err = ds.SetVerifyChecksum(true)
+			assert.Nil(mt, err, "SetVerifyChecksum error: %v", err)
+
+			_, err = io.ReadAll(ds)
+			var mismatch gridfs.ErrChecksumMismatch
+			assert.True(mt, errors.As(err, &mismatch), "expected error %T, got %v", mismatch, err)
+			_ = ds.Close()
+
+			err = bucket.Verify(context.Background(), fileID)
+			assert.True(mt, errors.As(err, &mismatch), "expected error %T, got %v", mismatch, err)
+		})
+		mt.Run("Verify detects missing chunks", func(mt *mtest.T) {
+			bucket, fileID := uploadFile(mt, options.ChecksumSHA256)
+			_, err := mt.DB.Collection("fs.chunks").DeleteOne(context.Background(), bson.D{{"files_id", fileID}, {"n", 1}})
+			assert.Nil(mt, err, "DeleteOne error: %v", err)
+
+			err = bucket.Verify(context.Background(), fileID)
+			assert.True(mt, errors.Is(err, gridfs.ErrWrongIndex), "expected error %v, got %v", gridfs.ErrWrongIndex, err)
+		})
+		mt.Run("Verify detects wrong chunk sizes", func(mt *mtest.T) {
+			bucket, fileID := uploadFile(mt, options.ChecksumSHA256)
+			corruptChunk(mt, fileID, 0, []byte("ab"))
+
+			err := bucket.Verify(context.Background(), fileID)
+			assert.True(mt, errors.Is(err, gridfs.ErrWrongSize), "expected error %v, got %v", gridfs.ErrWrongSize, err)
+		})
+		mt.Run("unsupported algorithm", func(mt *mtest.T) {
+			bucket, err := gridfs.NewBucket(mt.DB)
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+
+			_, err = bucket.OpenUploadStream("file", options.GridFSUpload().SetChecksum("md4"))
+			assert.True(mt, errors.Is(err, gridfs.ErrUnsupportedChecksum), "expected error %v, got %v",
+				gridfs.ErrUnsupportedChecksum, err)
+		})
+	})
+
+	mt.RunOpts("context operations", noClientOpts, func(mt *mtest.T) {
+		data := []byte("abc.def.ghi")
+		var chunkSize int32 = 4
+
+		newBucket := func(mt *mtest.T) *gridfs.Bucket {
+			bucket, err := gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(chunkSize))
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+			return bucket
+		}
+
+		mt.Run("round trip", func(mt *mtest.T) {
+			bucket := newBucket(mt)
+			ctx := context.Background()
+
+			fileID, err := bucket.UploadFromStreamContext(ctx, "context", bytes.NewReader(data))
+			assert.Nil(mt, err, "UploadFromStreamContext error: %v", err)
+
+			var downloaded bytes.Buffer
+			_, err = bucket.DownloadToStreamContext(ctx, fileID, &downloaded)
+			assert.Nil(mt, err, "DownloadToStreamContext error: %v", err)
+			assert.Equal(mt, data, downloaded.Bytes(), "expected data %q, got %q", data, downloaded.Bytes())
+
+			downloaded.Reset()
+			_, err = bucket.DownloadToStreamByNameContext(ctx, "context", &downloaded)
+			assert.Nil(mt, err, "DownloadToStreamByNameContext error: %v", err)
+			assert.Equal(mt, data, downloaded.Bytes(), "expected data %q, got %q", data, downloaded.Bytes())
+
+			ds, err := bucket.OpenDownloadStreamRangeContext(ctx, fileID, 4, 4)
+			assert.Nil(mt, err, "OpenDownloadStreamRangeContext error: %v", err)
+			got, err := io.ReadAll(ds)
+			assert.Nil(mt, err, "ReadAll error: %v", err)
+			assert.Equal(mt, data[4:8], got, "expected data %q, got %q", data[4:8], got)
+			err = ds.CloseContext(ctx)
+			assert.Nil(mt, err, "CloseContext error: %v", err)
+		})
+		mt.Run("canceled upload", func(mt *mtest.T) {
+			bucket := newBucket(mt)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := bucket.UploadFromStreamContext(ctx, "canceled", bytes.NewReader(data))
+			assert.True(mt, errors.Is(err, context.Canceled), "expected error %v, got %v", context.Canceled, err)
+
+			us, err := bucket.OpenUploadStreamContext(context.Background(), "canceled")
+			assert.Nil(mt, err, "OpenUploadStreamContext error: %v", err)
+			_, err = us.Write(data)
+			assert.Nil(mt, err, "Write error: %v", err)
+			err = us.CloseContext(ctx)
+			assert.True(mt, errors.Is(err, context.Canceled), "expected error %v, got %v", context.Canceled, err)
+			err = us.AbortContext(context.Background())
+			assert.Nil(mt, err, "AbortContext error: %v", err)
+		})
+		mt.Run("canceled download", func(mt *mtest.T) {
+			bucket := newBucket(mt)
+			fileID, err := bucket.UploadFromStream("download", bytes.NewReader(data))
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+			// The stream uses the context it was opened with, so canceling the context cancels later chunk finds.
+			ctx, cancel := context.WithCancel(context.Background())
+			ds, err := bucket.OpenDownloadStreamContext(ctx, fileID)
+			assert.Nil(mt, err, "OpenDownloadStreamContext error: %v", err)
+			defer func() { _ = ds.Close() }()
+			cancel()
+
+			_, err = ds.ReadAt(make([]byte, 4), 4)
+			assert.True(mt, errors.Is(err, context.Canceled), "expected error %v, got %v", context.Canceled, err)
+
+			// A single operation can be run with a different context.
+			buf := make([]byte, 4)
+			_, err = ds.ReadAtContext(context.Background(), buf, 4)
+			assert.Nil(mt, err, "ReadAtContext error: %v", err)
+			assert.Equal(mt, data[4:8], buf, "expected data %q, got %q", data[4:8], buf)
+		})
+	})
+
+	mt.RunOpts("chunk transformers", noClientOpts, func(mt *mtest.T) {
+		data := bytes.Repeat([]byte("abc.def.ghi\n"), 10)
+		var chunkSize int32 = 16
+		key := bytes.Repeat([]byte{7}, 32)
+		keys := func(string) ([]byte, error) { return key, nil }
+
+		testCases := []struct {
+			name         string
+			transformer  gridfs.ChunkTransformer
+			transformers []gridfs.ChunkTransformer // transformers available for downloads
+		}{
+			{"zstd", gridfs.NewZstdTransformer(3), nil},
+			{"snappy", gridfs.NewSnappyTransformer(), nil},
+			{
+				"aes-gcm",
+				gridfs.NewAESGCMTransformer("key", keys),
+				[]gridfs.ChunkTransformer{gridfs.NewAESGCMTransformer("", keys)},
+			},
+		}
+		for _, tc := range testCases {
+			mt.Run(tc.name, func(mt *mtest.T) {
+				bucketOpts := options.GridFSBucket().SetChunkSizeBytes(chunkSize).SetChunkTransformers(tc.transformers...)
+				bucket, err := gridfs.NewBucket(mt.DB, bucketOpts)
+				assert.Nil(mt, err, "NewBucket error: %v", err)
+				defer func() { _ = bucket.Drop() }()
+
+				uploadOpts := options.GridFSUpload().SetChunkTransformer(tc.transformer).SetChecksum(options.ChecksumSHA256)
+				fileID, err := bucket.UploadFromStream("transformed", bytes.NewReader(data), uploadOpts)
+				assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+				filesDoc, err := mt.DB.Collection("fs.files").FindOne(context.Background(), bson.D{{"_id", fileID}}).Raw()
+				assert.Nil(mt, err, "FindOne error: %v", err)
+				length := filesDoc.Lookup("length").Int64()
+				assert.Equal(mt, int64(len(data)), length, "expected length %d, got %d", len(data), length)
+				id := filesDoc.Lookup("transform", "id").StringValue()
+				assert.Equal(mt, tc.transformer.ID(), id, "expected transformer ID %q, got %q", tc.transformer.ID(), id)
+
+				var downloaded bytes.Buffer
+				_, err = bucket.DownloadToStream(fileID, &downloaded)
+				assert.Nil(mt, err, "DownloadToStream error: %v", err)
+				assert.Equal(mt, data, downloaded.Bytes(), "expected data %q, got %q", data, downloaded.Bytes())
+
+				ds, err := bucket.OpenDownloadStreamRange(fileID, 20, 30)
+				assert.Nil(mt, err, "OpenDownloadStreamRange error: %v", err)
+				got, err := io.ReadAll(ds)
+				assert.Nil(mt, err, "ReadAll error: %v", err)
+				assert.Equal(mt, data[20:50], got, "expected data %q, got %q", data[20:50], got)
+				_ = ds.Close()
+
+				err = bucket.Verify(context.Background(), fileID)
+				assert.Nil(mt, err, "Verify error: %v", err)
+			})
+		}
+
+		mt.Run("unknown transformer", func(mt *mtest.T) {
+			bucket, err := gridfs.NewBucket(mt.DB)
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+			defer func() { _ = bucket.Drop() }()
+
+			uploadOpts := options.GridFSUpload().SetChunkTransformer(gridfs.NewAESGCMTransformer("key", keys))
+			fileID, err := bucket.UploadFromStream("encrypted", bytes.NewReader(data), uploadOpts)
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+			_, err = bucket.OpenDownloadStream(fileID)
+			assert.True(mt, errors.Is(err, gridfs.ErrUnknownTransformer), "expected error %v, got %v",
+				gridfs.ErrUnknownTransformer, err)
+		})
+	})
+
+	mt.RunOpts("check and repair", mtest.NewOptions().MinServerVersion("4.4").CreateClient(false), func(mt *mtest.T) {
+		data := []byte("abc.def.ghi")
+		var chunkSize int32 = 4
+
+		// setup uploads a valid file, a file with a missing chunk and a file with a truncated chunk, and inserts orphaned
+		// chunks for an old and a new file ID.
+		setup := func(mt *mtest.T) (bucket *gridfs.Bucket, missing, inconsistent, oldOrphan, newOrphan interface{}) {
+			var err error
+			bucket, err = gridfs.NewBucket(mt.DB, options.GridFSBucket().SetChunkSizeBytes(chunkSize))
+			assert.Nil(mt, err, "NewBucket error: %v", err)
+			err = bucket.Drop()
+			assert.Nil(mt, err, "Drop error: %v", err)
+
+			_, err = bucket.UploadFromStream("valid", bytes.NewReader(data))
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+			missing, err = bucket.UploadFromStream("missing", bytes.NewReader(data))
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+			inconsistent, err = bucket.UploadFromStream("inconsistent", bytes.NewReader(data))
+			assert.Nil(mt, err, "UploadFromStream error: %v", err)
+
+			chunks := mt.DB.Collection("fs.chunks")
+			_, err = chunks.DeleteOne(context.Background(), bson.D{{"files_id", missing}, {"n", 1}})
+			assert.Nil(mt, err, "DeleteOne error: %v", err)
+			_, err = chunks.UpdateOne(context.Background(),
+				bson.D{{"files_id", inconsistent}, {"n", 0}},
+				bson.D{{"$set", bson.D{{"data", primitive.Binary{Data: []byte("ab")}}}}})
+			assert.Nil(mt, err, "UpdateOne error: %v", err)
+
+			oldOrphan = primitive.NewObjectID()
+			newOrphan = primitive.NewObjectID()
+			oldChunkID := primitive.NewObjectIDFromTimestamp(time.Now().Add(-48 * time.Hour))
+			orphans := []interface{}{
+				bson.D{{"_id", oldChunkID}, {"files_id", oldOrphan}, {"n", 0}, {"data", primitive.Binary{Data: data[:4]}}},
+				bson.D{{"_id", primitive.NewObjectID()}, {"files_id", newOrphan}, {"n", 0}, {"data", primitive.Binary{Data: data[:4]}}},
+				bson.D{{"_id", primitive.NewObjectID()}, {"files_id", newOrphan}, {"n", 1}, {"data", primitive.Binary{Data: data[4:8]}}},
+			}
+			_, err = chunks.InsertMany(context.Background(), orphans)
+			assert.Nil(mt, err, "InsertMany error: %v", err)
+			return bucket, missing, inconsistent, oldOrphan, newOrphan
+		}
+		countChunks := func(mt *mtest.T, fileID interface{}) int64 {
+			count, err := mt.DB.Collection("fs.chunks").CountDocuments(context.Background(), bson.D{{"files_id", fileID}})
+			assert.Nil(mt, err, "CountDocuments error: %v", err)
+			return count
+		}
+
+		mt.Run("Check", func(mt *mtest.T) {
+			bucket, missing, inconsistent, oldOrphan, newOrphan := setup(mt)
+
+			report, err := bucket.Check(context.Background())
+			assert.Nil(mt, err, "Check error: %v", err)
+			defer func() { _ = report.Close(context.Background()) }()
+
+			got := make(map[interface{}]gridfs.Problem)
+			for report.Next(context.Background()) {
+				problem := report.Problem()
+				got[problem.FileID] = problem
+			}
+			assert.Nil(mt, report.Err(), "report error: %v", report.Err())
+			assert.Equal(mt, 4, len(got), "expected 4 problems, got %v", got)
+
+			expected := map[interface{}]gridfs.ProblemType{
+				missing:      gridfs.MissingChunks,
+				inconsistent: gridfs.InconsistentFile,
+				oldOrphan:    gridfs.OrphanedChunks,
+				newOrphan:    gridfs.OrphanedChunks,
+			}
+			for fileID, problemType := range expected {
+				problem, ok := got[fileID]
+				assert.True(mt, ok, "expected problem for file %v", fileID)
+				assert.Equal(mt, problemType, problem.Type, "expected problem type %v for file %v, got %v",
+					problemType, fileID, problem.Type)
+			}
+			assert.Equal(mt, int64(2), got[newOrphan].ChunkCount, "expected 2 chunks, got %v", got[newOrphan].ChunkCount)
+		})
+		mt.Run("Repair dry run", func(mt *mtest.T) {
+			bucket, _, _, oldOrphan, newOrphan := setup(mt)
+
+			res, err := bucket.Repair(context.Background(), options.GridFSRepair().SetDryRun(true))
+			assert.Nil(mt, err, "Repair error: %v", err)
+			assert.Equal(mt, []interface{}{oldOrphan}, res.DeletedFileIDs, "expected deleted file IDs %v, got %v",
+				[]interface{}{oldOrphan}, res.DeletedFileIDs)
+			assert.Equal(mt, []interface{}{newOrphan}, res.SkippedFileIDs, "expected skipped file IDs %v, got %v",
+				[]interface{}{newOrphan}, res.SkippedFileIDs)
+			assert.Equal(mt, int64(1), res.DeletedChunks, "expected 1 deleted chunk, got %v", res.DeletedChunks)
+			assert.Equal(mt, int64(1), countChunks(mt, oldOrphan), "expected orphaned chunk to be kept")
+		})
+		mt.Run("Repair", func(mt *mtest.T) {
+			bucket, missing, _, oldOrphan, newOrphan := setup(mt)
+
+			res, err := bucket.Repair(context.Background())
+			assert.Nil(mt, err, "Repair error: %v", err)
+			assert.Equal(mt, int64(1), res.DeletedChunks, "expected 1 deleted chunk, got %v", res.DeletedChunks)
+			assert.Equal(mt, int64(0), countChunks(mt, oldOrphan), "expected old orphaned chunk to be deleted")
+			assert.Equal(mt, int64(2), countChunks(mt, newOrphan), "expected new orphaned chunks to be kept")
+			assert.Equal(mt, int64(2), countChunks(mt, missing), "expected chunks of existing file to be kept")
+
+			// Without a grace period, the remaining orphaned chunks are deleted in batches.
+			repairOpts := options.GridFSRepair().SetGracePeriod(0).SetBatchSize(1)
+			res, err = bucket.Repair(context.Background(), repairOpts)
+			assert.Nil(mt, err, "Repair error: %v", err)
+			assert.Equal(mt, int64(2), res.DeletedChunks, "expected 2 deleted chunks, got %v", res.DeletedChunks)
+			assert.Equal(mt, int64(0), countChunks(mt, newOrphan), "expected new orphaned chunks to be deleted")
+		})
+	})
+
 	mt.RunOpts("bucket collection accessors", noClientOpts, func(mt *mtest.T) {
 		// Tests for the GetFilesCollection and GetChunksCollection accessors.
 
@@ -0,0 +1,24 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"expvar"
+)
+
+// Var returns an expvar.Var whose value is the JSON encoding of the current Snapshot of the Registry.
+func (r *Registry) Var() expvar.Var {
+	return expvar.Func(func() interface{} {
+		return r.Snapshot()
+	})
+}
+
+// Publish publishes the metrics of the Registry as an expvar variable with the given name. Like expvar.Publish, it
+// panics if a variable with the name is already published.
+func (r *Registry) Publish(name string) {
+	expvar.Publish(name, r.Var())
+}
@@ -0,0 +1,90 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds of the histogram buckets used if no buckets are specified.
+var DefaultBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Bucket is a histogram bucket.
+type Bucket struct {
+	// UpperBound is the inclusive upper bound of the bucket.
+	UpperBound time.Duration
+
+	// Count is the number of observations less than or equal to UpperBound, so the count of a bucket includes the
+	// counts of all buckets before it.
+	Count uint64
+}
+
+// Histogram is a snapshot of the distribution of durations.
+type Histogram struct {
+	// Buckets are the buckets of the histogram in increasing order of their upper bounds. Observations that are
+	// greater than the last upper bound are only included in Count and Sum.
+	Buckets []Bucket
+
+	// Count is the total number of observations.
+	Count uint64
+
+	// Sum is the sum of all observations.
+	Sum time.Duration
+}
+
+// histogram records the distribution of durations. It is not safe for concurrent use.
+type histogram struct {
+	bounds []time.Duration
+	counts []uint64
+	count  uint64
+	sum    time.Duration
+}
+
+func newHistogram(bounds []time.Duration) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	idx := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+	if idx < len(h.counts) {
+		h.counts[idx]++
+	}
+	h.count++
+	h.sum += d
+}
+
+func (h *histogram) snapshot() Histogram {
+	snap := Histogram{
+		Buckets: make([]Bucket, len(h.bounds)),
+		Count:   h.count,
+		Sum:     h.sum,
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		snap.Buckets[i] = Bucket{UpperBound: bound, Count: cumulative}
+	}
+	return snap
+}
@@ -0,0 +1,388 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package metrics collects metrics about the commands, connection pools and topology of a mongo.Client.
+//
+// A Registry provides monitors that record metrics from the events published by the client:
+//
+//	registry := metrics.NewRegistry()
+//	opts := options.Client().
+//		ApplyURI("mongodb://localhost:27017").
+//		SetMonitor(registry.CommandMonitor()).
+//		SetPoolMonitor(registry.PoolMonitor()).
+//		SetServerMonitor(registry.ServerMonitor())
+//	client, err := mongo.Connect(ctx, opts)
+//
+// The collected metrics are read with Registry.Snapshot, exposed in the Prometheus text exposition format with
+// WritePrometheus or PrometheusHandler, or published as an expvar variable with Registry.Publish.
+package metrics // import "github.com/hongyuyang/mongo-go-driver/mongo/metrics"
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/event"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+)
+
+// Registry collects metrics from client events. A Registry must only be used by a single client.
+type Registry struct {
+	buckets []time.Duration
+
+	mu              sync.Mutex
+	inProgress      map[commandKey]commandLabels
+	commands        map[commandLabels]*histogram
+	commandErrors   map[commandError]uint64
+	commandRetries  map[string]uint64
+	pools           map[string]*poolStats
+	heartbeats      map[string]*heartbeatStats
+	topologyChanges uint64
+	serverChanges   map[string]uint64
+}
+
+// commandKey identifies an in-progress command.
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// commandLabels are the labels of the latency histogram of a command.
+type commandLabels struct {
+	command    string
+	database   string
+	collection string
+}
+
+// commandError identifies the errors of a command with the same code.
+type commandError struct {
+	command string
+	code    int32
+}
+
+type poolStats struct {
+	total            int64
+	inUse            int64
+	waitQueue        int64
+	checkoutWait     *histogram
+	checkoutFailures uint64
+	clears           uint64
+}
+
+type heartbeatStats struct {
+	rtt      *histogram
+	failures uint64
+}
+
+// NewRegistry creates a Registry. The histograms of the Registry use the given bucket upper bounds, or DefaultBuckets
+// if none are given.
+func NewRegistry(buckets ...time.Duration) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]time.Duration(nil), buckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	return &Registry{
+		buckets:        buckets,
+		inProgress:     make(map[commandKey]commandLabels),
+		commands:       make(map[commandLabels]*histogram),
+		commandErrors:  make(map[commandError]uint64),
+		commandRetries: make(map[string]uint64),
+		pools:          make(map[string]*poolStats),
+		heartbeats:     make(map[string]*heartbeatStats),
+		serverChanges:  make(map[string]uint64),
+	}
+}
+
+// CommandMonitor returns a CommandMonitor that records command latencies, errors and retries.
+func (r *Registry) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			labels := commandLabels{
+				command:    evt.CommandName,
+				database:   evt.DatabaseName,
+				collection: driverutil.CommandCollection(evt.Command),
+			}
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.inProgress[commandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}] = labels
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.commandFinished(evt.CommandFinishedEvent)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.commandFinished(evt.CommandFinishedEvent)
+			r.commandErrors[commandError{command: evt.CommandName, code: evt.FailureCode}]++
+		},
+		Retry: func(_ context.Context, evt *event.CommandRetryEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.commandRetries[evt.CommandName]++
+		},
+	}
+}
+
+// commandFinished records the latency of a finished command. It must be called with the lock held.
+func (r *Registry) commandFinished(evt event.CommandFinishedEvent) {
+	key := commandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}
+	labels, ok := r.inProgress[key]
+	if !ok {
+		labels = commandLabels{command: evt.CommandName, database: evt.DatabaseName}
+	}
+	delete(r.inProgress, key)
+
+	h, ok := r.commands[labels]
+	if !ok {
+		h = newHistogram(r.buckets)
+		r.commands[labels] = h
+	}
+	h.observe(evt.Duration)
+}
+
+// PoolMonitor returns a PoolMonitor that records the connections and checkouts of connection pools.
+func (r *Registry) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			if evt.Type == event.PoolClosedEvent {
+				delete(r.pools, evt.Address)
+				return
+			}
+
+			pool, ok := r.pools[evt.Address]
+			if !ok {
+				pool = &poolStats{checkoutWait: newHistogram(r.buckets)}
+				r.pools[evt.Address] = pool
+			}
+
+			switch evt.Type {
+			case event.ConnectionCreated:
+				pool.total++
+			case event.ConnectionClosed:
+				pool.total--
+			case event.GetStarted:
+				pool.waitQueue++
+			case event.GetSucceeded:
+				pool.waitQueue--
+				pool.inUse++
+				pool.checkoutWait.observe(evt.Duration)
+			case event.GetFailed:
+				pool.waitQueue--
+				pool.checkoutFailures++
+			case event.ConnectionReturned:
+				pool.inUse--
+			case event.PoolCleared:
+				pool.clears++
+			}
+		},
+	}
+}
+
+// ServerMonitor returns a ServerMonitor that records heartbeats and topology changes.
+//
+// The heartbeat RTT histograms record the RTT samples of the servers rather than the durations of the heartbeats,
+// because the duration of an awaited heartbeat includes the time the server waited for a topology change. With the
+// streaming protocol, the samples are measured by the RTT monitoring connection of each server.
+func (r *Registry) ServerMonitor() *event.ServerMonitor {
+	return &event.ServerMonitor{
+		ServerRTTSampled: func(evt *event.ServerRTTSampledEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.heartbeat(evt.Address.String()).rtt.observe(evt.RTT)
+		},
+		ServerHeartbeatFailed: func(evt *event.ServerHeartbeatFailedEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.heartbeat(driverutil.ConnectionAddress(evt.ConnectionID)).failures++
+		},
+		ServerDescriptionChanged: func(evt *event.ServerDescriptionChangedEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.serverChanges[evt.Address.String()]++
+		},
+		TopologyDescriptionChanged: func(*event.TopologyDescriptionChangedEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.topologyChanges++
+		},
+	}
+}
+
+// heartbeat returns the heartbeat stats of a server. It must be called with the lock held.
+func (r *Registry) heartbeat(address string) *heartbeatStats {
+	hb, ok := r.heartbeats[address]
+	if !ok {
+		hb = &heartbeatStats{rtt: newHistogram(r.buckets)}
+		r.heartbeats[address] = hb
+	}
+	return hb
+}
+
+// Snapshot is a point-in-time copy of the metrics of a Registry. The slices are sorted by their labels.
+type Snapshot struct {
+	Commands        []CommandMetrics
+	CommandErrors   []CommandErrorMetrics
+	CommandRetries  []CommandRetryMetrics
+	Pools           []PoolMetrics
+	Heartbeats      []HeartbeatMetrics
+	TopologyChanges uint64
+	ServerChanges   []ServerChangeMetrics
+}
+
+// CommandMetrics are the latencies of the commands with the same name and namespace. Collection is empty for commands
+// that don't operate on a collection.
+type CommandMetrics struct {
+	Command    string
+	Database   string
+	Collection string
+	Latency    Histogram
+}
+
+// CommandErrorMetrics is the number of failed commands with the same name and error code. Code is 0 for errors that
+// are not server errors, e.g. network errors.
+type CommandErrorMetrics struct {
+	Command string
+	Code    int32
+	Count   uint64
+}
+
+// CommandRetryMetrics is the number of times the commands with the same name were retried.
+type CommandRetryMetrics struct {
+	Command string
+	Count   uint64
+}
+
+// PoolMetrics are the metrics of the connection pool of a server.
+type PoolMetrics struct {
+	Address string
+
+	// Total is the number of open connections, including connections that are being established.
+	Total int64
+
+	// Idle is the number of open connections that are not checked out.
+	Idle int64
+
+	// InUse is the number of checked out connections.
+	InUse int64
+
+	// WaitQueue is the number of operations waiting to check out a connection.
+	WaitQueue int64
+
+	// CheckoutWait is the distribution of the time it took to check out connections.
+	CheckoutWait Histogram
+
+	// CheckoutFailures is the number of failed checkouts.
+	CheckoutFailures uint64
+
+	// Clears is the number of times the pool was cleared.
+	Clears uint64
+}
+
+// HeartbeatMetrics are the heartbeat metrics of a server.
+type HeartbeatMetrics struct {
+	Address  string
+	RTT      Histogram
+	Failures uint64
+}
+
+// ServerChangeMetrics is the number of times the description of a server changed.
+type ServerChangeMetrics struct {
+	Address string
+	Count   uint64
+}
+
+// Snapshot returns a copy of the current metrics.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snap := Snapshot{TopologyChanges: r.topologyChanges}
+	for labels, h := range r.commands {
+		snap.Commands = append(snap.Commands, CommandMetrics{
+			Command:    labels.command,
+			Database:   labels.database,
+			Collection: labels.collection,
+			Latency:    h.snapshot(),
+		})
+	}
+	sort.Slice(snap.Commands, func(i, j int) bool {
+		a, b := snap.Commands[i], snap.Commands[j]
+		if a.Command != b.Command {
+			return a.Command < b.Command
+		}
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		return a.Collection < b.Collection
+	})
+
+	for key, count := range r.commandErrors {
+		snap.CommandErrors = append(snap.CommandErrors, CommandErrorMetrics{
+			Command: key.command,
+			Code:    key.code,
+			Count:   count,
+		})
+	}
+	sort.Slice(snap.CommandErrors, func(i, j int) bool {
+		a, b := snap.CommandErrors[i], snap.CommandErrors[j]
+		if a.Command != b.Command {
+			return a.Command < b.Command
+		}
+		return a.Code < b.Code
+	})
+
+	for command, count := range r.commandRetries {
+		snap.CommandRetries = append(snap.CommandRetries, CommandRetryMetrics{Command: command, Count: count})
+	}
+	sort.Slice(snap.CommandRetries, func(i, j int) bool {
+		return snap.CommandRetries[i].Command < snap.CommandRetries[j].Command
+	})
+
+	for address, pool := range r.pools {
+		idle := pool.total - pool.inUse
+		if idle < 0 {
+			idle = 0
+		}
+		snap.Pools = append(snap.Pools, PoolMetrics{
+			Address:          address,
+			Total:            pool.total,
+			Idle:             idle,
+			InUse:            pool.inUse,
+			WaitQueue:        pool.waitQueue,
+			CheckoutWait:     pool.checkoutWait.snapshot(),
+			CheckoutFailures: pool.checkoutFailures,
+			Clears:           pool.clears,
+		})
+	}
+	sort.Slice(snap.Pools, func(i, j int) bool { return snap.Pools[i].Address < snap.Pools[j].Address })
+
+	for address, hb := range r.heartbeats {
+		snap.Heartbeats = append(snap.Heartbeats, HeartbeatMetrics{
+			Address:  address,
+			RTT:      hb.rtt.snapshot(),
+			Failures: hb.failures,
+		})
+	}
+	sort.Slice(snap.Heartbeats, func(i, j int) bool { return snap.Heartbeats[i].Address < snap.Heartbeats[j].Address })
+
+	for address, count := range r.serverChanges {
+		snap.ServerChanges = append(snap.ServerChanges, ServerChangeMetrics{Address: address, Count: count})
+	}
+	sort.Slice(snap.ServerChanges, func(i, j int) bool {
+		return snap.ServerChanges[i].Address < snap.ServerChanges[j].Address
+	})
+
+	return snap
+}
@@ -0,0 +1,189 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/event"
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+)
+
+func TestRegistry(t *testing.T) {
+	const connID = "localhost:27017[-1]"
+
+	// runCommand publishes the events of a command to a CommandMonitor.
+	runCommand := func(cm *event.CommandMonitor, requestID int64, cmd bson.D, duration time.Duration, failureCode int32) {
+		raw, err := bson.Marshal(cmd)
+		assert.Nil(t, err, "Marshal error: %v", err)
+
+		cm.Started(context.Background(), &event.CommandStartedEvent{
+			Command:      raw,
+			DatabaseName: "db",
+			CommandName:  cmd[0].Key,
+			RequestID:    requestID,
+			ConnectionID: connID,
+		})
+		finished := event.CommandFinishedEvent{
+			CommandName:  cmd[0].Key,
+			DatabaseName: "db",
+			RequestID:    requestID,
+			ConnectionID: connID,
+			Duration:     duration,
+		}
+		if failureCode == 0 {
+			cm.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+			return
+		}
+		cm.Failed(context.Background(), &event.CommandFailedEvent{
+			CommandFinishedEvent: finished,
+			Failure:              "failure",
+			FailureCode:          failureCode,
+		})
+	}
+
+	t.Run("commands", func(t *testing.T) {
+		r := NewRegistry(10*time.Millisecond, time.Millisecond)
+		cm := r.CommandMonitor()
+		runCommand(cm, 1, bson.D{{"find", "coll"}}, 500*time.Microsecond, 0)
+		runCommand(cm, 2, bson.D{{"find", "coll"}}, 5*time.Millisecond, 0)
+		runCommand(cm, 3, bson.D{{"find", "coll"}}, time.Second, 13)
+		runCommand(cm, 4, bson.D{{"getMore", int64(1)}, {"collection", "coll"}}, time.Millisecond, 0)
+		runCommand(cm, 5, bson.D{{"ping", 1}}, time.Millisecond, 0)
+
+		snap := r.Snapshot()
+		assert.Equal(t, 3, len(snap.Commands), "expected 3 command metrics, got %d", len(snap.Commands))
+		find := snap.Commands[0]
+		assert.Equal(t, "find", find.Command, "expected command %q, got %q", "find", find.Command)
+		assert.Equal(t, "coll", find.Collection, "expected collection %q, got %q", "coll", find.Collection)
+		wantLatency := Histogram{
+			Buckets: []Bucket{
+				{UpperBound: time.Millisecond, Count: 1},
+				{UpperBound: 10 * time.Millisecond, Count: 2},
+			},
+			Count: 3,
+			Sum:   time.Second + 5500*time.Microsecond,
+		}
+		assert.Equal(t, wantLatency, find.Latency, "expected latency %v, got %v", wantLatency, find.Latency)
+
+		getMore := snap.Commands[1]
+		assert.Equal(t, "coll", getMore.Collection, "expected getMore collection %q, got %q", "coll",
+			getMore.Collection)
+		ping := snap.Commands[2]
+		assert.Equal(t, "", ping.Collection, "expected no ping collection, got %q", ping.Collection)
+
+		wantErrors := []CommandErrorMetrics{{Command: "find", Code: 13, Count: 1}}
+		assert.Equal(t, wantErrors, snap.CommandErrors, "expected errors %v, got %v", wantErrors, snap.CommandErrors)
+	})
+	t.Run("retries", func(t *testing.T) {
+		r := NewRegistry()
+		cm := r.CommandMonitor()
+		for _, name := range []string{"find", "insert", "find"} {
+			cm.Retry(context.Background(), &event.CommandRetryEvent{CommandName: name, Attempt: 2})
+		}
+
+		want := []CommandRetryMetrics{{Command: "find", Count: 2}, {Command: "insert", Count: 1}}
+		snap := r.Snapshot()
+		assert.Equal(t, want, snap.CommandRetries, "expected retries %v, got %v", want, snap.CommandRetries)
+
+		var buf bytes.Buffer
+		err := WritePrometheus(&buf, snap)
+		assert.Nil(t, err, "WritePrometheus error: %v", err)
+		assert.True(t, strings.Contains(buf.String(), `mongodb_command_retries_total{command="find"} 2`),
+			"expected the retries in the exposition, got %s", buf.String())
+	})
+	t.Run("pools", func(t *testing.T) {
+		r := NewRegistry()
+		pm := r.PoolMonitor()
+		const addr = "localhost:27017"
+		for _, typ := range []string{
+			event.ConnectionCreated, event.ConnectionCreated, event.ConnectionCreated,
+			event.GetStarted, event.GetStarted, event.GetStarted,
+			event.GetSucceeded, event.GetSucceeded, event.GetFailed,
+			event.GetStarted,
+			event.ConnectionReturned,
+			event.PoolCleared,
+		} {
+			pm.Event(&event.PoolEvent{Type: typ, Address: addr, Duration: time.Millisecond})
+		}
+
+		snap := r.Snapshot()
+		assert.Equal(t, 1, len(snap.Pools), "expected 1 pool, got %d", len(snap.Pools))
+		pool := snap.Pools[0]
+		assert.Equal(t, int64(3), pool.Total, "expected 3 connections, got %d", pool.Total)
+		assert.Equal(t, int64(1), pool.InUse, "expected 1 in-use connection, got %d", pool.InUse)
+		assert.Equal(t, int64(2), pool.Idle, "expected 2 idle connections, got %d", pool.Idle)
+		assert.Equal(t, int64(1), pool.WaitQueue, "expected wait queue length 1, got %d", pool.WaitQueue)
+		assert.Equal(t, uint64(2), pool.CheckoutWait.Count, "expected 2 checkouts, got %d", pool.CheckoutWait.Count)
+		assert.Equal(t, uint64(1), pool.CheckoutFailures, "expected 1 checkout failure, got %d", pool.CheckoutFailures)
+		assert.Equal(t, uint64(1), pool.Clears, "expected 1 clear, got %d", pool.Clears)
+
+		pm.Event(&event.PoolEvent{Type: event.PoolClosedEvent, Address: addr})
+		snap = r.Snapshot()
+		assert.Equal(t, 0, len(snap.Pools), "expected no pools after close, got %d", len(snap.Pools))
+	})
+	t.Run("servers", func(t *testing.T) {
+		r := NewRegistry()
+		sm := r.ServerMonitor()
+		sm.ServerRTTSampled(&event.ServerRTTSampledEvent{Address: address.Address("localhost:27017"), RTT: time.Millisecond})
+		sm.ServerRTTSampled(&event.ServerRTTSampledEvent{Address: address.Address("localhost:27017"), RTT: time.Millisecond})
+		sm.ServerHeartbeatFailed(&event.ServerHeartbeatFailedEvent{ConnectionID: connID})
+		sm.ServerDescriptionChanged(&event.ServerDescriptionChangedEvent{Address: address.Address("localhost:27017")})
+		sm.TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{})
+		sm.TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{})
+
+		snap := r.Snapshot()
+		assert.Equal(t, 1, len(snap.Heartbeats), "expected 1 server, got %d", len(snap.Heartbeats))
+		hb := snap.Heartbeats[0]
+		assert.Equal(t, "localhost:27017", hb.Address, "expected address %q, got %q", "localhost:27017", hb.Address)
+		assert.Equal(t, uint64(2), hb.RTT.Count, "expected 2 RTT observations, got %d", hb.RTT.Count)
+		assert.Equal(t, uint64(1), hb.Failures, "expected 1 heartbeat failure, got %d", hb.Failures)
+		wantChanges := []ServerChangeMetrics{{Address: "localhost:27017", Count: 1}}
+		assert.Equal(t, wantChanges, snap.ServerChanges, "expected server changes %v, got %v", wantChanges,
+			snap.ServerChanges)
+		assert.Equal(t, uint64(2), snap.TopologyChanges, "expected 2 topology changes, got %d", snap.TopologyChanges)
+	})
+	t.Run("prometheus", func(t *testing.T) {
+		r := NewRegistry(time.Millisecond)
+		runCommand(r.CommandMonitor(), 1, bson.D{{"insert", `co"ll`}}, 2*time.Millisecond, 11000)
+		r.PoolMonitor().Event(&event.PoolEvent{Type: event.ConnectionCreated, Address: "localhost:27017"})
+
+		var buf bytes.Buffer
+		err := WritePrometheus(&buf, r.Snapshot())
+		assert.Nil(t, err, "WritePrometheus error: %v", err)
+
+		for _, want := range []string{
+			"# TYPE mongodb_command_duration_seconds histogram\n",
+			`mongodb_command_duration_seconds_bucket{command="insert",database="db",collection="co\"ll",le="0.001"} 0` + "\n",
+			`mongodb_command_duration_seconds_bucket{command="insert",database="db",collection="co\"ll",le="+Inf"} 1` + "\n",
+			`mongodb_command_duration_seconds_sum{command="insert",database="db",collection="co\"ll"} 0.002` + "\n",
+			`mongodb_command_errors_total{command="insert",code="11000"} 1` + "\n",
+			`mongodb_pool_connections{address="localhost:27017"} 1` + "\n",
+			`mongodb_pool_idle_connections{address="localhost:27017"} 1` + "\n",
+			"mongodb_topology_changes_total 0\n",
+		} {
+			assert.True(t, strings.Contains(buf.String(), want), "expected output to contain %q, got:\n%s", want,
+				buf.String())
+		}
+	})
+	t.Run("expvar", func(t *testing.T) {
+		r := NewRegistry()
+		r.ServerMonitor().TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{})
+
+		var snap Snapshot
+		err := json.Unmarshal([]byte(r.Var().String()), &snap)
+		assert.Nil(t, err, "Unmarshal error: %v", err)
+		assert.Equal(t, uint64(1), snap.TopologyChanges, "expected 1 topology change, got %d", snap.TopologyChanges)
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes a snapshot in the Prometheus text exposition format. Durations are written in seconds.
+func WritePrometheus(w io.Writer, snap Snapshot) error {
+	pw := &promWriter{w: bufio.NewWriter(w)}
+
+	pw.header("mongodb_command_duration_seconds", "histogram", "Duration of commands sent to the server.")
+	for _, cmd := range snap.Commands {
+		pw.histogram("mongodb_command_duration_seconds", cmd.Latency,
+			"command", cmd.Command, "database", cmd.Database, "collection", cmd.Collection)
+	}
+
+	pw.header("mongodb_command_errors_total", "counter", "Number of failed commands by server error code.")
+	for _, cmdErr := range snap.CommandErrors {
+		pw.sample("mongodb_command_errors_total", float64(cmdErr.Count),
+			"command", cmdErr.Command, "code", strconv.Itoa(int(cmdErr.Code)))
+	}
+
+	pw.header("mongodb_command_retries_total", "counter", "Number of command retries.")
+	for _, retry := range snap.CommandRetries {
+		pw.sample("mongodb_command_retries_total", float64(retry.Count), "command", retry.Command)
+	}
+
+	pw.header("mongodb_pool_connections", "gauge", "Number of open connections in the pool.")
+	for _, pool := range snap.Pools {
+		pw.sample("mongodb_pool_connections", float64(pool.Total), "address", pool.Address)
+	}
+	pw.header("mongodb_pool_idle_connections", "gauge", "Number of idle connections in the pool.")
+	for _, pool := range snap.Pools {
+		pw.sample("mongodb_pool_idle_connections", float64(pool.Idle), "address", pool.Address)
+	}
+	pw.header("mongodb_pool_in_use_connections", "gauge", "Number of checked out connections.")
+	for _, pool := range snap.Pools {
+		pw.sample("mongodb_pool_in_use_connections", float64(pool.InUse), "address", pool.Address)
+	}
+	pw.header("mongodb_pool_wait_queue_length", "gauge", "Number of operations waiting to check out a connection.")
+	for _, pool := range snap.Pools {
+		pw.sample("mongodb_pool_wait_queue_length", float64(pool.WaitQueue), "address", pool.Address)
+	}
+	pw.header("mongodb_pool_checkout_duration_seconds", "histogram", "Time it took to check out connections.")
+	for _, pool := range snap.Pools {
+		pw.histogram("mongodb_pool_checkout_duration_seconds", pool.CheckoutWait, "address", pool.Address)
+	}
+	pw.header("mongodb_pool_checkout_failures_total", "counter", "Number of failed connection checkouts.")
+	for _, pool := range snap.Pools {
+		pw.sample("mongodb_pool_checkout_failures_total", float64(pool.CheckoutFailures), "address", pool.Address)
+	}
+	pw.header("mongodb_pool_clears_total", "counter", "Number of times the pool was cleared.")
+	for _, pool := range snap.Pools {
+		pw.sample("mongodb_pool_clears_total", float64(pool.Clears), "address", pool.Address)
+	}
+
+	pw.header("mongodb_heartbeat_rtt_seconds", "histogram", "Round trip time samples of servers.")
+	for _, hb := range snap.Heartbeats {
+		pw.histogram("mongodb_heartbeat_rtt_seconds", hb.RTT, "address", hb.Address)
+	}
+	pw.header("mongodb_heartbeat_failures_total", "counter", "Number of failed heartbeats.")
+	for _, hb := range snap.Heartbeats {
+		pw.sample("mongodb_heartbeat_failures_total", float64(hb.Failures), "address", hb.Address)
+	}
+
+	pw.header("mongodb_topology_changes_total", "counter", "Number of topology description changes.")
+	pw.sample("mongodb_topology_changes_total", float64(snap.TopologyChanges))
+	pw.header("mongodb_server_changes_total", "counter", "Number of server description changes.")
+	for _, change := range snap.ServerChanges {
+		pw.sample("mongodb_server_changes_total", float64(change.Count), "address", change.Address)
+	}
+
+	if pw.err != nil {
+		return pw.err
+	}
+	return pw.w.Flush()
+}
+
+// PrometheusHandler returns an http.Handler that serves the metrics of a Registry in the Prometheus text exposition
+// format.
+func PrometheusHandler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		_ = WritePrometheus(w, r.Snapshot())
+	})
+}
+
+// promWriter writes samples in the Prometheus text exposition format and keeps the first error.
+type promWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (pw *promWriter) write(s string) {
+	if pw.err == nil {
+		_, pw.err = pw.w.WriteString(s)
+	}
+}
+
+func (pw *promWriter) header(name, typ, help string) {
+	pw.write("# HELP " + name + " " + help + "\n")
+	pw.write("# TYPE " + name + " " + typ + "\n")
+}
+
+// sample writes a sample with labels given as alternating names and values.
+func (pw *promWriter) sample(name string, value float64, labels ...string) {
+	pw.write(name + formatLabels(labels) + " " + formatFloat(value) + "\n")
+}
+
+func (pw *promWriter) histogram(name string, h Histogram, labels ...string) {
+	for _, bucket := range h.Buckets {
+		pw.sample(name+"_bucket", float64(bucket.Count), append(labels, "le", formatFloat(bucket.UpperBound.Seconds()))...)
+	}
+	pw.sample(name+"_bucket", float64(h.Count), append(labels, "le", "+Inf")...)
+	pw.sample(name+"_sum", h.Sum.Seconds(), labels...)
+	pw.sample(name+"_count", float64(h.Count), labels...)
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(labels[i])
+		sb.WriteString(`="`)
+		sb.WriteString(labelValueReplacer.Replace(labels[i+1]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// labelValueReplacer escapes label values as required by the text exposition format.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
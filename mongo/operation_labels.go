@@ -0,0 +1,57 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+)
+
+// WithOperationLabels returns a context that labels all operations run with it, like the name of the business
+// operation that runs them:
+//
+//	ctx = mongo.WithOperationLabels(ctx, map[string]string{"op": "checkout.reserveStock"})
+//
+// The labels are added to the comment of the commands sent to servers with MongoDB 4.4 or later, so they appear in
+// the server's slow query log, profiler and currentOp output. How they are combined with the comment of an operation
+// is specified by the CommentPolicy option. The labels are also set on the CommandStartedEvent, CommandSucceededEvent
+// and CommandFailedEvent of the commands and included in command log messages.
+//
+// If the context already has labels, the given labels are added to them, replacing labels with the same name.
+func WithOperationLabels(
+	ctx context.Context,
+	labels map[string]string,
+	opts ...*options.OperationLabelsOptions,
+) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent, _ := driverutil.OperationLabelsFromContext(ctx)
+	merged := make(map[string]string, len(parent.Labels)+len(labels))
+	for name, value := range parent.Labels {
+		merged[name] = value
+	}
+	for name, value := range labels {
+		merged[name] = value
+	}
+
+	policy := parent.CommentPolicy
+	if lo := options.MergeOperationLabelsOptions(opts...); lo.CommentPolicy != nil {
+		policy = driverutil.LabelsCommentPolicy(*lo.CommentPolicy)
+	}
+
+	return driverutil.WithOperationLabels(ctx, driverutil.OperationLabels{Labels: merged, CommentPolicy: policy})
+}
+
+// OperationLabels returns the operation labels of a context, which were added with WithOperationLabels.
+func OperationLabels(ctx context.Context) map[string]string {
+	labels, _ := driverutil.OperationLabelsFromContext(ctx)
+	return labels.Labels
+}
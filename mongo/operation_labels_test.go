@@ -0,0 +1,33 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+)
+
+func TestWithOperationLabels(t *testing.T) {
+	ctx := WithOperationLabels(context.Background(), map[string]string{"op": "checkout", "team": "shop"},
+		options.OperationLabels().SetCommentPolicy(options.LabelsCommentPreserve))
+	ctx = WithOperationLabels(ctx, map[string]string{"op": "checkout.reserveStock"})
+
+	want := map[string]string{"op": "checkout.reserveStock", "team": "shop"}
+	got := OperationLabels(ctx)
+	assert.Equal(t, want, got, "expected labels %v, got %v", want, got)
+
+	labels, ok := driverutil.OperationLabelsFromContext(ctx)
+	assert.True(t, ok, "expected labels in context")
+	assert.Equal(t, driverutil.LabelsCommentPreserve, labels.CommentPolicy,
+		"expected the comment policy of the parent context, got %v", labels.CommentPolicy)
+
+	assert.Nil(t, OperationLabels(context.Background()), "expected no labels")
+}
@@ -0,0 +1,39 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
+)
+
+// WithOperationPriority returns a context that gives all operations run with it the given priority:
+//
+//	ctx = mongo.WithOperationPriority(ctx, priority.High)
+//
+// When the connection pool of a server has no idle connection and can't create a new one, operations wait for a
+// connection in the order of their priority, so latency-critical operations don't wait behind batch jobs. Operations
+// that have waited for a long time are served before newer operations with a higher priority, so operations with a
+// low priority are not starved. The share of the connection pool the operations of each priority can hold is limited
+// with options.ClientOptions.SetPoolPriorityShare.
+//
+// Operations run without a priority have priority.Normal. Priorities above priority.High are treated as priority.High
+// and priorities below priority.Low are treated as priority.Low.
+func WithOperationPriority(ctx context.Context, p priority.Priority) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return driverutil.WithPriority(ctx, p)
+}
+
+// OperationPriority returns the priority of the operations run with a context, which was set with
+// WithOperationPriority.
+func OperationPriority(ctx context.Context) priority.Priority {
+	return driverutil.PriorityFromContext(ctx)
+}
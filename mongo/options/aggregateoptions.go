@@ -63,6 +63,14 @@ type AggregateOptions struct {
 	// option names and values. Values must be Marshalable. Custom options may conflict with non-custom options, and custom
 	// options bypass client-side validation. Prefer using non-custom options where possible.
 	Custom bson.M
+
+	// Timeout is the timeout for the operation. The default value is nil, which means that the timeout of the
+	// Collection or Database will be used. A timeout of 0 means no timeout.
+	Timeout *time.Duration
+
+	// TimeoutMode specifies how Timeout applies to the returned cursor. The default value is nil, which means
+	// TimeoutModeCursorLifetime.
+	TimeoutMode *TimeoutMode
 }
 
 // Aggregate creates a new AggregateOptions instance.
@@ -137,6 +145,18 @@ func (ao *AggregateOptions) SetCustom(c bson.M) *AggregateOptions {
 	return ao
 }
 
+// SetTimeout sets the value for the Timeout field.
+func (ao *AggregateOptions) SetTimeout(d time.Duration) *AggregateOptions {
+	ao.Timeout = &d
+	return ao
+}
+
+// SetTimeoutMode sets the value for the TimeoutMode field.
+func (ao *AggregateOptions) SetTimeoutMode(mode TimeoutMode) *AggregateOptions {
+	ao.TimeoutMode = &mode
+	return ao
+}
+
 // MergeAggregateOptions combines the given AggregateOptions instances into a single AggregateOptions in a last-one-wins
 // fashion.
 //
@@ -178,6 +198,12 @@ func MergeAggregateOptions(opts ...*AggregateOptions) *AggregateOptions {
 		if ao.Custom != nil {
 			aggOpts.Custom = ao.Custom
 		}
+		if ao.Timeout != nil {
+			aggOpts.Timeout = ao.Timeout
+		}
+		if ao.TimeoutMode != nil {
+			aggOpts.TimeoutMode = ao.TimeoutMode
+		}
 	}
 
 	return aggOpts
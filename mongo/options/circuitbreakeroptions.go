@@ -0,0 +1,100 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"fmt"
+	"time"
+)
+
+// CircuitBreakerOptions represents options that can be used to configure the circuit breaker of each server with
+// ClientOptions.SetCircuitBreaker.
+//
+// The circuit breaker of a server tracks the outcomes of the reads sent to the server in a sliding window. Only the
+// reads that can be sent to other servers are tracked, which are the reads sent to a sharded cluster and the reads with
+// a read preference other than primary sent to a replica set. Network errors, retryable server errors such as
+// ExceededTimeLimit and errors labeled "SystemOverloadedError" are failures; other errors show the server is serving
+// reads, so they count as successes. When the failure rate in the window reaches the threshold, the circuit breaker
+// opens and the server is no longer selected for the tracked reads, although it is still monitored and selected for
+// writes and primary reads. After the cool-down, the circuit breaker becomes half-open and lets a limited number of
+// probe reads be sent to the server. It closes once they all succeed and opens again as soon as one of them fails.
+// Transitions are published to the CircuitBreakerOpened, CircuitBreakerHalfOpened and CircuitBreakerClosed callbacks
+// of the event.ServerMonitor, and servers whose circuit breaker is open are reported with
+// description.Server.CircuitOpen set.
+type CircuitBreakerOptions struct {
+	// FailureRate is the failure rate of the reads in the sliding window at which the circuit breaker opens, which
+	// must be greater than 0 and at most 1. The default is 0.5.
+	FailureRate *float64
+
+	// MinCommands is the minimum number of reads in the sliding window for the circuit breaker to open, so that a
+	// few failures of a server with little traffic don't open it. The default is 20.
+	MinCommands *int
+
+	// Window is the duration of the sliding window. The default is 30 seconds.
+	Window *time.Duration
+
+	// CoolDown is how long the circuit breaker stays open before it becomes half-open. The default is 30 seconds.
+	CoolDown *time.Duration
+
+	// Probes is the number of reads sent to the server while the circuit breaker is half-open, which must all
+	// succeed to close it. If the probes don't complete within the cool-down, new probes are sent. The default is 5.
+	Probes *int
+}
+
+// CircuitBreaker creates a new CircuitBreakerOptions instance.
+func CircuitBreaker() *CircuitBreakerOptions {
+	return &CircuitBreakerOptions{}
+}
+
+// SetFailureRate sets the value for the FailureRate field.
+func (cbo *CircuitBreakerOptions) SetFailureRate(rate float64) *CircuitBreakerOptions {
+	cbo.FailureRate = &rate
+	return cbo
+}
+
+// SetMinCommands sets the value for the MinCommands field.
+func (cbo *CircuitBreakerOptions) SetMinCommands(commands int) *CircuitBreakerOptions {
+	cbo.MinCommands = &commands
+	return cbo
+}
+
+// SetWindow sets the value for the Window field.
+func (cbo *CircuitBreakerOptions) SetWindow(d time.Duration) *CircuitBreakerOptions {
+	cbo.Window = &d
+	return cbo
+}
+
+// SetCoolDown sets the value for the CoolDown field.
+func (cbo *CircuitBreakerOptions) SetCoolDown(d time.Duration) *CircuitBreakerOptions {
+	cbo.CoolDown = &d
+	return cbo
+}
+
+// SetProbes sets the value for the Probes field.
+func (cbo *CircuitBreakerOptions) SetProbes(probes int) *CircuitBreakerOptions {
+	cbo.Probes = &probes
+	return cbo
+}
+
+func (cbo *CircuitBreakerOptions) validate() error {
+	if rate := cbo.FailureRate; rate != nil && (*rate <= 0 || *rate > 1) {
+		return fmt.Errorf("circuit breaker failure rate must be greater than 0 and at most 1, got %v", *rate)
+	}
+	if commands := cbo.MinCommands; commands != nil && *commands <= 0 {
+		return fmt.Errorf("circuit breaker min commands must be greater than 0, got %d", *commands)
+	}
+	if window := cbo.Window; window != nil && *window <= 0 {
+		return fmt.Errorf("circuit breaker window must be greater than 0, got %v", *window)
+	}
+	if coolDown := cbo.CoolDown; coolDown != nil && *coolDown <= 0 {
+		return fmt.Errorf("circuit breaker cool-down must be greater than 0, got %v", *coolDown)
+	}
+	if probes := cbo.Probes; probes != nil && *probes <= 0 {
+		return fmt.Errorf("circuit breaker probes must be greater than 0, got %d", *probes)
+	}
+	return nil
+}
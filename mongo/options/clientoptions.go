@@ -23,6 +23,9 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/bson/bsoncodec"
 	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/httputil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
 	"github.com/hongyuyang/mongo-go-driver/mongo/writeconcern"
@@ -200,24 +203,32 @@ type ClientOptions struct {
 	AppName                  *string
 	Auth                     *Credential
 	AutoEncryptionOptions    *AutoEncryptionOptions
+	CircuitBreaker           *CircuitBreakerOptions
 	ConnectTimeout           *time.Duration
 	Compressors              []string
 	Dialer                   ContextDialer
 	Direct                   *bool
 	DisableOCSPEndpointCheck *bool
+	FaultInjector            *faultinject.Injector
 	HeartbeatInterval        *time.Duration
+	Hedging                  *HedgingOptions
 	Hosts                    []string
 	HTTPClient               *http.Client
 	LoadBalanced             *bool
+	LoadShedding             *LoadSheddingOptions
 	LocalThreshold           *time.Duration
 	LoggerOptions            *LoggerOptions
 	MaxConnIdleTime          *time.Duration
+	MaxConnLifeTime          *time.Duration
+	MaxConnLifeTimeJitter    *time.Duration
 	MaxPoolSize              *uint64
 	MinPoolSize              *uint64
 	MaxConnecting            *uint64
 	PoolMonitor              *event.PoolMonitor
 	Monitor                  *event.CommandMonitor
 	ServerMonitor            *event.ServerMonitor
+	ServerSelectionMonitor   *event.ServerSelectionMonitor
+	ServerSelector           description.ServerSelector
 	ReadConcern              *readconcern.ReadConcern
 	ReadPreference           *readpref.ReadPref
 	BSONOptions              *BSONOptions
@@ -228,6 +239,7 @@ type ClientOptions struct {
 	ServerAPIOptions         *ServerAPIOptions
 	ServerMonitoringMode     *string
 	ServerSelectionTimeout   *time.Duration
+	SlowOperationThreshold   *time.Duration
 	SRVMaxHosts              *int
 	SRVServiceName           *string
 	Timeout                  *time.Duration
@@ -236,6 +248,19 @@ type ClientOptions struct {
 	ZlibLevel                *int
 	ZstdLevel                *int
 
+	// SlowOperationCommandThresholds and SlowOperationSampleRate configure slow operation logging together with
+	// SlowOperationThreshold.
+	SlowOperationCommandThresholds map[string]time.Duration
+	SlowOperationSampleRate        *float64
+
+	// PoolPriorityShares limits the share of the connection pool of each server that operations of a priority can
+	// hold.
+	PoolPriorityShares map[priority.Priority]float64
+
+	// PoolPriorityAging is how long a checkOut waits for a connection before it's ranked like a checkOut of the next
+	// higher priority.
+	PoolPriorityAging *time.Duration
+
 	err error
 	cs  *connstring.ConnString
 
@@ -331,6 +356,40 @@ func (c *ClientOptions) validate() error {
 		return fmt.Errorf("invalid server monitoring mode: %q", *mode)
 	}
 
+	if rate := c.SlowOperationSampleRate; rate != nil && (*rate <= 0 || *rate > 1) {
+		return fmt.Errorf("slow operation sample rate must be greater than 0 and at most 1, got %v", *rate)
+	}
+
+	if c.LoadShedding != nil {
+		if err := c.LoadShedding.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.CircuitBreaker != nil {
+		if err := c.CircuitBreaker.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Hedging != nil {
+		if err := c.Hedging.validate(); err != nil {
+			return err
+		}
+	}
+
+	for p, share := range c.PoolPriorityShares {
+		if !p.Valid() {
+			return fmt.Errorf("pool priority share set for an %v", p)
+		}
+		if share <= 0 || share > 1 {
+			return fmt.Errorf("pool priority share must be greater than 0 and at most 1, got %v for priority %v", share, p)
+		}
+	}
+	if c.PoolPriorityAging != nil && *c.PoolPriorityAging <= 0 {
+		return fmt.Errorf("pool priority aging must be positive, got %v", *c.PoolPriorityAging)
+	}
+
 	return nil
 }
 
@@ -428,6 +487,14 @@ func (c *ClientOptions) ApplyURI(uri string) *ClientOptions {
 		c.MaxConnIdleTime = &cs.MaxConnIdleTime
 	}
 
+	if cs.MaxConnLifeTimeSet {
+		c.MaxConnLifeTime = &cs.MaxConnLifeTime
+	}
+
+	if cs.MaxConnLifeTimeJitterSet {
+		c.MaxConnLifeTimeJitter = &cs.MaxConnLifeTimeJitter
+	}
+
 	if cs.MaxPoolSizeSet {
 		c.MaxPoolSize = &cs.MaxPoolSize
 	}
@@ -706,6 +773,25 @@ func (c *ClientOptions) SetMaxConnIdleTime(d time.Duration) *ClientOptions {
 	return c
 }
 
+// SetMaxConnLifeTime specifies the maximum amount of time that a connection can exist before it is removed from the
+// connection pool and closed, regardless of how often it is used. Connections in use are closed when they are checked
+// back in. Limiting the lifetime of connections lets the connections be rebalanced across the servers behind a load
+// balancer after it is scaled out. This can also be set through the "maxConnLifeTimeMS" URI option (e.g.
+// "maxConnLifeTimeMS=600000"). The default is 0, meaning the lifetime of a connection is not limited.
+func (c *ClientOptions) SetMaxConnLifeTime(d time.Duration) *ClientOptions {
+	c.MaxConnLifeTime = &d
+	return c
+}
+
+// SetMaxConnLifeTimeJitter specifies the maximum random duration added to the lifetime of each connection set with
+// SetMaxConnLifeTime, so that the connections created at the same time don't all expire at the same time. This can
+// also be set through the "maxConnLifeTimeJitterMS" URI option (e.g. "maxConnLifeTimeJitterMS=60000"). The default is
+// 0, meaning all connections have the same lifetime. It has no effect if the lifetime of connections is not limited.
+func (c *ClientOptions) SetMaxConnLifeTimeJitter(d time.Duration) *ClientOptions {
+	c.MaxConnLifeTimeJitter = &d
+	return c
+}
+
 // SetMaxPoolSize specifies that maximum number of connections allowed in the driver's connection pool to each server.
 // Requests to a server will block if this maximum is reached. This can also be set through the "maxPoolSize" URI option
 // (e.g. "maxPoolSize=100"). If this is 0, maximum connection pool size is not limited. The default is 100.
@@ -750,6 +836,13 @@ func (c *ClientOptions) SetServerMonitor(m *event.ServerMonitor) *ClientOptions
 	return c
 }
 
+// SetServerSelectionMonitor specifies a ServerSelectionMonitor to receive server selection events. See the
+// event.ServerSelectionMonitor documentation for more information about the events that can be received.
+func (c *ClientOptions) SetServerSelectionMonitor(m *event.ServerSelectionMonitor) *ClientOptions {
+	c.ServerSelectionMonitor = m
+	return c
+}
+
 // SetReadConcern specifies the read concern to use for read operations. A read concern level can also be set through
 // the "readConcernLevel" URI option (e.g. "readConcernLevel=majority"). The default is nil, meaning the server will use
 // its configured default.
@@ -831,6 +924,63 @@ func (c *ClientOptions) SetRetryReads(b bool) *ClientOptions {
 	return c
 }
 
+// SetServerSelector specifies a server selector that runs after the read preference and latency window selectors of
+// every read operation, like find, aggregate without $out or $merge, count, distinct and the list commands. It receives
+// the topology description and the servers that are suitable for the operation, and returns the servers the operation
+// may be sent to. The MinRTT and P90RTT fields of the server descriptions contain the round trip time statistics of the
+// servers, which can be used to implement zone affinity or RTT-based routing. If the selector returns no servers,
+// server selection waits for the topology to change until the server selection timeout expires. The selector can be
+// overridden for a single operation with mongo.WithServerSelector.
+//
+// The selector is not used for writes and other commands that are not reads, which are sent to the primary, for
+// operations on a session that is pinned to a server, like a transaction on a sharded cluster, or for load-balanced
+// deployments. The default is nil, which means no additional selection.
+func (c *ClientOptions) SetServerSelector(ss description.ServerSelector) *ClientOptions {
+	c.ServerSelector = ss
+	return c
+}
+
+// SetLoadShedding specifies options to enable load shedding, which limits the number of operations in progress on
+// each server to an adaptive concurrency limit, rejects the operations over the limit without waiting for a connection,
+// and retries the operations rejected because a server is overloaded within a retry budget. See LoadSheddingOptions
+// for more information. Limiting the operations in progress lets an overloaded server recover instead of being sent
+// more operations as they pile up in the connection pool until they time out. Operations rejected by the client return
+// an error that wraps mongo.ErrOverloaded. The default is nil, meaning load shedding is disabled.
+func (c *ClientOptions) SetLoadShedding(opts *LoadSheddingOptions) *ClientOptions {
+	c.LoadShedding = opts
+	return c
+}
+
+// SetCircuitBreaker specifies options to enable a circuit breaker for each server, which removes a server from read
+// selection while the commands sent to it keep failing and lets a few probe operations through after a cool-down to
+// find out whether it recovered. See CircuitBreakerOptions for more information. Servers whose circuit breaker is open
+// are still monitored and selected for writes. The circuit breaker is not used in load balanced mode. The default is
+// nil, meaning the circuit breaker is disabled.
+func (c *ClientOptions) SetCircuitBreaker(opts *CircuitBreakerOptions) *ClientOptions {
+	c.CircuitBreaker = opts
+	return c
+}
+
+// SetHedging specifies options to enable client-side hedged reads, which send the reads that a replica set member has
+// not replied to after a delay to another member and use the first reply, within a hedging budget. See HedgingOptions
+// for more information. Hedged reads lower the tail latency of reads that can be sent to several members when one of
+// them is slow, at the cost of sending some reads twice. The default is nil, meaning hedged reads are disabled.
+func (c *ClientOptions) SetHedging(opts *HedgingOptions) *ClientOptions {
+	c.Hedging = opts
+	return c
+}
+
+// SetFaultInjector specifies a fault injector that adds latency or errors to the commands of the client, closes their
+// connections or fails heartbeats according to its rules, to test how an application handles a slow or failing
+// deployment. See the faultinject package for more information. Fault injection is meant for testing and staging
+// environments. The default is nil, which disables fault injection. Rules can only be changed at runtime through
+// mongo.Client.FaultInjector if an injector is set, so set an injector without rules to configure faults only at
+// runtime.
+func (c *ClientOptions) SetFaultInjector(injector *faultinject.Injector) *ClientOptions {
+	c.FaultInjector = injector
+	return c
+}
+
 // SetServerSelectionTimeout specifies how long the driver will wait to find an available, suitable server to execute an
 // operation. This can also be set through the "serverSelectionTimeoutMS" URI option (e.g.
 // "serverSelectionTimeoutMS=30000"). The default value is 30 seconds.
@@ -839,6 +989,58 @@ func (c *ClientOptions) SetServerSelectionTimeout(d time.Duration) *ClientOption
 	return c
 }
 
+// SetSlowOperationThreshold specifies the duration after which a command is considered slow. Slow commands are logged
+// with the message "Slow operation" at the info level of LogComponentCommand, so the LoggerOptions of the client must
+// enable that component at LogLevelInfo or higher. The record includes the command name, namespace, the shape of the
+// filter or pipeline with all values redacted, the number of returned documents for cursor replies, the server
+// address, the connection ID and the comment of the command. The default is nil, meaning slow commands are not logged
+// unless a threshold is set for their command name with SetSlowOperationCommandThreshold.
+func (c *ClientOptions) SetSlowOperationThreshold(d time.Duration) *ClientOptions {
+	c.SlowOperationThreshold = &d
+	return c
+}
+
+// SetSlowOperationCommandThreshold specifies the slow operation threshold for commands with the given name (e.g.
+// "find" or "aggregate"), which overrides the threshold set with SetSlowOperationThreshold.
+func (c *ClientOptions) SetSlowOperationCommandThreshold(commandName string, d time.Duration) *ClientOptions {
+	if c.SlowOperationCommandThresholds == nil {
+		c.SlowOperationCommandThresholds = make(map[string]time.Duration)
+	}
+	c.SlowOperationCommandThresholds[commandName] = d
+	return c
+}
+
+// SetSlowOperationSampleRate specifies the fraction of slow commands that are logged, which must be greater than 0 and
+// at most 1. The default is 1, meaning all slow commands are logged.
+func (c *ClientOptions) SetSlowOperationSampleRate(rate float64) *ClientOptions {
+	c.SlowOperationSampleRate = &rate
+	return c
+}
+
+// SetPoolPriorityShare specifies the maximum share of the maxPoolSize connections of a server that operations with the
+// given priority can have checked out at the same time, which must be greater than 0 and at most 1. Once operations
+// with the priority hold that many connections, other operations with the priority wait for one of them to be checked
+// in, even if the pool has idle connections, so they can't take the connections needed by operations with another
+// priority. The limit is rounded up to a whole number of connections. The priority of operations is set with
+// mongo.WithOperationPriority. The default is 1 for all priorities, meaning operations of any priority can check out
+// all connections. It has no effect if the maximum pool size is 0.
+func (c *ClientOptions) SetPoolPriorityShare(p priority.Priority, share float64) *ClientOptions {
+	if c.PoolPriorityShares == nil {
+		c.PoolPriorityShares = make(map[priority.Priority]float64)
+	}
+	c.PoolPriorityShares[p] = share
+	return c
+}
+
+// SetPoolPriorityAging specifies how long an operation waits for a connection from the connection pool of a server
+// before it's ranked like an operation with the next higher priority, so that operations with a low priority can't
+// wait forever while the pool is saturated. It must be positive. The priority of operations is set with
+// mongo.WithOperationPriority. The default is 500 milliseconds.
+func (c *ClientOptions) SetPoolPriorityAging(d time.Duration) *ClientOptions {
+	c.PoolPriorityAging = &d
+	return c
+}
+
 // SetSocketTimeout specifies how long the driver will wait for a socket read or write to return before returning a
 // network error. This can also be set through the "socketTimeoutMS" URI option (e.g. "socketTimeoutMS=1000"). The
 // default value is 0, meaning no timeout is used and socket operations can block indefinitely.
@@ -1051,6 +1253,12 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.MaxConnIdleTime != nil {
 			c.MaxConnIdleTime = opt.MaxConnIdleTime
 		}
+		if opt.MaxConnLifeTime != nil {
+			c.MaxConnLifeTime = opt.MaxConnLifeTime
+		}
+		if opt.MaxConnLifeTimeJitter != nil {
+			c.MaxConnLifeTimeJitter = opt.MaxConnLifeTimeJitter
+		}
 		if opt.MaxPoolSize != nil {
 			c.MaxPoolSize = opt.MaxPoolSize
 		}
@@ -1072,6 +1280,24 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.ServerMonitor != nil {
 			c.ServerMonitor = opt.ServerMonitor
 		}
+		if opt.ServerSelectionMonitor != nil {
+			c.ServerSelectionMonitor = opt.ServerSelectionMonitor
+		}
+		if opt.ServerSelector != nil {
+			c.ServerSelector = opt.ServerSelector
+		}
+		if opt.FaultInjector != nil {
+			c.FaultInjector = opt.FaultInjector
+		}
+		if opt.LoadShedding != nil {
+			c.LoadShedding = opt.LoadShedding
+		}
+		if opt.CircuitBreaker != nil {
+			c.CircuitBreaker = opt.CircuitBreaker
+		}
+		if opt.Hedging != nil {
+			c.Hedging = opt.Hedging
+		}
 		if opt.ReadConcern != nil {
 			c.ReadConcern = opt.ReadConcern
 		}
@@ -1096,6 +1322,21 @@ func MergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.ServerSelectionTimeout != nil {
 			c.ServerSelectionTimeout = opt.ServerSelectionTimeout
 		}
+		if opt.SlowOperationThreshold != nil {
+			c.SlowOperationThreshold = opt.SlowOperationThreshold
+		}
+		if opt.SlowOperationCommandThresholds != nil {
+			c.SlowOperationCommandThresholds = opt.SlowOperationCommandThresholds
+		}
+		if opt.SlowOperationSampleRate != nil {
+			c.SlowOperationSampleRate = opt.SlowOperationSampleRate
+		}
+		if opt.PoolPriorityShares != nil {
+			c.PoolPriorityShares = opt.PoolPriorityShares
+		}
+		if opt.PoolPriorityAging != nil {
+			c.PoolPriorityAging = opt.PoolPriorityAging
+		}
 		if opt.Direct != nil {
 			c.Direct = opt.Direct
 		}
@@ -29,6 +29,7 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
 	"github.com/hongyuyang/mongo-go-driver/internal/httputil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
 	"github.com/hongyuyang/mongo-go-driver/mongo/writeconcern"
@@ -74,17 +75,22 @@ func TestClientOptions(t *testing.T) {
 			{"Hosts", (*ClientOptions).SetHosts, []string{"localhost:27017", "localhost:27018", "localhost:27019"}, "Hosts", true},
 			{"LocalThreshold", (*ClientOptions).SetLocalThreshold, 5 * time.Second, "LocalThreshold", true},
 			{"MaxConnIdleTime", (*ClientOptions).SetMaxConnIdleTime, 5 * time.Second, "MaxConnIdleTime", true},
+			{"MaxConnLifeTime", (*ClientOptions).SetMaxConnLifeTime, 10 * time.Minute, "MaxConnLifeTime", true},
+			{"MaxConnLifeTimeJitter", (*ClientOptions).SetMaxConnLifeTimeJitter, time.Minute, "MaxConnLifeTimeJitter", true},
 			{"MaxPoolSize", (*ClientOptions).SetMaxPoolSize, uint64(250), "MaxPoolSize", true},
 			{"MinPoolSize", (*ClientOptions).SetMinPoolSize, uint64(10), "MinPoolSize", true},
 			{"MaxConnecting", (*ClientOptions).SetMaxConnecting, uint64(10), "MaxConnecting", true},
 			{"PoolMonitor", (*ClientOptions).SetPoolMonitor, &event.PoolMonitor{}, "PoolMonitor", false},
 			{"Monitor", (*ClientOptions).SetMonitor, &event.CommandMonitor{}, "Monitor", false},
+			{"ServerSelectionMonitor", (*ClientOptions).SetServerSelectionMonitor, &event.ServerSelectionMonitor{}, "ServerSelectionMonitor", false},
 			{"ReadConcern", (*ClientOptions).SetReadConcern, readconcern.Majority(), "ReadConcern", false},
 			{"ReadPreference", (*ClientOptions).SetReadPreference, readpref.SecondaryPreferred(), "ReadPreference", false},
 			{"Registry", (*ClientOptions).SetRegistry, bson.NewRegistryBuilder().Build(), "Registry", false},
 			{"ReplicaSet", (*ClientOptions).SetReplicaSet, "example-replicaset", "ReplicaSet", true},
 			{"RetryWrites", (*ClientOptions).SetRetryWrites, true, "RetryWrites", true},
 			{"ServerSelectionTimeout", (*ClientOptions).SetServerSelectionTimeout, 5 * time.Second, "ServerSelectionTimeout", true},
+			{"SlowOperationThreshold", (*ClientOptions).SetSlowOperationThreshold, 100 * time.Millisecond, "SlowOperationThreshold", true},
+			{"SlowOperationSampleRate", (*ClientOptions).SetSlowOperationSampleRate, 0.5, "SlowOperationSampleRate", true},
 			{"Direct", (*ClientOptions).SetDirect, true, "Direct", true},
 			{"SocketTimeout", (*ClientOptions).SetSocketTimeout, 5 * time.Second, "SocketTimeout", true},
 			{"TLSConfig", (*ClientOptions).SetTLSConfig, &tls.Config{}, "TLSConfig", false},
@@ -341,6 +347,11 @@ func TestClientOptions(t *testing.T) {
 				"mongodb://localhost/?maxIdleTimeMS=300000",
 				baseClient().SetMaxConnIdleTime(5 * time.Minute),
 			},
+			{
+				"MaxConnLifeTime",
+				"mongodb://localhost/?maxConnLifeTimeMS=600000&maxConnLifeTimeJitterMS=60000",
+				baseClient().SetMaxConnLifeTime(10 * time.Minute).SetMaxConnLifeTimeJitter(time.Minute),
+			},
 			{
 				"MaxPoolSize",
 				"mongodb://localhost/?maxPoolSize=256",
@@ -795,6 +806,165 @@ func TestClientOptions(t *testing.T) {
 			})
 		}
 	})
+	t.Run("slow operation sample rate", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name string
+			rate float64
+			err  error
+		}{
+			{"valid", 0.25, nil},
+			{"one", 1, nil},
+			{"zero", 0, errors.New("slow operation sample rate must be greater than 0 and at most 1, got 0")},
+			{"too large", 1.5, errors.New("slow operation sample rate must be greater than 0 and at most 1, got 1.5")},
+		}
+
+		for _, tc := range testCases {
+			tc := tc // Capture the range variable
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := Client().SetSlowOperationSampleRate(tc.rate).Validate()
+				assert.Equal(t, tc.err, err, "expected error %v, got %v", tc.err, err)
+			})
+		}
+	})
+	t.Run("pool priority share", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name     string
+			priority priority.Priority
+			share    float64
+			err      error
+		}{
+			{"valid", priority.Low, 0.25, nil},
+			{"one", priority.High, 1, nil},
+			{"zero", priority.Low, 0, errors.New("pool priority share must be greater than 0 and at most 1, got 0 for priority low")},
+			{"too large", priority.Normal, 1.5, errors.New("pool priority share must be greater than 0 and at most 1, got 1.5 for priority normal")},
+			{"invalid priority", priority.Priority(5), 0.5, errors.New("pool priority share set for an unknown priority 5")},
+		}
+
+		for _, tc := range testCases {
+			tc := tc // Capture the range variable
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := Client().SetPoolPriorityShare(tc.priority, tc.share).Validate()
+				assert.Equal(t, tc.err, err, "expected error %v, got %v", tc.err, err)
+			})
+		}
+	})
+	t.Run("pool priority aging", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name  string
+			aging time.Duration
+			err   error
+		}{
+			{"valid", time.Second, nil},
+			{"zero", 0, errors.New("pool priority aging must be positive, got 0s")},
+			{"negative", -time.Second, errors.New("pool priority aging must be positive, got -1s")},
+		}
+
+		for _, tc := range testCases {
+			tc := tc // Capture the range variable
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := Client().SetPoolPriorityAging(tc.aging).Validate()
+				assert.Equal(t, tc.err, err, "expected error %v, got %v", tc.err, err)
+			})
+		}
+	})
+	t.Run("load shedding", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name string
+			opts *LoadSheddingOptions
+			err  error
+		}{
+			{"defaults", LoadShedding(), nil},
+			{"valid", LoadShedding().SetMinLimit(5).SetMaxLimit(50).SetMaxRetries(0).SetRetryBudget(1), nil},
+			{"zero min limit", LoadShedding().SetMinLimit(0), errors.New("load shedding min limit must be greater than 0")},
+			{"min over max limit", LoadShedding().SetMinLimit(10).SetMaxLimit(5), errors.New("load shedding min limit 10 must not exceed max limit 5")},
+			{"latency tolerance", LoadShedding().SetLatencyTolerance(0.5), errors.New("load shedding latency tolerance must be at least 1, got 0.5")},
+			{"negative max retries", LoadShedding().SetMaxRetries(-1), errors.New("load shedding max retries must not be negative, got -1")},
+			{"zero retry backoff", LoadShedding().SetRetryBackoff(0), errors.New("load shedding retry backoff must be greater than 0, got 0s")},
+			{"retry budget", LoadShedding().SetRetryBudget(2), errors.New("load shedding retry budget must be greater than 0 and at most 1, got 2")},
+		}
+
+		for _, tc := range testCases {
+			tc := tc // Capture the range variable
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := Client().SetLoadShedding(tc.opts).Validate()
+				assert.Equal(t, tc.err, err, "expected error %v, got %v", tc.err, err)
+			})
+		}
+	})
+	t.Run("circuit breaker", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name string
+			opts *CircuitBreakerOptions
+			err  error
+		}{
+			{"defaults", CircuitBreaker(), nil},
+			{"valid", CircuitBreaker().SetFailureRate(1).SetMinCommands(1).SetWindow(time.Second).SetCoolDown(time.Second).SetProbes(1), nil},
+			{"failure rate", CircuitBreaker().SetFailureRate(0), errors.New("circuit breaker failure rate must be greater than 0 and at most 1, got 0")},
+			{"zero min commands", CircuitBreaker().SetMinCommands(0), errors.New("circuit breaker min commands must be greater than 0, got 0")},
+			{"zero window", CircuitBreaker().SetWindow(0), errors.New("circuit breaker window must be greater than 0, got 0s")},
+			{"negative cool-down", CircuitBreaker().SetCoolDown(-time.Second), errors.New("circuit breaker cool-down must be greater than 0, got -1s")},
+			{"zero probes", CircuitBreaker().SetProbes(0), errors.New("circuit breaker probes must be greater than 0, got 0")},
+		}
+
+		for _, tc := range testCases {
+			tc := tc // Capture the range variable
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := Client().SetCircuitBreaker(tc.opts).Validate()
+				assert.Equal(t, tc.err, err, "expected error %v, got %v", tc.err, err)
+			})
+		}
+	})
+	t.Run("hedging", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := []struct {
+			name string
+			opts *HedgingOptions
+			err  error
+		}{
+			{"defaults", Hedging(), nil},
+			{"valid", Hedging().SetDelay(10 * time.Millisecond).SetBudget(1), nil},
+			{"zero delay", Hedging().SetDelay(0), errors.New("hedging delay must be greater than 0, got 0s")},
+			{"zero budget", Hedging().SetBudget(0), errors.New("hedging budget must be greater than 0 and at most 1, got 0")},
+			{"budget over 1", Hedging().SetBudget(1.5), errors.New("hedging budget must be greater than 0 and at most 1, got 1.5")},
+		}
+
+		for _, tc := range testCases {
+			tc := tc // Capture the range variable
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := Client().SetHedging(tc.opts).Validate()
+				assert.Equal(t, tc.err, err, "expected error %v, got %v", tc.err, err)
+			})
+		}
+	})
 }
 
 func createCertPool(t *testing.T, paths ...string) *x509.CertPool {
@@ -7,6 +7,8 @@
 package options
 
 import (
+	"time"
+
 	"github.com/hongyuyang/mongo-go-driver/bson/bsoncodec"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
@@ -34,6 +36,10 @@ type CollectionOptions struct {
 	// Registry is the BSON registry to marshal and unmarshal documents for operations executed on the Collection. The default value
 	// is nil, which means that the registry of the Database used to configure the Collection will be used.
 	Registry *bsoncodec.Registry
+
+	// Timeout is the timeout for operations executed on the Collection. The default value is nil, which means that the
+	// timeout of the Database used to configure the Collection will be used. A timeout of 0 means no timeout.
+	Timeout *time.Duration
 }
 
 // Collection creates a new CollectionOptions instance.
@@ -71,6 +77,12 @@ func (c *CollectionOptions) SetRegistry(r *bsoncodec.Registry) *CollectionOption
 	return c
 }
 
+// SetTimeout sets the value for the Timeout field.
+func (c *CollectionOptions) SetTimeout(d time.Duration) *CollectionOptions {
+	c.Timeout = &d
+	return c
+}
+
 // MergeCollectionOptions combines the given CollectionOptions instances into a single *CollectionOptions in a
 // last-one-wins fashion.
 //
@@ -98,6 +110,9 @@ func MergeCollectionOptions(opts ...*CollectionOptions) *CollectionOptions {
 		if opt.BSONOptions != nil {
 			c.BSONOptions = opt.BSONOptions
 		}
+		if opt.Timeout != nil {
+			c.Timeout = opt.Timeout
+		}
 	}
 
 	return c
@@ -7,6 +7,8 @@
 package options
 
 import (
+	"time"
+
 	"github.com/hongyuyang/mongo-go-driver/bson/bsoncodec"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
@@ -34,6 +36,10 @@ type DatabaseOptions struct {
 	// Registry is the BSON registry to marshal and unmarshal documents for operations executed on the Database. The default value
 	// is nil, which means that the registry of the Client used to configure the Database will be used.
 	Registry *bsoncodec.Registry
+
+	// Timeout is the timeout for operations executed on the Database. The default value is nil, which means that the
+	// timeout of the Client used to configure the Database will be used. A timeout of 0 means no timeout.
+	Timeout *time.Duration
 }
 
 // Database creates a new DatabaseOptions instance.
@@ -71,6 +77,12 @@ func (d *DatabaseOptions) SetRegistry(r *bsoncodec.Registry) *DatabaseOptions {
 	return d
 }
 
+// SetTimeout sets the value for the Timeout field.
+func (d *DatabaseOptions) SetTimeout(timeout time.Duration) *DatabaseOptions {
+	d.Timeout = &timeout
+	return d
+}
+
 // MergeDatabaseOptions combines the given DatabaseOptions instances into a single DatabaseOptions in a last-one-wins
 // fashion.
 //
@@ -98,6 +110,9 @@ func MergeDatabaseOptions(opts ...*DatabaseOptions) *DatabaseOptions {
 		if opt.BSONOptions != nil {
 			d.BSONOptions = opt.BSONOptions
 		}
+		if opt.Timeout != nil {
+			d.Timeout = opt.Timeout
+		}
 	}
 
 	return d
@@ -109,6 +109,15 @@ type FindOptions struct {
 	// Values must be constant or closed expressions that do not reference document fields. Parameters can then be
 	// accessed as variables in an aggregate expression context (e.g. "$$var").
 	Let interface{}
+
+	// Timeout is the timeout for the operation. The default value is nil, which means that the timeout of the
+	// Collection will be used. A timeout of 0 means no timeout.
+	Timeout *time.Duration
+
+	// TimeoutMode specifies how Timeout applies to the returned cursor. The default value is nil, which means
+	// TimeoutModeIteration for tailable cursors and TimeoutModeCursorLifetime otherwise. TimeoutModeCursorLifetime
+	// cannot be used with a TailableAwait cursor.
+	TimeoutMode *TimeoutMode
 }
 
 // Find creates a new FindOptions instance.
@@ -250,6 +259,18 @@ func (f *FindOptions) SetSort(sort interface{}) *FindOptions {
 	return f
 }
 
+// SetTimeout sets the value for the Timeout field.
+func (f *FindOptions) SetTimeout(d time.Duration) *FindOptions {
+	f.Timeout = &d
+	return f
+}
+
+// SetTimeoutMode sets the value for the TimeoutMode field.
+func (f *FindOptions) SetTimeoutMode(mode TimeoutMode) *FindOptions {
+	f.TimeoutMode = &mode
+	return f
+}
+
 // MergeFindOptions combines the given FindOptions instances into a single FindOptions in a last-one-wins fashion.
 //
 // Deprecated: Merging options structs will not be supported in Go Driver 2.0. Users should create a
@@ -323,6 +344,12 @@ func MergeFindOptions(opts ...*FindOptions) *FindOptions {
 		if opt.Sort != nil {
 			fo.Sort = opt.Sort
 		}
+		if opt.Timeout != nil {
+			fo.Timeout = opt.Timeout
+		}
+		if opt.TimeoutMode != nil {
+			fo.TimeoutMode = opt.TimeoutMode
+		}
 	}
 
 	return fo
@@ -418,6 +445,10 @@ type FindOneOptions struct {
 	// A document specifying the sort order to apply to the query. The first document in the sorted order will be
 	// returned. The driver will return an error if the sort parameter is a multi-key map.
 	Sort interface{}
+
+	// Timeout is the timeout for the operation. The default value is nil, which means that the timeout of the
+	// Collection will be used. A timeout of 0 means no timeout.
+	Timeout *time.Duration
 }
 
 // FindOne creates a new FindOneOptions instance.
@@ -550,6 +581,12 @@ func (f *FindOneOptions) SetSort(sort interface{}) *FindOneOptions {
 	return f
 }
 
+// SetTimeout sets the value for the Timeout field.
+func (f *FindOneOptions) SetTimeout(d time.Duration) *FindOneOptions {
+	f.Timeout = &d
+	return f
+}
+
 // MergeFindOneOptions combines the given FindOneOptions instances into a single FindOneOptions in a last-one-wins
 // fashion.
 //
@@ -615,6 +652,9 @@ func MergeFindOneOptions(opts ...*FindOneOptions) *FindOneOptions {
 		if opt.Sort != nil {
 			fo.Sort = opt.Sort
 		}
+		if opt.Timeout != nil {
+			fo.Timeout = opt.Timeout
+		}
 	}
 
 	return fo
@@ -25,6 +25,50 @@ var DefaultChunkSize int32 = 255 * 1024
 // DefaultRevision is the default revision number for a download by name operation.
 var DefaultRevision int32 = -1
 
+// ChecksumAlgorithm specifies the algorithm used to compute the checksum of a GridFS file.
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumSHA256 computes a SHA-256 digest of the file.
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+	// ChecksumCRC32C computes a CRC-32 checksum of the file using the Castagnoli polynomial.
+	ChecksumCRC32C ChecksumAlgorithm = "crc32c"
+)
+
+// ChunkTransformer encodes the data of each GridFS chunk before it is uploaded and decodes it after it is downloaded,
+// for example to compress or encrypt file content. The gridfs package provides compression and encryption transformers.
+//
+// The ID and parameters of the transformer used to upload a file are stored in the "transform" field of the document in
+// the files collection, so the matching transformer can be selected when the file is downloaded. The "length" field
+// and the "chunkSize" field of the document refer to the data before it is encoded.
+type ChunkTransformer interface {
+	// ID returns the identifier of the transformer, which is stored in the files collection document.
+	ID() string
+
+	// Params returns the parameters that are stored with the ID in the files collection document and passed to Decode
+	// when the file is downloaded. Params can return nil if the transformer has no parameters.
+	Params() bson.D
+
+	// Encode encodes the data of a chunk before it is uploaded.
+	Encode(chunk ChunkInfo, data []byte) ([]byte, error)
+
+	// Decode decodes the data of a chunk after it is downloaded. params are the parameters that were stored in the
+	// files collection document when the file was uploaded.
+	Decode(chunk ChunkInfo, data []byte, params bson.Raw) ([]byte, error)
+}
+
+// ChunkInfo describes a GridFS chunk that is passed to a ChunkTransformer.
+type ChunkInfo struct {
+	// FileID is the ID of the file that the chunk belongs to.
+	FileID interface{}
+
+	// N is the index of the chunk in the file.
+	N int32
+
+	// Size is the number of bytes of the chunk's data before it is encoded.
+	Size int
+}
+
 // BucketOptions represents options that can be used to configure GridFS bucket.
 type BucketOptions struct {
 	// The name of the bucket. The default value is "fs".
@@ -44,6 +88,11 @@ type BucketOptions struct {
 	// The read preference for the bucket. The default value is the read preference of the database from which the
 	// bucket is created.
 	ReadPreference *readpref.ReadPref
+
+	// The chunk transformers used to decode downloaded files. A file uploaded with a ChunkTransformer can only be
+	// downloaded if a transformer with the same ID is available. The zstd and snappy compression transformers of the
+	// gridfs package are always available. The default value is nil.
+	ChunkTransformers []ChunkTransformer
 }
 
 // GridFSBucket creates a new BucketOptions instance.
@@ -84,6 +133,12 @@ func (b *BucketOptions) SetReadPreference(rp *readpref.ReadPref) *BucketOptions
 	return b
 }
 
+// SetChunkTransformers sets the value for the ChunkTransformers field.
+func (b *BucketOptions) SetChunkTransformers(transformers ...ChunkTransformer) *BucketOptions {
+	b.ChunkTransformers = transformers
+	return b
+}
+
 // MergeBucketOptions combines the given BucketOptions instances into a single BucketOptions in a last-one-wins fashion.
 //
 // Deprecated: Merging options structs will not be supported in Go Driver 2.0. Users should create a
@@ -110,6 +165,9 @@ func MergeBucketOptions(opts ...*BucketOptions) *BucketOptions {
 		if opt.ReadPreference != nil {
 			b.ReadPreference = opt.ReadPreference
 		}
+		if opt.ChunkTransformers != nil {
+			b.ChunkTransformers = opt.ChunkTransformers
+		}
 	}
 
 	return b
@@ -127,6 +185,22 @@ type UploadOptions struct {
 
 	// The BSON registry to use for converting filters to BSON documents. The default value is bson.DefaultRegistry.
 	Registry *bsoncodec.Registry
+
+	// The maximum number of chunk batches that are inserted into the chunks collection concurrently. Each batch holds
+	// up to gridfs.UploadBufferSize bytes, so an upload can buffer up to MaxConcurrentBatches+1 batches in memory. The
+	// files collection document is only written after all batches have been inserted. The default value is 1, which
+	// means that batches are inserted sequentially.
+	MaxConcurrentBatches *int
+
+	// The algorithm used to compute a checksum of the file content. If set, the checksum is stored in the "checksum"
+	// field of the document in the files collection and can be verified when downloading the file. The default value is
+	// nil, which means that no checksum is computed.
+	Checksum *ChecksumAlgorithm
+
+	// The transformer used to encode the data of each chunk before it is uploaded, for example to compress or encrypt
+	// the file. The checksum is computed from the data before it is encoded. The default value is nil, which means that
+	// chunks are uploaded unchanged.
+	ChunkTransformer ChunkTransformer
 }
 
 // GridFSUpload creates a new UploadOptions instance.
@@ -146,6 +220,24 @@ func (u *UploadOptions) SetMetadata(doc interface{}) *UploadOptions {
 	return u
 }
 
+// SetMaxConcurrentBatches sets the value for the MaxConcurrentBatches field.
+func (u *UploadOptions) SetMaxConcurrentBatches(n int) *UploadOptions {
+	u.MaxConcurrentBatches = &n
+	return u
+}
+
+// SetChecksum sets the value for the Checksum field.
+func (u *UploadOptions) SetChecksum(algorithm ChecksumAlgorithm) *UploadOptions {
+	u.Checksum = &algorithm
+	return u
+}
+
+// SetChunkTransformer sets the value for the ChunkTransformer field.
+func (u *UploadOptions) SetChunkTransformer(transformer ChunkTransformer) *UploadOptions {
+	u.ChunkTransformer = transformer
+	return u
+}
+
 // MergeUploadOptions combines the given UploadOptions instances into a single UploadOptions in a last-one-wins fashion.
 //
 // Deprecated: Merging options structs will not be supported in Go Driver 2.0. Users should create a
@@ -166,6 +258,15 @@ func MergeUploadOptions(opts ...*UploadOptions) *UploadOptions {
 		if opt.Registry != nil {
 			u.Registry = opt.Registry
 		}
+		if opt.MaxConcurrentBatches != nil {
+			u.MaxConcurrentBatches = opt.MaxConcurrentBatches
+		}
+		if opt.Checksum != nil {
+			u.Checksum = opt.Checksum
+		}
+		if opt.ChunkTransformer != nil {
+			u.ChunkTransformer = opt.ChunkTransformer
+		}
 	}
 
 	return u
@@ -339,3 +440,42 @@ func MergeGridFSFindOptions(opts ...*GridFSFindOptions) *GridFSFindOptions {
 
 	return fo
 }
+
+// GridFSRepairOptions represents options that can be used to configure a GridFS Repair operation.
+type GridFSRepairOptions struct {
+	// The minimum age of orphaned chunks that are deleted. The age of the orphaned chunks of a file ID is determined
+	// from the ObjectID of the most recently inserted chunk, so chunks of uploads that are still in progress are not
+	// deleted. Orphaned chunks whose IDs are not ObjectIDs are only deleted if the grace period is 0. The default value
+	// is 24 hours.
+	GracePeriod *time.Duration
+
+	// The maximum number of chunks deleted by a single delete operation. The default value is 1000.
+	BatchSize *int32
+
+	// If true, Repair reports the orphaned chunks that would be deleted without deleting them. The default value is
+	// false.
+	DryRun *bool
+}
+
+// GridFSRepair creates a new GridFSRepairOptions instance.
+func GridFSRepair() *GridFSRepairOptions {
+	return &GridFSRepairOptions{}
+}
+
+// SetGracePeriod sets the value for the GracePeriod field.
+func (r *GridFSRepairOptions) SetGracePeriod(d time.Duration) *GridFSRepairOptions {
+	r.GracePeriod = &d
+	return r
+}
+
+// SetBatchSize sets the value for the BatchSize field.
+func (r *GridFSRepairOptions) SetBatchSize(i int32) *GridFSRepairOptions {
+	r.BatchSize = &i
+	return r
+}
+
+// SetDryRun sets the value for the DryRun field.
+func (r *GridFSRepairOptions) SetDryRun(b bool) *GridFSRepairOptions {
+	r.DryRun = &b
+	return r
+}
@@ -0,0 +1,60 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"fmt"
+	"time"
+)
+
+// HedgingOptions represents options that can be used to configure client-side hedged reads with
+// ClientOptions.SetHedging.
+//
+// With hedged reads, a read sent to a replica set member that has not replied after the hedging delay is also sent to
+// another member suitable for its read preference, and the first reply is used. The other read is interrupted by
+// closing its connection, and the cursor it opened is killed if it replied too. Only find, count, distinct and
+// aggregate commands without an $out or $merge stage are hedged, if their read preference is not primary and they
+// don't run in a transaction. Within a causally consistent session, reads are only hedged to members whose last write
+// is not older than the operation time of the session, and only the reply that is used advances it. Unlike
+// readpref.WithHedgeEnabled, which enables the hedged reads of mongos, these reads are hedged by the client and are
+// not used with sharded clusters.
+type HedgingOptions struct {
+	// Delay is how long to wait for the reply of a member before hedging a read. The default is the 95th percentile of
+	// the round-trip time to the member measured by its monitor, and reads are not hedged until it is measured.
+	Delay *time.Duration
+
+	// Budget is the number of hedged reads that each read that can be hedged adds to the hedging budget, which must be
+	// greater than 0 and at most 1. The default is 0.1, which allows about one hedged read every 10 reads.
+	Budget *float64
+}
+
+// Hedging creates a new HedgingOptions instance.
+func Hedging() *HedgingOptions {
+	return &HedgingOptions{}
+}
+
+// SetDelay sets the value for the Delay field.
+func (ho *HedgingOptions) SetDelay(d time.Duration) *HedgingOptions {
+	ho.Delay = &d
+	return ho
+}
+
+// SetBudget sets the value for the Budget field.
+func (ho *HedgingOptions) SetBudget(budget float64) *HedgingOptions {
+	ho.Budget = &budget
+	return ho
+}
+
+func (ho *HedgingOptions) validate() error {
+	if delay := ho.Delay; delay != nil && *delay <= 0 {
+		return fmt.Errorf("hedging delay must be greater than 0, got %v", *delay)
+	}
+	if budget := ho.Budget; budget != nil && (*budget <= 0 || *budget > 1) {
+		return fmt.Errorf("hedging budget must be greater than 0 and at most 1, got %v", *budget)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadSheddingOptions represents options that can be used to configure load shedding with
+// ClientOptions.SetLoadShedding.
+//
+// With load shedding, the client limits the number of operations in progress on each server, holding or waiting for
+// a connection, to an adaptive concurrency limit. The limit decreases when the latency of operations or the RTT to the
+// server increases, or when the server rejects commands because it is overloaded, and increases again when the server
+// recovers. Operations over the limit are rejected without waiting for a connection with an error labeled
+// "SystemOverloadedError" and "RetryableError" that wraps mongo.ErrOverloaded, so that they don't pile up in the
+// connection pool of a slow server. Operations rejected by the client or by the server with these labels are retried
+// after a backoff delay, within a retry budget that stops the retries when most operations are rejected.
+type LoadSheddingOptions struct {
+	// InitialLimit is the concurrency limit of each server before it is adjusted. The default is 20.
+	InitialLimit *uint64
+
+	// MinLimit is the minimum concurrency limit of each server. The default is 10.
+	MinLimit *uint64
+
+	// MaxLimit is the maximum concurrency limit of each server. The default is the maximum pool size, or 1000 if the
+	// pool size is not limited.
+	MaxLimit *uint64
+
+	// LatencyTolerance is how many times their long-term average latency the latency of operations can reach before
+	// the concurrency limit is decreased. It must be at least 1. The default is 1.5.
+	LatencyTolerance *float64
+
+	// MaxRetries is the maximum number of times an operation rejected because the server is overloaded is retried.
+	// These retries don't count against the retries enabled by RetryReads and RetryWrites. If it is 0, the operations
+	// are not retried. The default is 2.
+	MaxRetries *int
+
+	// RetryBackoff is the base delay before retrying an operation rejected because the server is overloaded. The
+	// delay is chosen at random up to a maximum that doubles with each retry of an operation. The default is 100ms.
+	RetryBackoff *time.Duration
+
+	// RetryBudget is the number of retries that each successful command adds to the retry budget of its server,
+	// which must be greater than 0 and at most 1. The default is 0.1, which allows about one retry every 10 successful
+	// commands while the server is overloaded.
+	RetryBudget *float64
+}
+
+// LoadShedding creates a new LoadSheddingOptions instance.
+func LoadShedding() *LoadSheddingOptions {
+	return &LoadSheddingOptions{}
+}
+
+// SetInitialLimit sets the value for the InitialLimit field.
+func (lso *LoadSheddingOptions) SetInitialLimit(limit uint64) *LoadSheddingOptions {
+	lso.InitialLimit = &limit
+	return lso
+}
+
+// SetMinLimit sets the value for the MinLimit field.
+func (lso *LoadSheddingOptions) SetMinLimit(limit uint64) *LoadSheddingOptions {
+	lso.MinLimit = &limit
+	return lso
+}
+
+// SetMaxLimit sets the value for the MaxLimit field.
+func (lso *LoadSheddingOptions) SetMaxLimit(limit uint64) *LoadSheddingOptions {
+	lso.MaxLimit = &limit
+	return lso
+}
+
+// SetLatencyTolerance sets the value for the LatencyTolerance field.
+func (lso *LoadSheddingOptions) SetLatencyTolerance(tolerance float64) *LoadSheddingOptions {
+	lso.LatencyTolerance = &tolerance
+	return lso
+}
+
+// SetMaxRetries sets the value for the MaxRetries field.
+func (lso *LoadSheddingOptions) SetMaxRetries(retries int) *LoadSheddingOptions {
+	lso.MaxRetries = &retries
+	return lso
+}
+
+// SetRetryBackoff sets the value for the RetryBackoff field.
+func (lso *LoadSheddingOptions) SetRetryBackoff(d time.Duration) *LoadSheddingOptions {
+	lso.RetryBackoff = &d
+	return lso
+}
+
+// SetRetryBudget sets the value for the RetryBudget field.
+func (lso *LoadSheddingOptions) SetRetryBudget(budget float64) *LoadSheddingOptions {
+	lso.RetryBudget = &budget
+	return lso
+}
+
+func (lso *LoadSheddingOptions) validate() error {
+	if lso.MinLimit != nil && *lso.MinLimit == 0 {
+		return fmt.Errorf("load shedding min limit must be greater than 0")
+	}
+	if lso.MinLimit != nil && lso.MaxLimit != nil && *lso.MinLimit > *lso.MaxLimit {
+		return fmt.Errorf("load shedding min limit %d must not exceed max limit %d", *lso.MinLimit, *lso.MaxLimit)
+	}
+	if tolerance := lso.LatencyTolerance; tolerance != nil && *tolerance < 1 {
+		return fmt.Errorf("load shedding latency tolerance must be at least 1, got %v", *tolerance)
+	}
+	if retries := lso.MaxRetries; retries != nil && *retries < 0 {
+		return fmt.Errorf("load shedding max retries must not be negative, got %d", *retries)
+	}
+	if backoff := lso.RetryBackoff; backoff != nil && *backoff <= 0 {
+		return fmt.Errorf("load shedding retry backoff must be greater than 0, got %v", *backoff)
+	}
+	if budget := lso.RetryBudget; budget != nil && (*budget <= 0 || *budget > 1) {
+		return fmt.Errorf("load shedding retry budget must be greater than 0 and at most 1, got %v", *budget)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ package options
 
 import (
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
+	"github.com/hongyuyang/mongo-go-driver/mongo/redact"
 )
 
 // LogLevel is an enumeration representing the supported log severity levels.
@@ -84,6 +85,13 @@ type LoggerOptions struct {
 	// If the underlying document is larger than this value, it will be
 	// truncated and appended with an ellipses "...".
 	MaxDocumentLength uint
+
+	// Redaction is the policy used to redact the values of logged commands
+	// and replies. If it redacts any values, the error messages returned by
+	// the server are also redacted from logged replies and failures, as they
+	// can contain values of the command. If this is nil, only
+	// security-sensitive commands are redacted.
+	Redaction *redact.Policy
 }
 
 // Logger creates a new LoggerOptions instance.
@@ -113,3 +121,10 @@ func (opts *LoggerOptions) SetSink(sink LogSink) *LoggerOptions {
 
 	return opts
 }
+
+// SetRedaction sets the policy used to redact logged commands and replies.
+func (opts *LoggerOptions) SetRedaction(policy *redact.Policy) *LoggerOptions {
+	opts.Redaction = policy
+
+	return opts
+}
@@ -0,0 +1,31 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+//go:build go1.21
+
+package options
+
+import (
+	"log/slog"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/logger"
+)
+
+// SlogSink creates a LogSink that writes the driver's logs to a slog.Logger.
+// If the logger is nil, slog.Default() is used.
+//
+// Informational messages are logged at slog.LevelInfo, debug messages at
+// slog.LevelDebug and errors at slog.LevelError. The attributes of each
+// message are grouped by the component that logged it, i.e. "command",
+// "topology", "serverSelection" or "connection". Logged commands and replies
+// are nested groups, which are only built if the handler of the logger is
+// enabled for the level of the message.
+//
+// The levels of the components still need to be enabled with
+// SetComponentLevel for the driver to log messages.
+func SlogSink(l *slog.Logger) LogSink {
+	return logger.NewSlogSink(l)
+}
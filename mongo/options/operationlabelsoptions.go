@@ -0,0 +1,67 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+import "github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+
+// LabelsCommentPolicy specifies how operation labels are added to the comment of the commands sent to the server.
+type LabelsCommentPolicy int
+
+const (
+	// LabelsCommentMerge adds the labels to the comment of commands. If the comment is a document, the labels that
+	// are not fields of the document are added to it. If the comment has another type, it is replaced with a document
+	// containing the labels and the original comment in the "comment" field. This is the default.
+	LabelsCommentMerge LabelsCommentPolicy = LabelsCommentPolicy(driverutil.LabelsCommentMerge)
+
+	// LabelsCommentPreserve only adds the labels to the comment of commands that do not have a comment.
+	LabelsCommentPreserve LabelsCommentPolicy = LabelsCommentPolicy(driverutil.LabelsCommentPreserve)
+
+	// LabelsCommentReplace replaces the comment of commands with the labels.
+	LabelsCommentReplace LabelsCommentPolicy = LabelsCommentPolicy(driverutil.LabelsCommentReplace)
+
+	// LabelsCommentOmit does not add the labels to the comment of commands. The labels are still added to command
+	// monitoring events and log messages.
+	LabelsCommentOmit LabelsCommentPolicy = LabelsCommentPolicy(driverutil.LabelsCommentOmit)
+)
+
+// OperationLabelsOptions represents options that can be used to configure the operation labels added to a context
+// with mongo.WithOperationLabels.
+type OperationLabelsOptions struct {
+	// CommentPolicy specifies how the labels are added to the comment of commands. The default is
+	// LabelsCommentMerge, or the policy of the labels of the parent context if it has labels.
+	CommentPolicy *LabelsCommentPolicy
+}
+
+// OperationLabels creates a new OperationLabelsOptions instance.
+func OperationLabels() *OperationLabelsOptions {
+	return &OperationLabelsOptions{}
+}
+
+// SetCommentPolicy sets the value for the CommentPolicy field.
+func (olo *OperationLabelsOptions) SetCommentPolicy(policy LabelsCommentPolicy) *OperationLabelsOptions {
+	olo.CommentPolicy = &policy
+	return olo
+}
+
+// MergeOperationLabelsOptions combines the given OperationLabelsOptions instances into a single
+// OperationLabelsOptions in a last-one-wins fashion.
+//
+// Deprecated: Merging options structs will not be supported in Go Driver 2.0. Users should create a
+// single options struct instead.
+func MergeOperationLabelsOptions(opts ...*OperationLabelsOptions) *OperationLabelsOptions {
+	o := OperationLabels()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.CommentPolicy != nil {
+			o.CommentPolicy = opt.CommentPolicy
+		}
+	}
+
+	return o
+}
@@ -44,6 +44,10 @@ type SessionOptions struct {
 	// error. DefaultMaxCommitTime is ignored if Timeout is set on the client.
 	DefaultMaxCommitTime *time.Duration
 
+	// DefaultTimeout is the default timeout for transactions started from the session. The default value is nil,
+	// which means that the timeout of the Client used to start the session will be used.
+	DefaultTimeout *time.Duration
+
 	// If true, all read operations performed with this session will be read from the same snapshot. This option cannot
 	// be set to true if CausalConsistency is set to true. Transactions and write operations are not allowed on
 	// snapshot sessions and will error. The default value is false.
@@ -90,6 +94,12 @@ func (s *SessionOptions) SetDefaultMaxCommitTime(mct *time.Duration) *SessionOpt
 	return s
 }
 
+// SetDefaultTimeout sets the value for the DefaultTimeout field.
+func (s *SessionOptions) SetDefaultTimeout(d time.Duration) *SessionOptions {
+	s.DefaultTimeout = &d
+	return s
+}
+
 // SetSnapshot sets the value for the Snapshot field.
 func (s *SessionOptions) SetSnapshot(b bool) *SessionOptions {
 	s.Snapshot = &b
@@ -122,6 +132,9 @@ func MergeSessionOptions(opts ...*SessionOptions) *SessionOptions {
 		if opt.DefaultMaxCommitTime != nil {
 			s.DefaultMaxCommitTime = opt.DefaultMaxCommitTime
 		}
+		if opt.DefaultTimeout != nil {
+			s.DefaultTimeout = opt.DefaultTimeout
+		}
 		if opt.Snapshot != nil {
 			s.Snapshot = opt.Snapshot
 		}
@@ -0,0 +1,20 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package options
+
+// TimeoutMode specifies how the timeout of an operation that creates a cursor applies to the cursor.
+type TimeoutMode int
+
+const (
+	// TimeoutModeCursorLifetime applies the timeout to the whole lifetime of the cursor, including the initial
+	// command and all getMore commands. This is the default.
+	TimeoutModeCursorLifetime TimeoutMode = iota
+
+	// TimeoutModeIteration applies the timeout separately to the initial command and to each call to Next, TryNext
+	// or All on the cursor.
+	TimeoutModeIteration
+)
@@ -39,6 +39,11 @@ type TransactionOptions struct {
 	// be used in its place to control the amount of time that a single operation can run before returning an error.
 	// MaxCommitTime is ignored if Timeout is set on the client.
 	MaxCommitTime *time.Duration
+
+	// Timeout is the timeout for committing or aborting the transaction. It is also applied to the callback of
+	// Session.WithTransaction if the context passed to it has no deadline. The default value is nil, which means that
+	// the default timeout of the session used to start the transaction will be used.
+	Timeout *time.Duration
 }
 
 // Transaction creates a new TransactionOptions instance.
@@ -74,6 +79,12 @@ func (t *TransactionOptions) SetMaxCommitTime(mct *time.Duration) *TransactionOp
 	return t
 }
 
+// SetTimeout sets the value for the Timeout field.
+func (t *TransactionOptions) SetTimeout(d time.Duration) *TransactionOptions {
+	t.Timeout = &d
+	return t
+}
+
 // MergeTransactionOptions combines the given TransactionOptions instances into a single TransactionOptions in a
 // last-one-wins fashion.
 //
@@ -97,6 +108,9 @@ func MergeTransactionOptions(opts ...*TransactionOptions) *TransactionOptions {
 		if opt.MaxCommitTime != nil {
 			t.MaxCommitTime = opt.MaxCommitTime
 		}
+		if opt.Timeout != nil {
+			t.Timeout = opt.Timeout
+		}
 	}
 
 	return t
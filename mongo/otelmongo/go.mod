@@ -0,0 +1,22 @@
+module github.com/hongyuyang/mongo-go-driver/mongo/otelmongo
+
+go 1.20
+
+replace github.com/hongyuyang/mongo-go-driver => ../../
+
+require (
+	// Note that the Go driver version is replaced with the local Go driver code
+	// by the replace directive above.
+	github.com/hongyuyang/mongo-go-driver v1.16.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+)
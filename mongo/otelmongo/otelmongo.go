@@ -0,0 +1,318 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package otelmongo traces the operations of a mongo.Client with OpenTelemetry.
+//
+// A Monitor creates a span for every command the client sends, for every connection checkout that precedes a command
+// and for every server selection. Spans are children of the span in the context passed to the operation:
+//
+//	monitor := otelmongo.NewMonitor()
+//	opts := options.Client().
+//		ApplyURI("mongodb://localhost:27017").
+//		SetMonitor(monitor.CommandMonitor()).
+//		SetPoolMonitor(monitor.PoolMonitor()).
+//		SetServerSelectionMonitor(monitor.ServerSelectionMonitor())
+//	client, err := mongo.Connect(ctx, opts)
+//
+// Command spans follow the OpenTelemetry semantic conventions for database clients. The db.statement attribute is
+// sanitized by the StatementRedactor of the Monitor, which replaces all values in the command with "?" by default.
+//
+// Pool events don't carry the context of an operation, so a checkout span is recorded when the first command is sent
+// on the checked out connection. Checkouts that fail are not recorded.
+//
+// Server selection spans are created from the events of an event.ServerSelectionMonitor. The ServerSelectionMonitor
+// and the event fields used by a Monitor were added in driver version 1.16.0, which this module requires.
+//
+// This package is a separate module so the driver does not depend on OpenTelemetry.
+package otelmongo // import "github.com/hongyuyang/mongo-go-driver/mongo/otelmongo"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer used by a Monitor.
+const ScopeName = "github.com/hongyuyang/mongo-go-driver/mongo/otelmongo"
+
+// Span names for connection checkouts and server selections.
+const (
+	CheckoutSpanName        = "checkout"
+	ServerSelectionSpanName = "server selection"
+)
+
+// Option configures a Monitor.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	redactor       StatementRedactor
+}
+
+// WithTracerProvider specifies the TracerProvider used to create spans. The default is the global TracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.tracerProvider = tp
+	}
+}
+
+// WithStatementRedactor specifies the StatementRedactor used to create the db.statement attribute of command spans.
+// The default is RedactValues.
+func WithStatementRedactor(redactor StatementRedactor) Option {
+	return func(cfg *config) {
+		cfg.redactor = redactor
+	}
+}
+
+// Monitor creates spans for the commands, connection checkouts and server selections of a client. A Monitor must
+// only be used by a single client.
+type Monitor struct {
+	tracer   trace.Tracer
+	redactor StatementRedactor
+
+	mu        sync.Mutex
+	commands  map[commandKey]trace.Span
+	checkouts map[connectionKey]checkout
+}
+
+// commandKey identifies an in-progress command.
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// connectionKey identifies a pooled connection.
+type connectionKey struct {
+	address string
+	id      uint64
+}
+
+// checkout is a connection checkout that has not been recorded yet.
+type checkout struct {
+	start time.Time
+	end   time.Time
+}
+
+// NewMonitor creates a Monitor with the given options.
+func NewMonitor(opts ...Option) *Monitor {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		redactor:       RedactValues,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Monitor{
+		tracer:    cfg.tracerProvider.Tracer(ScopeName, trace.WithSchemaURL(semconv.SchemaURL)),
+		redactor:  cfg.redactor,
+		commands:  make(map[commandKey]trace.Span),
+		checkouts: make(map[connectionKey]checkout),
+	}
+}
+
+// CommandMonitor returns a CommandMonitor that creates a span for each command.
+func (m *Monitor) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started:   m.commandStarted,
+		Succeeded: m.commandSucceeded,
+		Failed:    m.commandFailed,
+	}
+}
+
+// PoolMonitor returns a PoolMonitor that records connection checkouts.
+func (m *Monitor) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: m.poolEvent}
+}
+
+// ServerSelectionMonitor returns a ServerSelectionMonitor that creates a span for each server selection.
+func (m *Monitor) ServerSelectionMonitor() *event.ServerSelectionMonitor {
+	return &event.ServerSelectionMonitor{
+		Succeeded: m.serverSelectionSucceeded,
+		Failed:    m.serverSelectionFailed,
+	}
+}
+
+func (m *Monitor) commandStarted(ctx context.Context, evt *event.CommandStartedEvent) {
+	address := connectionAddress(evt.ConnectionID)
+	serverAttrs := serverAttributes(address)
+
+	m.mu.Lock()
+	co, checkedOut := m.checkouts[connectionKey{address: address, id: evt.DriverConnectionID}]
+	if checkedOut {
+		delete(m.checkouts, connectionKey{address: address, id: evt.DriverConnectionID})
+	}
+	m.mu.Unlock()
+
+	if checkedOut {
+		_, span := m.tracer.Start(ctx, CheckoutSpanName,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithTimestamp(co.start),
+			trace.WithAttributes(semconv.DBSystemMongoDB),
+			trace.WithAttributes(serverAttrs...))
+		span.End(trace.WithTimestamp(co.end))
+	}
+
+	collection := commandCollection(evt.Command)
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBName(evt.DatabaseName),
+		semconv.DBOperation(evt.CommandName),
+	}
+	if collection != "" {
+		attrs = append(attrs, semconv.DBMongoDBCollection(collection))
+	}
+	attrs = append(attrs, serverAttrs...)
+	if statement := m.redactor(evt.CommandName, evt.Command); statement != "" {
+		attrs = append(attrs, semconv.DBStatement(statement))
+	}
+
+	_, span := m.tracer.Start(ctx, commandSpanName(evt.CommandName, evt.DatabaseName, collection),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	m.mu.Lock()
+	m.commands[commandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}] = span
+	m.mu.Unlock()
+}
+
+func (m *Monitor) commandSucceeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := m.finishCommand(evt.CommandFinishedEvent); ok {
+		span.End()
+	}
+}
+
+func (m *Monitor) commandFailed(_ context.Context, evt *event.CommandFailedEvent) {
+	if span, ok := m.finishCommand(evt.CommandFinishedEvent); ok {
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+// finishCommand removes the span of a finished command.
+func (m *Monitor) finishCommand(evt event.CommandFinishedEvent) (trace.Span, bool) {
+	key := commandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	span, ok := m.commands[key]
+	delete(m.commands, key)
+	return span, ok
+}
+
+func (m *Monitor) poolEvent(evt *event.PoolEvent) {
+	key := connectionKey{address: evt.Address, id: evt.ConnectionID}
+
+	switch evt.Type {
+	case event.GetSucceeded:
+		end := time.Now()
+
+		m.mu.Lock()
+		m.checkouts[key] = checkout{start: end.Add(-evt.Duration), end: end}
+		m.mu.Unlock()
+	case event.ConnectionReturned, event.ConnectionClosed:
+		// The connection was not used for a command, so the checkout can't be associated with an operation.
+		m.mu.Lock()
+		delete(m.checkouts, key)
+		m.mu.Unlock()
+	}
+}
+
+func (m *Monitor) serverSelectionSucceeded(ctx context.Context, evt *event.ServerSelectionSucceededEvent) {
+	attrs := serverSelectionAttributes(evt.Operation, evt.Selector)
+	attrs = append(attrs, serverAttributes(evt.Address.String())...)
+	m.recordServerSelection(ctx, evt.Duration, attrs, nil)
+}
+
+func (m *Monitor) serverSelectionFailed(ctx context.Context, evt *event.ServerSelectionFailedEvent) {
+	m.recordServerSelection(ctx, evt.Duration, serverSelectionAttributes(evt.Operation, evt.Selector), evt.Failure)
+}
+
+// recordServerSelection records a server selection span that ended now.
+func (m *Monitor) recordServerSelection(ctx context.Context, duration time.Duration, attrs []attribute.KeyValue,
+	err error) {
+
+	end := time.Now()
+	_, span := m.tracer.Start(ctx, ServerSelectionSpanName,
+		trace.WithTimestamp(end.Add(-duration)),
+		trace.WithAttributes(attrs...))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+func serverSelectionAttributes(operation string, selector interface{}) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.DBSystemMongoDB}
+	if operation != "" {
+		attrs = append(attrs, semconv.DBOperation(operation))
+	}
+	if stringer, ok := selector.(fmt.Stringer); ok {
+		attrs = append(attrs, attribute.String("db.mongodb.server_selector", stringer.String()))
+	}
+	return attrs
+}
+
+// commandSpanName returns the name of a command span, which is the command name followed by the namespace.
+func commandSpanName(commandName, database, collection string) string {
+	if collection == "" {
+		return commandName + " " + database
+	}
+	return commandName + " " + database + "." + collection
+}
+
+func serverAttributes(address string) []attribute.KeyValue {
+	if address == "" {
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return []attribute.KeyValue{semconv.ServerAddress(address)}
+	}
+	attrs := []attribute.KeyValue{semconv.ServerAddress(host)}
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(p))
+	}
+	return attrs
+}
+
+// commandCollection returns the collection a command operates on, which is the value of the command name for most
+// commands and the "collection" field for getMore.
+func commandCollection(cmd bson.Raw) string {
+	elem, err := cmd.IndexErr(0)
+	if err != nil {
+		return ""
+	}
+	if collection, ok := elem.Value().StringValueOK(); ok {
+		return collection
+	}
+	collection, _ := cmd.Lookup("collection").StringValueOK()
+	return collection
+}
+
+// connectionAddress returns the server address of a connection ID, which is the address followed by the connection
+// number in brackets.
+func connectionAddress(connectionID string) string {
+	if idx := strings.LastIndex(connectionID, "[-"); idx >= 0 {
+		return connectionID[:idx]
+	}
+	return connectionID
+}
@@ -0,0 +1,234 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package otelmongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/event"
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMonitor(t *testing.T) {
+	const connID = "localhost:27017[-7]"
+
+	findCmd, err := bson.Marshal(bson.D{
+		{"find", "coll"},
+		{"filter", bson.D{{"name", "alice"}, {"age", bson.D{{"$gt", 30}}}}},
+		{"lsid", bson.D{{"id", "session"}}},
+		{"$db", "db"},
+	})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	// newMonitor returns a Monitor that records spans in an in-memory exporter and a context with a parent span.
+	newMonitor := func(opts ...Option) (*Monitor, *tracetest.InMemoryExporter, context.Context, trace.Span) {
+		exporter := tracetest.NewInMemoryExporter()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+		return NewMonitor(append([]Option{WithTracerProvider(tp)}, opts...)...), exporter, ctx, parent
+	}
+	attributes := func(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes {
+			attrs[kv.Key] = kv.Value
+		}
+		return attrs
+	}
+	started := &event.CommandStartedEvent{
+		Command:            findCmd,
+		DatabaseName:       "db",
+		CommandName:        "find",
+		RequestID:          42,
+		ConnectionID:       connID,
+		DriverConnectionID: 3,
+	}
+	finished := event.CommandFinishedEvent{
+		CommandName:  "find",
+		DatabaseName: "db",
+		RequestID:    42,
+		ConnectionID: connID,
+	}
+
+	t.Run("command succeeded", func(t *testing.T) {
+		monitor, exporter, ctx, parent := newMonitor()
+		cm := monitor.CommandMonitor()
+		cm.Started(ctx, started)
+		cm.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+
+		spans := exporter.GetSpans()
+		assertEqual(t, 1, len(spans), "expected 1 span, got %d", len(spans))
+		span := spans[0]
+		assertEqual(t, "find db.coll", span.Name, "expected name %q, got %q", "find db.coll", span.Name)
+		assertEqual(t, trace.SpanKindClient, span.SpanKind, "expected client span, got %v", span.SpanKind)
+		assertEqual(t, parent.SpanContext().SpanID(), span.Parent.SpanID(), "expected span to be a child of the parent")
+
+		attrs := attributes(span)
+		want := map[attribute.Key]string{
+			"db.system":             "mongodb",
+			"db.name":               "db",
+			"db.operation":          "find",
+			"db.mongodb.collection": "coll",
+			"server.address":        "localhost",
+			"db.statement":          `{"find":"coll","filter":{"name":"?","age":{"$gt":"?"}}}`,
+		}
+		for key, val := range want {
+			assertEqual(t, val, attrs[key].AsString(), "expected %s to be %q, got %q", key, val, attrs[key].AsString())
+		}
+		assertEqual(t, int64(27017), attrs["server.port"].AsInt64(), "expected server.port 27017, got %d",
+			attrs["server.port"].AsInt64())
+		assertEqual(t, codes.Unset, span.Status.Code, "expected unset status, got %v", span.Status.Code)
+	})
+	t.Run("command failed", func(t *testing.T) {
+		monitor, exporter, ctx, _ := newMonitor()
+		cm := monitor.CommandMonitor()
+		cm.Started(ctx, started)
+		cm.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: "(Unauthorized) denied"})
+
+		spans := exporter.GetSpans()
+		assertEqual(t, 1, len(spans), "expected 1 span, got %d", len(spans))
+		assertEqual(t, codes.Error, spans[0].Status.Code, "expected error status, got %v", spans[0].Status.Code)
+		assertEqual(t, "(Unauthorized) denied", spans[0].Status.Description, "expected status description %q, got %q",
+			"(Unauthorized) denied", spans[0].Status.Description)
+	})
+	t.Run("statement redaction", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			redactor StatementRedactor
+			want     string
+		}{
+			{"none", RedactNone, `{"find":"coll","filter":{"name":"alice","age":{"$gt":30}}}`},
+			{"omit", OmitStatement, ""},
+			{"custom", func(commandName string, _ bson.Raw) string { return commandName }, "find"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				monitor, exporter, ctx, _ := newMonitor(WithStatementRedactor(tc.redactor))
+				cm := monitor.CommandMonitor()
+				cm.Started(ctx, started)
+				cm.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+
+				spans := exporter.GetSpans()
+				assertEqual(t, 1, len(spans), "expected 1 span, got %d", len(spans))
+				statement, ok := attributes(spans[0])["db.statement"]
+				assertEqual(t, tc.want != "", ok, "expected db.statement to be set: %v, got %v", tc.want != "", ok)
+				assertEqual(t, tc.want, statement.AsString(), "expected statement %q, got %q", tc.want,
+					statement.AsString())
+			})
+		}
+	})
+	t.Run("redact arrays", func(t *testing.T) {
+		cmd, err := bson.Marshal(bson.D{
+			{"insert", "coll"},
+			{"documents", bson.A{bson.D{{"x", 1}}, bson.D{{"y", "secret"}}}},
+			{"ids", bson.A{1, 2, 3}},
+		})
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+
+		want := `{"insert":"coll","documents":[{"x":"?"},{"y":"?"}],"ids":"?"}`
+		got := RedactValues("insert", cmd)
+		assertEqual(t, want, got, "expected statement %q, got %q", want, got)
+	})
+	t.Run("checkout", func(t *testing.T) {
+		monitor, exporter, ctx, parent := newMonitor()
+		monitor.PoolMonitor().Event(&event.PoolEvent{
+			Type:         event.GetSucceeded,
+			Address:      "localhost:27017",
+			ConnectionID: 3,
+			Duration:     5 * time.Millisecond,
+		})
+		cm := monitor.CommandMonitor()
+		cm.Started(ctx, started)
+		cm.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+
+		spans := exporter.GetSpans()
+		assertEqual(t, 2, len(spans), "expected 2 spans, got %d", len(spans))
+		checkout := spans[0]
+		assertEqual(t, CheckoutSpanName, checkout.Name, "expected name %q, got %q", CheckoutSpanName, checkout.Name)
+		assertEqual(t, parent.SpanContext().SpanID(), checkout.Parent.SpanID(),
+			"expected checkout span to be a child of the parent")
+		duration := checkout.EndTime.Sub(checkout.StartTime)
+		assertEqual(t, 5*time.Millisecond, duration, "expected duration 5ms, got %v", duration)
+
+		// The checkout is only recorded for the first command on the connection.
+		cm.Started(ctx, started)
+		cm.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		spans = exporter.GetSpans()
+		assertEqual(t, 3, len(spans), "expected 3 spans, got %d", len(spans))
+	})
+	t.Run("checkout of another server", func(t *testing.T) {
+		monitor, exporter, ctx, _ := newMonitor()
+		monitor.PoolMonitor().Event(&event.PoolEvent{
+			Type:         event.GetSucceeded,
+			Address:      "otherhost:27017",
+			ConnectionID: 3,
+		})
+		cm := monitor.CommandMonitor()
+		cm.Started(ctx, started)
+		cm.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+
+		spans := exporter.GetSpans()
+		assertEqual(t, 1, len(spans), "expected 1 span, got %d", len(spans))
+	})
+	t.Run("server selection", func(t *testing.T) {
+		monitor, exporter, ctx, parent := newMonitor()
+		ssm := monitor.ServerSelectionMonitor()
+		ssm.Succeeded(ctx, &event.ServerSelectionSucceededEvent{
+			Selector:  description.WriteSelector(),
+			Operation: "insert",
+			Address:   address.Address("localhost:27017"),
+			Duration:  time.Millisecond,
+		})
+		selectionErr := errors.New("server selection timeout")
+		ssm.Failed(ctx, &event.ServerSelectionFailedEvent{
+			Selector:  description.WriteSelector(),
+			Operation: "find",
+			Duration:  time.Millisecond,
+			Failure:   selectionErr,
+		})
+
+		spans := exporter.GetSpans()
+		assertEqual(t, 2, len(spans), "expected 2 spans, got %d", len(spans))
+		for _, span := range spans {
+			assertEqual(t, ServerSelectionSpanName, span.Name, "expected name %q, got %q", ServerSelectionSpanName,
+				span.Name)
+			assertEqual(t, parent.SpanContext().SpanID(), span.Parent.SpanID(),
+				"expected server selection span to be a child of the parent")
+		}
+
+		succeeded := attributes(spans[0])
+		assertEqual(t, "insert", succeeded["db.operation"].AsString(), "expected db.operation %q, got %q", "insert",
+			succeeded["db.operation"].AsString())
+		assertEqual(t, "localhost", succeeded["server.address"].AsString(), "expected server.address %q, got %q",
+			"localhost", succeeded["server.address"].AsString())
+		assertEqual(t, codes.Error, spans[1].Status.Code, "expected error status, got %v", spans[1].Status.Code)
+		assertEqual(t, selectionErr.Error(), spans[1].Status.Description, "expected status description %q, got %q",
+			selectionErr.Error(), spans[1].Status.Description)
+	})
+}
+
+func assertEqual(t *testing.T, expected, actual interface{}, format string, args ...interface{}) {
+	t.Helper()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf(format, args...)
+	}
+}
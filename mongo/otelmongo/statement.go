@@ -0,0 +1,110 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package otelmongo
+
+import (
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/bson/bsontype"
+)
+
+// StatementRedactor returns the db.statement attribute of a command span. The attribute is omitted if the returned
+// string is empty.
+type StatementRedactor func(commandName string, command bson.Raw) string
+
+// placeholder replaces redacted values.
+const placeholder = "?"
+
+// internalFields are the fields the driver adds to every command. They are removed from statements because they
+// don't describe the command.
+var internalFields = map[string]bool{
+	"lsid":         true,
+	"$clusterTime": true,
+	"$db":          true,
+	"txnNumber":    true,
+}
+
+// RedactValues is a StatementRedactor that returns the command as relaxed extended JSON with every value replaced
+// by "?", except the value of the command name. Arrays of documents are redacted element by element and other arrays
+// are replaced by "?". Fields added to every command by the driver, like "lsid" and "$clusterTime", are removed.
+func RedactValues(_ string, command bson.Raw) string {
+	elems, err := command.Elements()
+	if err != nil {
+		return ""
+	}
+
+	doc := make(bson.D, 0, len(elems))
+	for i, elem := range elems {
+		key := elem.Key()
+		if internalFields[key] {
+			continue
+		}
+		if i == 0 {
+			doc = append(doc, bson.E{Key: key, Value: elem.Value()})
+			continue
+		}
+		doc = append(doc, bson.E{Key: key, Value: redactValue(elem.Value())})
+	}
+	return marshalStatement(doc)
+}
+
+// RedactNone is a StatementRedactor that returns the command as relaxed extended JSON without redacting values. The
+// statement contains the documents the application sends to the server, so it should only be used if the trace
+// backend may store them.
+func RedactNone(_ string, command bson.Raw) string {
+	elems, err := command.Elements()
+	if err != nil {
+		return ""
+	}
+
+	doc := make(bson.D, 0, len(elems))
+	for _, elem := range elems {
+		if !internalFields[elem.Key()] {
+			doc = append(doc, bson.E{Key: elem.Key(), Value: elem.Value()})
+		}
+	}
+	return marshalStatement(doc)
+}
+
+// OmitStatement is a StatementRedactor that omits the db.statement attribute.
+func OmitStatement(string, bson.Raw) string {
+	return ""
+}
+
+func redactValue(val bson.RawValue) interface{} {
+	switch val.Type {
+	case bsontype.EmbeddedDocument:
+		elems, err := val.Document().Elements()
+		if err != nil {
+			return placeholder
+		}
+		doc := make(bson.D, 0, len(elems))
+		for _, elem := range elems {
+			doc = append(doc, bson.E{Key: elem.Key(), Value: redactValue(elem.Value())})
+		}
+		return doc
+	case bsontype.Array:
+		values, err := val.Array().Values()
+		if err != nil || len(values) == 0 || values[0].Type != bsontype.EmbeddedDocument {
+			return placeholder
+		}
+		arr := make(bson.A, 0, len(values))
+		for _, v := range values {
+			arr = append(arr, redactValue(v))
+		}
+		return arr
+	default:
+		return placeholder
+	}
+}
+
+func marshalStatement(doc bson.D) string {
+	b, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,43 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package priority defines the priorities of operations. When the connection pool of a server is saturated, operations
+// with a higher priority check out connections before operations with a lower priority.
+package priority
+
+import "fmt"
+
+// Priority is the priority of an operation.
+type Priority int8
+
+// These constants are the available priorities. The zero value is Normal.
+const (
+	// Low is the priority of background and batch operations that can wait for latency-critical operations.
+	Low Priority = -1
+	// Normal is the default priority.
+	Normal Priority = 0
+	// High is the priority of latency-critical operations.
+	High Priority = 1
+)
+
+// Valid returns true if p is one of the available priorities.
+func (p Priority) Valid() bool {
+	return p >= Low && p <= High
+}
+
+// String implements the Stringer interface.
+func (p Priority) String() string {
+	switch p {
+	case Low:
+		return "low"
+	case Normal:
+		return "normal"
+	case High:
+		return "high"
+	default:
+		return fmt.Sprintf("unknown priority %d", int8(p))
+	}
+}
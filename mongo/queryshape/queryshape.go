@@ -0,0 +1,470 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package queryshape computes the shapes of queries, which identify queries that only differ in their literal values.
+//
+// The shape of a filter, sort, projection or aggregation pipeline keeps its field names, operators and field paths and
+// replaces literal values with markers of their type, like "?string" or "?number". Arrays of literals, like the
+// argument of $in, are collapsed into a single marker, like "?array<?number>". The order of operators and of the
+// clauses of $and, $or and $nor is canonicalized, so filters that only differ in that order have the same shape.
+//
+// The shape of a command, which also includes the command name and namespace, is computed from the Command of an
+// event.CommandStartedEvent with FromCommand. Its hash can be used to group metrics or slow query reports:
+//
+//	Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+//		if shape, err := queryshape.FromCommand(evt.Command); err == nil {
+//			record(evt.CommandName, shape.Hash())
+//		}
+//	}
+package queryshape // import "github.com/hongyuyang/mongo-go-driver/mongo/queryshape"
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/bson/bsontype"
+)
+
+// Shape is the shape of a command. Fields that the command does not have are nil.
+type Shape struct {
+	Command    string
+	Namespace  string
+	Filter     bson.D
+	Sort       bson.D
+	Projection bson.D
+	Pipeline   bson.A
+}
+
+// FromCommand computes the shape of a command, like the Command of an event.CommandStartedEvent. The filter is taken
+// from the "filter" or "query" field, or from the "q" field of the first statement of an update or delete command.
+// The projection is taken from the "projection" or "fields" field. The namespace is only set if the command contains
+// the "$db" field.
+func FromCommand(cmd bson.Raw) (*Shape, error) {
+	elems, err := cmd.Elements()
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return &Shape{}, nil
+	}
+
+	shape := &Shape{Command: elems[0].Key()}
+	collection, _ := elems[0].Value().StringValueOK()
+	var db string
+	for _, elem := range elems {
+		val := elem.Value()
+		switch elem.Key() {
+		case "$db":
+			db, _ = val.StringValueOK()
+		case "filter", "query":
+			if doc, ok := val.DocumentOK(); ok {
+				shape.Filter = normalizeFilter(doc)
+			}
+		case "sort":
+			if doc, ok := val.DocumentOK(); ok {
+				shape.Sort = normalizeSort(doc)
+			}
+		case "projection", "fields":
+			if doc, ok := val.DocumentOK(); ok {
+				shape.Projection = normalizeProjection(doc)
+			}
+		case "pipeline":
+			if arr, ok := val.ArrayOK(); ok {
+				shape.Pipeline = normalizePipeline(arr)
+			}
+		case "updates", "deletes":
+			// Use the filter of the first statement.
+			if shape.Filter != nil {
+				break
+			}
+			if stmts, ok := val.ArrayOK(); ok {
+				if stmt, ok := stmts.Index(0).Value().DocumentOK(); ok {
+					if q, ok := stmt.Lookup("q").DocumentOK(); ok {
+						shape.Filter = normalizeFilter(q)
+					}
+				}
+			}
+		}
+	}
+
+	if db != "" {
+		shape.Namespace = db
+		if collection != "" {
+			shape.Namespace += "." + collection
+		}
+	}
+	return shape, nil
+}
+
+// Document returns the shape as a document with the fields "cmd", "ns", "filter", "sort", "projection" and
+// "pipeline". Fields that are not set are omitted.
+func (s *Shape) Document() bson.D {
+	doc := bson.D{{"cmd", s.Command}}
+	if s.Namespace != "" {
+		doc = append(doc, bson.E{"ns", s.Namespace})
+	}
+	if s.Filter != nil {
+		doc = append(doc, bson.E{"filter", s.Filter})
+	}
+	if s.Sort != nil {
+		doc = append(doc, bson.E{"sort", s.Sort})
+	}
+	if s.Projection != nil {
+		doc = append(doc, bson.E{"projection", s.Projection})
+	}
+	if s.Pipeline != nil {
+		doc = append(doc, bson.E{"pipeline", s.Pipeline})
+	}
+	return doc
+}
+
+// Hash returns a short hash of the shape. Commands with the same shape have the same hash.
+func (s *Shape) Hash() string {
+	return hashValue(s.Document())
+}
+
+// String returns the shape as relaxed extended JSON.
+func (s *Shape) String() string {
+	return toJSON(s.Document())
+}
+
+// Filter returns the shape of a query filter.
+func Filter(filter bson.Raw) (bson.D, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return normalizeFilter(filter), nil
+}
+
+// Sort returns the shape of a sort specification. Sort specifications do not contain literals, so the shape is the
+// specification itself.
+func Sort(sortSpec bson.Raw) (bson.D, error) {
+	if err := sortSpec.Validate(); err != nil {
+		return nil, err
+	}
+	return normalizeSort(sortSpec), nil
+}
+
+// Projection returns the shape of a projection. Inclusions and exclusions are kept and all other values are normalized
+// as aggregation expressions.
+func Projection(projection bson.Raw) (bson.D, error) {
+	if err := projection.Validate(); err != nil {
+		return nil, err
+	}
+	return normalizeProjection(projection), nil
+}
+
+// Pipeline returns the shape of an aggregation pipeline given as a BSON array of stages.
+func Pipeline(pipeline bson.Raw) (bson.A, error) {
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	return normalizePipeline(pipeline), nil
+}
+
+// Hash returns a short hash of a shape returned by Filter, Sort, Projection or Pipeline.
+func Hash(shape interface{}) string {
+	return hashValue(shape)
+}
+
+// hashValue returns the hex-encoded prefix of the SHA-256 hash of the BSON encoding of a value.
+func hashValue(val interface{}) string {
+	_, data, err := bson.MarshalValue(val)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// toJSON returns a value as relaxed extended JSON.
+func toJSON(val interface{}) string {
+	b, err := bson.MarshalExtJSON(val, false, false)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// normalizeFilter returns the shape of a filter. The fields and operators of a filter are implicitly joined with
+// $and, so they are sorted.
+func normalizeFilter(filter bson.Raw) bson.D {
+	elems, _ := filter.Elements()
+	shape := make(bson.D, 0, len(elems))
+	for _, elem := range elems {
+		key, val := elem.Key(), elem.Value()
+		switch key {
+		case "$and", "$or", "$nor":
+			shape = append(shape, bson.E{key, normalizeClauses(val)})
+		case "$expr":
+			shape = append(shape, bson.E{key, normalizeExpression(val)})
+		default:
+			if strings.HasPrefix(key, "$") {
+				shape = append(shape, bson.E{key, literal(val)})
+			} else {
+				shape = append(shape, bson.E{key, normalizePredicate(val)})
+			}
+		}
+	}
+	sortKeys(shape)
+	return shape
+}
+
+// normalizeClauses returns the shape of the clauses of $and, $or or $nor, which are sorted.
+func normalizeClauses(val bson.RawValue) interface{} {
+	arr, ok := val.ArrayOK()
+	if !ok {
+		return literal(val)
+	}
+
+	values, _ := arr.Values()
+	clauses := make(bson.A, 0, len(values))
+	for _, v := range values {
+		if doc, ok := v.DocumentOK(); ok {
+			clauses = append(clauses, normalizeFilter(doc))
+		} else {
+			clauses = append(clauses, literal(v))
+		}
+	}
+	sortValues(clauses)
+	return clauses
+}
+
+// normalizePredicate returns the shape of the value of a field in a filter. Documents whose fields are all operators
+// are predicates, and all other values are literals that the field is compared to.
+func normalizePredicate(val bson.RawValue) interface{} {
+	doc, ok := val.DocumentOK()
+	if !ok || !isOperatorDocument(doc) {
+		return literal(val)
+	}
+
+	elems, _ := doc.Elements()
+	shape := make(bson.D, 0, len(elems))
+	for _, elem := range elems {
+		key, v := elem.Key(), elem.Value()
+		switch key {
+		case "$elemMatch":
+			if sub, ok := v.DocumentOK(); ok && isOperatorDocument(sub) {
+				shape = append(shape, bson.E{key, normalizePredicate(v)})
+			} else if ok {
+				shape = append(shape, bson.E{key, normalizeFilter(sub)})
+			} else {
+				shape = append(shape, bson.E{key, literal(v)})
+			}
+		case "$not":
+			shape = append(shape, bson.E{key, normalizePredicate(v)})
+		default:
+			shape = append(shape, bson.E{key, literal(v)})
+		}
+	}
+	sortKeys(shape)
+	return shape
+}
+
+// normalizeSort returns the shape of a sort specification, which is kept as is.
+func normalizeSort(sortSpec bson.Raw) bson.D {
+	elems, _ := sortSpec.Elements()
+	shape := make(bson.D, 0, len(elems))
+	for _, elem := range elems {
+		shape = append(shape, bson.E{elem.Key(), elem.Value()})
+	}
+	return shape
+}
+
+// normalizeProjection returns the shape of a projection.
+func normalizeProjection(projection bson.Raw) bson.D {
+	elems, _ := projection.Elements()
+	shape := make(bson.D, 0, len(elems))
+	for _, elem := range elems {
+		val := elem.Value()
+		switch val.Type {
+		case bsontype.Boolean, bsontype.Int32, bsontype.Int64, bsontype.Double:
+			shape = append(shape, bson.E{elem.Key(), val})
+		default:
+			shape = append(shape, bson.E{elem.Key(), normalizeExpression(val)})
+		}
+	}
+	return shape
+}
+
+// normalizePipeline returns the shape of an aggregation pipeline. The order of the stages is kept.
+func normalizePipeline(pipeline bson.Raw) bson.A {
+	values, _ := pipeline.Values()
+	shape := make(bson.A, 0, len(values))
+	for _, val := range values {
+		stage, ok := val.DocumentOK()
+		if !ok {
+			shape = append(shape, literal(val))
+			continue
+		}
+
+		elems, _ := stage.Elements()
+		stageShape := make(bson.D, 0, len(elems))
+		for _, elem := range elems {
+			stageShape = append(stageShape, bson.E{elem.Key(), normalizeStage(elem.Key(), elem.Value())})
+		}
+		shape = append(shape, stageShape)
+	}
+	return shape
+}
+
+// normalizeStage returns the shape of the argument of an aggregation stage.
+func normalizeStage(name string, val bson.RawValue) interface{} {
+	doc, isDoc := val.DocumentOK()
+	switch {
+	case name == "$match" && isDoc:
+		return normalizeFilter(doc)
+	case name == "$sort" && isDoc:
+		return normalizeSort(doc)
+	case name == "$project" && isDoc:
+		return normalizeProjection(doc)
+	case (name == "$lookup" || name == "$unionWith" || name == "$facet") && isDoc:
+		// These stages have nested pipelines.
+		elems, _ := doc.Elements()
+		shape := make(bson.D, 0, len(elems))
+		for _, elem := range elems {
+			v := elem.Value()
+			if arr, ok := v.ArrayOK(); ok && (elem.Key() == "pipeline" || name == "$facet") {
+				shape = append(shape, bson.E{elem.Key(), normalizePipeline(arr)})
+			} else if s, ok := v.StringValueOK(); ok {
+				// Collection names and field paths identify the query, so they are kept.
+				shape = append(shape, bson.E{elem.Key(), s})
+			} else {
+				shape = append(shape, bson.E{elem.Key(), normalizeExpression(v)})
+			}
+		}
+		return shape
+	default:
+		return normalizeExpression(val)
+	}
+}
+
+// normalizeExpression returns the shape of an aggregation expression. Field paths and variables, which start with "$",
+// are kept, and all other literals are replaced with markers.
+func normalizeExpression(val bson.RawValue) interface{} {
+	switch val.Type {
+	case bsontype.EmbeddedDocument:
+		elems, _ := val.Document().Elements()
+		shape := make(bson.D, 0, len(elems))
+		for _, elem := range elems {
+			if elem.Key() == "$literal" {
+				shape = append(shape, bson.E{elem.Key(), literal(elem.Value())})
+				continue
+			}
+			shape = append(shape, bson.E{elem.Key(), normalizeExpression(elem.Value())})
+		}
+		return shape
+	case bsontype.Array:
+		values, _ := val.Array().Values()
+		shape := make(bson.A, 0, len(values))
+		for _, v := range values {
+			shape = append(shape, normalizeExpression(v))
+		}
+		return shape
+	case bsontype.String:
+		if s := val.StringValue(); strings.HasPrefix(s, "$") {
+			return s
+		}
+		return literal(val)
+	default:
+		return literal(val)
+	}
+}
+
+// literal returns the marker of a literal value. Arrays are collapsed into a single marker with the marker of their
+// elements if all elements have the same marker.
+func literal(val bson.RawValue) string {
+	switch val.Type {
+	case bsontype.Double, bsontype.Int32, bsontype.Int64, bsontype.Decimal128:
+		return "?number"
+	case bsontype.String, bsontype.Symbol:
+		return "?string"
+	case bsontype.EmbeddedDocument:
+		return "?object"
+	case bsontype.Array:
+		values, _ := val.Array().Values()
+		var elem string
+		for i, v := range values {
+			marker := literal(v)
+			if i > 0 && marker != elem {
+				return "?array<>"
+			}
+			elem = marker
+		}
+		return "?array<" + elem + ">"
+	case bsontype.Binary:
+		return "?binData"
+	case bsontype.ObjectID:
+		return "?objectId"
+	case bsontype.Boolean:
+		return "?bool"
+	case bsontype.DateTime:
+		return "?date"
+	case bsontype.Null, bsontype.Undefined:
+		return "?null"
+	case bsontype.Regex:
+		return "?regex"
+	case bsontype.JavaScript, bsontype.CodeWithScope:
+		return "?javascript"
+	case bsontype.Timestamp:
+		return "?timestamp"
+	case bsontype.MinKey:
+		return "?minKey"
+	case bsontype.MaxKey:
+		return "?maxKey"
+	default:
+		return "?"
+	}
+}
+
+// isOperatorDocument returns whether all fields of a non-empty document are operators.
+func isOperatorDocument(doc bson.Raw) bool {
+	elems, err := doc.Elements()
+	if err != nil || len(elems) == 0 {
+		return false
+	}
+	for _, elem := range elems {
+		if !strings.HasPrefix(elem.Key(), "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// sortKeys sorts the elements of a document by key. The sort is stable, so repeated keys keep their order.
+func sortKeys(doc bson.D) {
+	sort.SliceStable(doc, func(i, j int) bool {
+		return doc[i].Key < doc[j].Key
+	})
+}
+
+// sortValues sorts values by their BSON encoding.
+func sortValues(values bson.A) {
+	encoded := make([][]byte, len(values))
+	for i, v := range values {
+		_, encoded[i], _ = bson.MarshalValue(v)
+	}
+	sort.Sort(byEncoding{values: values, encoded: encoded})
+}
+
+// byEncoding sorts values by their BSON encoding.
+type byEncoding struct {
+	values  bson.A
+	encoded [][]byte
+}
+
+func (b byEncoding) Len() int { return len(b.values) }
+
+func (b byEncoding) Less(i, j int) bool { return bytes.Compare(b.encoded[i], b.encoded[j]) < 0 }
+
+func (b byEncoding) Swap(i, j int) {
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+	b.encoded[i], b.encoded[j] = b.encoded[j], b.encoded[i]
+}
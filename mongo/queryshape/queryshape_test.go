@@ -0,0 +1,152 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package queryshape
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/require"
+)
+
+func marshal(t *testing.T, val interface{}) bson.Raw {
+	t.Helper()
+
+	raw, err := bson.Marshal(val)
+	require.NoError(t, err, "Marshal error")
+	return raw
+}
+
+func TestFilter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter bson.D
+		want   string
+	}{
+		{
+			"literals",
+			bson.D{
+				{"name", "alice"},
+				{"age", int32(30)},
+				{"score", 1.5},
+				{"active", true},
+				{"created", primitive.NewDateTimeFromTime(time.Now())},
+				{"_id", primitive.NewObjectID()},
+				{"address", bson.D{{"zip", "12345"}}},
+				{"deleted", nil},
+			},
+			`{"_id":"?objectId","active":"?bool","address":"?object","age":"?number","created":"?date",` +
+				`"deleted":"?null","name":"?string","score":"?number"}`,
+		},
+		{
+			"operators",
+			bson.D{{"age", bson.D{{"$lt", 40}, {"$gt", 30}}}, {"tags", bson.D{{"$in", bson.A{"a", "b"}}}}},
+			`{"age":{"$gt":"?number","$lt":"?number"},"tags":{"$in":"?array<?string>"}}`,
+		},
+		{
+			"mixed and empty arrays",
+			bson.D{{"a", bson.D{{"$in", bson.A{1, "x"}}}}, {"b", bson.D{{"$nin", bson.A{}}}}},
+			`{"a":{"$in":"?array<>"},"b":{"$nin":"?array<>"}}`,
+		},
+		{
+			"logical operators",
+			bson.D{{"$or", bson.A{bson.D{{"b", 1}}, bson.D{{"a", bson.D{{"$not", bson.D{{"$eq", 2}}}}}}}}},
+			`{"$or":[{"b":"?number"},{"a":{"$not":{"$eq":"?number"}}}]}`,
+		},
+		{
+			"elemMatch",
+			bson.D{{"items", bson.D{{"$elemMatch", bson.D{{"qty", bson.D{{"$gt", 5}}}, {"sku", "x"}}}}}},
+			`{"items":{"$elemMatch":{"qty":{"$gt":"?number"},"sku":"?string"}}}`,
+		},
+		{
+			"expr",
+			bson.D{{"$expr", bson.D{{"$gt", bson.A{"$spent", bson.D{{"$multiply", bson.A{"$budget", 2}}}}}}}},
+			`{"$expr":{"$gt":["$spent",{"$multiply":["$budget","?number"]}]}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shape, err := Filter(marshal(t, tc.filter))
+			require.NoError(t, err, "Filter error")
+			assert.Equal(t, tc.want, toJSON(shape), "expected shape %s, got %s", tc.want, toJSON(shape))
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := func(t *testing.T, filter bson.D) string {
+		t.Helper()
+
+		shape, err := Filter(marshal(t, filter))
+		require.NoError(t, err, "Filter error")
+		return Hash(shape)
+	}
+
+	h1 := hash(t, bson.D{{"a", 1}, {"$or", bson.A{bson.D{{"x", 1}}, bson.D{{"y", "s"}}}}})
+	h2 := hash(t, bson.D{{"$or", bson.A{bson.D{{"y", "t"}}, bson.D{{"x", 2}}}}, {"a", 5}})
+	h3 := hash(t, bson.D{{"a", "1"}})
+	assert.Equal(t, 16, len(h1), "expected 16 hex characters, got %q", h1)
+	assert.Equal(t, h1, h2, "expected equal hashes for filters with the same shape")
+	assert.NotEqual(t, h1, h3, "expected different hashes for filters with different shapes")
+}
+
+func TestFromCommand(t *testing.T) {
+	t.Run("find", func(t *testing.T) {
+		shape, err := FromCommand(marshal(t, bson.D{
+			{"find", "users"},
+			{"filter", bson.D{{"email", "alice@example.com"}}},
+			{"sort", bson.D{{"age", -1}}},
+			{"projection", bson.D{{"name", 1}, {"upper", bson.D{{"$toUpper", "$name"}}}}},
+			{"limit", 10},
+			{"$db", "app"},
+		}))
+		require.NoError(t, err, "FromCommand error")
+
+		want := `{"cmd":"find","ns":"app.users","filter":{"email":"?string"},"sort":{"age":-1},` +
+			`"projection":{"name":1,"upper":{"$toUpper":"$name"}}}`
+		assert.Equal(t, want, shape.String(), "expected shape %s, got %s", want, shape.String())
+	})
+	t.Run("aggregate", func(t *testing.T) {
+		shape, err := FromCommand(marshal(t, bson.D{
+			{"aggregate", "orders"},
+			{"pipeline", bson.A{
+				bson.D{{"$match", bson.D{{"status", "A"}}}},
+				bson.D{{"$lookup", bson.D{
+					{"from", "items"},
+					{"pipeline", bson.A{bson.D{{"$match", bson.D{{"qty", bson.D{{"$gte", 3}}}}}}}},
+					{"as", "items"},
+				}}},
+				bson.D{{"$group", bson.D{{"_id", "$cust"}, {"total", bson.D{{"$sum", "$amount"}}}}}},
+				bson.D{{"$limit", 5}},
+			}},
+			{"$db", "shop"},
+		}))
+		require.NoError(t, err, "FromCommand error")
+
+		want := `{"cmd":"aggregate","ns":"shop.orders","pipeline":[{"$match":{"status":"?string"}},` +
+			`{"$lookup":{"from":"items","pipeline":[{"$match":{"qty":{"$gte":"?number"}}}],"as":"items"}},` +
+			`{"$group":{"_id":"$cust","total":{"$sum":"$amount"}}},{"$limit":"?number"}]}`
+		assert.Equal(t, want, shape.String(), "expected shape %s, got %s", want, shape.String())
+	})
+	t.Run("delete", func(t *testing.T) {
+		shape, err := FromCommand(marshal(t, bson.D{
+			{"delete", "users"},
+			{"deletes", bson.A{bson.D{{"q", bson.D{{"_id", 1}}}, {"limit", 1}}}},
+		}))
+		require.NoError(t, err, "FromCommand error")
+
+		want := `{"cmd":"delete","filter":{"_id":"?number"}}`
+		assert.Equal(t, want, shape.String(), "expected shape %s, got %s", want, shape.String())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := FromCommand(bson.Raw{0x01})
+		assert.NotNil(t, err, "expected error for invalid command, got nil")
+	})
+}
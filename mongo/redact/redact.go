@@ -0,0 +1,302 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package redact removes personally identifiable information from the commands and replies that the driver logs and
+// publishes to command monitors.
+//
+// A Policy replaces the values of fields that match its paths or patterns. Fields are identified by their path in
+// the document, which is the dot-separated list of field names from the top-level field to the field. Array indexes
+// and operators (field names starting with "$") are not part of the path, so the "email" field of the documents of an
+// insert command has the path "documents.email" and the value of {"filter": {"email": {"$in": [...]}}} has the path
+// "filter.email".
+//
+// A Policy is applied with Policy.Apply, or configured for the driver with options.LoggerOptions.SetRedaction and
+// the Redaction field of event.CommandMonitor. Error messages can't be redacted field by field, so when the driver
+// applies a Policy that redacts any values, it also replaces the error messages returned by the server with
+// Placeholder in replies and failures, keeping their codes and names.
+package redact // import "github.com/hongyuyang/mongo-go-driver/mongo/redact"
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"regexp"
+	"strings"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/bson/bsontype"
+	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
+	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
+)
+
+// Placeholder is the value that replaces redacted strings.
+const Placeholder = "REDACTED"
+
+// Replacement specifies how the value of a redacted field is replaced.
+type Replacement int
+
+const (
+	// ReplaceWithPlaceholder replaces a value with a placeholder of the same type: Placeholder for strings, zero for
+	// numbers, dates and timestamps, false for booleans, the zero ObjectID for ObjectIDs and empty values for binary
+	// data, regular expressions and JavaScript. Other values are replaced with null.
+	ReplaceWithPlaceholder Replacement = iota
+
+	// ReplaceWithHash replaces a value with a string containing a hash of its type and data. Equal values have equal
+	// hashes, so redacted values can still be correlated. Use Policy.SetHashKey to prevent the values of small
+	// domains, like phone numbers, from being recovered by hashing all possible values.
+	ReplaceWithHash
+)
+
+// Policy specifies which values of a document are redacted. The zero value redacts nothing. A Policy must not be
+// modified after it is passed to the driver.
+type Policy struct {
+	paths      []pathRule
+	patterns   []patternRule
+	valuesOnly bool
+	hashKey    []byte
+}
+
+type pathRule struct {
+	segments    []string
+	replacement Replacement
+}
+
+type patternRule struct {
+	pattern     *regexp.Regexp
+	replacement Replacement
+}
+
+// NewPolicy creates an empty Policy.
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+// AddPath redacts the fields whose path ends with the given dot-separated path. For example, "email" redacts all
+// fields named "email" and "address.zip" redacts the "zip" field of all "address" documents. If the value of a
+// matching field is a document or an array, all values in it are redacted.
+func (p *Policy) AddPath(path string, replacement Replacement) *Policy {
+	p.paths = append(p.paths, pathRule{segments: strings.Split(path, "."), replacement: replacement})
+	return p
+}
+
+// AddPattern redacts the fields whose full dot-separated path matches the given regular expression. If the value of a
+// matching field is a document or an array, all values in it are redacted.
+func (p *Policy) AddPattern(pattern *regexp.Regexp, replacement Replacement) *Policy {
+	p.patterns = append(p.patterns, patternRule{pattern: pattern, replacement: replacement})
+	return p
+}
+
+// SetValuesOnly specifies whether all values in the documents and arrays of a command or reply are replaced with
+// placeholders, which keeps the shape of the document but strips every literal. The top-level values, like the
+// collection name of a command or the "ok" field of a reply, are kept. Fields that match a path or pattern of the
+// policy are still replaced as specified by their rule.
+func (p *Policy) SetValuesOnly(valuesOnly bool) *Policy {
+	p.valuesOnly = valuesOnly
+	return p
+}
+
+// SetHashKey specifies the key used to compute hashes for ReplaceWithHash. If a key is set, hashes are computed
+// with HMAC-SHA256. Otherwise, they are computed with SHA-256.
+func (p *Policy) SetHashKey(key []byte) *Policy {
+	p.hashKey = key
+	return p
+}
+
+// Enabled returns whether the policy redacts any values.
+func (p *Policy) Enabled() bool {
+	return p != nil && (p.valuesOnly || len(p.paths) > 0 || len(p.patterns) > 0)
+}
+
+// Apply returns a copy of the document with the values redacted by the policy. If the policy does not redact any
+// value of the document, the document is returned as is. Invalid documents are returned as is.
+func (p *Policy) Apply(doc bsoncore.Document) bsoncore.Document {
+	if !p.Enabled() {
+		return doc
+	}
+
+	r := redactor{policy: p}
+	dst, changed, ok := r.document(make([]byte, 0, len(doc)), doc, false, nil, 0)
+	if !ok || !changed {
+		return doc
+	}
+	return dst
+}
+
+// Redact returns a copy of the document with the values redacted by the policy, like Apply. It implements the
+// event.Redactor interface.
+func (p *Policy) Redact(doc bson.Raw) bson.Raw {
+	return bson.Raw(p.Apply(bsoncore.Document(doc)))
+}
+
+// redactor holds the state of a single application of a policy.
+type redactor struct {
+	policy *Policy
+	path   []string
+	hash   hash.Hash
+}
+
+// match returns the replacement of the field at the current path, if the field is matched by a rule.
+func (r *redactor) match() (Replacement, bool) {
+	for _, rule := range r.policy.paths {
+		if hasSuffix(r.path, rule.segments) {
+			return rule.replacement, true
+		}
+	}
+	if len(r.policy.patterns) > 0 {
+		path := strings.Join(r.path, ".")
+		for _, rule := range r.policy.patterns {
+			if rule.pattern.MatchString(path) {
+				return rule.replacement, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// forced is the replacement of all values in a document or array whose field was matched by a rule or that is
+// redacted because of the values-only mode.
+type forced struct {
+	replacement Replacement
+}
+
+// document appends the redacted copy of a document or array to dst. depth is the nesting level of the document, which
+// is 0 for the top-level document.
+func (r *redactor) document(dst []byte, doc []byte, isArray bool, force *forced, depth int) ([]byte, bool, bool) {
+	length, rem, ok := bsoncore.ReadLength(doc)
+	if !ok || length < 5 || int(length) > len(doc) {
+		return dst, false, false
+	}
+	rem = rem[:length-5]
+
+	idx, dst := bsoncore.AppendDocumentStart(dst)
+	var changed bool
+	for len(rem) > 0 {
+		var elem bsoncore.Element
+		elem, rem, ok = bsoncore.ReadElement(rem)
+		if !ok {
+			return dst, false, false
+		}
+		val, err := elem.ValueErr()
+		if err != nil {
+			return dst, false, false
+		}
+
+		// Array indexes and operators are not part of the path. Field names containing dots, like the keys of a
+		// filter on a nested field, are split into their segments.
+		key := elem.Key()
+		var pushed int
+		if !isArray && !strings.HasPrefix(key, "$") {
+			segments := strings.Split(key, ".")
+			r.path = append(r.path, segments...)
+			pushed = len(segments)
+		}
+
+		var elemChanged bool
+		dst, elemChanged, ok = r.element(dst, key, val, force, depth)
+		r.path = r.path[:len(r.path)-pushed]
+		if !ok {
+			return dst, false, false
+		}
+		changed = changed || elemChanged
+	}
+
+	dst, err := bsoncore.AppendDocumentEnd(dst, idx)
+	if err != nil {
+		return dst, false, false
+	}
+	return dst, changed, true
+}
+
+// element appends the redacted element with the given key and value to dst. depth is the nesting level of the
+// document that contains the element.
+func (r *redactor) element(dst []byte, key string, val bsoncore.Value, force *forced, depth int) ([]byte, bool, bool) {
+	if force == nil {
+		if replacement, ok := r.match(); ok {
+			force = &forced{replacement: replacement}
+		}
+	}
+
+	switch {
+	case val.Type == bsontype.EmbeddedDocument || val.Type == bsontype.Array:
+		dst = bsoncore.AppendHeader(dst, val.Type, key)
+		return r.document(dst, val.Data, val.Type == bsontype.Array, force, depth+1)
+	case force == nil && r.policy.valuesOnly && depth > 0:
+		// In values-only mode, nested values that are not matched by a rule are replaced with placeholders.
+		return appendPlaceholder(dst, key, val), true, true
+	case force == nil:
+		return bsoncore.AppendValueElement(dst, key, val), false, true
+	case force.replacement == ReplaceWithHash:
+		return bsoncore.AppendStringElement(dst, key, r.hashValue(val)), true, true
+	default:
+		return appendPlaceholder(dst, key, val), true, true
+	}
+}
+
+// hashValue returns the hex-encoded prefix of the hash of a value.
+func (r *redactor) hashValue(val bsoncore.Value) string {
+	if r.hash == nil {
+		if r.policy.hashKey != nil {
+			r.hash = hmac.New(sha256.New, r.policy.hashKey)
+		} else {
+			r.hash = sha256.New()
+		}
+	}
+
+	r.hash.Reset()
+	r.hash.Write([]byte{byte(val.Type)})
+	r.hash.Write(val.Data)
+	return hex.EncodeToString(r.hash.Sum(nil)[:8])
+}
+
+// appendPlaceholder appends an element with the placeholder for a value of the given type.
+func appendPlaceholder(dst []byte, key string, val bsoncore.Value) []byte {
+	switch val.Type {
+	case bsontype.String:
+		return bsoncore.AppendStringElement(dst, key, Placeholder)
+	case bsontype.Symbol:
+		return bsoncore.AppendSymbolElement(dst, key, Placeholder)
+	case bsontype.Int32:
+		return bsoncore.AppendInt32Element(dst, key, 0)
+	case bsontype.Int64:
+		return bsoncore.AppendInt64Element(dst, key, 0)
+	case bsontype.Double:
+		return bsoncore.AppendDoubleElement(dst, key, 0)
+	case bsontype.Decimal128:
+		return bsoncore.AppendDecimal128Element(dst, key, primitive.NewDecimal128(0, 0))
+	case bsontype.Boolean:
+		return bsoncore.AppendBooleanElement(dst, key, false)
+	case bsontype.DateTime:
+		return bsoncore.AppendDateTimeElement(dst, key, 0)
+	case bsontype.Timestamp:
+		return bsoncore.AppendTimestampElement(dst, key, 0, 0)
+	case bsontype.ObjectID:
+		return bsoncore.AppendObjectIDElement(dst, key, primitive.NilObjectID)
+	case bsontype.Binary:
+		subtype, _ := val.Binary()
+		return bsoncore.AppendBinaryElement(dst, key, subtype, nil)
+	case bsontype.Regex:
+		return bsoncore.AppendRegexElement(dst, key, "", "")
+	case bsontype.JavaScript:
+		return bsoncore.AppendJavaScriptElement(dst, key, "")
+	default:
+		return bsoncore.AppendNullElement(dst, key)
+	}
+}
+
+// hasSuffix returns whether the path ends with the given segments.
+func hasSuffix(path []string, suffix []string) bool {
+	if len(suffix) > len(path) {
+		return false
+	}
+	offset := len(path) - len(suffix)
+	for i, segment := range suffix {
+		if path[offset+i] != segment {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package redact
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/require"
+	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
+)
+
+func TestPolicy(t *testing.T) {
+	// apply marshals a document, applies a policy to it and unmarshals the result.
+	apply := func(t *testing.T, p *Policy, doc bson.D) bson.D {
+		t.Helper()
+
+		raw, err := bson.Marshal(doc)
+		require.NoError(t, err, "Marshal error")
+
+		var got bson.D
+		err = bson.Unmarshal(p.Apply(raw), &got)
+		require.NoError(t, err, "Unmarshal error")
+		return got
+	}
+
+	insert := bson.D{
+		{"insert", "users"},
+		{"documents", bson.A{
+			bson.D{{"name", "alice"}, {"email", "alice@example.com"}, {"address", bson.D{{"zip", "12345"}}}},
+			bson.D{{"name", "bob"}, {"email", "bob@example.com"}, {"address", bson.D{{"zip", "67890"}}}},
+		}},
+	}
+
+	t.Run("paths", func(t *testing.T) {
+		p := NewPolicy().AddPath("email", ReplaceWithPlaceholder).AddPath("address.zip", ReplaceWithPlaceholder)
+		want := bson.D{
+			{"insert", "users"},
+			{"documents", bson.A{
+				bson.D{{"name", "alice"}, {"email", Placeholder}, {"address", bson.D{{"zip", Placeholder}}}},
+				bson.D{{"name", "bob"}, {"email", Placeholder}, {"address", bson.D{{"zip", Placeholder}}}},
+			}},
+		}
+		got := apply(t, p, insert)
+		assert.Equal(t, want, got, "expected %v, got %v", want, got)
+	})
+	t.Run("filter paths", func(t *testing.T) {
+		p := NewPolicy().AddPath("filter.address.zip", ReplaceWithPlaceholder)
+		find := bson.D{
+			{"find", "users"},
+			{"filter", bson.D{
+				{"address.zip", "12345"},
+				{"$or", bson.A{bson.D{{"address", bson.D{{"zip", bson.D{{"$in", bson.A{"1", "2"}}}}}}}}},
+			}},
+		}
+		want := bson.D{
+			{"find", "users"},
+			{"filter", bson.D{
+				{"address.zip", Placeholder},
+				{"$or", bson.A{bson.D{{"address", bson.D{{"zip", bson.D{{"$in", bson.A{Placeholder, Placeholder}}}}}}}}},
+			}},
+		}
+		got := apply(t, p, find)
+		assert.Equal(t, want, got, "expected %v, got %v", want, got)
+	})
+	t.Run("patterns", func(t *testing.T) {
+		p := NewPolicy().AddPattern(regexp.MustCompile(`^documents\.(name|address)$`), ReplaceWithPlaceholder)
+		want := bson.D{
+			{"insert", "users"},
+			{"documents", bson.A{
+				bson.D{{"name", Placeholder}, {"email", "alice@example.com"}, {"address", bson.D{{"zip", Placeholder}}}},
+				bson.D{{"name", Placeholder}, {"email", "bob@example.com"}, {"address", bson.D{{"zip", Placeholder}}}},
+			}},
+		}
+		got := apply(t, p, insert)
+		assert.Equal(t, want, got, "expected %v, got %v", want, got)
+	})
+	t.Run("placeholders keep types", func(t *testing.T) {
+		p := NewPolicy().AddPath("v", ReplaceWithPlaceholder)
+		doc := bson.D{{"v", bson.A{
+			"s", int32(1), int64(2), 3.5, true,
+			primitive.NewDateTimeFromTime(time.Now()),
+			primitive.NewObjectID(),
+			primitive.Binary{Subtype: 4, Data: []byte{1, 2}},
+			primitive.Null{},
+		}}}
+		want := bson.D{{"v", bson.A{
+			Placeholder, int32(0), int64(0), 0.0, false,
+			primitive.DateTime(0),
+			primitive.NilObjectID,
+			primitive.Binary{Subtype: 4, Data: []byte{}},
+			nil,
+		}}}
+		got := apply(t, p, doc)
+		assert.Equal(t, want, got, "expected %v, got %v", want, got)
+	})
+	t.Run("hashes", func(t *testing.T) {
+		doc := bson.D{{"a", "alice@example.com"}, {"b", "alice@example.com"}, {"c", "bob@example.com"}}
+
+		got := apply(t, NewPolicy().AddPattern(regexp.MustCompile(`.`), ReplaceWithHash), doc)
+		assert.Equal(t, got[0].Value, got[1].Value, "expected equal values to have equal hashes, got %v", got)
+		assert.NotEqual(t, got[0].Value, got[2].Value, "expected different values to have different hashes, got %v", got)
+		assert.Equal(t, 16, len(got[0].Value.(string)), "expected 16 hex characters, got %v", got[0].Value)
+
+		keyed := apply(t, NewPolicy().AddPattern(regexp.MustCompile(`.`), ReplaceWithHash).SetHashKey([]byte("key")), doc)
+		assert.NotEqual(t, got[0].Value, keyed[0].Value, "expected keyed hash to differ, got %v", keyed[0].Value)
+	})
+	t.Run("values only", func(t *testing.T) {
+		p := NewPolicy().SetValuesOnly(true).AddPath("name", ReplaceWithHash)
+		got := apply(t, p, insert)
+
+		assert.Equal(t, "users", got[0].Value, "expected top-level value to be kept, got %v", got[0].Value)
+		first := got[1].Value.(bson.A)[0].(bson.D)
+		assert.NotEqual(t, Placeholder, first[0].Value, "expected name to be hashed, got %v", first[0].Value)
+		assert.Equal(t, Placeholder, first[1].Value, "expected email to be redacted, got %v", first[1].Value)
+		assert.Equal(t, bson.D{{"zip", Placeholder}}, first[2].Value, "expected zip to be redacted, got %v",
+			first[2].Value)
+	})
+	t.Run("unchanged documents", func(t *testing.T) {
+		raw, err := bson.Marshal(insert)
+		require.NoError(t, err, "Marshal error")
+
+		got := NewPolicy().AddPath("ssn", ReplaceWithPlaceholder).Apply(raw)
+		assert.Equal(t, &raw[0], &got[0], "expected the document to be returned as is")
+
+		var nilPolicy *Policy
+		got = nilPolicy.Apply(raw)
+		assert.Equal(t, &raw[0], &got[0], "expected the document to be returned as is")
+
+		invalid := bsoncore.Document{0x01}
+		assert.Equal(t, invalid, NewPolicy().AddPath("x", ReplaceWithPlaceholder).Apply(invalid),
+			"expected invalid document to be returned as is")
+	})
+}
@@ -140,7 +140,7 @@ func (siv SearchIndexView) CreateMany(
 		ServerSelector(selector).ClusterClock(siv.coll.client.clock).
 		Collection(siv.coll.name).Database(siv.coll.db.name).
 		Deployment(siv.coll.client.deployment).ServerAPI(siv.coll.client.serverAPI).
-		Timeout(siv.coll.client.timeout)
+		Timeout(siv.coll.timeout)
 
 	err = op.Execute(ctx)
 	if err != nil {
@@ -195,7 +195,7 @@ func (siv SearchIndexView) DropOne(
 		ServerSelector(selector).ClusterClock(siv.coll.client.clock).
 		Collection(siv.coll.name).Database(siv.coll.db.name).
 		Deployment(siv.coll.client.deployment).ServerAPI(siv.coll.client.serverAPI).
-		Timeout(siv.coll.client.timeout)
+		Timeout(siv.coll.timeout)
 
 	err = op.Execute(ctx)
 	if de, ok := err.(driver.Error); ok && de.NamespaceNotFound() {
@@ -249,7 +249,7 @@ func (siv SearchIndexView) UpdateOne(
 		ServerSelector(selector).ClusterClock(siv.coll.client.clock).
 		Collection(siv.coll.name).Database(siv.coll.db.name).
 		Deployment(siv.coll.client.deployment).ServerAPI(siv.coll.client.serverAPI).
-		Timeout(siv.coll.client.timeout)
+		Timeout(siv.coll.timeout)
 
 	return op.Execute(ctx)
 }
@@ -0,0 +1,100 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/topology"
+)
+
+// WithServerSelector returns a copy of ctx with a server selector that replaces the selector set with
+// options.ClientOptions.SetServerSelector for the operations run with the returned context. Like the selector of the
+// client, it runs after the read preference and latency window selectors of read operations and is not used for
+// writes. A nil selector disables the selector of the client.
+func WithServerSelector(ctx context.Context, selector description.ServerSelector) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return driverutil.WithServerSelector(ctx, selector)
+}
+
+// SelectionDiagnostics is the outcome of a server selection dry run returned by Client.DiagnoseSelection.
+type SelectionDiagnostics struct {
+	// Topology is the topology description the server was selected from.
+	Topology description.Topology
+
+	// Suitable contains the servers that match the read preference, the latency window and the application server
+	// selector.
+	Suitable []description.Server
+
+	// Selected is the server an operation would be sent to, or nil if no server is suitable.
+	Selected *description.Server
+
+	// Rejections contains the reason each server other than Selected was rejected, such as being of the wrong kind,
+	// not matching the tag sets, being too stale, being outside the latency window or failing its heartbeats.
+	Rejections []description.ServerRejection
+}
+
+// DiagnoseSelection performs server selection for a read with the given read preference against the current topology
+// description without running an operation, and explains why each server that would not be selected was rejected.
+// If rp is nil, the read preference of the client is used. Unlike operations, DiagnoseSelection does not wait for a
+// suitable server to become available, so Selected is nil if there is none.
+//
+// The server selector of ctx or of the client is applied as it would be for an operation.
+func (c *Client) DiagnoseSelection(ctx context.Context, rp *readpref.ReadPref) (*SelectionDiagnostics, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if rp == nil {
+		rp = c.readPreference
+	}
+
+	topo, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return nil, errors.New("server selection diagnostics are not supported by the deployment of the client")
+	}
+
+	selector := description.CompositeSelector([]description.ServerSelector{
+		description.ReadPrefSelector(rp),
+		description.LatencySelector(c.localThreshold),
+	})
+	diag, err := topo.DiagnoseSelection(ctx, selector)
+	if err != nil {
+		return nil, replaceErrors(err)
+	}
+
+	return &SelectionDiagnostics{
+		Topology:   diag.Topology,
+		Suitable:   diag.Suitable,
+		Selected:   diag.Selected,
+		Rejections: diag.Rejections,
+	}, nil
+}
+
+// SetServerExcluded excludes the server with the given host and port from server selection, or includes it again if
+// excluded is false, without reconnecting the client. This can be used to move traffic off a mongos or a secondary
+// before it is restarted for maintenance. While a server is excluded, its idle connections are closed and its in-use
+// connections are closed once the operations using them complete. Excluded servers are reported with
+// description.Server.Excluded set in TopologyDescriptionChangedEvents and as rejected by Client.DiagnoseSelection and
+// server selection errors.
+//
+// Excluding a server does not affect operations pinned to it, such as getMore commands of open cursors. An error is
+// returned if the address is not a member of the topology or if the client is in load balanced mode.
+func (c *Client) SetServerExcluded(addr string, excluded bool) error {
+	topo, ok := c.deployment.(*topology.Topology)
+	if !ok {
+		return errors.New("excluding servers is not supported by the deployment of the client")
+	}
+
+	return replaceErrors(topo.SetServerExcluded(address.Address(addr), excluded))
+}
@@ -101,12 +101,14 @@ type Session interface {
 
 	// WithTransaction starts a transaction on this session and runs the fn callback. Errors with
 	// the TransientTransactionError and UnknownTransactionCommitResult labels are retried for up to
-	// 120 seconds. Inside the callback, the SessionContext must be used as the Context parameter
-	// for any operations that should be part of the transaction. If the ctx parameter already has a
-	// Session attached to it, it will be replaced by this session. The fn callback may be run
-	// multiple times during WithTransaction due to retry attempts, so it must be idempotent.
-	// Non-retryable operation errors or any operation errors that occur after the timeout expires
-	// will be returned without retrying. If the callback fails, the driver will call
+	// 120 seconds, or for up to the timeout of the transaction if one is set. If the ctx parameter
+	// has no deadline, the timeout of the transaction also applies to the callback. Inside the
+	// callback, the SessionContext must be used as the Context parameter for any operations that
+	// should be part of the transaction. If the ctx parameter already has a Session attached to it,
+	// it will be replaced by this session. The fn callback may be run multiple times during
+	// WithTransaction due to retry attempts, so it must be idempotent. Non-retryable operation
+	// errors or any operation errors that occur after the timeout expires will be returned without
+	// retrying. If the callback fails, the driver will call
 	// AbortTransaction. Because this method must succeed to ensure that server-side resources are
 	// properly cleaned up, context deadlines and cancellations will not be respected during this
 	// call. For a usage example, see the Client.StartSession method documentation.
@@ -181,8 +183,7 @@ func (s *sessionImpl) EndSession(ctx context.Context) {
 // WithTransaction implements the Session interface.
 func (s *sessionImpl) WithTransaction(ctx context.Context, fn func(ctx SessionContext) (interface{}, error),
 	opts ...*options.TransactionOptions) (interface{}, error) {
-	timeout := time.NewTimer(withTransactionTimeout)
-	defer timeout.Stop()
+	var timeout *time.Timer
 	var err error
 	for {
 		err = s.StartTransaction(opts...)
@@ -190,6 +191,22 @@ func (s *sessionImpl) WithTransaction(ctx context.Context, fn func(ctx SessionCo
 			return nil, err
 		}
 
+		if timeout == nil {
+			// If the transaction has a timeout, it replaces the time limit for retrying the transaction and it bounds
+			// the callback unless the context already has a deadline.
+			limit := withTransactionTimeout
+			if txnTimeout := s.clientSession.CurrentTimeout; txnTimeout != nil && *txnTimeout > 0 {
+				limit = *txnTimeout
+				if _, deadlineSet := ctx.Deadline(); !deadlineSet {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, limit)
+					defer cancel()
+				}
+			}
+			timeout = time.NewTimer(limit)
+			defer timeout.Stop()
+		}
+
 		res, err := fn(NewSessionContext(ctx, s))
 		if err != nil {
 			if s.clientSession.TransactionRunning() {
@@ -273,6 +290,7 @@ func (s *sessionImpl) StartTransaction(opts ...*options.TransactionOptions) erro
 		ReadPreference: topts.ReadPreference,
 		WriteConcern:   topts.WriteConcern,
 		MaxCommitTime:  topts.MaxCommitTime,
+		Timeout:        topts.Timeout,
 	}
 
 	return s.clientSession.StartTransaction(coreOpts)
@@ -296,7 +314,8 @@ func (s *sessionImpl) AbortTransaction(ctx context.Context) error {
 	_ = operation.NewAbortTransaction().Session(s.clientSession).ClusterClock(s.client.clock).Database("admin").
 		Deployment(s.deployment).WriteConcern(s.clientSession.CurrentWc).ServerSelector(selector).
 		Retry(driver.RetryOncePerCommand).CommandMonitor(s.client.monitor).
-		RecoveryToken(bsoncore.Document(s.clientSession.RecoveryToken)).ServerAPI(s.client.serverAPI).Execute(ctx)
+		RecoveryToken(bsoncore.Document(s.clientSession.RecoveryToken)).ServerAPI(s.client.serverAPI).
+		Timeout(s.clientSession.CurrentTimeout).Execute(ctx)
 
 	s.clientSession.Aborting = false
 	_ = s.clientSession.AbortTransaction()
@@ -328,7 +347,7 @@ func (s *sessionImpl) CommitTransaction(ctx context.Context) error {
 		Session(s.clientSession).ClusterClock(s.client.clock).Database("admin").Deployment(s.deployment).
 		WriteConcern(s.clientSession.CurrentWc).ServerSelector(selector).Retry(driver.RetryOncePerCommand).
 		CommandMonitor(s.client.monitor).RecoveryToken(bsoncore.Document(s.clientSession.RecoveryToken)).
-		ServerAPI(s.client.serverAPI).MaxTime(s.clientSession.CurrentMct)
+		ServerAPI(s.client.serverAPI).MaxTime(s.clientSession.CurrentMct).Timeout(s.clientSession.CurrentTimeout)
 
 	err = op.Execute(ctx)
 	// Return error without updating transaction state if it is a timeout, as the transaction has not
@@ -0,0 +1,230 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/event"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+	"github.com/hongyuyang/mongo-go-driver/internal/logger"
+	xrand "github.com/hongyuyang/mongo-go-driver/internal/rand"
+	"github.com/hongyuyang/mongo-go-driver/internal/randutil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+	"github.com/hongyuyang/mongo-go-driver/mongo/queryshape"
+	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
+)
+
+// slowOperationMonitor logs commands that take longer than their slow operation threshold. It wraps the command
+// monitor of a client.
+type slowOperationMonitor struct {
+	logger            *logger.Logger
+	threshold         *time.Duration
+	commandThresholds map[string]time.Duration
+	sampleRate        float64
+	random            *xrand.Rand
+
+	mu      sync.Mutex
+	started map[slowOperationKey]slowOperation
+}
+
+// slowOperation contains the values of an in-progress command that are logged if the command is slow. They are
+// computed when the command starts so the command itself is not kept until it finishes.
+type slowOperation struct {
+	namespace string
+	shape     string // formatted query shape, empty if the command has no query shape
+	shapeHash string
+	comment   string // formatted and redacted comment, empty if the command has no comment
+}
+
+// slowOperationKey identifies an in-progress command.
+type slowOperationKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// newSlowOperationMonitor creates a slowOperationMonitor from client options. It returns nil if no slow operation
+// threshold is set or if the client does not log.
+func newSlowOperationMonitor(clientOpt *options.ClientOptions, log *logger.Logger) *slowOperationMonitor {
+	if clientOpt.SlowOperationThreshold == nil && len(clientOpt.SlowOperationCommandThresholds) == 0 {
+		return nil
+	}
+	if log == nil || !log.LevelComponentEnabled(logger.LevelInfo, logger.ComponentCommand) {
+		return nil
+	}
+
+	sampleRate := 1.0
+	if clientOpt.SlowOperationSampleRate != nil {
+		sampleRate = *clientOpt.SlowOperationSampleRate
+	}
+	return &slowOperationMonitor{
+		logger:            log,
+		threshold:         clientOpt.SlowOperationThreshold,
+		commandThresholds: clientOpt.SlowOperationCommandThresholds,
+		sampleRate:        sampleRate,
+		random:            randutil.NewLockedRand(),
+		started:           make(map[slowOperationKey]slowOperation),
+	}
+}
+
+// wrap returns a CommandMonitor that logs slow commands and publishes all events to the given monitor, which may be
+// nil.
+func (m *slowOperationMonitor) wrap(monitor *event.CommandMonitor) *event.CommandMonitor {
+	if monitor == nil {
+		monitor = &event.CommandMonitor{}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			m.commandStarted(evt)
+			if monitor.Started != nil {
+				monitor.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			m.commandFinished(ctx, &evt.CommandFinishedEvent, evt.Reply, "")
+			if monitor.Succeeded != nil {
+				monitor.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			m.commandFinished(ctx, &evt.CommandFinishedEvent, nil, evt.Failure)
+			if monitor.Failed != nil {
+				monitor.Failed(ctx, evt)
+			}
+		},
+		Retry:     monitor.Retry,
+		Redaction: monitor.Redaction,
+	}
+}
+
+// commandThreshold returns the slow operation threshold of a command.
+func (m *slowOperationMonitor) commandThreshold(commandName string) (time.Duration, bool) {
+	if threshold, ok := m.commandThresholds[commandName]; ok {
+		return threshold, true
+	}
+	if m.threshold != nil {
+		return *m.threshold, true
+	}
+	return 0, false
+}
+
+func (m *slowOperationMonitor) commandStarted(evt *event.CommandStartedEvent) {
+	if _, ok := m.commandThreshold(evt.CommandName); !ok {
+		return
+	}
+
+	op := slowOperation{namespace: evt.DatabaseName}
+	if collection := driverutil.CommandCollection(evt.Command); collection != "" {
+		op.namespace += "." + collection
+	}
+	if shape, err := queryshape.FromCommand(evt.Command); err == nil {
+		op.shape = logger.FormatMessage(shape.String(), m.logger.MaxDocumentLength)
+		op.shapeHash = shape.Hash()
+	}
+	if comment, err := evt.Command.LookupErr("comment"); err == nil {
+		// The comment is logged, so apply the redaction policy of the logger to it.
+		doc := bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendValueElement(nil, "comment", bsoncore.Value{Type: comment.Type, Data: comment.Value}))
+		comment = bson.Raw(m.logger.Redaction.Apply(doc)).Lookup("comment")
+		op.comment = logger.FormatMessage(comment.String(), m.logger.MaxDocumentLength)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started[slowOperationKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}] = op
+}
+
+func (m *slowOperationMonitor) commandFinished(
+	ctx context.Context,
+	evt *event.CommandFinishedEvent,
+	reply bson.Raw,
+	failure string,
+) {
+	key := slowOperationKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}
+
+	m.mu.Lock()
+	op, ok := m.started[key]
+	delete(m.started, key)
+	m.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	threshold, _ := m.commandThreshold(evt.CommandName)
+	if evt.Duration <= threshold {
+		return
+	}
+	if m.sampleRate < 1 && m.random.Float64() >= m.sampleRate {
+		return
+	}
+
+	host, port, err := net.SplitHostPort(driverutil.ConnectionAddress(evt.ConnectionID))
+	if err != nil {
+		host = driverutil.ConnectionAddress(evt.ConnectionID)
+	}
+	operationID, _ := logger.OperationID(ctx)
+
+	keysAndValues := []interface{}{
+		logger.KeyNamespace, op.namespace,
+		logger.KeyDurationMS, evt.Duration.Milliseconds(),
+		logger.KeyThresholdMS, threshold.Milliseconds(),
+		logger.KeyConnectionID, evt.ConnectionID,
+	}
+	if op.shape != "" {
+		keysAndValues = append(keysAndValues, logger.KeyShape, op.shape, logger.KeyShapeHash, op.shapeHash)
+	}
+	if nreturned, ok := cursorReplyLength(reply); ok {
+		keysAndValues = append(keysAndValues, logger.KeyNReturned, nreturned)
+	}
+	if op.comment != "" {
+		keysAndValues = append(keysAndValues, logger.KeyComment, op.comment)
+	}
+	if failure != "" {
+		keysAndValues = append(keysAndValues,
+			logger.KeyFailure, logger.FormatMessage(failure, m.logger.MaxDocumentLength))
+	}
+
+	m.logger.Print(logger.LevelInfo,
+		logger.ComponentCommand,
+		logger.SlowOperation,
+		logger.SerializeCommand(logger.Command{
+			DriverConnectionID: evt.DriverConnectionID,
+			Message:            logger.SlowOperation,
+			Name:               evt.CommandName,
+			DatabaseName:       evt.DatabaseName,
+			OperationID:        operationID,
+			RequestID:          evt.RequestID,
+			ServerConnectionID: evt.ServerConnectionID64,
+			ServerHost:         host,
+			ServerPort:         port,
+			ServiceID:          evt.ServiceID,
+			Labels:             evt.Labels,
+		}, keysAndValues...)...)
+}
+
+// cursorReplyLength returns the number of documents in the batch of a cursor reply.
+func cursorReplyLength(reply bson.Raw) (int, bool) {
+	cursor, ok := reply.Lookup("cursor").DocumentOK()
+	if !ok {
+		return 0, false
+	}
+	for _, field := range []string{"firstBatch", "nextBatch"} {
+		if batch, ok := cursor.Lookup(field).ArrayOK(); ok {
+			values, err := batch.Values()
+			if err != nil {
+				return 0, false
+			}
+			return len(values), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,184 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson"
+	"github.com/hongyuyang/mongo-go-driver/event"
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/logger"
+	"github.com/hongyuyang/mongo-go-driver/internal/require"
+	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+)
+
+// slowOperationSink records the key-value pairs of the messages it logs.
+type slowOperationSink struct {
+	records []map[string]interface{}
+}
+
+func (s *slowOperationSink) Info(_ int, _ string, keysAndValues ...interface{}) {
+	record := make(map[string]interface{})
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		record[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	s.records = append(s.records, record)
+}
+
+func (s *slowOperationSink) Error(error, string, ...interface{}) {}
+
+func TestSlowOperationMonitor(t *testing.T) {
+	const connID = "localhost:27017[-4]"
+
+	newMonitor := func(t *testing.T, clientOpt *options.ClientOptions) (*event.CommandMonitor, *slowOperationSink) {
+		t.Helper()
+
+		sink := &slowOperationSink{}
+		log, err := logger.New(sink, 0, map[logger.Component]logger.Level{
+			logger.ComponentCommand: logger.LevelInfo,
+		})
+		require.NoError(t, err, "error creating logger")
+
+		som := newSlowOperationMonitor(clientOpt, log)
+		require.NotNil(t, som, "expected slow operation monitor, got nil")
+		return som.wrap(nil), sink
+	}
+	// runCommand publishes the events of a command that took the given duration.
+	runCommand := func(t *testing.T, cm *event.CommandMonitor, cmd bson.D, reply bson.D, duration time.Duration) {
+		t.Helper()
+
+		rawCmd, err := bson.Marshal(cmd)
+		require.NoError(t, err, "Marshal error")
+		rawReply, err := bson.Marshal(reply)
+		require.NoError(t, err, "Marshal error")
+
+		cm.Started(context.Background(), &event.CommandStartedEvent{
+			Command:      rawCmd,
+			DatabaseName: "db",
+			CommandName:  cmd[0].Key,
+			RequestID:    1,
+			ConnectionID: connID,
+		})
+		cm.Succeeded(context.Background(), &event.CommandSucceededEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{
+				Duration:     duration,
+				CommandName:  cmd[0].Key,
+				DatabaseName: "db",
+				RequestID:    1,
+				ConnectionID: connID,
+			},
+			Reply: rawReply,
+		})
+	}
+	findCmd := bson.D{
+		{"find", "coll"},
+		{"filter", bson.D{{"email", "alice@example.com"}, {"age", bson.D{{"$in", bson.A{30, 40}}}}}},
+		{"comment", "checkout"},
+	}
+	findReply := bson.D{{"cursor", bson.D{{"firstBatch", bson.A{bson.D{}, bson.D{}}}, {"id", int64(0)}}}}
+
+	t.Run("disabled", func(t *testing.T) {
+		som := newSlowOperationMonitor(options.Client(), &logger.Logger{})
+		assert.Nil(t, som, "expected no monitor without a threshold, got %v", som)
+
+		som = newSlowOperationMonitor(options.Client().SetSlowOperationThreshold(time.Second), nil)
+		assert.Nil(t, som, "expected no monitor without a logger, got %v", som)
+	})
+	t.Run("logs slow commands", func(t *testing.T) {
+		cm, sink := newMonitor(t, options.Client().SetSlowOperationThreshold(100*time.Millisecond))
+		runCommand(t, cm, findCmd, findReply, 50*time.Millisecond)
+		assert.Equal(t, 0, len(sink.records), "expected no records for a fast command, got %d", len(sink.records))
+
+		runCommand(t, cm, findCmd, findReply, 150*time.Millisecond)
+		require.Equal(t, 1, len(sink.records), "expected 1 record, got %d", len(sink.records))
+
+		want := map[string]interface{}{
+			logger.KeyMessage:      logger.SlowOperation,
+			logger.KeyCommandName:  "find",
+			logger.KeyNamespace:    "db.coll",
+			logger.KeyDurationMS:   int64(150),
+			logger.KeyThresholdMS:  int64(100),
+			logger.KeyServerHost:   "localhost",
+			logger.KeyServerPort:   int64(27017),
+			logger.KeyConnectionID: connID,
+			logger.KeyShape:        `{"cmd":"find","filter":{"age":{"$in":"?array<?number>"},"email":"?string"}}`,
+			logger.KeyNReturned:    2,
+			logger.KeyComment:      `"checkout"`,
+		}
+		for key, val := range want {
+			assert.Equal(t, val, sink.records[0][key], "expected %s to be %v, got %v", key, val, sink.records[0][key])
+		}
+		assert.Equal(t, 16, len(sink.records[0][logger.KeyShapeHash].(string)), "expected shape hash, got %v",
+			sink.records[0][logger.KeyShapeHash])
+	})
+	t.Run("command thresholds", func(t *testing.T) {
+		opts := options.Client().
+			SetSlowOperationThreshold(time.Second).
+			SetSlowOperationCommandThreshold("find", 10*time.Millisecond).
+			SetSlowOperationCommandThreshold("insert", time.Hour)
+		cm, sink := newMonitor(t, opts)
+
+		runCommand(t, cm, findCmd, findReply, 20*time.Millisecond)
+		runCommand(t, cm, bson.D{{"insert", "coll"}}, bson.D{{"ok", 1}}, 2*time.Second)
+		runCommand(t, cm, bson.D{{"delete", "coll"}, {"deletes", bson.A{bson.D{{"q", bson.D{{"x", 1}}}}}}},
+			bson.D{{"ok", 1}}, 2*time.Second)
+
+		require.Equal(t, 2, len(sink.records), "expected 2 records, got %d", len(sink.records))
+		assert.Equal(t, "find", sink.records[0][logger.KeyCommandName], "expected find record, got %v",
+			sink.records[0][logger.KeyCommandName])
+		assert.Equal(t, "delete", sink.records[1][logger.KeyCommandName], "expected delete record, got %v",
+			sink.records[1][logger.KeyCommandName])
+		assert.Equal(t, `{"cmd":"delete","filter":{"x":"?number"}}`, sink.records[1][logger.KeyShape],
+			"expected delete shape, got %v",
+			sink.records[1][logger.KeyShape])
+	})
+	t.Run("does not keep the command", func(t *testing.T) {
+		cm, sink := newMonitor(t, options.Client().SetSlowOperationThreshold(0))
+
+		rawCmd, err := bson.Marshal(findCmd)
+		require.NoError(t, err, "Marshal error")
+		cm.Started(context.Background(), &event.CommandStartedEvent{
+			Command:      rawCmd,
+			DatabaseName: "db",
+			CommandName:  "find",
+			RequestID:    1,
+			ConnectionID: connID,
+		})
+		// The buffer of the command may be reused once the command is sent.
+		for i := range rawCmd {
+			rawCmd[i] = 0
+		}
+		cm.Failed(context.Background(), &event.CommandFailedEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{
+				Duration:     time.Millisecond,
+				CommandName:  "find",
+				DatabaseName: "db",
+				RequestID:    1,
+				ConnectionID: connID,
+			},
+			Failure: "interrupted",
+		})
+
+		require.Equal(t, 1, len(sink.records), "expected 1 record, got %d", len(sink.records))
+		assert.Equal(t, "db.coll", sink.records[0][logger.KeyNamespace], "expected namespace %q, got %v", "db.coll",
+			sink.records[0][logger.KeyNamespace])
+		assert.Equal(t, `"checkout"`, sink.records[0][logger.KeyComment], "expected comment %q, got %v", `"checkout"`,
+			sink.records[0][logger.KeyComment])
+	})
+	t.Run("sampling", func(t *testing.T) {
+		opts := options.Client().SetSlowOperationThreshold(0).SetSlowOperationSampleRate(0.5)
+		cm, sink := newMonitor(t, opts)
+		for i := 0; i < 1000; i++ {
+			runCommand(t, cm, findCmd, findReply, time.Millisecond)
+		}
+		assert.True(t, len(sink.records) > 350 && len(sink.records) < 650,
+			"expected about half of 1000 commands to be logged, got %d", len(sink.records))
+	})
+}
@@ -433,6 +433,11 @@ func (bc *BatchCursor) getMore(ctx context.Context) {
 		Crypt:          bc.crypt,
 		ServerAPI:      bc.serverAPI,
 
+		// The getMore command must not have a "maxTimeMS" calculated from the Timeout context, as
+		// it would limit the time the server waits for new documents for tailable awaitData
+		// cursors, which only use the "maxTimeMS" set with SetMaxTime.
+		OmitCSOTMaxTimeMS: true,
+
 		// No read preference is passed to the getMore command,
 		// resulting in the default read preference: "primaryPreferred".
 		// Since this could be confusing, and there is no requirement
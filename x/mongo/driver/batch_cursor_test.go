@@ -7,10 +7,15 @@
 package driver
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/csot"
+	"github.com/hongyuyang/mongo-go-driver/internal/require"
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
 )
 
 func TestBatchCursor(t *testing.T) {
@@ -131,3 +136,70 @@ func TestBatchCursorSetMaxTime(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchCursorGetMoreMaxTimeMS(t *testing.T) {
+	t.Parallel()
+
+	response := createExhaustServerResponse(bsoncore.BuildDocumentFromElements(nil,
+		bsoncore.AppendDocumentElement(nil, "cursor", bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt64Element(nil, "id", 0),
+			bsoncore.AppendStringElement(nil, "ns", "db.coll"),
+			bsoncore.BuildArrayElement(nil, "nextBatch"),
+		)),
+		bsoncore.AppendInt32Element(nil, "ok", 1),
+	), false)
+
+	tests := []struct {
+		name      string
+		maxTimeMS int64
+		want      []int64
+	}{
+		{
+			name: "no maxTimeMS is calculated from the Timeout context",
+		},
+		{
+			name:      "only the maxTimeMS set with SetMaxTime is sent",
+			maxTimeMS: 100,
+			want:      []int64{100},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: response,
+			}
+			bc := &BatchCursor{
+				id:           1,
+				database:     "db",
+				collection:   "coll",
+				maxTimeMS:    test.maxTimeMS,
+				comment:      bsoncore.Value{},
+				server:       mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}},
+				currentBatch: new(bsoncore.DocumentSequence),
+			}
+
+			ctx, cancel := csot.MakeTimeoutContext(context.Background(), time.Minute)
+			defer cancel()
+
+			bc.getMore(ctx)
+			require.NoError(t, bc.Err(), "getMore error")
+
+			elems, err := bsoncore.Document(conn.pWriteWM[21:]).Elements()
+			require.NoError(t, err, "error reading the getMore command")
+
+			var got []int64
+			for _, elem := range elems {
+				if elem.Key() == "maxTimeMS" {
+					got = append(got, elem.Value().Int64())
+				}
+			}
+			assert.Equal(t, test.want, got, "expected and actual maxTimeMS of the getMore command are different")
+		})
+	}
+}
@@ -125,6 +125,10 @@ type ConnString struct {
 	LocalThresholdSet                  bool
 	MaxConnIdleTime                    time.Duration
 	MaxConnIdleTimeSet                 bool
+	MaxConnLifeTime                    time.Duration
+	MaxConnLifeTimeSet                 bool
+	MaxConnLifeTimeJitter              time.Duration
+	MaxConnLifeTimeJitterSet           bool
 	MaxPoolSize                        uint64
 	MaxPoolSizeSet                     bool
 	MinPoolSize                        uint64
@@ -439,6 +443,20 @@ func (u *ConnString) addOptions(connectionArgPairs []string) error {
 			}
 			u.MaxConnIdleTime = time.Duration(n) * time.Millisecond
 			u.MaxConnIdleTimeSet = true
+		case "maxconnlifetimems":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid value for %q: %q", key, value)
+			}
+			u.MaxConnLifeTime = time.Duration(n) * time.Millisecond
+			u.MaxConnLifeTimeSet = true
+		case "maxconnlifetimejitterms":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid value for %q: %q", key, value)
+			}
+			u.MaxConnLifeTimeJitter = time.Duration(n) * time.Millisecond
+			u.MaxConnLifeTimeJitterSet = true
 		case "maxpoolsize":
 			n, err := strconv.Atoi(value)
 			if err != nil || n < 0 {
@@ -265,6 +265,39 @@ func TestMaxConnIdleTime(t *testing.T) {
 	}
 }
 
+func TestMaxConnLifeTime(t *testing.T) {
+	tests := []struct {
+		s              string
+		expected       time.Duration
+		expectedJitter time.Duration
+		err            bool
+	}{
+		{s: "maxConnLifeTimeMS=10", expected: time.Duration(10) * time.Millisecond},
+		{
+			s:              "maxConnLifeTimeMS=60000&maxConnLifeTimeJitterMS=5000",
+			expected:       time.Duration(60000) * time.Millisecond,
+			expectedJitter: time.Duration(5000) * time.Millisecond,
+		},
+		{s: "maxConnLifeTimeMS=-2", err: true},
+		{s: "maxConnLifeTimeMS=gsdge", err: true},
+		{s: "maxConnLifeTimeJitterMS=-2", err: true},
+	}
+
+	for _, test := range tests {
+		s := fmt.Sprintf("mongodb://localhost/?%s", test.s)
+		t.Run(s, func(t *testing.T) {
+			cs, err := connstring.ParseAndValidate(s)
+			if test.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.expected, cs.MaxConnLifeTime)
+				require.Equal(t, test.expectedJitter, cs.MaxConnLifeTimeJitter)
+			}
+		})
+	}
+}
+
 func TestMaxPoolSize(t *testing.T) {
 	tests := []struct {
 		s        string
@@ -85,6 +85,9 @@ type RTTMonitor interface {
 	// P90 returns the 90th percentile observed round-trip time over the window period.
 	P90() time.Duration
 
+	// P95 returns the 95th percentile observed round-trip time over the window period.
+	P95() time.Duration
+
 	// Stats returns stringified stats of the current state of the monitor.
 	Stats() string
 }
@@ -165,6 +168,38 @@ type ErrorProcessor interface {
 	ProcessError(err error, conn Connection) ProcessErrorResult
 }
 
+// OverloadRetrier is implemented by Servers that limit the retries of commands rejected because the server is
+// overloaded. If a Server implements it, Operation.Execute retries the commands whose errors are labeled
+// SystemOverloadedError and RetryableError after the delay returned by OverloadRetryBackoff.
+type OverloadRetrier interface {
+	// OverloadRetryBackoff returns how long to wait before retrying a command that was rejected the given number of
+	// consecutive times, or false if it must not be retried.
+	OverloadRetryBackoff(rejections int) (time.Duration, bool)
+}
+
+// ReadTracker is implemented by Servers that track the outcomes of the reads sent to them, such as to remove themselves
+// from read selection while their reads keep failing. If a Server implements it, Operation.Execute calls StartRead
+// before sending it a read that can be sent to other servers, which are the reads sent to a sharded cluster and the reads
+// with a read preference other than primary sent to a replica set, and FinishRead with the outcome of the read.
+type ReadTracker interface {
+	StartRead()
+	FinishRead(err error)
+}
+
+// ReadHedger is implemented by Deployments that hedge reads. If a Deployment implements it, Operation.Execute sends
+// the idempotent reads that a server has not replied to after the delay returned by HedgeDelay to a second server, and
+// uses the first reply.
+type ReadHedger interface {
+	// HedgeDelay returns how long to wait for the reply of a read sent to the given server before hedging it, or
+	// false if the read must not be hedged. Each call counts a read that can be hedged in the hedging budget.
+	HedgeDelay(srvr Server) (time.Duration, bool)
+
+	// SelectHedgeServer selects a server suitable for the selector other than the server at exclude without waiting
+	// for the topology to change, and takes a hedge from the hedging budget. It returns nil if no other server is
+	// suitable or if the hedging budget is used up.
+	SelectHedgeServer(ctx context.Context, ss description.ServerSelector, exclude address.Address) Server
+}
+
 // HandshakeInformation contains information extracted from a MongoDB connection handshake. This is a helper type that
 // augments description.Server by also tracking server connection ID and authentication-related fields. We use this type
 // rather than adding authentication-related fields to description.Server to avoid retaining sensitive information in a
@@ -45,6 +45,13 @@ var (
 	RetryableWriteError = "RetryableWriteError"
 	// NoWritesPerformed is an error label indicated that no writes were performed for an operation.
 	NoWritesPerformed = "NoWritesPerformed"
+	// SystemOverloadedError is an error label for commands rejected because the server is overloaded.
+	SystemOverloadedError = "SystemOverloadedError"
+	// RetryableError is an error label for errors of commands that can be retried because they were not executed.
+	RetryableError = "RetryableError"
+	// ErrOverloaded is returned when an operation is rejected without being sent because the number of operations in
+	// progress on the server reached its adaptive concurrency limit.
+	ErrOverloaded = errors.New("server is overloaded")
 	// ErrCursorNotFound is the cursor not found error for legacy find operations.
 	ErrCursorNotFound = errors.New("cursor not found")
 	// ErrUnacknowledgedWrite is returned from functions that have an unacknowledged
@@ -0,0 +1,344 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/bson/bsontype"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
+	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/wiremessage"
+)
+
+// hedgedCommands are the commands of the idempotent reads that can be hedged. Aggregates are only
+// hedged if they don't have an output stage.
+var hedgedCommands = map[string]bool{
+	driverutil.FindOp:      true,
+	driverutil.AggregateOp: true,
+	driverutil.CountOp:     true,
+	driverutil.DistinctOp:  true,
+}
+
+// readAttempt is the round trip of a read that can be hedged to a server.
+type readAttempt struct {
+	srvr         Server
+	conn         Connection
+	desc         description.SelectedServer
+	finishedInfo finishedInformation
+	startedTime  time.Time
+
+	cancel context.CancelFunc
+	res    bsoncore.Document
+	err    error
+}
+
+// replied returns true if the server replied to the read, even with a command error, as opposed to
+// the round trip failing.
+func (ra *readAttempt) replied() bool {
+	if ra.err == nil {
+		return true
+	}
+	var derr Error
+	return errors.As(ra.err, &derr) && !derr.NetworkError()
+}
+
+// hedgeDelay returns the deployment that hedges the operation and how long to wait for the reply of
+// the server before hedging it, or false if the operation can't be hedged. Only idempotent reads
+// sent to a replica set with a read preference other than primary are hedged, and not those that
+// run in a transaction, use a session pinned to a server or a connection, or are decrypted.
+func (op Operation) hedgeDelay(srvr Server, moreToCome bool) (ReadHedger, time.Duration, bool) {
+	hedger, ok := op.Deployment.(ReadHedger)
+	if !ok || moreToCome || op.Type != Read || op.IsOutputAggregate || !hedgedCommands[op.Name] || op.Crypt != nil {
+		return nil, 0, false
+	}
+	if kind := op.Deployment.Kind(); kind != description.ReplicaSetWithPrimary && kind != description.ReplicaSetNoPrimary {
+		return nil, 0, false
+	}
+	if op.Client != nil &&
+		(op.Client.TransactionRunning() || op.Client.PinnedServer != nil || op.Client.PinnedConnection != nil) {
+		return nil, 0, false
+	}
+	if rp := op.ReadPreference; rp == nil || rp.Mode() == readpref.PrimaryMode {
+		return nil, 0, false
+	}
+
+	delay, ok := hedger.HedgeDelay(srvr)
+	return hedger, delay, ok
+}
+
+// hedgeSelector returns the selector of the servers a read can be hedged to. Within a causally
+// consistent session, the servers whose last write is older than the operation time of the session
+// are not selected, as they would wait to catch up with it before running the read.
+func (op Operation) hedgeSelector() description.ServerSelector {
+	selector := op.serverSelector()
+	if op.Client == nil || !op.Client.Consistent || op.Client.OperationTime == nil {
+		return selector
+	}
+
+	opTime := time.Unix(int64(op.Client.OperationTime.T), 0)
+	caughtUp := description.ServerSelectorFunc(func(_ description.Topology, candidates []description.Server) ([]description.Server, error) {
+		servers := make([]description.Server, 0, len(candidates))
+		for _, s := range candidates {
+			if s.LastWriteTime.IsZero() || !s.LastWriteTime.Before(opTime) {
+				servers = append(servers, s)
+			}
+		}
+		return servers, nil
+	})
+	return description.CompositeSelector([]description.ServerSelector{selector, caughtUp})
+}
+
+// hedgedRoundTrip sends the wire message of a read to the server of the primary attempt and, if
+// the server has not replied after the delay, sends the read to a second server selected by the
+// hedger. It returns the attempt of the first server that replied, or the primary attempt if
+// neither replied. The other attempt is interrupted by closing its connection, and the cursor it
+// opened is killed if it replied too.
+//
+// The round trips don't update the session, which is only updated from the reply of the returned
+// attempt, so that the other reply doesn't advance its operation time or set its snapshot time.
+func (op Operation) hedgedRoundTrip(
+	ctx context.Context,
+	rtCtx context.Context,
+	hedger ReadHedger,
+	delay time.Duration,
+	primary *readAttempt,
+	wm []byte,
+	attempt int,
+) *readAttempt {
+	rtOp := op
+	rtOp.Client = nil
+
+	results := make(chan *readAttempt, 2)
+	start := func(ctx context.Context, a *readAttempt, wm []byte) {
+		ctx, a.cancel = context.WithCancel(ctx)
+		go func() {
+			a.res, a.err = rtOp.roundTrip(ctx, a.conn, wm)
+			results <- a
+		}()
+	}
+
+	start(rtCtx, primary, wm)
+	pending := 1
+	var finished []*readAttempt
+
+	var hedge *readAttempt
+	timer := time.NewTimer(delay)
+	select {
+	case <-timer.C:
+		var hedgeCtx context.Context
+		var hedgeWM []byte
+		var err error
+		hedge, hedgeCtx, hedgeWM, err = op.startHedge(ctx, hedger, primary, attempt)
+		switch {
+		case hedge == nil:
+		case err != nil:
+			// The hedge failed before its round trip because of an injected fault.
+			hedge.cancel = func() {}
+			hedge.err = err
+			finished = append(finished, hedge)
+		default:
+			start(hedgeCtx, hedge, hedgeWM)
+			pending++
+		}
+	case a := <-results:
+		pending--
+		finished = append(finished, a)
+	}
+	timer.Stop()
+
+	var winner *readAttempt
+	for _, a := range finished {
+		if a.replied() {
+			winner = a
+			break
+		}
+	}
+	for winner == nil && pending > 0 {
+		a := <-results
+		pending--
+		finished = append(finished, a)
+		if a.replied() {
+			winner = a
+		}
+	}
+
+	// Interrupt the attempt that has not finished and wait for it, so that the wire message and the
+	// connections are not used after returning.
+	primary.cancel()
+	if hedge != nil {
+		hedge.cancel()
+	}
+	interrupted := pending > 0
+	for ; pending > 0; pending-- {
+		<-results
+	}
+
+	if winner == nil {
+		winner = primary
+	}
+	op.updateSession(winner.res)
+	if !winner.replied() && op.Client != nil {
+		op.Client.MarkDirty()
+	}
+
+	if hedge != nil {
+		loser := hedge
+		if winner == hedge {
+			loser = primary
+		}
+		op.finishLoser(ctx, loser, interrupted)
+	}
+	return winner
+}
+
+// startHedge selects a server to hedge a read to other than the server of the primary attempt,
+// checks out a connection to it and publishes the started event of the read. It returns the
+// attempt of the hedge with the context and the wire message of its round trip, or nil if the read
+// can't be hedged. It returns an error if a fault was injected instead of the round trip.
+func (op Operation) startHedge(
+	ctx context.Context,
+	hedger ReadHedger,
+	primary *readAttempt,
+	attempt int,
+) (*readAttempt, context.Context, []byte, error) {
+	srvr := hedger.SelectHedgeServer(ctx, op.hedgeSelector(), primary.desc.Server.Addr)
+	if srvr == nil {
+		return nil, nil, nil, nil
+	}
+	conn, err := srvr.Connection(ctx)
+	if err != nil {
+		return nil, nil, nil, nil
+	}
+
+	maxTimeMS, err := op.calculateMaxTimeMS(ctx, srvr.RTTMonitor())
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, nil
+	}
+	if conn.Description().IsCryptd {
+		maxTimeMS = 0
+	}
+
+	desc := description.SelectedServer{Server: conn.Description(), Kind: op.Deployment.Kind()}
+	wm, startedInfo, err := op.createWireMessage(ctx, maxTimeMS, nil, desc, conn, wiremessage.NextRequestID())
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, nil
+	}
+	if compressor, ok := conn.(Compressor); ok && op.canCompress(op.Name) {
+		if wm, err = compressor.CompressWireMessage(wm, nil); err != nil {
+			_ = conn.Close()
+			return nil, nil, nil, nil
+		}
+	}
+
+	startedInfo.connID = conn.ID()
+	startedInfo.driverConnectionID = conn.DriverConnectionID()
+	startedInfo.cmdName = op.getCommandName(startedInfo.cmd)
+	startedInfo.redacted = op.redactCommand(startedInfo.cmdName, startedInfo.cmd)
+	startedInfo.serviceID = conn.Description().ServiceID
+	startedInfo.serverConnID = conn.ServerConnectionID()
+	startedInfo.serverAddress = conn.Description().Addr
+	startedInfo.attempt = attempt
+
+	op.publishStartedEvent(ctx, startedInfo)
+
+	hedge := &readAttempt{
+		srvr: srvr,
+		conn: conn,
+		desc: desc,
+		finishedInfo: finishedInformation{
+			cmdName:            startedInfo.cmdName,
+			driverConnectionID: startedInfo.driverConnectionID,
+			requestID:          startedInfo.requestID,
+			connID:             startedInfo.connID,
+			serverConnID:       startedInfo.serverConnID,
+			redacted:           startedInfo.redacted,
+			serviceID:          startedInfo.serviceID,
+			serverAddress:      desc.Server.Addr,
+		},
+		startedTime: time.Now(),
+	}
+	if tracker, ok := op.readTracker(srvr); ok {
+		tracker.StartRead()
+	}
+	rtCtx, err := op.injectFault(ctx, srvr, conn, startedInfo)
+	return hedge, rtCtx, wm, err
+}
+
+// finishLoser publishes the finished event of the attempt of a hedged read whose reply was not
+// used and releases its connection. If the attempt was interrupted, the session is marked dirty as
+// the server may still be running the read, and the cursor opened by the read is killed if it
+// replied anyway. Otherwise, the attempt failed and its error is processed by its server.
+func (op Operation) finishLoser(ctx context.Context, loser *readAttempt, interrupted bool) {
+	info := loser.finishedInfo
+	info.response = loser.res
+	info.cmdErr = loser.err
+	info.duration = time.Since(loser.startedTime)
+	op.publishFinishedEvent(ctx, info)
+
+	// The outcome of an attempt interrupted before the server replied is unknown, so it is not recorded.
+	if tracker, ok := op.readTracker(loser.srvr); ok && (!interrupted || loser.replied()) {
+		tracker.FinishRead(loser.err)
+	}
+
+	switch {
+	case interrupted && loser.err == nil:
+		op.killHedgedCursor(ctx, loser.conn, loser.res)
+	case interrupted:
+		if op.Client != nil {
+			op.Client.MarkDirty()
+		}
+	default:
+		if ep, ok := loser.srvr.(ErrorProcessor); ok {
+			_ = ep.ProcessError(loser.err, loser.conn)
+		}
+	}
+	_ = loser.conn.Close()
+}
+
+// killHedgedCursor kills the cursor opened on the connection by a read whose reply was not used.
+func (op Operation) killHedgedCursor(ctx context.Context, conn Connection, res bsoncore.Document) {
+	cursor, ok := res.Lookup("cursor").DocumentOK()
+	if !ok {
+		return
+	}
+	id, ok := cursor.Lookup("id").Int64OK()
+	if !ok || id == 0 {
+		return
+	}
+	ns, ok := cursor.Lookup("ns").StringValueOK()
+	if !ok {
+		return
+	}
+	database, collection, ok := strings.Cut(ns, ".")
+	if !ok {
+		return
+	}
+
+	_ = Operation{
+		CommandFn: func(dst []byte, desc description.SelectedServer) ([]byte, error) {
+			dst = bsoncore.AppendStringElement(dst, "killCursors", collection)
+			dst = bsoncore.BuildArrayElement(dst, "cursors", bsoncore.Value{Type: bsontype.Int64, Data: bsoncore.AppendInt64(nil, id)})
+			return dst, nil
+		},
+		Database:           database,
+		Deployment:         SingleConnectionDeployment{C: conn},
+		Client:             op.Client,
+		Clock:              op.Clock,
+		Legacy:             LegacyKillCursors,
+		CommandMonitor:     op.CommandMonitor,
+		ServerAPI:          op.ServerAPI,
+		omitReadPreference: true,
+	}.Execute(ctx)
+}
@@ -13,6 +13,8 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,8 +30,10 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
 	"github.com/hongyuyang/mongo-go-driver/mongo/address"
 	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
+	"github.com/hongyuyang/mongo-go-driver/mongo/redact"
 	"github.com/hongyuyang/mongo-go-driver/mongo/writeconcern"
 	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/session"
@@ -102,6 +106,7 @@ type startedInformation struct {
 	redacted                 bool
 	serviceID                *primitive.ObjectID
 	serverAddress            address.Address
+	attempt                  int
 }
 
 // finishedInformation keeps track of all of the information necessary for monitoring success and failure events.
@@ -158,7 +163,7 @@ type ResponseInfo struct {
 	CurrentIndex          int
 }
 
-func redactStartedInformationCmd(op Operation, info startedInformation) bson.Raw {
+func redactStartedInformationCmd(op Operation, info startedInformation, policy event.Redactor) bson.Raw {
 	var cmdCopy bson.Raw
 
 	// Make a copy of the command. Redact if the command is security
@@ -176,19 +181,72 @@ func redactStartedInformationCmd(op Operation, info startedInformation) bson.Raw
 			// add back 0 byte and update length
 			cmdCopy, _ = bsoncore.AppendDocumentEnd(cmdCopy, 0)
 		}
+
+		// Remove the values selected by the user's redaction policy.
+		if redactionEnabled(policy) {
+			cmdCopy = policy.Redact(cmdCopy)
+		}
 	}
 
 	return cmdCopy
 }
 
-func redactFinishedInformationResponse(info finishedInformation) bson.Raw {
+func redactFinishedInformationResponse(info finishedInformation, policy event.Redactor) bson.Raw {
 	if !info.redacted {
-		return bson.Raw(info.response)
+		if !redactionEnabled(policy) {
+			return bson.Raw(info.response)
+		}
+		response := policy.Redact(bson.Raw(info.response))
+		return bson.Raw(errorMessagePolicy.Apply(bsoncore.Document(response)))
 	}
 
 	return bson.Raw{}
 }
 
+var _ event.Redactor = (*redact.Policy)(nil)
+
+// redactionEnabled returns true if the policy redacts any values.
+func redactionEnabled(policy event.Redactor) bool {
+	return policy != nil && policy.Enabled()
+}
+
+// errorMessagePolicy redacts the error messages of a reply, which can contain values of the command, like the key of a
+// duplicate key error. It is applied to the replies redacted by a policy that redacts any values.
+var errorMessagePolicy = redact.NewPolicy().AddPattern(
+	regexp.MustCompile(`^((writeErrors|writeConcernError)\.)?errmsg$`), redact.ReplaceWithPlaceholder)
+
+// redactFailure returns the message of the error of a failed command. If the policy redacts any values, the messages
+// returned by the server are replaced with redact.Placeholder, as they can contain values of the command, like the key
+// of a duplicate key error. The codes and names of the errors are kept.
+func redactFailure(err error, policy event.Redactor) string {
+	if !redactionEnabled(policy) {
+		return err.Error()
+	}
+
+	var serverErr Error
+	if errors.As(err, &serverErr) && !serverErr.NetworkError() {
+		serverErr.Message = redact.Placeholder
+		return serverErr.Error()
+	}
+
+	var writeErr WriteCommandError
+	if errors.As(err, &writeErr) {
+		writeErrors := make(WriteErrors, len(writeErr.WriteErrors))
+		for i, we := range writeErr.WriteErrors {
+			we.Message = redact.Placeholder
+			writeErrors[i] = we
+		}
+		writeErr.WriteErrors = writeErrors
+		if wce := writeErr.WriteConcernError; wce != nil {
+			redacted := *wce
+			redacted.Message = redact.Placeholder
+			writeErr.WriteConcernError = &redacted
+		}
+		return writeErr.Error()
+	}
+	return err.Error()
+}
+
 // Operation is used to execute an operation. It contains all of the common code required to
 // select a server, transform an operation into a command, write the command to a connection from
 // the selected server, read a response from that connection, process the response, and potentially
@@ -388,6 +446,41 @@ func (oss *opServerSelector) SelectServer(
 	return filteredServers, nil
 }
 
+// serverSelector returns the selector of the operation, or a selector based on its read preference if
+// it does not have one.
+func (op Operation) serverSelector() description.ServerSelector {
+	if op.Selector != nil {
+		return op.Selector
+	}
+
+	rp := op.ReadPreference
+	if rp == nil {
+		rp = readpref.Primary()
+	}
+	return description.CompositeSelector([]description.ServerSelector{
+		description.ReadPrefSelector(rp),
+		description.LatencySelector(defaultLocalThreshold),
+	})
+}
+
+// readTracker returns the tracker of the reads sent to the server if the server implements ReadTracker and the
+// operation is a read that can be sent to other servers.
+func (op Operation) readTracker(srvr Server) (ReadTracker, bool) {
+	tracker, ok := srvr.(ReadTracker)
+	if !ok || op.Type != Read || op.IsOutputAggregate {
+		return nil, false
+	}
+
+	switch op.Deployment.Kind() {
+	case description.Sharded:
+		return tracker, true
+	case description.ReplicaSetNoPrimary, description.ReplicaSetWithPrimary:
+		rp := op.ReadPreference
+		return tracker, rp != nil && rp.Mode() != readpref.PrimaryMode
+	}
+	return nil, false
+}
+
 // selectServer handles performing server selection for an operation.
 func (op Operation) selectServer(
 	ctx context.Context,
@@ -398,26 +491,20 @@ func (op Operation) selectServer(
 		return nil, err
 	}
 
-	selector := op.Selector
-	if selector == nil {
-		rp := op.ReadPreference
-		if rp == nil {
-			rp = readpref.Primary()
-		}
-		selector = description.CompositeSelector([]description.ServerSelector{
-			description.ReadPrefSelector(rp),
-			description.LatencySelector(defaultLocalThreshold),
-		})
-	}
-
 	oss := &opServerSelector{
-		selector:             selector,
+		selector:             op.serverSelector(),
 		deprioritizedServers: deprioritized,
 	}
 
 	ctx = logger.WithOperationName(ctx, op.Name)
 	ctx = logger.WithOperationID(ctx, requestID)
 
+	// The application server selector must not reject the server a session is pinned to, so disable it. It is also
+	// disabled for writes, which must go to the primary, so that it only routes reads.
+	if (op.Client != nil && op.Client.PinnedServer != nil) || op.Type != Read || op.IsOutputAggregate {
+		ctx = driverutil.WithServerSelector(ctx, nil)
+	}
+
 	return op.Deployment.SelectServer(ctx, oss)
 }
 
@@ -446,10 +533,11 @@ func (op Operation) getServerAndConnection(
 		return server, op.Client.PinnedConnection, nil
 	}
 
-	// Otherwise, default to checking out a connection from the server's pool.
+	// Otherwise, default to checking out a connection from the server's pool. Return the server with
+	// the error so the caller can apply its retry policy if the server rejected the operation.
 	conn, err := server.Connection(ctx)
 	if err != nil {
-		return nil, nil, err
+		return server, nil, err
 	}
 
 	// If we're in load balanced mode and this is the first operation in a transaction, pin the session to a connection.
@@ -559,17 +647,27 @@ func (op Operation) Execute(ctx context.Context) error {
 	retrySupported := false
 	first := true
 	currIndex := 0
+	// attempt is the number of the current attempt of the command and lastRequestID is the request ID of the last
+	// command sent, which are published to the command monitor when the command is retried.
+	attempt := 1
+	var lastRequestID int32
 
 	// deprioritizedServers are a running list of servers that should be
 	// deprioritized during server selection. Per the specifications, we should
 	// only ever deprioritize the "previous server".
 	var deprioritizedServers []description.Server
 
-	// resetForRetry records the error that caused the retry, decrements retries, and resets the
-	// retry loop variables to request a new server and a new connection for the next attempt.
-	resetForRetry := func(err error) {
-		retries--
+	// overloadRejections is the number of consecutive attempts rejected because the server was
+	// overloaded. Retries of those attempts do not count against retries.
+	overloadRejections := 0
+
+	// resetForAttempt records the error that caused the retry and resets the retry loop variables to
+	// request a new server and a new connection for the next attempt.
+	resetForAttempt := func(err error) {
 		prevErr = err
+		attempt++
+		op.publishRetryEvent(ctx, lastRequestID, attempt, err)
+		lastRequestID = 0
 
 		// Set the previous indefinite error to be returned in any case where a retryable write error does not have a
 		// NoWritesPerfomed label (the definite case).
@@ -607,6 +705,12 @@ func (op Operation) Execute(ctx context.Context) error {
 		conn = nil
 	}
 
+	// resetForRetry decrements retries and resets the retry loop variables for the next attempt.
+	resetForRetry := func(err error) {
+		retries--
+		resetForAttempt(err)
+	}
+
 	wm := memoryPool.Get().(*[]byte)
 	defer func() {
 		// Proper usage of a sync.Pool requires each entry to have approximately the same memory
@@ -635,6 +739,14 @@ func (op Operation) Execute(ctx context.Context) error {
 		if srvr == nil || conn == nil {
 			srvr, conn, err = op.getServerAndConnection(ctx, requestID, deprioritizedServers)
 			if err != nil {
+				// If the server rejected the operation because it is overloaded, retry it after the
+				// backoff delay of the server if its retry budget allows it.
+				if op.waitForOverloadRetry(ctx, srvr, err, overloadRejections+1) {
+					overloadRejections++
+					resetForAttempt(err)
+					continue
+				}
+
 				// If the returned error is retryable and there are retries remaining (negative
 				// retries means retry indefinitely), then retry the operation. Set the server
 				// and connection to nil to request a new server and connection.
@@ -750,6 +862,8 @@ func (op Operation) Execute(ctx context.Context) error {
 		startedInfo.serviceID = conn.Description().ServiceID
 		startedInfo.serverConnID = conn.ServerConnectionID()
 		startedInfo.serverAddress = conn.Description().Addr
+		startedInfo.attempt = attempt
+		lastRequestID = startedInfo.requestID
 
 		op.publishStartedEvent(ctx, startedInfo)
 
@@ -797,15 +911,53 @@ func (op Operation) Execute(ctx context.Context) error {
 			}
 		}
 
+		var rtCtx context.Context
 		if err == nil {
-			// roundtrip using either the full roundTripper or a special one for when the moreToCome
-			// flag is set
-			roundTrip := op.roundTrip
-			if moreToCome {
-				roundTrip = op.moreToComeRoundTrip
+			if tracker, ok := op.readTracker(srvr); ok {
+				tracker.StartRead()
 			}
-			res, err = roundTrip(ctx, conn, *wm)
+			rtCtx, err = op.injectFault(ctx, srvr, conn, startedInfo)
+		}
 
+		if err == nil {
+			if hedger, delay, ok := op.hedgeDelay(srvr, moreToCome); ok {
+				// Hedge the read to a second server if the server has not replied after the delay, and
+				// continue with the attempt of the server that replied first.
+				primary := &readAttempt{
+					srvr:         srvr,
+					conn:         conn,
+					desc:         desc,
+					finishedInfo: finishedInfo,
+					startedTime:  startedTime,
+				}
+				winner := op.hedgedRoundTrip(ctx, rtCtx, hedger, delay, primary, *wm, attempt)
+				if winner != primary {
+					defer winner.conn.Close()
+					srvr, conn, desc = winner.srvr, winner.conn, winner.desc
+					finishedInfo, startedTime = winner.finishedInfo, winner.startedTime
+				}
+				res, err = winner.res, winner.err
+			} else {
+				// roundtrip using either the full roundTripper or a special one for when the moreToCome
+				// flag is set
+				roundTrip := op.roundTrip
+				if moreToCome {
+					roundTrip = op.moreToComeRoundTrip
+				}
+				res, err = roundTrip(rtCtx, conn, *wm)
+			}
+
+			if tracker, ok := op.readTracker(srvr); ok {
+				tracker.FinishRead(err)
+			}
+			if ep, ok := srvr.(ErrorProcessor); ok {
+				_ = ep.ProcessError(err, conn)
+			}
+		} else if _, ok := err.(Error); ok {
+			// Injected server errors change the server state like the errors returned by the server.
+			if tracker, ok := op.readTracker(srvr); ok {
+				tracker.FinishRead(err)
+			}
 			if ep, ok := srvr.(ErrorProcessor); ok {
 				_ = ep.ProcessError(err, conn)
 			}
@@ -939,6 +1091,16 @@ func (op Operation) Execute(ctx context.Context) error {
 				retryableErr = tt.RetryableRead()
 			}
 
+			// If the server rejected the command because it is overloaded, retry it after the backoff
+			// delay of the server if its retry budget allows it. The command was not executed, so it can
+			// be retried even if retries are not supported for the operation.
+			if op.waitForOverloadRetry(ctx, srvr, tt, overloadRejections+1) {
+				overloadRejections++
+				resetForAttempt(tt)
+				continue
+			}
+			overloadRejections = 0
+
 			// If retries are supported for the current operation on the first server description,
 			// the error is considered retryable, and there are retries remaining (negative retries
 			// means retry indefinitely), then retry the operation.
@@ -1030,6 +1192,8 @@ func (op Operation) Execute(ctx context.Context) error {
 			}
 			currIndex += len(op.Batches.Current)
 			op.Batches.ClearBatch()
+			attempt = 1
+			lastRequestID = 0
 			continue
 		}
 		break
@@ -1040,6 +1204,40 @@ func (op Operation) Execute(ctx context.Context) error {
 	return nil
 }
 
+// waitForOverloadRetry reports whether a command rejected the given number of consecutive times
+// because the server is overloaded can be retried, after waiting for the backoff delay of the
+// server. Commands are not retried within transactions or if the backoff delay would exceed the
+// context deadline.
+func (op Operation) waitForOverloadRetry(ctx context.Context, srvr Server, err error, rejections int) bool {
+	var le labeledError
+	if !errors.As(err, &le) || !le.HasErrorLabel(SystemOverloadedError) || !le.HasErrorLabel(RetryableError) {
+		return false
+	}
+	if op.Client != nil && op.Client.TransactionRunning() {
+		return false
+	}
+	retrier, ok := srvr.(OverloadRetrier)
+	if !ok {
+		return false
+	}
+	delay, ok := retrier.OverloadRetryBackoff(rejections)
+	if !ok {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // Retryable writes are supported if the server supports sessions, the operation is not
 // within a transaction, and the write is acknowledged
 func (op Operation) retryable(desc description.Server) bool {
@@ -1064,6 +1262,66 @@ func (op Operation) retryable(desc description.Server) bool {
 	return false
 }
 
+// faultInjector is implemented by the servers that inject faults into the commands sent to them.
+type faultInjector interface {
+	FaultInjector() *faultinject.Injector
+}
+
+// injectFault applies the fault injected by the server's fault injector into the command described by info, if any.
+// It waits for the injected latency and returns the injected server error, or the context to perform the round trip
+// with.
+func (op Operation) injectFault(
+	ctx context.Context,
+	srvr Server,
+	conn Connection,
+	info startedInformation,
+) (context.Context, error) {
+	// Check whether the injector has any rules before parsing the command, so that commands are not slowed down
+	// while fault injection is not used.
+	fi, ok := srvr.(faultInjector)
+	if !ok || !fi.FaultInjector().Active() {
+		return ctx, nil
+	}
+
+	// The collection is the value of the command name element for most commands, or the "collection" element for
+	// getMore.
+	var coll string
+	if info.cmdName == "getMore" {
+		coll, _ = info.cmd.Lookup("collection").StringValueOK()
+	} else if elem, err := info.cmd.IndexErr(0); err == nil {
+		coll, _ = elem.Value().StringValueOK()
+	}
+
+	fault := fi.FaultInjector().CommandFault(faultinject.Command{
+		Name:       info.cmdName,
+		Database:   op.Database,
+		Collection: coll,
+		Address:    conn.Address(),
+	})
+
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx, ctx.Err()
+		}
+	}
+	if fault.Error != nil {
+		return ctx, Error{
+			Code:    fault.Error.Code,
+			Name:    fault.Error.Name,
+			Message: fault.Error.Message,
+			Labels:  append([]string(nil), fault.Error.Labels...),
+		}
+	}
+	if fault.CloseConnection {
+		return driverutil.WithCloseConnection(ctx), nil
+	}
+	return ctx, nil
+}
+
 // roundTrip writes a wiremessage to the connection and then reads a wiremessage. The wm parameter
 // is reused when reading the wiremessage.
 func (op Operation) roundTrip(ctx context.Context, conn Connection, wm []byte) ([]byte, error) {
@@ -1103,14 +1361,7 @@ func (op Operation) readWireMessage(ctx context.Context, conn Connection) (resul
 	res, err := op.decodeResult(ctx, opcode, rem)
 	// Update cluster/operation time and recovery tokens before handling the error to ensure we're properly updating
 	// everything.
-	op.updateClusterTimes(res)
-	op.updateOperationTime(res)
-	op.Client.UpdateRecoveryToken(bson.Raw(res))
-
-	// Update snapshot time if operation was a "find", "aggregate" or "distinct".
-	if op.Name == driverutil.FindOp || op.Name == driverutil.AggregateOp || op.Name == driverutil.DistinctOp {
-		op.Client.UpdateSnapshotTime(res)
-	}
+	op.updateSession(res)
 
 	if err != nil {
 		return res, err
@@ -1123,6 +1374,19 @@ func (op Operation) readWireMessage(ctx context.Context, conn Connection) (resul
 	return res, err
 }
 
+// updateSession updates the cluster and operation times, the recovery token and the snapshot time
+// of the session and the cluster clock attached to this operation from a response.
+func (op Operation) updateSession(res bsoncore.Document) {
+	op.updateClusterTimes(res)
+	op.updateOperationTime(res)
+	op.Client.UpdateRecoveryToken(bson.Raw(res))
+
+	// Update snapshot time if operation was a "find", "aggregate" or "distinct".
+	if op.Name == driverutil.FindOp || op.Name == driverutil.AggregateOp || op.Name == driverutil.DistinctOp {
+		op.Client.UpdateSnapshotTime(res)
+	}
+}
+
 // networkError wraps the provided error in an Error with label "NetworkError" and, if a transaction
 // is running or committing, the appropriate transaction state labels. The returned error indicates
 // the operation should be retried for reads and writes. If err is nil, networkError returns nil.
@@ -1395,7 +1659,12 @@ func (op Operation) createWireMessage(
 // has already been added and does not add the final 0 byte.
 func (op Operation) addCommandFields(ctx context.Context, dst []byte, desc description.SelectedServer) ([]byte, error) {
 	if !op.shouldEncrypt() {
-		return op.CommandFn(dst, desc)
+		start := len(dst)
+		dst, err := op.CommandFn(dst, desc)
+		if err != nil {
+			return dst, err
+		}
+		return addOperationLabels(ctx, dst, start, desc), nil
 	}
 
 	if desc.WireVersion.Max < cryptMinWireVersion {
@@ -1409,6 +1678,7 @@ func (op Operation) addCommandFields(ctx context.Context, dst []byte, desc descr
 	if err != nil {
 		return dst, err
 	}
+	cmdDst = addOperationLabels(ctx, cmdDst, int(cidx)+4, desc)
 	// use a BSON array instead of a type 1 payload because mongocryptd will convert to arrays regardless
 	if op.Batches != nil && len(op.Batches.Current) > 0 {
 		cmdDst = op.addBatchArray(cmdDst)
@@ -1425,6 +1695,82 @@ func (op Operation) addCommandFields(ctx context.Context, dst []byte, desc descr
 	return dst, nil
 }
 
+// addOperationLabels adds the operation labels of the context to the comment of the command whose elements start at
+// dst[start:], as specified by the comment policy of the labels. The elements must not include the final 0 byte of the
+// command. Labels are only added for servers that support comments on all commands.
+func addOperationLabels(ctx context.Context, dst []byte, start int, desc description.SelectedServer) []byte {
+	labels, ok := driverutil.OperationLabelsFromContext(ctx)
+	if !ok || labels.CommentPolicy == driverutil.LabelsCommentOmit {
+		return dst
+	}
+	if desc.WireVersion == nil || desc.WireVersion.Max < 9 {
+		return dst
+	}
+
+	// Find the existing comment.
+	var comment bsoncore.Value
+	commentStart, commentEnd := -1, -1
+	for rem, pos := dst[start:], start; len(rem) > 0; {
+		elem, next, ok := bsoncore.ReadElement(rem)
+		if !ok {
+			return dst
+		}
+		if elem.Key() == "comment" {
+			comment = elem.Value()
+			commentStart, commentEnd = pos, pos+len(elem)
+			break
+		}
+		pos += len(elem)
+		rem = next
+	}
+
+	var merged bsoncore.Document
+	switch {
+	case commentStart < 0 || labels.CommentPolicy == driverutil.LabelsCommentReplace:
+		merged = labelsDocument(labels.Labels, nil)
+	case labels.CommentPolicy == driverutil.LabelsCommentPreserve:
+		return dst
+	case comment.Type == bsontype.EmbeddedDocument:
+		merged = labelsDocument(labels.Labels, comment.Document())
+	default:
+		merged = labelsDocument(labels.Labels, bsoncore.NewDocumentBuilder().AppendValue("comment", comment).Build())
+	}
+
+	if commentStart >= 0 {
+		// The original comment is backed by dst, so it is removed only after the merged comment was built.
+		dst = append(dst[:commentStart], dst[commentEnd:]...)
+	}
+	return bsoncore.AppendDocumentElement(dst, "comment", merged)
+}
+
+// labelsDocument returns a document with the fields of base followed by the labels that are not fields of base, in
+// sorted order.
+func labelsDocument(labels map[string]string, base bsoncore.Document) bsoncore.Document {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if base != nil {
+			if _, err := base.LookupErr(name); err == nil {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	idx, doc := bsoncore.AppendDocumentStart(nil)
+	if base != nil {
+		elems, _ := base.Elements()
+		for _, elem := range elems {
+			doc = append(doc, elem...)
+		}
+	}
+	for _, name := range names {
+		doc = bsoncore.AppendStringElement(doc, name, labels[name])
+	}
+	doc, _ = bsoncore.AppendDocumentEnd(doc, idx)
+	return doc
+}
+
 // addServerAPI adds the relevant fields for server API specification to the wire message in dst.
 func (op Operation) addServerAPI(dst []byte) []byte {
 	sa := op.ServerAPI
@@ -1949,11 +2295,13 @@ func (op Operation) canPublishStartedEvent() bool {
 // an unacknowledged write, a CommandSucceededEvent will be published as well. If started events are not being monitored,
 // no events are published.
 func (op Operation) publishStartedEvent(ctx context.Context, info startedInformation) {
+	labels, _ := driverutil.OperationLabelsFromContext(ctx)
+
 	// If logging is enabled for the command component at the debug level, log the command response.
 	if op.canLogCommandMessage() {
 		host, port, _ := net.SplitHostPort(info.serverAddress.String())
 
-		redactedCmd := redactStartedInformationCmd(op, info).String()
+		redactedCmd := redactStartedInformationCmd(op, info, op.Logger.Redaction).String()
 		formattedCmd := logger.FormatMessage(redactedCmd, op.Logger.MaxDocumentLength)
 
 		op.Logger.Print(logger.LevelDebug,
@@ -1969,6 +2317,7 @@ func (op Operation) publishStartedEvent(ctx context.Context, info startedInforma
 				ServerHost:         host,
 				ServerPort:         port,
 				ServiceID:          info.serviceID,
+				Labels:             labels.Labels,
 			},
 				logger.KeyCommand, formattedCmd)...)
 
@@ -1976,19 +2325,39 @@ func (op Operation) publishStartedEvent(ctx context.Context, info startedInforma
 
 	if op.canPublishStartedEvent() {
 		started := &event.CommandStartedEvent{
-			Command:              redactStartedInformationCmd(op, info),
+			Command:              redactStartedInformationCmd(op, info, op.CommandMonitor.Redaction),
 			DatabaseName:         op.Database,
 			CommandName:          info.cmdName,
 			RequestID:            int64(info.requestID),
 			ConnectionID:         info.connID,
+			DriverConnectionID:   info.driverConnectionID,
 			ServerConnectionID:   convertInt64PtrToInt32Ptr(info.serverConnID),
 			ServerConnectionID64: info.serverConnID,
 			ServiceID:            info.serviceID,
+			Labels:               labels.Labels,
+			Attempt:              info.attempt,
 		}
 		op.CommandMonitor.Started(ctx, started)
 	}
 }
 
+// publishRetryEvent publishes a CommandRetryEvent to the operation's command monitor if it monitors retries.
+func (op Operation) publishRetryEvent(ctx context.Context, requestID int32, attempt int, err error) {
+	if op.CommandMonitor == nil || op.CommandMonitor.Retry == nil {
+		return
+	}
+
+	labels, _ := driverutil.OperationLabelsFromContext(ctx)
+	op.CommandMonitor.Retry(ctx, &event.CommandRetryEvent{
+		CommandName:  op.Name,
+		DatabaseName: op.Database,
+		RequestID:    int64(requestID),
+		Attempt:      attempt,
+		Failure:      err,
+		Labels:       labels.Labels,
+	})
+}
+
 // canPublishFinishedEvent returns true if a CommandSucceededEvent can be
 // published for the given command. This is true if the command is not an
 // unacknowledged write and the command monitor is monitoring succeeded events.
@@ -2003,10 +2372,12 @@ func (op Operation) canPublishFinishedEvent(info finishedInformation) bool {
 // publishFinishedEvent publishes either a CommandSucceededEvent or a CommandFailedEvent to the operation's command
 // monitor if possible. If success/failure events aren't being monitored, no events are published.
 func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInformation) {
+	labels, _ := driverutil.OperationLabelsFromContext(ctx)
+
 	if op.canLogCommandMessage() && info.success() {
 		host, port, _ := net.SplitHostPort(info.serverAddress.String())
 
-		redactedReply := redactFinishedInformationResponse(info).String()
+		redactedReply := redactFinishedInformationResponse(info, op.Logger.Redaction).String()
 		formattedReply := logger.FormatMessage(redactedReply, op.Logger.MaxDocumentLength)
 
 		op.Logger.Print(logger.LevelDebug,
@@ -2022,6 +2393,7 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 				ServerHost:         host,
 				ServerPort:         port,
 				ServiceID:          info.serviceID,
+				Labels:             labels.Labels,
 			},
 				logger.KeyDurationMS, info.duration.Milliseconds(),
 				logger.KeyReply, formattedReply)...)
@@ -2030,7 +2402,7 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 	if op.canLogCommandMessage() && !info.success() {
 		host, port, _ := net.SplitHostPort(info.serverAddress.String())
 
-		formattedReply := logger.FormatMessage(info.cmdErr.Error(), op.Logger.MaxDocumentLength)
+		formattedReply := logger.FormatMessage(redactFailure(info.cmdErr, op.Logger.Redaction), op.Logger.MaxDocumentLength)
 
 		op.Logger.Print(logger.LevelDebug,
 			logger.ComponentCommand,
@@ -2045,6 +2417,7 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 				ServerHost:         host,
 				ServerPort:         port,
 				ServiceID:          info.serviceID,
+				Labels:             labels.Labels,
 			},
 				logger.KeyDurationMS, info.duration.Milliseconds(),
 				logger.KeyFailure, formattedReply)...)
@@ -2060,16 +2433,18 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 		DatabaseName:         op.Database,
 		RequestID:            int64(info.requestID),
 		ConnectionID:         info.connID,
+		DriverConnectionID:   info.driverConnectionID,
 		Duration:             info.duration,
 		DurationNanos:        info.duration.Nanoseconds(),
 		ServerConnectionID:   convertInt64PtrToInt32Ptr(info.serverConnID),
 		ServerConnectionID64: info.serverConnID,
 		ServiceID:            info.serviceID,
+		Labels:               labels.Labels,
 	}
 
 	if info.success() {
 		successEvent := &event.CommandSucceededEvent{
-			Reply:                redactFinishedInformationResponse(info),
+			Reply:                redactFinishedInformationResponse(info, op.CommandMonitor.Redaction),
 			CommandFinishedEvent: finished,
 		}
 		op.CommandMonitor.Succeeded(ctx, successEvent)
@@ -2078,9 +2453,13 @@ func (op Operation) publishFinishedEvent(ctx context.Context, info finishedInfor
 	}
 
 	failedEvent := &event.CommandFailedEvent{
-		Failure:              info.cmdErr.Error(),
+		Failure:              redactFailure(info.cmdErr, op.CommandMonitor.Redaction),
 		CommandFinishedEvent: finished,
 	}
+	var serverErr Error
+	if errors.As(info.cmdErr, &serverErr) {
+		failedEvent.FailureCode = serverErr.Code
+	}
 	op.CommandMonitor.Failed(ctx, failedEvent)
 }
 
@@ -9,6 +9,7 @@ package operation
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
@@ -33,6 +34,7 @@ type AbortTransaction struct {
 	writeConcern  *writeconcern.WriteConcern
 	retry         *driver.RetryMode
 	serverAPI     *driver.ServerAPIOptions
+	timeout       *time.Duration
 }
 
 // NewAbortTransaction constructs and returns a new AbortTransaction.
@@ -65,6 +67,7 @@ func (at *AbortTransaction) Execute(ctx context.Context) error {
 		Selector:          at.selector,
 		WriteConcern:      at.writeConcern,
 		ServerAPI:         at.serverAPI,
+		Timeout:           at.timeout,
 		Name:              driverutil.AbortTransactionOp,
 	}.Execute(ctx)
 
@@ -199,3 +202,13 @@ func (at *AbortTransaction) ServerAPI(serverAPI *driver.ServerAPIOptions) *Abort
 	at.serverAPI = serverAPI
 	return at
 }
+
+// Timeout sets the timeout for this operation.
+func (at *AbortTransaction) Timeout(timeout *time.Duration) *AbortTransaction {
+	if at == nil {
+		at = new(AbortTransaction)
+	}
+
+	at.timeout = timeout
+	return at
+}
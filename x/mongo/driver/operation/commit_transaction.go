@@ -34,6 +34,7 @@ type CommitTransaction struct {
 	writeConcern  *writeconcern.WriteConcern
 	retry         *driver.RetryMode
 	serverAPI     *driver.ServerAPIOptions
+	timeout       *time.Duration
 }
 
 // NewCommitTransaction constructs and returns a new CommitTransaction.
@@ -67,6 +68,7 @@ func (ct *CommitTransaction) Execute(ctx context.Context) error {
 		Selector:          ct.selector,
 		WriteConcern:      ct.writeConcern,
 		ServerAPI:         ct.serverAPI,
+		Timeout:           ct.timeout,
 		Name:              driverutil.CommitTransactionOp,
 	}.Execute(ctx)
 
@@ -201,3 +203,13 @@ func (ct *CommitTransaction) ServerAPI(serverAPI *driver.ServerAPIOptions) *Comm
 	ct.serverAPI = serverAPI
 	return ct
 }
+
+// Timeout sets the timeout for this operation.
+func (ct *CommitTransaction) Timeout(timeout *time.Duration) *CommitTransaction {
+	if ct == nil {
+		ct = new(CommitTransaction)
+	}
+
+	ct.timeout = timeout
+	return ct
+}
@@ -15,17 +15,22 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hongyuyang/mongo-go-driver/bson"
 	"github.com/hongyuyang/mongo-go-driver/bson/bsontype"
 	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
+	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
 	"github.com/hongyuyang/mongo-go-driver/internal/csot"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
 	"github.com/hongyuyang/mongo-go-driver/internal/handshake"
 	"github.com/hongyuyang/mongo-go-driver/internal/require"
 	"github.com/hongyuyang/mongo-go-driver/internal/uuid"
 	"github.com/hongyuyang/mongo-go-driver/mongo/address"
 	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readconcern"
 	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
+	"github.com/hongyuyang/mongo-go-driver/mongo/redact"
 	"github.com/hongyuyang/mongo-go-driver/mongo/writeconcern"
 	"github.com/hongyuyang/mongo-go-driver/tag"
 	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
@@ -101,6 +106,39 @@ func TestOperation(t *testing.T) {
 				t.Error("The selectServer method should use a default selector when not specified on Operation, but it passed <nil>.")
 			}
 		})
+		t.Run("disables the application server selector for writes", func(t *testing.T) {
+			want := new(mockServerSelector)
+			testCases := []struct {
+				name     string
+				op       Operation
+				disabled bool
+			}{
+				{"read", Operation{Type: Read}, false},
+				{"write", Operation{Type: Write}, true},
+				{"output aggregate", Operation{Type: Read, IsOutputAggregate: true}, true},
+			}
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					d := new(mockDeployment)
+					op := tc.op
+					op.CommandFn = func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil }
+					op.Deployment = d
+					op.Database = "testing"
+
+					ctx := driverutil.WithServerSelector(context.Background(), want)
+					_, err := op.selectServer(ctx, 1, nil)
+					noerr(t, err)
+
+					got, ok := driverutil.ServerSelectorFromContext(d.params.ctx)
+					require.True(t, ok)
+					if tc.disabled {
+						assert.Nil(t, got, "expected the application server selector to be disabled, got %v", got)
+					} else {
+						assert.Equal(t, want, got, "expected the application server selector %v, got %v", want, got)
+					}
+				})
+			}
+		})
 	})
 	t.Run("Validate", func(t *testing.T) {
 		cmdFn := func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil }
@@ -240,6 +278,76 @@ func TestOperation(t *testing.T) {
 			t.Errorf("WriteConcern elements do not match. got %v; want %v", got, want)
 		}
 	})
+	t.Run("addOperationLabels", func(t *testing.T) {
+		labels := map[string]string{"op": "checkout", "app": "shop"}
+		desc := description.SelectedServer{
+			Server: description.Server{WireVersion: &description.VersionRange{Max: 21}},
+		}
+		labelsDoc := bsoncore.NewDocumentBuilder().
+			AppendString("app", "shop").
+			AppendString("op", "checkout").
+			Build()
+
+		testCases := []struct {
+			name    string
+			policy  driverutil.LabelsCommentPolicy
+			comment bsoncore.Value
+			desc    description.SelectedServer
+			want    bsoncore.Value
+		}{
+			{"no comment", driverutil.LabelsCommentMerge, bsoncore.Value{}, desc, bsoncore.Value{
+				Type: bsontype.EmbeddedDocument, Data: labelsDoc,
+			}},
+			{"merge document", driverutil.LabelsCommentMerge,
+				bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.NewDocumentBuilder().
+					AppendString("op", "user").Build()},
+				desc,
+				bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.NewDocumentBuilder().
+					AppendString("op", "user").AppendString("app", "shop").Build()},
+			},
+			{"merge string", driverutil.LabelsCommentMerge, bsoncore.Value{
+				Type: bsontype.String, Data: bsoncore.AppendString(nil, "hi"),
+			}, desc, bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: bsoncore.NewDocumentBuilder().
+				AppendString("comment", "hi").AppendString("app", "shop").AppendString("op", "checkout").Build()},
+			},
+			{"preserve", driverutil.LabelsCommentPreserve, bsoncore.Value{
+				Type: bsontype.String, Data: bsoncore.AppendString(nil, "hi"),
+			}, desc, bsoncore.Value{Type: bsontype.String, Data: bsoncore.AppendString(nil, "hi")}},
+			{"replace", driverutil.LabelsCommentReplace, bsoncore.Value{
+				Type: bsontype.String, Data: bsoncore.AppendString(nil, "hi"),
+			}, desc, bsoncore.Value{Type: bsontype.EmbeddedDocument, Data: labelsDoc}},
+			{"omit", driverutil.LabelsCommentOmit, bsoncore.Value{}, desc, bsoncore.Value{}},
+			{"old server", driverutil.LabelsCommentMerge, bsoncore.Value{}, description.SelectedServer{
+				Server: description.Server{WireVersion: &description.VersionRange{Max: 8}},
+			}, bsoncore.Value{}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				ctx := driverutil.WithOperationLabels(context.Background(), driverutil.OperationLabels{
+					Labels:        labels,
+					CommentPolicy: tc.policy,
+				})
+
+				idx, cmd := bsoncore.AppendDocumentStart(nil)
+				cmd = bsoncore.AppendStringElement(cmd, "find", "coll")
+				if tc.comment.Type != 0 {
+					cmd = bsoncore.AppendValueElement(cmd, "comment", tc.comment)
+				}
+				cmd = bsoncore.AppendInt32Element(cmd, "limit", 1)
+				cmd = addOperationLabels(ctx, cmd, int(idx)+4, tc.desc)
+				cmd, _ = bsoncore.AppendDocumentEnd(cmd, idx)
+
+				got, err := bsoncore.Document(cmd).LookupErr("comment")
+				if tc.want.Type == 0 {
+					assert.NotNil(t, err, "expected no comment, got %v", got)
+					return
+				}
+				require.NoError(t, err, "expected comment")
+				assert.Equal(t, tc.want, got, "expected comment %v, got %v", tc.want, got)
+				assert.Equal(t, "find", bsoncore.Document(cmd).Index(0).Key(), "expected command name first")
+			})
+		}
+	})
 	t.Run("addSession", func(t *testing.T) { t.Skip("These tests should be covered by spec tests.") })
 	t.Run("addClusterTime", func(t *testing.T) {
 		t.Run("adds max cluster time", func(t *testing.T) {
@@ -683,6 +791,414 @@ func TestOperation(t *testing.T) {
 		assert.ErrorIs(t, err, ErrDeadlineWouldBeExceeded)
 		assert.ErrorIs(t, err, context.DeadlineExceeded)
 	})
+	t.Run("injected faults", func(t *testing.T) {
+		okResponse := createExhaustServerResponse(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 1),
+		), false)
+		newOperation := func(d Deployment, monitor *event.CommandMonitor) Operation {
+			retry := RetryOnce
+			return Operation{
+				CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendStringElement(dst, "find", "coll"), nil
+				},
+				Deployment:     d,
+				Database:       "db",
+				RetryMode:      &retry,
+				Type:           Read,
+				CommandMonitor: monitor,
+			}
+		}
+
+		t.Run("error is retried", func(t *testing.T) {
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: okResponse,
+			}
+			injector := faultinject.New(faultinject.Rule{
+				CommandNames: []string{"find"},
+				Namespace:    "db.coll",
+				Times:        1,
+				Error:        &faultinject.ServerError{Code: 91, Name: "ShutdownInProgress"},
+			})
+			d := new(mockDeployment)
+			d.returns.server = mockFaultInjectionServer{
+				mockServer: mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}},
+				injector:   injector,
+			}
+
+			var failures []error
+			monitor := &event.CommandMonitor{
+				Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+					failures = append(failures, errors.New(evt.Failure))
+				},
+			}
+			err := newOperation(d, monitor).Execute(context.Background())
+			assert.Nil(t, err, "expected the retry to succeed, got %v", err)
+			assert.Equal(t, 1, len(failures), "expected 1 failed command, got %d", len(failures))
+			assert.Equal(t, 0, len(injector.Rules()), "expected the rule to be removed after being applied once")
+			assert.NotNil(t, conn.pWriteWM, "expected the retry to be sent to the server")
+		})
+		t.Run("non-matching commands are sent", func(t *testing.T) {
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: okResponse,
+			}
+			injector := faultinject.New(faultinject.Rule{
+				Namespace: "db.other",
+				Error:     &faultinject.ServerError{Code: 91},
+			})
+			d := new(mockDeployment)
+			d.returns.server = mockFaultInjectionServer{
+				mockServer: mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}},
+				injector:   injector,
+			}
+
+			err := newOperation(d, nil).Execute(context.Background())
+			assert.Nil(t, err, "expected no error, got %v", err)
+			assert.Equal(t, 1, len(injector.Rules()), "expected the rule to be kept")
+		})
+		t.Run("latency respects the context", func(t *testing.T) {
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: okResponse,
+			}
+			d := new(mockDeployment)
+			d.returns.server = mockFaultInjectionServer{
+				mockServer: mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}},
+				injector:   faultinject.New(faultinject.Rule{Latency: time.Minute}),
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			err := newOperation(d, nil).Execute(ctx)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Nil(t, conn.pWriteWM, "expected the command not to be sent")
+		})
+	})
+	t.Run("overloaded errors", func(t *testing.T) {
+		okResponse := createExhaustServerResponse(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 1),
+		), false)
+		overloadedErr := &faultinject.ServerError{
+			Code:   462,
+			Name:   "IngressRequestRateLimitExceeded",
+			Labels: []string{SystemOverloadedError, RetryableError},
+		}
+		// The write is not retryable without a session, so only the retries of overloaded errors apply.
+		newOperation := func(d Deployment) Operation {
+			return Operation{
+				CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendStringElement(dst, "insert", "coll"), nil
+				},
+				Deployment: d,
+				Database:   "db",
+				Type:       Write,
+			}
+		}
+		newServer := func(conn Connection, maxRetries int, rules ...faultinject.Rule) *mockOverloadRetryServer {
+			return &mockOverloadRetryServer{
+				mockFaultInjectionServer: mockFaultInjectionServer{
+					mockServer: mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}},
+					injector:   faultinject.New(rules...),
+				},
+				maxRetries: maxRetries,
+			}
+		}
+
+		t.Run("are retried with backoff", func(t *testing.T) {
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: okResponse,
+			}
+			srvr := newServer(conn, 2, faultinject.Rule{Times: 2, Error: overloadedErr})
+			d := new(mockDeployment)
+			d.returns.server = srvr
+
+			err := newOperation(d).Execute(context.Background())
+			assert.Nil(t, err, "expected the retries to succeed, got %v", err)
+			assert.Equal(t, []int{1, 2}, srvr.rejections, "expected the backoff of 2 consecutive rejections")
+			assert.NotNil(t, conn.pWriteWM, "expected the retry to be sent to the server")
+		})
+		t.Run("are returned when the retries are used up", func(t *testing.T) {
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: okResponse,
+			}
+			srvr := newServer(conn, 1, faultinject.Rule{Error: overloadedErr})
+			d := new(mockDeployment)
+			d.returns.server = srvr
+
+			err := newOperation(d).Execute(context.Background())
+			var derr Error
+			assert.True(t, errors.As(err, &derr), "expected a driver.Error, got %v", err)
+			assert.True(t, derr.HasErrorLabel(SystemOverloadedError), "expected the SystemOverloadedError label")
+			assert.Equal(t, []int{1, 2}, srvr.rejections, "expected the backoff of 2 consecutive rejections")
+		})
+		t.Run("from the client are retried", func(t *testing.T) {
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: okResponse,
+			}
+			srvr := newServer(conn, 2)
+			srvr.connectionErrs = []error{Error{
+				Message: "concurrency limit reached",
+				Labels:  []string{SystemOverloadedError, RetryableError},
+				Wrapped: ErrOverloaded,
+			}}
+			d := new(mockDeployment)
+			d.returns.server = srvr
+
+			err := newOperation(d).Execute(context.Background())
+			assert.Nil(t, err, "expected the retry to succeed, got %v", err)
+			assert.Equal(t, []int{1}, srvr.rejections, "expected the backoff of 1 rejection")
+		})
+	})
+	t.Run("hedged reads", func(t *testing.T) {
+		cursorResponse := func(id int64) []byte {
+			return createExhaustServerResponse(bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendDocumentElement(nil, "cursor", bsoncore.BuildDocumentFromElements(nil,
+					bsoncore.AppendInt64Element(nil, "id", id),
+					bsoncore.AppendStringElement(nil, "ns", "db.coll"),
+					bsoncore.BuildArrayElement(nil, "firstBatch"),
+				)),
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+			), false)
+		}
+		newConn := func(addr address.Address, delay time.Duration, res []byte) *mockHedgeConnection {
+			return &mockHedgeConnection{
+				mockConnection: &mockConnection{
+					rDesc:   description.Server{Addr: addr, WireVersion: &description.VersionRange{Max: 17}},
+					rReadWM: res,
+				},
+				delay: delay,
+			}
+		}
+		newServer := func(conn Connection) Server {
+			return mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}}
+		}
+		newOperation := func(d Deployment, rp *readpref.ReadPref, servers *[]Server) Operation {
+			return Operation{
+				CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendStringElement(dst, "find", "coll"), nil
+				},
+				ProcessResponseFn: func(info ResponseInfo) error {
+					*servers = append(*servers, info.Server)
+					return nil
+				},
+				Deployment:     d,
+				Database:       "db",
+				Type:           Read,
+				Name:           driverutil.FindOp,
+				ReadPreference: rp,
+			}
+		}
+
+		t.Run("are sent to a second server after the delay", func(t *testing.T) {
+			primary := newConn("primary:27017", time.Hour, cursorResponse(0))
+			hedge := newConn("hedge:27017", 0, cursorResponse(0))
+			d := &mockHedgeDeployment{delay: time.Millisecond, hedge: newServer(hedge)}
+			d.returns.server = newServer(primary)
+			d.returns.kind = description.ReplicaSetWithPrimary
+
+			var servers []Server
+			err := newOperation(d, readpref.Nearest(), &servers).Execute(context.Background())
+			assert.Nil(t, err, "expected the hedged read to succeed, got %v", err)
+			assert.Equal(t, []Server{d.hedge}, servers, "expected the reply of the hedge to be used")
+			assert.Equal(t, address.Address("primary:27017"), d.excluded, "expected the first server to be excluded")
+			assert.True(t, primary.interrupted, "expected the read of the first server to be interrupted")
+			assert.NotNil(t, hedge.pWriteWM, "expected the read to be sent to the second server")
+		})
+		t.Run("are not sent if the server replies before the delay", func(t *testing.T) {
+			primary := newConn("primary:27017", 0, cursorResponse(0))
+			d := &mockHedgeDeployment{delay: time.Hour}
+			d.returns.server = newServer(primary)
+			d.returns.kind = description.ReplicaSetWithPrimary
+
+			var servers []Server
+			err := newOperation(d, readpref.Nearest(), &servers).Execute(context.Background())
+			assert.Nil(t, err, "expected the read to succeed, got %v", err)
+			assert.Equal(t, []Server{d.returns.server}, servers, "expected the reply of the first server to be used")
+			assert.Equal(t, 0, d.selections, "expected no server to be selected for a hedge")
+		})
+		t.Run("kill the cursor of the other reply", func(t *testing.T) {
+			primary := newConn("primary:27017", 50*time.Millisecond, cursorResponse(42))
+			primary.ignoreCancel = true
+			hedge := newConn("hedge:27017", 0, cursorResponse(7))
+			d := &mockHedgeDeployment{delay: time.Millisecond, hedge: newServer(hedge)}
+			d.returns.server = newServer(primary)
+			d.returns.kind = description.ReplicaSetWithPrimary
+
+			var servers []Server
+			err := newOperation(d, readpref.SecondaryPreferred(), &servers).Execute(context.Background())
+			assert.Nil(t, err, "expected the hedged read to succeed, got %v", err)
+			assert.Equal(t, []Server{d.hedge}, servers, "expected the reply of the hedge to be used")
+
+			cmd, err := bsoncore.Document(primary.pWriteWM[21:]).LookupErr("killCursors")
+			require.NoError(t, err, "expected killCursors to be sent to the first server")
+			assert.Equal(t, "coll", cmd.StringValue(), "expected the cursor of the collection to be killed")
+		})
+		t.Run("are not sent with a primary read preference", func(t *testing.T) {
+			primary := newConn("primary:27017", 10*time.Millisecond, cursorResponse(0))
+			d := &mockHedgeDeployment{delay: time.Millisecond, hedge: newServer(newConn("hedge:27017", 0, nil))}
+			d.returns.server = newServer(primary)
+			d.returns.kind = description.ReplicaSetWithPrimary
+
+			var servers []Server
+			err := newOperation(d, readpref.Primary(), &servers).Execute(context.Background())
+			assert.Nil(t, err, "expected the read to succeed, got %v", err)
+			assert.Equal(t, 0, d.selections, "expected no server to be selected for a hedge")
+		})
+		t.Run("are only sent to servers caught up with a causally consistent session", func(t *testing.T) {
+			sess, err := session.NewClientSession(session.NewPool(nil), uuid.UUID{})
+			require.NoError(t, err, "error creating session")
+			opTime := time.Date(2024, 1, 1, 0, 0, 10, 0, time.UTC)
+			require.NoError(t, sess.AdvanceOperationTime(&primitive.Timestamp{T: uint32(opTime.Unix())}))
+
+			stale := description.Server{Addr: "stale:27017", Kind: description.RSSecondary, LastWriteTime: opTime.Add(-time.Second)}
+			caughtUp := description.Server{Addr: "caughtup:27017", Kind: description.RSSecondary, LastWriteTime: opTime}
+			topo := description.Topology{Kind: description.ReplicaSetWithPrimary, Servers: []description.Server{stale, caughtUp}}
+			op := Operation{ReadPreference: readpref.Secondary(), Client: sess}
+
+			selected, err := op.hedgeSelector().SelectServer(topo, topo.Servers)
+			require.NoError(t, err, "error selecting servers")
+			assert.Equal(t, []description.Server{caughtUp}, selected, "expected the stale server not to be selected")
+
+			sess.Consistent = false
+			selected, err = op.hedgeSelector().SelectServer(topo, topo.Servers)
+			require.NoError(t, err, "error selecting servers")
+			assert.Len(t, selected, 2, "expected all servers to be selected without causal consistency")
+		})
+	})
+	t.Run("redacted duplicate key errors", func(t *testing.T) {
+		const errmsg = `E11000 duplicate key error collection: db.coll index: email_1 dup key: { email: "a@example.com" }`
+		policy := redact.NewPolicy().AddPath("email", redact.ReplaceWithPlaceholder)
+
+		execute := func(t *testing.T, reply bsoncore.Document, monitor *event.CommandMonitor) {
+			t.Helper()
+
+			conn := &mockConnection{
+				rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+				rReadWM: createExhaustServerResponse(reply, false),
+			}
+			d := new(mockDeployment)
+			d.returns.server = mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}}
+
+			_ = Operation{
+				CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+					return bsoncore.AppendStringElement(dst, "insert", "coll"), nil
+				},
+				Deployment:     d,
+				Database:       "db",
+				Type:           Write,
+				CommandMonitor: monitor,
+			}.Execute(context.Background())
+		}
+
+		t.Run("failure", func(t *testing.T) {
+			var failure string
+			execute(t, bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 0),
+				bsoncore.AppendInt32Element(nil, "code", 11000),
+				bsoncore.AppendStringElement(nil, "codeName", "DuplicateKey"),
+				bsoncore.AppendStringElement(nil, "errmsg", errmsg),
+			), &event.CommandMonitor{
+				Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+					failure = evt.Failure
+				},
+				Redaction: policy,
+			})
+
+			assert.NotContains(t, failure, "a@example.com", "expected the key to be redacted from the failure")
+			assert.Equal(t, "(DuplicateKey) "+redact.Placeholder, failure, "expected the message to be redacted")
+		})
+		t.Run("write errors of the reply", func(t *testing.T) {
+			var reply bson.Raw
+			execute(t, bsoncore.BuildDocumentFromElements(nil,
+				bsoncore.AppendInt32Element(nil, "ok", 1),
+				bsoncore.AppendInt32Element(nil, "n", 0),
+				bsoncore.BuildArrayElement(nil, "writeErrors", bsoncore.Value{
+					Type: bsontype.EmbeddedDocument,
+					Data: bsoncore.BuildDocumentFromElements(nil,
+						bsoncore.AppendInt32Element(nil, "index", 0),
+						bsoncore.AppendInt32Element(nil, "code", 11000),
+						bsoncore.AppendStringElement(nil, "errmsg", errmsg),
+					),
+				}),
+			), &event.CommandMonitor{
+				Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+					reply = evt.Reply
+				},
+				Redaction: policy,
+			})
+
+			require.NotNil(t, reply, "expected a succeeded event")
+			assert.NotContains(t, reply.String(), "a@example.com", "expected the key to be redacted from the reply")
+			got := reply.Lookup("writeErrors", "0", "errmsg").StringValue()
+			assert.Equal(t, redact.Placeholder, got, "expected the message to be redacted")
+		})
+		t.Run("write command errors", func(t *testing.T) {
+			err := WriteCommandError{
+				WriteErrors:       WriteErrors{{Index: 0, Code: 11000, Message: errmsg}},
+				WriteConcernError: &WriteConcernError{Name: "WriteConcernFailed", Message: errmsg},
+			}
+			failure := redactFailure(err, policy)
+			assert.NotContains(t, failure, "a@example.com", "expected the key to be redacted from the failure")
+			assert.Contains(t, err.Error(), "a@example.com", "expected the error not to be modified")
+			assert.Equal(t, err.Error(), redactFailure(err, nil), "expected no redaction without a policy")
+		})
+	})
+	t.Run("tracked reads", func(t *testing.T) {
+		okResponse := createExhaustServerResponse(bsoncore.BuildDocumentFromElements(nil,
+			bsoncore.AppendInt32Element(nil, "ok", 1),
+		), false)
+
+		testCases := []struct {
+			name    string
+			kind    description.TopologyKind
+			opType  Type
+			rp      *readpref.ReadPref
+			tracked bool
+		}{
+			{"secondary reads to a replica set", description.ReplicaSetWithPrimary, Read, readpref.Secondary(), true},
+			{"primary reads to a replica set", description.ReplicaSetWithPrimary, Read, readpref.Primary(), false},
+			{"reads without read preference to a replica set", description.ReplicaSetWithPrimary, Read, nil, false},
+			{"reads to a sharded cluster", description.Sharded, Read, nil, true},
+			{"reads to a single server", description.Single, Read, readpref.Secondary(), false},
+			{"writes", description.ReplicaSetWithPrimary, Write, readpref.Secondary(), false},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				conn := &mockConnection{
+					rDesc:   description.Server{WireVersion: &description.VersionRange{Max: 17}},
+					rReadWM: okResponse,
+				}
+				srvr := &mockReadTrackerServer{mockServer: mockServer{conn: conn, rttMonitor: &csot.ZeroRTTMonitor{}}}
+				d := new(mockDeployment)
+				d.returns.server = srvr
+				d.returns.kind = tc.kind
+
+				err := Operation{
+					CommandFn: func(dst []byte, _ description.SelectedServer) ([]byte, error) {
+						return bsoncore.AppendStringElement(dst, "find", "coll"), nil
+					},
+					Deployment:     d,
+					Database:       "db",
+					Type:           tc.opType,
+					ReadPreference: tc.rp,
+				}.Execute(context.Background())
+				require.NoError(t, err, "Execute error")
+
+				if !tc.tracked {
+					assert.Equal(t, 0, srvr.started, "expected the read not to be tracked")
+					assert.Len(t, srvr.finished, 0, "expected the read not to be tracked")
+					return
+				}
+				assert.Equal(t, 1, srvr.started, "expected the read to be started")
+				require.Len(t, srvr.finished, 1, "expected the read to be finished")
+				assert.Nil(t, srvr.finished[0], "expected the read to succeed")
+			})
+		}
+	})
 }
 
 func createExhaustServerResponse(response bsoncore.Document, moreToCome bool) []byte {
@@ -715,6 +1231,7 @@ func assertExhaustAllowedSet(t *testing.T, wm []byte, expected bool) {
 
 type mockDeployment struct {
 	params struct {
+		ctx      context.Context
 		selector description.ServerSelector
 	}
 	returns struct {
@@ -725,7 +1242,8 @@ type mockDeployment struct {
 	}
 }
 
-func (m *mockDeployment) SelectServer(_ context.Context, desc description.ServerSelector) (Server, error) {
+func (m *mockDeployment) SelectServer(ctx context.Context, desc description.ServerSelector) (Server, error) {
+	m.params.ctx = ctx
 	m.params.selector = desc
 	return m.returns.server, m.returns.err
 }
@@ -751,16 +1269,55 @@ type mockServer struct {
 func (ms mockServer) Connection(context.Context) (Connection, error) { return ms.conn, ms.err }
 func (ms mockServer) RTTMonitor() RTTMonitor                         { return ms.rttMonitor }
 
+type mockFaultInjectionServer struct {
+	mockServer
+	injector *faultinject.Injector
+}
+
+func (ms mockFaultInjectionServer) FaultInjector() *faultinject.Injector { return ms.injector }
+
+type mockOverloadRetryServer struct {
+	mockFaultInjectionServer
+	maxRetries     int
+	rejections     []int
+	connectionErrs []error
+}
+
+func (ms *mockOverloadRetryServer) Connection(ctx context.Context) (Connection, error) {
+	if len(ms.connectionErrs) > 0 {
+		err := ms.connectionErrs[0]
+		ms.connectionErrs = ms.connectionErrs[1:]
+		return nil, err
+	}
+	return ms.mockFaultInjectionServer.Connection(ctx)
+}
+
+func (ms *mockOverloadRetryServer) OverloadRetryBackoff(rejections int) (time.Duration, bool) {
+	ms.rejections = append(ms.rejections, rejections)
+	return time.Millisecond, rejections <= ms.maxRetries
+}
+
+type mockReadTrackerServer struct {
+	mockServer
+	started  int
+	finished []error
+}
+
+func (ms *mockReadTrackerServer) StartRead()           { ms.started++ }
+func (ms *mockReadTrackerServer) FinishRead(err error) { ms.finished = append(ms.finished, err) }
+
 type mockRTTMonitor struct {
 	ewma  time.Duration
 	min   time.Duration
 	p90   time.Duration
+	p95   time.Duration
 	stats string
 }
 
 func (mrm mockRTTMonitor) EWMA() time.Duration { return mrm.ewma }
 func (mrm mockRTTMonitor) Min() time.Duration  { return mrm.min }
 func (mrm mockRTTMonitor) P90() time.Duration  { return mrm.p90 }
+func (mrm mockRTTMonitor) P95() time.Duration  { return mrm.p95 }
 func (mrm mockRTTMonitor) Stats() string       { return mrm.stats }
 
 type mockConnection struct {
@@ -802,6 +1359,46 @@ func (m *mockConnection) ReadWireMessage(_ context.Context) ([]byte, error) {
 	return m.rReadWM, m.rReadErr
 }
 
+// mockHedgeDeployment is a replica set deployment that hedges reads to the hedge server.
+type mockHedgeDeployment struct {
+	mockDeployment
+	delay      time.Duration
+	hedge      Server
+	excluded   address.Address
+	selections int
+}
+
+func (m *mockHedgeDeployment) HedgeDelay(Server) (time.Duration, bool) { return m.delay, true }
+
+func (m *mockHedgeDeployment) SelectHedgeServer(_ context.Context, _ description.ServerSelector, exclude address.Address) Server {
+	m.excluded = exclude
+	m.selections++
+	return m.hedge
+}
+
+// mockHedgeConnection is a connection that replies after a delay, or when it is interrupted.
+type mockHedgeConnection struct {
+	*mockConnection
+	delay        time.Duration
+	ignoreCancel bool
+	interrupted  bool
+}
+
+func (m *mockHedgeConnection) ReadWireMessage(ctx context.Context) ([]byte, error) {
+	if m.ignoreCancel {
+		time.Sleep(m.delay)
+		return m.mockConnection.ReadWireMessage(ctx)
+	}
+
+	select {
+	case <-time.After(m.delay):
+		return m.mockConnection.ReadWireMessage(ctx)
+	case <-ctx.Done():
+		m.interrupted = true
+		return nil, ctx.Err()
+	}
+}
+
 type retryableError struct {
 	error
 }
@@ -864,6 +1461,39 @@ func TestRetry(t *testing.T) {
 			time.Now().After(deadline),
 			"expected operation to complete only after the context deadline is exceeded")
 	})
+	t.Run("publishes retry events", func(t *testing.T) {
+		d := new(mockDeployment)
+		ms := new(mockRetryServer)
+		d.returns.server = ms
+
+		var retries []*event.CommandRetryEvent
+		monitor := &event.CommandMonitor{
+			Retry: func(_ context.Context, evt *event.CommandRetryEvent) {
+				retries = append(retries, evt)
+			},
+		}
+
+		retry := RetryOnce
+		err := Operation{
+			CommandFn:      func([]byte, description.SelectedServer) ([]byte, error) { return nil, nil },
+			Deployment:     d,
+			Database:       "testing",
+			Name:           "find",
+			RetryMode:      &retry,
+			Type:           Read,
+			CommandMonitor: monitor,
+		}.Execute(context.Background())
+		assert.NotNil(t, err, "expected an error from Execute()")
+
+		assert.Equal(t, 2, ms.numCallsToConnection, "expected Connection() to be called twice")
+		assert.Equal(t, 1, len(retries), "expected 1 retry event, got %d", len(retries))
+		assert.Equal(t, "find", retries[0].CommandName, "unexpected command name")
+		assert.Equal(t, "testing", retries[0].DatabaseName, "unexpected database name")
+		assert.Equal(t, 2, retries[0].Attempt, "expected the retry to be the second attempt")
+		assert.Equal(t, int64(0), retries[0].RequestID, "expected no request ID for a failed checkout")
+		assert.True(t, errors.As(retries[0].Failure, new(retryableError)), "expected the checkout error, got %v",
+			retries[0].Failure)
+	})
 }
 
 func TestConvertI64PtrToI32Ptr(t *testing.T) {
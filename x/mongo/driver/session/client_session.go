@@ -120,12 +120,15 @@ type Client struct {
 	CurrentRp  *readpref.ReadPref
 	CurrentWc  *writeconcern.WriteConcern
 	CurrentMct *time.Duration
+	// CurrentTimeout is the timeout of the current transaction, which applies to committing and aborting it.
+	CurrentTimeout *time.Duration
 
 	// default transaction options
 	transactionRc            *readconcern.ReadConcern
 	transactionRp            *readpref.ReadPref
 	transactionWc            *writeconcern.WriteConcern
 	transactionMaxCommitTime *time.Duration
+	transactionTimeout       *time.Duration
 
 	pool             *Pool
 	TransactionState TransactionState
@@ -204,6 +207,9 @@ func NewClientSession(pool *Pool, clientID uuid.UUID, opts ...*ClientOptions) (*
 	if mergedOpts.DefaultMaxCommitTime != nil {
 		c.transactionMaxCommitTime = mergedOpts.DefaultMaxCommitTime
 	}
+	if mergedOpts.DefaultTimeout != nil {
+		c.transactionTimeout = mergedOpts.DefaultTimeout
+	}
 	if mergedOpts.Snapshot != nil {
 		c.Snapshot = *mergedOpts.Snapshot
 	}
@@ -412,6 +418,7 @@ func (c *Client) StartTransaction(opts *TransactionOptions) error {
 		c.CurrentRp = opts.ReadPreference
 		c.CurrentWc = opts.WriteConcern
 		c.CurrentMct = opts.MaxCommitTime
+		c.CurrentTimeout = opts.Timeout
 	}
 
 	if c.CurrentRc == nil {
@@ -430,6 +437,10 @@ func (c *Client) StartTransaction(opts *TransactionOptions) error {
 		c.CurrentMct = c.transactionMaxCommitTime
 	}
 
+	if c.CurrentTimeout == nil {
+		c.CurrentTimeout = c.transactionTimeout
+	}
+
 	if !writeconcern.AckWrite(c.CurrentWc) {
 		_ = c.clearTransactionOpts()
 		return ErrUnackWCUnsupported
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
@@ -195,6 +196,25 @@ func TestClientSession(t *testing.T) {
 		}
 	})
 
+	t.Run("transaction timeout", func(t *testing.T) {
+		defaultTimeout := time.Second
+		txnTimeout := 2 * time.Second
+
+		id, _ := uuid.New()
+		sess, err := NewClientSession(&Pool{}, id, &ClientOptions{DefaultTimeout: &defaultTimeout})
+		require.Nil(t, err, "Unexpected error")
+
+		err = sess.StartTransaction(nil)
+		require.Nil(t, err, "error starting transaction: %s", err)
+		assert.Equal(t, defaultTimeout, *sess.CurrentTimeout, "expected default timeout, got %v", *sess.CurrentTimeout)
+		err = sess.AbortTransaction()
+		require.Nil(t, err, "error aborting transaction: %s", err)
+
+		err = sess.StartTransaction(&TransactionOptions{Timeout: &txnTimeout})
+		require.Nil(t, err, "error starting transaction: %s", err)
+		assert.Equal(t, txnTimeout, *sess.CurrentTimeout, "expected transaction timeout, got %v", *sess.CurrentTimeout)
+	})
+
 	t.Run("causal consistency and snapshot", func(t *testing.T) {
 		falseVal := false
 		trueVal := true
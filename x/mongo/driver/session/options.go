@@ -21,6 +21,7 @@ type ClientOptions struct {
 	DefaultWriteConcern   *writeconcern.WriteConcern
 	DefaultReadPreference *readpref.ReadPref
 	DefaultMaxCommitTime  *time.Duration
+	DefaultTimeout        *time.Duration
 	Snapshot              *bool
 }
 
@@ -30,6 +31,7 @@ type TransactionOptions struct {
 	WriteConcern   *writeconcern.WriteConcern
 	ReadPreference *readpref.ReadPref
 	MaxCommitTime  *time.Duration
+	Timeout        *time.Duration
 }
 
 func mergeClientOptions(opts ...*ClientOptions) *ClientOptions {
@@ -53,6 +55,9 @@ func mergeClientOptions(opts ...*ClientOptions) *ClientOptions {
 		if opt.DefaultMaxCommitTime != nil {
 			c.DefaultMaxCommitTime = opt.DefaultMaxCommitTime
 		}
+		if opt.DefaultTimeout != nil {
+			c.DefaultTimeout = opt.DefaultTimeout
+		}
 		if opt.Snapshot != nil {
 			c.Snapshot = opt.Snapshot
 		}
@@ -0,0 +1,304 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
+)
+
+const (
+	defaultCircuitFailureRate = 0.5
+	defaultCircuitMinCommands = 20
+	defaultCircuitWindow      = 30 * time.Second
+	defaultCircuitCoolDown    = 30 * time.Second
+	defaultCircuitProbes      = 5
+
+	// circuitBuckets is the number of buckets of the sliding window of a circuit breaker.
+	circuitBuckets = 10
+)
+
+// CircuitBreakerConfig configures the circuit breaker of a server. Zero values are replaced with
+// the defaults.
+type CircuitBreakerConfig struct {
+	// FailureRate is the failure rate of the reads in the sliding window at which the circuit
+	// breaker opens. The default is 0.5.
+	FailureRate float64
+
+	// MinCommands is the minimum number of reads in the sliding window for the circuit breaker
+	// to open. The default is 20.
+	MinCommands int
+
+	// Window is the duration of the sliding window. The default is 30 seconds.
+	Window time.Duration
+
+	// CoolDown is how long the circuit breaker stays open before it becomes half-open. The default
+	// is 30 seconds.
+	CoolDown time.Duration
+
+	// Probes is the number of reads let through while the circuit breaker is half-open, which
+	// must all succeed to close it. The default is 5.
+	Probes int
+}
+
+// withDefaults returns a copy of cfg with the zero values replaced with the defaults.
+func (cfg CircuitBreakerConfig) withDefaults() CircuitBreakerConfig {
+	if cfg.FailureRate == 0 {
+		cfg.FailureRate = defaultCircuitFailureRate
+	}
+	if cfg.MinCommands == 0 {
+		cfg.MinCommands = defaultCircuitMinCommands
+	}
+	if cfg.Window == 0 {
+		cfg.Window = defaultCircuitWindow
+	}
+	if cfg.CoolDown == 0 {
+		cfg.CoolDown = defaultCircuitCoolDown
+	}
+	if cfg.Probes == 0 {
+		cfg.Probes = defaultCircuitProbes
+	}
+	return cfg
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitTransition describes a change of the state of a circuit breaker. changed is false if the
+// circuit breaker stayed half-open, but its probes were all let through or were renewed.
+type circuitTransition struct {
+	to          circuitState
+	changed     bool
+	failureRate float64
+	commands    int
+}
+
+// circuitBucket counts the outcomes of the reads of a slice of the sliding window.
+type circuitBucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+// circuitBreaker tracks the outcomes of the reads sent to a server in a sliding window made of
+// circuitBuckets buckets. It opens when the failure rate in the window reaches the threshold,
+// which removes the server from read selection. After the cool-down, it becomes half-open and lets
+// a limited number of probe reads through: it closes once they all succeed and opens again as soon
+// as one of them fails. A half-open circuit breaker whose probes don't complete within the
+// cool-down lets new probes through.
+type circuitBreaker struct {
+	cfg         CircuitBreakerConfig
+	bucketWidth time.Duration
+
+	// onTransition is called without holding mu after the state of the circuit breaker changed, or
+	// after the probes of a half-open circuit breaker were all let through or renewed.
+	onTransition func(circuitTransition)
+
+	mu             sync.Mutex // mu guards the fields below
+	state          circuitState
+	buckets        [circuitBuckets]circuitBucket
+	probesStarted  int
+	probeSuccesses int
+	timer          *time.Timer
+	timerEpoch     int // timerEpoch identifies the last scheduled timer, so earlier ones are ignored.
+	stopped        bool
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig, onTransition func(circuitTransition)) *circuitBreaker {
+	return &circuitBreaker{
+		cfg:          cfg,
+		bucketWidth:  cfg.Window / circuitBuckets,
+		onTransition: onTransition,
+	}
+}
+
+// isCircuitFailure reports whether err shows that the server failed to serve a command, as opposed
+// to the command itself being invalid. Network errors, retryable server errors such as
+// ExceededTimeLimit and commands rejected because the server is overloaded are failures.
+func isCircuitFailure(err error) bool {
+	var derr driver.Error
+	if !errors.As(err, &derr) {
+		return false
+	}
+	return derr.RetryableRead() || derr.HasErrorLabel(driver.SystemOverloadedError)
+}
+
+// record records the outcome of a read.
+func (cb *circuitBreaker) record(err error) {
+	if cb == nil {
+		return
+	}
+
+	failure := isCircuitFailure(err)
+
+	cb.mu.Lock()
+	var transition *circuitTransition
+	switch cb.state {
+	case circuitClosed:
+		now := time.Now()
+		bucket := cb.bucket(now)
+		if failure {
+			bucket.failures++
+		} else {
+			bucket.successes++
+		}
+
+		commands, failures := cb.count(now)
+		rate := float64(failures) / float64(commands)
+		if commands >= cb.cfg.MinCommands && rate >= cb.cfg.FailureRate {
+			transition = cb.open(rate, commands)
+		}
+	case circuitHalfOpen:
+		if failure {
+			transition = cb.open(1, 0)
+			break
+		}
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.cfg.Probes {
+			transition = cb.close()
+		}
+	}
+	cb.mu.Unlock()
+
+	if transition != nil {
+		cb.onTransition(*transition)
+	}
+}
+
+// bucket returns the bucket of the sliding window for the given time, resetting it if it holds
+// the outcomes of an earlier slice. It must be called while holding mu.
+func (cb *circuitBreaker) bucket(now time.Time) *circuitBucket {
+	start := now.Truncate(cb.bucketWidth)
+	bucket := &cb.buckets[int(start.UnixNano()/int64(cb.bucketWidth))%circuitBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+	return bucket
+}
+
+// count returns the number of commands and failures in the sliding window ending at the given
+// time. It must be called while holding mu.
+func (cb *circuitBreaker) count(now time.Time) (commands, failures int) {
+	windowStart := now.Add(-cb.cfg.Window)
+	for _, bucket := range cb.buckets {
+		if bucket.start.After(windowStart) {
+			commands += bucket.successes + bucket.failures
+			failures += bucket.failures
+		}
+	}
+	return commands, failures
+}
+
+// open opens the circuit breaker and schedules it to become half-open after the cool-down. It
+// must be called while holding mu.
+func (cb *circuitBreaker) open(rate float64, commands int) *circuitTransition {
+	cb.state = circuitOpen
+	cb.schedule()
+	return &circuitTransition{to: circuitOpen, changed: true, failureRate: rate, commands: commands}
+}
+
+// close closes the circuit breaker and starts a new sliding window. It must be called while
+// holding mu.
+func (cb *circuitBreaker) close() *circuitTransition {
+	cb.state = circuitClosed
+	cb.buckets = [circuitBuckets]circuitBucket{}
+	cb.timerEpoch++
+	if cb.timer != nil {
+		cb.timer.Stop()
+	}
+	return &circuitTransition{to: circuitClosed, changed: true}
+}
+
+// schedule calls halfOpen after the cool-down, replacing any call scheduled before. It must be
+// called while holding mu.
+func (cb *circuitBreaker) schedule() {
+	if cb.timer != nil {
+		cb.timer.Stop()
+	}
+	cb.timerEpoch++
+	if !cb.stopped {
+		epoch := cb.timerEpoch
+		cb.timer = time.AfterFunc(cb.cfg.CoolDown, func() { cb.halfOpen(epoch) })
+	}
+}
+
+// halfOpen makes an open circuit breaker half-open, or lets new probes through if the circuit
+// breaker is already half-open.
+func (cb *circuitBreaker) halfOpen(epoch int) {
+	cb.mu.Lock()
+	if cb.stopped || cb.state == circuitClosed || epoch != cb.timerEpoch {
+		cb.mu.Unlock()
+		return
+	}
+	changed := cb.state != circuitHalfOpen
+	cb.state = circuitHalfOpen
+	cb.probesStarted = 0
+	cb.probeSuccesses = 0
+	cb.schedule()
+	cb.mu.Unlock()
+
+	cb.onTransition(circuitTransition{to: circuitHalfOpen, changed: changed})
+}
+
+// startOperation counts a read sent to the server as a probe if the circuit breaker is
+// half-open. Once all of the probes are let through, the server is removed from read selection
+// again until they complete.
+func (cb *circuitBreaker) startOperation() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	if cb.state != circuitHalfOpen || cb.probesStarted >= cb.cfg.Probes {
+		cb.mu.Unlock()
+		return
+	}
+	cb.probesStarted++
+	exhausted := cb.probesStarted == cb.cfg.Probes
+	cb.mu.Unlock()
+
+	if exhausted {
+		cb.onTransition(circuitTransition{to: circuitHalfOpen})
+	}
+}
+
+// blocksReads returns true if the server must not be selected for reads, either because the
+// circuit breaker is open or because all of the probes of the half-open circuit breaker were let
+// through.
+func (cb *circuitBreaker) blocksReads() bool {
+	if cb == nil {
+		return false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state == circuitOpen || (cb.state == circuitHalfOpen && cb.probesStarted >= cb.cfg.Probes)
+}
+
+// stop stops the cool-down timer of the circuit breaker.
+func (cb *circuitBreaker) stop() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.stopped = true
+	if cb.timer != nil {
+		cb.timer.Stop()
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/require"
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	failure := driver.Error{Labels: []string{driver.NetworkError}}
+	overloaded := newOverloadedError(nil, 1)
+	commandError := driver.Error{Code: 11000, Message: "duplicate key"}
+
+	newBreaker := func(cfg CircuitBreakerConfig) (*circuitBreaker, chan circuitTransition) {
+		transitions := make(chan circuitTransition, 10)
+		cb := newCircuitBreaker(cfg.withDefaults(), func(transition circuitTransition) {
+			transitions <- transition
+		})
+		t.Cleanup(cb.stop)
+		return cb, transitions
+	}
+	nextTransition := func(t *testing.T, transitions chan circuitTransition) circuitTransition {
+		t.Helper()
+
+		select {
+		case transition := <-transitions:
+			return transition
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a transition of the circuit breaker")
+			return circuitTransition{}
+		}
+	}
+	// openBreaker records failures until the circuit breaker opens.
+	openBreaker := func(t *testing.T, cb *circuitBreaker, transitions chan circuitTransition) {
+		t.Helper()
+
+		for i := 0; i < cb.cfg.MinCommands; i++ {
+			cb.record(failure)
+		}
+		transition := nextTransition(t, transitions)
+		require.Equal(t, circuitOpen, transition.to, "expected the circuit breaker to open")
+	}
+
+	t.Run("classifies errors", func(t *testing.T) {
+		t.Parallel()
+
+		assert.True(t, isCircuitFailure(failure), "expected network errors to be failures")
+		assert.True(t, isCircuitFailure(overloaded), "expected overloaded errors to be failures")
+		assert.True(t, isCircuitFailure(driver.Error{Code: 262}), "expected ExceededTimeLimit errors to be failures")
+		assert.False(t, isCircuitFailure(commandError), "expected command errors not to be failures")
+		assert.False(t, isCircuitFailure(errors.New("other")), "expected other errors not to be failures")
+		assert.False(t, isCircuitFailure(nil), "expected successes not to be failures")
+	})
+	t.Run("opens when the failure rate reaches the threshold", func(t *testing.T) {
+		t.Parallel()
+
+		cb, transitions := newBreaker(CircuitBreakerConfig{FailureRate: 0.5, MinCommands: 4, CoolDown: time.Hour})
+		cb.record(nil)
+		cb.record(commandError)
+		cb.record(failure)
+		assert.False(t, cb.blocksReads(), "expected the circuit breaker to be closed below the minimum commands")
+
+		cb.record(overloaded)
+		transition := nextTransition(t, transitions)
+		assert.Equal(t, circuitOpen, transition.to, "expected the circuit breaker to open")
+		assert.True(t, transition.changed, "expected the state to change")
+		assert.Equal(t, 0.5, transition.failureRate, "expected the failure rate to be reported")
+		assert.Equal(t, 4, transition.commands, "expected the number of commands to be reported")
+		assert.True(t, cb.blocksReads(), "expected an open circuit breaker to block reads")
+	})
+	t.Run("does not open below the failure rate", func(t *testing.T) {
+		t.Parallel()
+
+		cb, transitions := newBreaker(CircuitBreakerConfig{FailureRate: 0.5, MinCommands: 4})
+		for i := 0; i < 10; i++ {
+			cb.record(nil)
+			cb.record(nil)
+			cb.record(failure)
+		}
+		assert.False(t, cb.blocksReads(), "expected the circuit breaker to stay closed")
+		assert.Equal(t, 0, len(transitions), "expected no transitions")
+	})
+	t.Run("forgets commands outside the window", func(t *testing.T) {
+		t.Parallel()
+
+		cb, transitions := newBreaker(CircuitBreakerConfig{MinCommands: 4, Window: 100 * time.Millisecond})
+		for i := 0; i < 3; i++ {
+			cb.record(failure)
+		}
+		time.Sleep(150 * time.Millisecond)
+		cb.record(failure)
+		assert.False(t, cb.blocksReads(), "expected the circuit breaker to stay closed")
+		assert.Equal(t, 0, len(transitions), "expected no transitions")
+	})
+	t.Run("closes after the probes succeed", func(t *testing.T) {
+		t.Parallel()
+
+		cb, transitions := newBreaker(CircuitBreakerConfig{MinCommands: 2, CoolDown: 200 * time.Millisecond, Probes: 2})
+		openBreaker(t, cb, transitions)
+
+		transition := nextTransition(t, transitions)
+		require.Equal(t, circuitHalfOpen, transition.to, "expected the circuit breaker to become half-open")
+		assert.True(t, transition.changed, "expected the state to change")
+		assert.False(t, cb.blocksReads(), "expected a half-open circuit breaker to let reads through")
+
+		cb.startOperation()
+		cb.record(nil)
+		assert.Equal(t, 0, len(transitions), "expected the circuit breaker to stay half-open")
+		cb.startOperation()
+		cb.record(commandError)
+
+		transition = nextTransition(t, transitions)
+		for transition.to == circuitHalfOpen {
+			transition = nextTransition(t, transitions)
+		}
+		assert.Equal(t, circuitClosed, transition.to, "expected the circuit breaker to close")
+		assert.False(t, cb.blocksReads(), "expected a closed circuit breaker to let reads through")
+	})
+	t.Run("reopens when a probe fails", func(t *testing.T) {
+		t.Parallel()
+
+		cb, transitions := newBreaker(CircuitBreakerConfig{MinCommands: 2, CoolDown: 50 * time.Millisecond, Probes: 2})
+		openBreaker(t, cb, transitions)
+		transition := nextTransition(t, transitions)
+		require.Equal(t, circuitHalfOpen, transition.to, "expected the circuit breaker to become half-open")
+
+		cb.startOperation()
+		cb.record(failure)
+
+		transition = nextTransition(t, transitions)
+		assert.Equal(t, circuitOpen, transition.to, "expected the circuit breaker to open again")
+		assert.True(t, cb.blocksReads(), "expected an open circuit breaker to block reads")
+	})
+	t.Run("blocks reads once all probes started", func(t *testing.T) {
+		t.Parallel()
+
+		cb, transitions := newBreaker(CircuitBreakerConfig{MinCommands: 2, CoolDown: 50 * time.Millisecond, Probes: 2})
+		openBreaker(t, cb, transitions)
+		transition := nextTransition(t, transitions)
+		require.Equal(t, circuitHalfOpen, transition.to, "expected the circuit breaker to become half-open")
+
+		cb.startOperation()
+		assert.False(t, cb.blocksReads(), "expected reads to be let through until all probes started")
+		cb.startOperation()
+		assert.True(t, cb.blocksReads(), "expected reads to be blocked once all probes started")
+
+		transition = nextTransition(t, transitions)
+		assert.Equal(t, circuitHalfOpen, transition.to, "expected the circuit breaker to stay half-open")
+		assert.False(t, transition.changed, "expected the state not to change")
+
+		transition = nextTransition(t, transitions)
+		assert.Equal(t, circuitHalfOpen, transition.to, "expected the probes to be renewed after the cool-down")
+		assert.False(t, transition.changed, "expected the state not to change")
+		assert.False(t, cb.blocksReads(), "expected renewed probes to let reads through")
+	})
+	t.Run("stop cancels the cool-down", func(t *testing.T) {
+		t.Parallel()
+
+		cb, transitions := newBreaker(CircuitBreakerConfig{MinCommands: 2, CoolDown: 10 * time.Millisecond})
+		openBreaker(t, cb, transitions)
+		cb.stop()
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 0, len(transitions), "expected no transitions after the circuit breaker stopped")
+		assert.True(t, cb.blocksReads(), "expected the circuit breaker to stay open")
+	})
+	t.Run("nil circuit breaker", func(t *testing.T) {
+		t.Parallel()
+
+		var cb *circuitBreaker
+		cb.record(failure)
+		cb.startOperation()
+		cb.stop()
+		assert.False(t, cb.blocksReads(), "expected a nil circuit breaker not to block reads")
+	})
+}
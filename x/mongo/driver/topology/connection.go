@@ -19,8 +19,10 @@ import (
 	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/internal/csot"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
 	"github.com/hongyuyang/mongo-go-driver/mongo/address"
 	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
 	"github.com/hongyuyang/mongo-go-driver/x/bsonx/bsoncore"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/ocsp"
@@ -55,6 +57,7 @@ type connection struct {
 	addr                 address.Address
 	idleTimeout          time.Duration
 	idleDeadline         atomic.Value // Stores a time.Time
+	lifeTimeDeadline     time.Time    // The zero time if the lifetime of the connection is not limited.
 	readTimeout          time.Duration
 	writeTimeout         time.Duration
 	desc                 description.Server
@@ -79,6 +82,15 @@ type connection struct {
 	driverConnectionID uint64
 	generation         uint64
 
+	// priority is the priority of the checkOut the connection was delivered to, and inUse is true
+	// while the connection is counted as checked out by that priority.
+	priority priority.Priority
+	inUse    bool
+
+	// checkedOutAt is the time the checkOut of the connection took a slot of the concurrency limit
+	// of the pool, or the zero time if it did not take a slot.
+	checkedOutAt time.Time
+
 	// awaitingResponse indicates that the server response was not completely
 	// read before returning the connection to the pool.
 	awaitingResponse bool
@@ -101,6 +113,13 @@ func newConnection(addr address.Address, opts ...ConnectionOption) *connection {
 		connectContextMade:   make(chan struct{}),
 		cancellationListener: newCancellListener(),
 	}
+	if cfg.lifeTime > 0 {
+		lifeTime := cfg.lifeTime
+		if cfg.lifeTimeJitter > 0 {
+			lifeTime += time.Duration(random.Int63n(int64(cfg.lifeTimeJitter)))
+		}
+		c.lifeTimeDeadline = time.Now().Add(lifeTime)
+	}
 	// Connections to non-load balanced deployments should eagerly set the generation numbers so errors encountered
 	// at any point during connection establishment can be processed without the connection being considered stale.
 	if !c.config.loadBalanced {
@@ -417,6 +436,12 @@ func (c *connection) readWireMessage(ctx context.Context) ([]byte, error) {
 		return nil, ConnectionError{ConnectionID: c.id, Wrapped: err, message: "failed to set read deadline"}
 	}
 
+	// Fault injection simulates a network error in the middle of reading the reply by closing the socket after the
+	// command was sent, so the read below fails like it would for a broken connection.
+	if driverutil.CloseConnectionFromContext(ctx) {
+		_ = c.nc.Close()
+	}
+
 	dst, errMsg, err := c.read(ctx)
 	if err != nil {
 		if nerr := net.Error(nil); errors.As(err, &nerr) && nerr.Timeout() && csot.IsTimeoutContext(ctx) {
@@ -523,6 +548,10 @@ func (c *connection) idleTimeoutExpired() bool {
 	return false
 }
 
+func (c *connection) lifeTimeExpired() bool {
+	return !c.lifeTimeDeadline.IsZero() && time.Now().After(c.lifeTimeDeadline)
+}
+
 func (c *connection) bumpIdleDeadline() {
 	if c.idleTimeout > 0 {
 		c.idleDeadline.Store(time.Now().Add(c.idleTimeout))
@@ -52,6 +52,8 @@ type connectionConfig struct {
 	dialer                   Dialer
 	handshaker               Handshaker
 	idleTimeout              time.Duration
+	lifeTime                 time.Duration
+	lifeTimeJitter           time.Duration
 	cmdMonitor               *event.CommandMonitor
 	readTimeout              time.Duration
 	writeTimeout             time.Duration
@@ -137,6 +139,13 @@ func WithIdleTimeout(fn func(time.Duration) time.Duration) ConnectionOption {
 	}
 }
 
+// WithLifeTime configures the maximum time a connection can exist and the maximum random jitter added to it.
+func WithLifeTime(fn func(time.Duration, time.Duration) (time.Duration, time.Duration)) ConnectionOption {
+	return func(c *connectionConfig) {
+		c.lifeTime, c.lifeTimeJitter = fn(c.lifeTime, c.lifeTimeJitter)
+	}
+}
+
 // WithReadTimeout configures the maximum read time for a connection.
 func WithReadTimeout(fn func(time.Duration) time.Duration) ConnectionOption {
 	return func(c *connectionConfig) {
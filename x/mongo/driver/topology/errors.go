@@ -10,6 +10,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/mongo/description"
@@ -54,14 +55,25 @@ func (e ConnectionError) Unwrap() error {
 type ServerSelectionError struct {
 	Desc    description.Topology
 	Wrapped error
+
+	// Rejections contains the reason each server of the topology was rejected by the last selection attempt.
+	Rejections []description.ServerRejection
 }
 
 // Error implements the error interface.
 func (e ServerSelectionError) Error() string {
+	var rejected string
+	if len(e.Rejections) > 0 {
+		reasons := make([]string, len(e.Rejections))
+		for i, rejection := range e.Rejections {
+			reasons[i] = rejection.String()
+		}
+		rejected = fmt.Sprintf("rejected servers: [%s], ", strings.Join(reasons, "; "))
+	}
 	if e.Wrapped != nil {
-		return fmt.Sprintf("server selection error: %s, current topology: { %s }", e.Wrapped.Error(), e.Desc.String())
+		return fmt.Sprintf("server selection error: %s, %scurrent topology: { %s }", e.Wrapped.Error(), rejected, e.Desc.String())
 	}
-	return fmt.Sprintf("server selection error: current topology: { %s }", e.Desc.String())
+	return fmt.Sprintf("server selection error: %scurrent topology: { %s }", rejected, e.Desc.String())
 }
 
 // Unwrap returns the underlying error.
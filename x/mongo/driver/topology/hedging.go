@@ -0,0 +1,139 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
+)
+
+const (
+	defaultHedgeBudget = 0.1
+
+	// hedgeBudgetTokens is the capacity of the hedging budget.
+	hedgeBudgetTokens = 10
+)
+
+// HedgingConfig configures the client-side hedged reads of a topology. Zero values are replaced
+// with the defaults.
+type HedgingConfig struct {
+	// Delay is how long to wait for the reply of a server before hedging a read. The default is the
+	// 95th percentile RTT to the server.
+	Delay time.Duration
+
+	// Budget is the number of hedged reads each read that can be hedged adds to the hedging budget.
+	// The default is 0.1.
+	Budget float64
+}
+
+// withDefaults returns a copy of cfg with the zero values replaced with the defaults.
+func (cfg HedgingConfig) withDefaults() HedgingConfig {
+	if cfg.Budget == 0 {
+		cfg.Budget = defaultHedgeBudget
+	}
+	return cfg
+}
+
+// hedgeBudget limits the hedged reads of a topology. It is a token bucket that holds
+// hedgeBudgetTokens tokens: each read that can be hedged adds a fraction of a token and each hedged
+// read takes a token, so the reads of a slow replica set don't double the load on it.
+type hedgeBudget struct {
+	ratio float64
+
+	mu     sync.Mutex // mu guards tokens
+	tokens float64
+}
+
+func newHedgeBudget(cfg HedgingConfig) *hedgeBudget {
+	return &hedgeBudget{
+		ratio:  cfg.Budget,
+		tokens: hedgeBudgetTokens,
+	}
+}
+
+// read adds a fraction of a token to the budget for a read that can be hedged.
+func (b *hedgeBudget) read() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(hedgeBudgetTokens, b.tokens+b.ratio)
+}
+
+// take takes a token from the budget for a hedged read. It returns false if the budget is used up.
+func (b *hedgeBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+var _ driver.ReadHedger = &Topology{}
+
+// HedgeDelay implements the driver.ReadHedger interface. Reads are not hedged if hedging is not
+// enabled, or if the delay is the 95th percentile RTT to the server and it is not measured yet.
+func (t *Topology) HedgeDelay(srvr driver.Server) (time.Duration, bool) {
+	if t.hedgeBudget == nil {
+		return 0, false
+	}
+
+	delay := t.cfg.Hedging.Delay
+	if delay == 0 {
+		delay = srvr.RTTMonitor().P95()
+	}
+	if delay <= 0 {
+		return 0, false
+	}
+
+	t.hedgeBudget.read()
+	return delay, true
+}
+
+// SelectHedgeServer implements the driver.ReadHedger interface. It selects a random server from the
+// servers suitable for the selector and the application server selector in the current topology
+// description.
+func (t *Topology) SelectHedgeServer(
+	ctx context.Context,
+	ss description.ServerSelector,
+	exclude address.Address,
+) driver.Server {
+	if t.hedgeBudget == nil || atomic.LoadInt64(&t.state) != topologyConnected {
+		return nil
+	}
+
+	selectionState := newServerSelectionState(t.applicationSelector(ctx, ss), nil)
+	suitable, err := t.selectServerFromDescription(t.Description(), selectionState)
+	if err != nil {
+		return nil
+	}
+
+	candidates := make([]description.Server, 0, len(suitable))
+	for _, s := range suitable {
+		if s.Addr != exclude {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	server, err := t.FindServer(candidates[random.Intn(len(candidates))])
+	if err != nil || server == nil || !t.hedgeBudget.take() {
+		return nil
+	}
+	return server
+}
@@ -0,0 +1,121 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/require"
+	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/readpref"
+)
+
+func TestHedgeBudget(t *testing.T) {
+	t.Parallel()
+
+	b := newHedgeBudget(HedgingConfig{Budget: 0.5}.withDefaults())
+	for i := 0; i < hedgeBudgetTokens; i++ {
+		assert.True(t, b.take(), "expected hedge %d to be allowed", i+1)
+	}
+	assert.False(t, b.take(), "expected no hedge once the budget is used up")
+
+	b.read()
+	assert.False(t, b.take(), "expected half a token not to allow a hedge")
+	b.read()
+	assert.True(t, b.take(), "expected reads to fill up the budget")
+}
+
+func TestTopologyHedging(t *testing.T) {
+	t.Parallel()
+
+	newTopology := func(t *testing.T, cfg *HedgingConfig) *Topology {
+		t.Helper()
+
+		topo, err := New(nil)
+		require.NoError(t, err, "error creating topology")
+		if cfg != nil {
+			topo.cfg.Hedging = cfg
+			topo.hedgeBudget = newHedgeBudget(cfg.withDefaults())
+		}
+		atomic.StoreInt64(&topo.state, topologyConnected)
+
+		desc := description.Topology{
+			Kind:    description.ReplicaSetWithPrimary,
+			SetName: "rs",
+			Servers: []description.Server{
+				{Addr: address.Address("primary:27017"), Kind: description.RSPrimary, SetName: "rs"},
+				{Addr: address.Address("secondary1:27017"), Kind: description.RSSecondary, SetName: "rs"},
+				{Addr: address.Address("secondary2:27017"), Kind: description.RSSecondary, SetName: "rs"},
+			},
+		}
+		topo.desc.Store(desc)
+		for _, srv := range desc.Servers {
+			s, err := ConnectServer(srv.Addr, topo.updateCallback, topo.id)
+			require.NoError(t, err, "error connecting server")
+			topo.servers[srv.Addr] = s
+		}
+		return topo
+	}
+
+	t.Run("delay", func(t *testing.T) {
+		t.Parallel()
+
+		srvr := &Server{rttMonitor: newRTTMonitor(&rttConfig{interval: time.Second})}
+
+		_, ok := newTopology(t, nil).HedgeDelay(srvr)
+		assert.False(t, ok, "expected reads not to be hedged if hedging is disabled")
+
+		delay, ok := newTopology(t, &HedgingConfig{Delay: time.Second}).HedgeDelay(srvr)
+		assert.True(t, ok, "expected reads to be hedged")
+		assert.Equal(t, time.Second, delay, "expected the configured delay")
+
+		topo := newTopology(t, &HedgingConfig{})
+		_, ok = topo.HedgeDelay(srvr)
+		assert.False(t, ok, "expected reads not to be hedged before the RTT is measured")
+
+		for i := 0; i < minSamples; i++ {
+			srvr.rttMonitor.addSample(time.Duration(i+1) * time.Millisecond)
+		}
+		delay, ok = topo.HedgeDelay(srvr)
+		assert.True(t, ok, "expected reads to be hedged")
+		assert.Equal(t, srvr.rttMonitor.P95(), delay, "expected the 95th percentile RTT")
+	})
+	t.Run("selects another suitable server", func(t *testing.T) {
+		t.Parallel()
+
+		topo := newTopology(t, &HedgingConfig{})
+		selector := description.ReadPrefSelector(readpref.Secondary())
+		for i := 0; i < 5; i++ {
+			srvr := topo.SelectHedgeServer(context.Background(), selector, "secondary1:27017")
+			require.NotNil(t, srvr, "expected a server to be selected")
+			assert.Equal(t, address.Address("secondary2:27017"), srvr.(*SelectedServer).address,
+				"expected the other secondary to be selected")
+		}
+	})
+	t.Run("selects no server", func(t *testing.T) {
+		t.Parallel()
+
+		topo := newTopology(t, &HedgingConfig{})
+		srvr := topo.SelectHedgeServer(context.Background(), description.ReadPrefSelector(readpref.Primary()), "primary:27017")
+		assert.Nil(t, srvr, "expected no other server to be suitable")
+
+		srvr = newTopology(t, nil).SelectHedgeServer(
+			context.Background(), description.ReadPrefSelector(readpref.Secondary()), "secondary1:27017")
+		assert.Nil(t, srvr, "expected no server to be selected if hedging is disabled")
+
+		for i := 0; i < hedgeBudgetTokens; i++ {
+			topo.hedgeBudget.take()
+		}
+		srvr = topo.SelectHedgeServer(context.Background(), description.ReadPrefSelector(readpref.Secondary()), "secondary1:27017")
+		assert.Nil(t, srvr, "expected no server to be selected once the budget is used up")
+	})
+}
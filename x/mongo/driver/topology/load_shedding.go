@@ -0,0 +1,327 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
+)
+
+const (
+	defaultConcurrencyLimit     = 20
+	defaultMinConcurrencyLimit  = 10
+	defaultMaxConcurrencyLimit  = 1000
+	defaultLatencyTolerance     = 1.5
+	defaultMaxOverloadRetries   = 2
+	defaultOverloadRetryBackoff = 100 * time.Millisecond
+	defaultOverloadRetryBudget  = 0.1
+
+	// maxOverloadRetryBackoff is the maximum delay before retrying a command rejected because the
+	// server is overloaded.
+	maxOverloadRetryBackoff = 10 * time.Second
+
+	// overloadRetryTokens is the capacity of the retry budget. Commands are retried while the
+	// budget holds more than half of its capacity.
+	overloadRetryTokens = 10
+
+	// limitSmoothing is the weight of the limit computed from each latency sample in the new limit.
+	limitSmoothing = 0.2
+
+	// longLatencyAlpha is the weight of each latency sample in the long-term average latency.
+	longLatencyAlpha = 0.01
+
+	// overloadDecrease is the factor the limit is multiplied by each time the server rejects a
+	// command because it is overloaded.
+	overloadDecrease = 0.9
+)
+
+// LoadSheddingConfig configures the adaptive concurrency limit of the operations in progress on a
+// server and the retries of the commands rejected because the server is overloaded. Zero values
+// are replaced with the defaults.
+type LoadSheddingConfig struct {
+	// InitialLimit is the concurrency limit before it is adjusted. The default is 20.
+	InitialLimit uint64
+
+	// MinLimit is the minimum concurrency limit. The default is 10.
+	MinLimit uint64
+
+	// MaxLimit is the maximum concurrency limit. The default is the maximum pool size, or 1000
+	// if the pool size is not limited.
+	MaxLimit uint64
+
+	// LatencyTolerance is how many times the long-term average latency the latency of operations
+	// can reach before the limit is decreased. The default is 1.5.
+	LatencyTolerance float64
+
+	// MaxRetries is the maximum number of times a command rejected because the server is
+	// overloaded is retried. The default is 2, and a negative value disables the retries.
+	MaxRetries int
+
+	// RetryBackoff is the base delay before retrying a command rejected because the server is
+	// overloaded. The default is 100ms.
+	RetryBackoff time.Duration
+
+	// RetryBudget is the number of retries each successful command adds to the retry budget. The
+	// default is 0.1.
+	RetryBudget float64
+}
+
+// withDefaults returns a copy of cfg with the zero values replaced with the defaults.
+func (cfg LoadSheddingConfig) withDefaults(maxPoolSize uint64) LoadSheddingConfig {
+	if cfg.MinLimit == 0 {
+		cfg.MinLimit = defaultMinConcurrencyLimit
+	}
+	if cfg.MaxLimit == 0 {
+		cfg.MaxLimit = maxPoolSize
+		if cfg.MaxLimit == 0 {
+			cfg.MaxLimit = defaultMaxConcurrencyLimit
+		}
+	}
+	if cfg.MinLimit > cfg.MaxLimit {
+		cfg.MinLimit = cfg.MaxLimit
+	}
+	if cfg.InitialLimit == 0 {
+		cfg.InitialLimit = defaultConcurrencyLimit
+	}
+	if cfg.LatencyTolerance == 0 {
+		cfg.LatencyTolerance = defaultLatencyTolerance
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxOverloadRetries
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = defaultOverloadRetryBackoff
+	}
+	if cfg.RetryBudget == 0 {
+		cfg.RetryBudget = defaultOverloadRetryBudget
+	}
+	return cfg
+}
+
+// concurrencyLimiter limits the number of operations in progress on a server. The limit is
+// adjusted after each operation with the gradient between the long-term average latency of
+// operations and the latency of the operation, so it decreases as soon as the server slows down
+// and increases again while operations are as fast as usual. The gradient between the minimum and
+// the average RTT measured by the RTT monitor also decreases the limit when the server is slow to
+// answer heartbeats. Commands rejected because the server is overloaded decrease the limit
+// multiplicatively.
+type concurrencyLimiter struct {
+	minLimit  float64
+	maxLimit  float64
+	tolerance float64
+
+	// rtt returns the minimum and the average RTT to the server.
+	rtt func() (time.Duration, time.Duration)
+
+	// onChange is called without holding mu when the integer part of the limit changes.
+	onChange func(limit, inFlight uint64)
+
+	mu          sync.Mutex // mu guards limit, inFlight and longLatency
+	limit       float64
+	inFlight    uint64
+	longLatency float64
+}
+
+func newConcurrencyLimiter(
+	cfg LoadSheddingConfig,
+	rtt func() (time.Duration, time.Duration),
+	onChange func(limit, inFlight uint64),
+) *concurrencyLimiter {
+	l := &concurrencyLimiter{
+		minLimit:  float64(cfg.MinLimit),
+		maxLimit:  float64(cfg.MaxLimit),
+		tolerance: cfg.LatencyTolerance,
+		rtt:       rtt,
+		onChange:  onChange,
+	}
+	l.limit = l.clamp(float64(cfg.InitialLimit))
+	return l
+}
+
+func (l *concurrencyLimiter) clamp(limit float64) float64 {
+	return math.Max(l.minLimit, math.Min(l.maxLimit, limit))
+}
+
+// acquire reserves a slot for an operation. It returns false if the number of operations in
+// progress reached the limit. A nil limiter does not limit operations.
+func (l *concurrencyLimiter) acquire() bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= uint64(l.limit) {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// release frees the slot of an operation that took the given latency and adjusts the limit. A
+// zero latency frees the slot without adjusting the limit, for operations that did not complete.
+func (l *concurrencyLimiter) release(latency time.Duration) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	old := uint64(l.limit)
+	inFlight := l.inFlight
+	l.inFlight--
+	if latency > 0 {
+		l.adjust(float64(latency), inFlight)
+	}
+	limit := uint64(l.limit)
+	l.mu.Unlock()
+
+	if limit != old && l.onChange != nil {
+		l.onChange(limit, inFlight-1)
+	}
+}
+
+// adjust computes the limit from a latency sample of one of the given number of operations in
+// progress. It must be called while holding mu.
+func (l *concurrencyLimiter) adjust(latency float64, inFlight uint64) {
+	if l.longLatency == 0 {
+		l.longLatency = latency
+	}
+	l.longLatency = l.longLatency*(1-longLatencyAlpha) + latency*longLatencyAlpha
+
+	// Let the long-term average latency recover quickly after the latency of a slow server went
+	// back to normal.
+	if l.longLatency > 2*latency {
+		l.longLatency *= 0.95
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.tolerance*l.longLatency/latency))
+	if l.rtt != nil {
+		if minRTT, avgRTT := l.rtt(); minRTT > 0 && avgRTT > 0 {
+			gradient = math.Min(gradient, math.Max(0.5, l.tolerance*float64(minRTT)/float64(avgRTT)))
+		}
+	}
+
+	// Don't increase the limit while the operations don't use it, as it would grow unbounded.
+	if gradient == 1 && float64(inFlight) < l.limit/2 {
+		return
+	}
+
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = l.clamp(l.limit*(1-limitSmoothing) + newLimit*limitSmoothing)
+}
+
+// overloaded decreases the limit after the server rejected a command because it is overloaded.
+func (l *concurrencyLimiter) overloaded() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	old := uint64(l.limit)
+	l.limit = l.clamp(l.limit * overloadDecrease)
+	limit := uint64(l.limit)
+	inFlight := l.inFlight
+	l.mu.Unlock()
+
+	if limit != old && l.onChange != nil {
+		l.onChange(limit, inFlight)
+	}
+}
+
+// current returns the limit and the number of operations in progress.
+func (l *concurrencyLimiter) current() (limit, inFlight uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return uint64(l.limit), l.inFlight
+}
+
+// overloadRetryBudget limits the retries of the commands rejected because a server is overloaded.
+// It is a token bucket that holds overloadRetryTokens tokens: each rejected command takes a token
+// and each successful command adds a fraction of a token. Commands are retried while the bucket
+// holds more than half of its tokens, so retries stop when most commands are rejected.
+type overloadRetryBudget struct {
+	maxRetries int
+	backoff    time.Duration
+	ratio      float64
+
+	mu     sync.Mutex // mu guards tokens
+	tokens float64
+}
+
+func newOverloadRetryBudget(cfg LoadSheddingConfig) *overloadRetryBudget {
+	return &overloadRetryBudget{
+		maxRetries: cfg.MaxRetries,
+		backoff:    cfg.RetryBackoff,
+		ratio:      cfg.RetryBudget,
+		tokens:     overloadRetryTokens,
+	}
+}
+
+// succeeded adds a fraction of a token to the budget after a command succeeded.
+func (b *overloadRetryBudget) succeeded() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(overloadRetryTokens, b.tokens+b.ratio)
+}
+
+// rejected takes a token from the budget after a command was rejected because the server is
+// overloaded.
+func (b *overloadRetryBudget) rejected() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Max(0, b.tokens-1)
+}
+
+// retryBackoff returns the delay before retrying a command rejected the given number of
+// consecutive times, or false if the command must not be retried. The delay is chosen at random
+// up to an exponentially increasing maximum, so the retries of the commands rejected at the same
+// time are spread out.
+func (b *overloadRetryBudget) retryBackoff(rejections int) (time.Duration, bool) {
+	if b == nil || rejections > b.maxRetries {
+		return 0, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens <= overloadRetryTokens/2 {
+		return 0, false
+	}
+
+	backoff := maxOverloadRetryBackoff
+	if shift := rejections - 1; shift < 32 && b.backoff<<shift < maxOverloadRetryBackoff {
+		backoff = b.backoff << shift
+	}
+	return time.Duration(random.Int63n(int64(backoff) + 1)), true
+}
+
+// newOverloadedError returns the error of an operation rejected because the number of operations
+// in progress on the server at addr reached the concurrency limit. It is labeled like the errors
+// of commands rejected by an overloaded server, so it is retried in the same way.
+func newOverloadedError(addr fmt.Stringer, limit uint64) error {
+	return driver.Error{
+		Message: fmt.Sprintf("concurrency limit of %d operations in progress on %v reached", limit, addr),
+		Labels:  []string{driver.SystemOverloadedError, driver.RetryableError},
+		Wrapped: driver.ErrOverloaded,
+	}
+}
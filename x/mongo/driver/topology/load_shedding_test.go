@@ -0,0 +1,154 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package topology
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Parallel()
+
+	newLimiter := func(cfg LoadSheddingConfig, changes *[]uint64) *concurrencyLimiter {
+		return newConcurrencyLimiter(cfg.withDefaults(100), nil, func(limit, _ uint64) {
+			*changes = append(*changes, limit)
+		})
+	}
+	// run acquires n slots and releases them with the given latency.
+	run := func(l *concurrencyLimiter, n int, latency time.Duration) {
+		for i := 0; i < n; i++ {
+			assert.True(t, l.acquire(), "expected a slot to be acquired")
+		}
+		for i := 0; i < n; i++ {
+			l.release(latency)
+		}
+	}
+
+	t.Run("rejects operations over the limit", func(t *testing.T) {
+		t.Parallel()
+
+		var changes []uint64
+		l := newLimiter(LoadSheddingConfig{InitialLimit: 2, MinLimit: 1}, &changes)
+		assert.True(t, l.acquire(), "expected the first slot to be acquired")
+		assert.True(t, l.acquire(), "expected the second slot to be acquired")
+		assert.False(t, l.acquire(), "expected the third slot to be rejected")
+
+		l.release(0)
+		assert.True(t, l.acquire(), "expected a released slot to be acquired")
+		assert.Equal(t, 0, len(changes), "expected released slots without latency not to change the limit")
+	})
+	t.Run("increases while operations are fast", func(t *testing.T) {
+		t.Parallel()
+
+		var changes []uint64
+		l := newLimiter(LoadSheddingConfig{InitialLimit: 20}, &changes)
+		for i := 0; i < 10; i++ {
+			limit, _ := l.current()
+			run(l, int(limit), 10*time.Millisecond)
+		}
+
+		limit, inFlight := l.current()
+		assert.Greater(t, limit, uint64(20), "expected the limit to increase")
+		assert.Equal(t, uint64(0), inFlight, "expected no operations in progress")
+		assert.NotEqual(t, 0, len(changes), "expected the changes of the limit to be published")
+	})
+	t.Run("does not increase while operations don't use it", func(t *testing.T) {
+		t.Parallel()
+
+		var changes []uint64
+		l := newLimiter(LoadSheddingConfig{InitialLimit: 20}, &changes)
+		for i := 0; i < 100; i++ {
+			run(l, 1, 10*time.Millisecond)
+		}
+
+		limit, _ := l.current()
+		assert.Equal(t, uint64(20), limit, "expected the limit not to change")
+	})
+	t.Run("decreases when operations slow down", func(t *testing.T) {
+		t.Parallel()
+
+		var changes []uint64
+		l := newLimiter(LoadSheddingConfig{InitialLimit: 50, MinLimit: 5}, &changes)
+		run(l, 50, 10*time.Millisecond)
+		before, _ := l.current()
+		run(l, int(before), 100*time.Millisecond)
+
+		limit, _ := l.current()
+		assert.Less(t, limit, before/2, "expected the limit to decrease")
+		assert.GreaterOrEqual(t, limit, uint64(5), "expected the limit not to go below the minimum")
+	})
+	t.Run("decreases when the RTT increases", func(t *testing.T) {
+		t.Parallel()
+
+		l := newConcurrencyLimiter(
+			LoadSheddingConfig{InitialLimit: 50}.withDefaults(100),
+			func() (time.Duration, time.Duration) { return time.Millisecond, 10 * time.Millisecond },
+			nil)
+		for i := 0; i < 10; i++ {
+			limit, _ := l.current()
+			run(l, int(limit), 10*time.Millisecond)
+		}
+
+		limit, _ := l.current()
+		assert.Less(t, limit, uint64(50), "expected the limit to decrease")
+	})
+	t.Run("decreases when the server is overloaded", func(t *testing.T) {
+		t.Parallel()
+
+		var changes []uint64
+		l := newLimiter(LoadSheddingConfig{InitialLimit: 20, MinLimit: 10}, &changes)
+		for i := 0; i < 20; i++ {
+			l.overloaded()
+		}
+
+		limit, _ := l.current()
+		assert.Equal(t, uint64(10), limit, "expected the limit to decrease to the minimum")
+		assert.Equal(t, uint64(10), changes[len(changes)-1], "expected the last change to be published")
+	})
+}
+
+func TestOverloadRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limits the number of retries of a command", func(t *testing.T) {
+		t.Parallel()
+
+		b := newOverloadRetryBudget(LoadSheddingConfig{MaxRetries: 2}.withDefaults(100))
+		for rejections := 1; rejections <= 2; rejections++ {
+			backoff, ok := b.retryBackoff(rejections)
+			assert.True(t, ok, "expected rejection %d to be retried", rejections)
+			maxBackoff := defaultOverloadRetryBackoff << (rejections - 1)
+			assert.LessOrEqual(t, backoff, maxBackoff, "expected the backoff to be at most %v", maxBackoff)
+		}
+		_, ok := b.retryBackoff(3)
+		assert.False(t, ok, "expected the third rejection not to be retried")
+	})
+	t.Run("stops retries when it is used up", func(t *testing.T) {
+		t.Parallel()
+
+		b := newOverloadRetryBudget(LoadSheddingConfig{RetryBudget: 0.5}.withDefaults(100))
+		for i := 0; i < overloadRetryTokens/2; i++ {
+			b.rejected()
+		}
+		_, ok := b.retryBackoff(1)
+		assert.False(t, ok, "expected no retries once half of the budget is used up")
+
+		b.succeeded()
+		_, ok = b.retryBackoff(1)
+		assert.True(t, ok, "expected successful commands to fill up the budget")
+	})
+	t.Run("retries can be disabled", func(t *testing.T) {
+		t.Parallel()
+
+		b := newOverloadRetryBudget(LoadSheddingConfig{MaxRetries: -1}.withDefaults(100))
+		_, ok := b.retryBackoff(1)
+		assert.False(t, ok, "expected no retries")
+	})
+}
@@ -9,6 +9,7 @@ package topology
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -16,8 +17,10 @@ import (
 
 	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
 	"github.com/hongyuyang/mongo-go-driver/event"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
 	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
 )
 
@@ -73,7 +76,13 @@ type poolConfig struct {
 	MaxPoolSize      uint64
 	MaxConnecting    uint64
 	MaxIdleTime      time.Duration
+	MaxLifeTime      time.Duration
+	LifeTimeJitter   time.Duration
 	MaintainInterval time.Duration
+	PriorityShares   map[priority.Priority]float64
+	PriorityAging    time.Duration
+	LoadShedding     *LoadSheddingConfig
+	RTT              func() (time.Duration, time.Duration)
 	LoadBalanced     bool
 	PoolMonitor      *event.PoolMonitor
 	Logger           *logger.Logger
@@ -89,6 +98,7 @@ type pool struct {
 	nextID                       uint64 // nextID is the next pool ID for a new connection.
 	pinnedCursorConnections      uint64
 	pinnedTransactionConnections uint64
+	inUse                        [numPriorities]int64 // inUse holds the number of checked-out connections of each priority.
 
 	address       address.Address
 	minSize       uint64
@@ -105,6 +115,14 @@ type pool struct {
 	connOpts   []ConnectionOption
 	generation *poolGenerationMap
 
+	// priorityLimits holds the maximum number of connections the checkOuts of each priority can
+	// hold, or 0 if the number is not limited.
+	priorityLimits [numPriorities]int64
+
+	// limiter limits the number of checked-out connections and checkOuts waiting for one, or is nil
+	// if load shedding is disabled.
+	limiter *concurrencyLimiter
+
 	maintainInterval time.Duration   // maintainInterval is the maintain() loop interval.
 	maintainReady    chan struct{}   // maintainReady is a signal channel that starts the maintain() loop when ready() is called.
 	backgroundDone   *sync.WaitGroup // backgroundDone waits for all background goroutines to return.
@@ -177,6 +195,11 @@ func connectionPerished(conn *connection) (reason, bool) {
 			loggerConn: logger.ReasonConnClosedIdle,
 			event:      event.ReasonIdle,
 		}, true
+	case conn.lifeTimeExpired():
+		return reason{
+			loggerConn: logger.ReasonConnClosedLifeTime,
+			event:      event.ReasonLifeTimeExpired,
+		}, true
 	case conn.pool.stale(conn):
 		return reason{
 			loggerConn: logger.ReasonConnClosedStale,
@@ -192,6 +215,11 @@ func newPool(config poolConfig, connOpts ...ConnectionOption) *pool {
 	if config.MaxIdleTime != time.Duration(0) {
 		connOpts = append(connOpts, WithIdleTimeout(func(_ time.Duration) time.Duration { return config.MaxIdleTime }))
 	}
+	if config.MaxLifeTime != time.Duration(0) {
+		connOpts = append(connOpts, WithLifeTime(func(time.Duration, time.Duration) (time.Duration, time.Duration) {
+			return config.MaxLifeTime, config.LifeTimeJitter
+		}))
+	}
 
 	var maxConnecting uint64 = 2
 	if config.MaxConnecting > 0 {
@@ -203,6 +231,11 @@ func newPool(config poolConfig, connOpts ...ConnectionOption) *pool {
 		maintainInterval = config.MaintainInterval
 	}
 
+	priorityAging := defaultPriorityAging
+	if config.PriorityAging != 0 {
+		priorityAging = config.PriorityAging
+	}
+
 	pool := &pool{
 		address:               config.Address,
 		minSize:               config.MinPoolSize,
@@ -221,6 +254,19 @@ func newPool(config poolConfig, connOpts ...ConnectionOption) *pool {
 		createConnectionsCond: sync.NewCond(&sync.Mutex{}),
 		conns:                 make(map[uint64]*connection, config.MaxPoolSize),
 		idleConns:             make([]*connection, 0, config.MaxPoolSize),
+		newConnWait:           wantConnQueue{aging: priorityAging},
+		idleConnWait:          wantConnQueue{aging: priorityAging},
+	}
+	if config.MaxPoolSize != 0 {
+		for prio, share := range config.PriorityShares {
+			if share > 0 && share < 1 {
+				pool.priorityLimits[priorityIndex(prio)] = int64(math.Ceil(share * float64(config.MaxPoolSize)))
+			}
+		}
+	}
+	if config.LoadShedding != nil {
+		cfg := config.LoadShedding.withDefaults(config.MaxPoolSize)
+		pool.limiter = newConcurrencyLimiter(cfg, config.RTT, pool.publishConcurrencyLimit)
 	}
 	// minSize must not exceed maxSize if maxSize is not 0
 	if pool.maxSize != 0 && pool.minSize > pool.maxSize {
@@ -384,7 +430,7 @@ func (p *pool) close(ctx context.Context) {
 	}
 	p.idleConns = p.idleConns[:0]
 	for {
-		w := p.idleConnWait.popFront()
+		w := p.idleConnWait.popFront(nil)
 		if w == nil {
 			break
 		}
@@ -401,7 +447,7 @@ func (p *pool) close(ctx context.Context) {
 		conns = append(conns, conn)
 	}
 	for {
-		w := p.newConnWait.popFront()
+		w := p.newConnWait.popFront(nil)
 		if w == nil {
 			break
 		}
@@ -458,12 +504,15 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 		logPoolMessage(p, logger.ConnectionCheckoutStarted)
 	}
 
+	prio := priorityOf(driverutil.PriorityFromContext(ctx))
+
 	// TODO(CSOT): If a Timeout was specified at any level, respect the Timeout is server selection, connection
 	// TODO checkout.
 	if p.monitor != nil {
 		p.monitor.Event(&event.PoolEvent{
-			Type:    event.GetStarted,
-			Address: p.address.String(),
+			Type:     event.GetStarted,
+			Address:  p.address.String(),
+			Priority: int(prio),
 		})
 	}
 
@@ -493,6 +542,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 				Type:     event.GetFailed,
 				Address:  p.address.String(),
 				Duration: duration,
+				Priority: int(prio),
 				Reason:   event.ReasonPoolClosed,
 			})
 		}
@@ -516,6 +566,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 				Type:     event.GetFailed,
 				Address:  p.address.String(),
 				Duration: duration,
+				Priority: int(prio),
 				Reason:   event.ReasonConnectionErrored,
 				Error:    err,
 			})
@@ -523,6 +574,46 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 		return nil, err
 	}
 
+	// Reject the checkOut without waiting if the number of checked-out connections and checkOuts
+	// waiting for one reached the concurrency limit, so that operations don't pile up while the
+	// server is overloaded. Otherwise, free the slot taken by the checkOut if it fails.
+	if !p.limiter.acquire() {
+		p.stateMu.RUnlock()
+
+		limit, _ := p.limiter.current()
+		err := newOverloadedError(p.address, limit)
+
+		duration := time.Since(start)
+		if mustLogPoolMessage(p) {
+			keysAndValues := logger.KeyValues{
+				logger.KeyDurationMS, duration.Milliseconds(),
+				logger.KeyReason, logger.ReasonConnCheckoutFailedOverloaded,
+			}
+
+			logPoolMessage(p, logger.ConnectionCheckoutFailed, keysAndValues...)
+		}
+
+		if p.monitor != nil {
+			p.monitor.Event(&event.PoolEvent{
+				Type:             event.GetFailed,
+				Address:          p.address.String(),
+				Duration:         duration,
+				Priority:         int(prio),
+				Reason:           event.ReasonOverloaded,
+				Error:            err,
+				ConcurrencyLimit: limit,
+			})
+		}
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			p.limiter.release(0)
+		} else if p.limiter != nil {
+			conn.checkedOutAt = start
+		}
+	}()
+
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -531,7 +622,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 	// cancel the wantConn if checkOut() returned an error to make sure any delivered connections
 	// are returned to the pool (e.g. if a connection was delivered immediately after the Context
 	// timed out).
-	w := newWantConn()
+	w := newWantConn(prio)
 	defer func() {
 		if err != nil {
 			w.cancel(p, err)
@@ -562,6 +653,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 					Type:     event.GetFailed,
 					Address:  p.address.String(),
 					Duration: duration,
+					Priority: int(prio),
 					Reason:   event.ReasonConnectionErrored,
 					Error:    w.err,
 				})
@@ -585,6 +677,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 				Address:      p.address.String(),
 				ConnectionID: w.conn.driverConnectionID,
 				Duration:     duration,
+				Priority:     int(prio),
 			})
 		}
 
@@ -617,6 +710,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 					Type:     event.GetFailed,
 					Address:  p.address.String(),
 					Duration: duration,
+					Priority: int(prio),
 					Reason:   event.ReasonConnectionErrored,
 					Error:    w.err,
 				})
@@ -641,6 +735,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 				Address:      p.address.String(),
 				ConnectionID: w.conn.driverConnectionID,
 				Duration:     duration,
+				Priority:     int(prio),
 			})
 		}
 		return w.conn, nil
@@ -662,6 +757,7 @@ func (p *pool) checkOut(ctx context.Context) (conn *connection, err error) {
 				Type:     event.GetFailed,
 				Address:  p.address.String(),
 				Duration: duration,
+				Priority: int(prio),
 				Reason:   event.ReasonTimedOut,
 				Error:    ctx.Err(),
 			})
@@ -878,6 +974,15 @@ func (p *pool) checkInNoEvent(conn *connection) error {
 		return ErrWrongPool
 	}
 
+	p.releaseInUse(conn)
+
+	// Free the slot of the concurrency limit taken by the checkOut of the connection, adjusting the
+	// limit with the time the connection was checked out.
+	if !conn.checkedOutAt.IsZero() {
+		p.limiter.release(time.Since(conn.checkedOutAt))
+		conn.checkedOutAt = time.Time{}
+	}
+
 	// If the connection has an awaiting server response, try to read the
 	// response in another goroutine before checking it back into the pool.
 	//
@@ -920,7 +1025,7 @@ func (p *pool) checkInNoEvent(conn *connection) error {
 	defer p.idleMu.Unlock()
 
 	for {
-		w := p.idleConnWait.popFront()
+		w := p.idleConnWait.popFront(p.belowPriorityLimit)
 		if w == nil {
 			break
 		}
@@ -1043,7 +1148,7 @@ func (p *pool) clearImpl(err error, serviceID *primitive.ObjectID, interruptAllC
 		// Clear the idle connections wait queue.
 		p.idleMu.Lock()
 		for {
-			w := p.idleConnWait.popFront()
+			w := p.idleConnWait.popFront(nil)
 			if w == nil {
 				break
 			}
@@ -1056,7 +1161,7 @@ func (p *pool) clearImpl(err error, serviceID *primitive.ObjectID, interruptAllC
 		// wantConns into newConnWait until the pool is marked "ready" again.
 		p.createConnectionsCond.L.Lock()
 		for {
-			w := p.newConnWait.popFront()
+			w := p.newConnWait.popFront(nil)
 			if w == nil {
 				break
 			}
@@ -1066,16 +1171,43 @@ func (p *pool) clearImpl(err error, serviceID *primitive.ObjectID, interruptAllC
 	}
 }
 
+// drain marks all connections as stale by incrementing the generation number and closes the idle
+// connections. Unlike clear, drain doesn't pause the pool or fail the requests waiting for a
+// connection, so in-use connections are closed when they are checked in and new connections can
+// still be checked out.
+func (p *pool) drain(err error) {
+	if p.getState() == poolClosed {
+		return
+	}
+
+	p.generation.clear(nil)
+
+	if mustLogPoolMessage(p) {
+		logPoolMessage(p, logger.ConnectionPoolDrained)
+	}
+
+	if p.monitor != nil {
+		p.monitor.Event(&event.PoolEvent{
+			Type:    event.PoolDrained,
+			Address: p.address.String(),
+			Error:   err,
+		})
+	}
+
+	p.removePerishedConns()
+}
+
 // getOrQueueForIdleConn attempts to deliver an idle connection to the given wantConn. If there is
-// an idle connection in the idle connections stack, it pops an idle connection, delivers it to the
-// wantConn, and returns true. If there are no idle connections in the idle connections stack, it
-// adds the wantConn to the idleConnWait queue and returns false.
+// an idle connection in the idle connections stack and the checkOuts with the priority of the
+// wantConn hold less than their share of the pool, it pops an idle connection, delivers it to the
+// wantConn, and returns true. Otherwise, it adds the wantConn to the idleConnWait queue and returns
+// false.
 func (p *pool) getOrQueueForIdleConn(w *wantConn) bool {
 	p.idleMu.Lock()
 	defer p.idleMu.Unlock()
 
 	// Try to deliver an idle connection from the idleConns stack first.
-	for len(p.idleConns) > 0 {
+	for len(p.idleConns) > 0 && p.belowPriorityLimit(w) {
 		conn := p.idleConns[len(p.idleConns)-1]
 		p.idleConns = p.idleConns[:len(p.idleConns)-1]
 
@@ -1138,7 +1270,7 @@ func (p *pool) createConnections(ctx context.Context, wg *sync.WaitGroup) {
 	// wait. Note that the condition also listens for Context cancellation, which also causes the
 	// loop to continue, allowing for a subsequent check to return from createConnections().
 	condition := func() bool {
-		checkOutWaiting := p.newConnWait.len() > 0
+		checkOutWaiting := p.newConnWait.peekFront(p.belowPriorityLimit) != nil
 		poolHasSpace := p.maxSize == 0 || uint64(len(p.conns)) < p.maxSize
 		cancelled := ctx.Err() != nil
 		return (checkOutWaiting && poolHasSpace) || cancelled
@@ -1160,8 +1292,7 @@ func (p *pool) createConnections(ctx context.Context, wg *sync.WaitGroup) {
 			return nil, nil, false
 		}
 
-		p.newConnWait.cleanFront()
-		w := p.newConnWait.popFront()
+		w := p.newConnWait.popFront(p.belowPriorityLimit)
 		if w == nil {
 			return nil, nil, false
 		}
@@ -1323,7 +1454,7 @@ func (p *pool) maintain(ctx context.Context, wg *sync.WaitGroup) {
 		}
 
 		for i := 0; i < n; i++ {
-			w := newWantConn()
+			w := newWantConn(priority.Low)
 			p.queueForNewConn(w)
 			wantConns = append(wantConns, w)
 
@@ -1384,14 +1515,19 @@ func compact(arr []*connection) []*connection {
 type wantConn struct {
 	ready chan struct{}
 
+	priority priority.Priority // priority is the priority of the checkOut.
+	queuedAt time.Time         // queuedAt is when the checkOut started waiting for a connection.
+
 	mu   sync.Mutex // Guards conn, err
 	conn *connection
 	err  error
 }
 
-func newWantConn() *wantConn {
+func newWantConn(prio priority.Priority) *wantConn {
 	return &wantConn{
-		ready: make(chan struct{}, 1),
+		ready:    make(chan struct{}, 1),
+		priority: priorityOf(prio),
+		queuedAt: time.Now(),
 	}
 }
 
@@ -1420,6 +1556,12 @@ func (w *wantConn) tryDeliver(conn *connection, err error) bool {
 		panic("x/mongo/driver/topology: internal error: misuse of tryDeliver")
 	}
 
+	// Count the connection as in use by the priority of w before w is ready, so the count is
+	// always incremented before the connection can be checked back in.
+	if conn != nil && conn.pool != nil {
+		conn.pool.acquireInUse(conn, w.priority)
+	}
+
 	close(w.ready)
 
 	return true
@@ -1447,9 +1589,165 @@ func (w *wantConn) cancel(p *pool, err error) {
 	}
 }
 
-// A wantConnQueue is a queue of wantConns.
-// Based on https://cs.opensource.google/go/go/+/refs/tags/go1.16.6:src/net/http/transport.go;l=1242-1306
+// numPriorities is the number of operation priorities, which is the number of queues in a
+// wantConnQueue.
+const numPriorities = int(priority.High-priority.Low) + 1
+
+// defaultPriorityAging is the default duration after which a waiting checkOut is ranked like a
+// checkOut with the next higher priority.
+const defaultPriorityAging = 500 * time.Millisecond
+
+// priorityOf returns the closest operation priority to prio.
+func priorityOf(prio priority.Priority) priority.Priority {
+	switch {
+	case prio < priority.Low:
+		return priority.Low
+	case prio > priority.High:
+		return priority.High
+	default:
+		return prio
+	}
+}
+
+// priorityIndex returns the index of the wait queue and the in-use connection count of prio.
+func priorityIndex(prio priority.Priority) int {
+	return int(priorityOf(prio) - priority.Low)
+}
+
+// acquireInUse counts conn as checked out by a checkOut with the given priority.
+func (p *pool) acquireInUse(conn *connection, prio priority.Priority) {
+	conn.priority = prio
+	conn.inUse = true
+	atomic.AddInt64(&p.inUse[priorityIndex(prio)], 1)
+}
+
+// releaseInUse stops counting conn as checked out. If the checkOuts with the priority of conn were
+// limited to their share of the pool, releaseInUse wakes up createConnections() because a waiting
+// checkOut with the priority may now get a new connection.
+func (p *pool) releaseInUse(conn *connection) {
+	if !conn.inUse {
+		return
+	}
+	conn.inUse = false
+
+	idx := priorityIndex(conn.priority)
+	atomic.AddInt64(&p.inUse[idx], -1)
+
+	if p.priorityLimits[idx] > 0 {
+		p.createConnectionsCond.L.Lock()
+		p.createConnectionsCond.Signal()
+		p.createConnectionsCond.L.Unlock()
+	}
+}
+
+// publishConcurrencyLimit publishes a ConcurrencyLimitChanged event with the new concurrency limit
+// and the number of checked-out connections and checkOuts waiting for one.
+func (p *pool) publishConcurrencyLimit(limit, inFlight uint64) {
+	if p.monitor != nil {
+		p.monitor.Event(&event.PoolEvent{
+			Type:             event.ConcurrencyLimitChanged,
+			Address:          p.address.String(),
+			ConcurrencyLimit: limit,
+			InFlight:         inFlight,
+		})
+	}
+}
+
+// belowPriorityLimit returns true if the checkOuts with the priority of w hold less than their
+// share of the pool, so w may get a connection.
+func (p *pool) belowPriorityLimit(w *wantConn) bool {
+	idx := priorityIndex(w.priority)
+	limit := p.priorityLimits[idx]
+	return limit == 0 || atomic.LoadInt64(&p.inUse[idx]) < limit
+}
+
+// A wantConnQueue is a queue of wantConns ordered by priority. It holds a FIFO queue for each
+// priority and pops the front wantConn with the highest rank. The rank of a wantConn is its
+// priority, increased by one for each aging interval it has waited, so that checkOuts with a low
+// priority are eventually served while checkOuts with a higher priority keep arriving. wantConns
+// with the same rank are popped in the order they started waiting.
 type wantConnQueue struct {
+	queues [numPriorities]wantConnFIFO
+	aging  time.Duration
+}
+
+// len returns the number of items in the queue.
+func (q *wantConnQueue) len() int {
+	n := 0
+	for i := range q.queues {
+		n += q.queues[i].len()
+	}
+	return n
+}
+
+// pushBack adds w to the back of the queue of its priority.
+func (q *wantConnQueue) pushBack(w *wantConn) {
+	q.queues[priorityIndex(w.priority)].pushBack(w)
+}
+
+// popFront removes and returns the waiting wantConn with the highest rank for which eligible
+// returns true. If eligible is nil, all wantConns are eligible.
+func (q *wantConnQueue) popFront(eligible func(*wantConn) bool) *wantConn {
+	idx := q.front(eligible)
+	if idx < 0 {
+		return nil
+	}
+	return q.queues[idx].popFront()
+}
+
+// peekFront returns the waiting wantConn with the highest rank for which eligible returns true
+// without removing it. If eligible is nil, all wantConns are eligible.
+func (q *wantConnQueue) peekFront(eligible func(*wantConn) bool) *wantConn {
+	idx := q.front(eligible)
+	if idx < 0 {
+		return nil
+	}
+	return q.queues[idx].peekFront()
+}
+
+// front returns the index of the queue whose front wantConn has the highest rank among the
+// eligible ones, or -1 if there is no eligible wantConn. Because a wantConn only becomes eligible
+// or ineligible with the in-use connection count of its priority, only the front of each queue has
+// to be considered.
+func (q *wantConnQueue) front(eligible func(*wantConn) bool) int {
+	q.cleanFront()
+
+	now := time.Now()
+	best, bestRank := -1, 0
+	for i := range q.queues {
+		w := q.queues[i].peekFront()
+		if w == nil || (eligible != nil && !eligible(w)) {
+			continue
+		}
+
+		rank := q.rank(w, now)
+		if best < 0 || rank > bestRank ||
+			(rank == bestRank && w.queuedAt.Before(q.queues[best].peekFront().queuedAt)) {
+			best, bestRank = i, rank
+		}
+	}
+	return best
+}
+
+// rank returns the priority of w increased by one for each aging interval w has waited.
+func (q *wantConnQueue) rank(w *wantConn, now time.Time) int {
+	rank := int(w.priority)
+	if q.aging > 0 {
+		rank += int(now.Sub(w.queuedAt) / q.aging)
+	}
+	return rank
+}
+
+// cleanFront pops any wantConns that are no longer waiting from the heads of the queues.
+func (q *wantConnQueue) cleanFront() {
+	for i := range q.queues {
+		q.queues[i].cleanFront()
+	}
+}
+
+// A wantConnFIFO is a FIFO queue of wantConns.
+// Based on https://cs.opensource.google/go/go/+/refs/tags/go1.16.6:src/net/http/transport.go;l=1242-1306
+type wantConnFIFO struct {
 	// This is a queue, not a deque.
 	// It is split into two stages - head[headPos:] and tail.
 	// popFront is trivial (headPos++) on the first stage, and
@@ -1466,17 +1764,17 @@ type wantConnQueue struct {
 }
 
 // len returns the number of items in the queue.
-func (q *wantConnQueue) len() int {
+func (q *wantConnFIFO) len() int {
 	return len(q.head) - q.headPos + len(q.tail)
 }
 
 // pushBack adds w to the back of the queue.
-func (q *wantConnQueue) pushBack(w *wantConn) {
+func (q *wantConnFIFO) pushBack(w *wantConn) {
 	q.tail = append(q.tail, w)
 }
 
 // popFront removes and returns the wantConn at the front of the queue.
-func (q *wantConnQueue) popFront() *wantConn {
+func (q *wantConnFIFO) popFront() *wantConn {
 	if q.headPos >= len(q.head) {
 		if len(q.tail) == 0 {
 			return nil
@@ -1491,7 +1789,7 @@ func (q *wantConnQueue) popFront() *wantConn {
 }
 
 // peekFront returns the wantConn at the front of the queue without removing it.
-func (q *wantConnQueue) peekFront() *wantConn {
+func (q *wantConnFIFO) peekFront() *wantConn {
 	if q.headPos < len(q.head) {
 		return q.head[q.headPos]
 	}
@@ -1502,7 +1800,7 @@ func (q *wantConnQueue) peekFront() *wantConn {
 }
 
 // cleanFront pops any wantConns that are no longer waiting from the head of the queue.
-func (q *wantConnQueue) cleanFront() {
+func (q *wantConnFIFO) cleanFront() {
 	for {
 		w := q.peekFront()
 		if w == nil || w.waiting() {
@@ -16,9 +16,11 @@ import (
 
 	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
 	"github.com/hongyuyang/mongo-go-driver/internal/eventtest"
 	"github.com/hongyuyang/mongo-go-driver/internal/require"
 	"github.com/hongyuyang/mongo-go-driver/mongo/address"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/operation"
 )
@@ -818,6 +820,168 @@ func TestPool(t *testing.T) {
 
 			p.close(context.Background())
 		})
+		t.Run("serves higher priorities first", func(t *testing.T) {
+			t.Parallel()
+
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+				<-cleanup
+				_ = nc.Close()
+			})
+
+			p := newPool(poolConfig{
+				Address:       address.Address(addr.String()),
+				MaxPoolSize:   1,
+				PriorityAging: time.Hour,
+			})
+			err := p.ready()
+			noerr(t, err)
+			defer p.close(context.Background())
+
+			c, err := p.checkOut(context.Background())
+			noerr(t, err)
+
+			order := startPriorityCheckOuts(t, p, priority.Low, priority.Normal, priority.High)
+
+			// Check in the connection and expect the waiting checkOuts to get it in order of their
+			// priority, even though they started waiting in the opposite order.
+			err = p.checkIn(c)
+			noerr(t, err)
+			for _, want := range []priority.Priority{priority.High, priority.Normal, priority.Low} {
+				assert.Equal(t, want, <-order, "expected the checkOuts to be served in order of priority")
+			}
+		})
+		t.Run("serves low priorities that waited for a long time", func(t *testing.T) {
+			t.Parallel()
+
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+				<-cleanup
+				_ = nc.Close()
+			})
+
+			p := newPool(poolConfig{
+				Address:       address.Address(addr.String()),
+				MaxPoolSize:   1,
+				PriorityAging: 10 * time.Millisecond,
+			})
+			err := p.ready()
+			noerr(t, err)
+			defer p.close(context.Background())
+
+			c, err := p.checkOut(context.Background())
+			noerr(t, err)
+
+			// Let the low priority checkOut wait for more than two aging intervals, so it outranks
+			// the high priority checkOut that starts waiting after it.
+			order := startPriorityCheckOuts(t, p, priority.Low)
+			time.Sleep(50 * time.Millisecond)
+			highOrder := startPriorityCheckOuts(t, p, priority.High)
+
+			err = p.checkIn(c)
+			noerr(t, err)
+			assert.Equal(t, priority.Low, <-order, "expected the low priority checkOut to be served first")
+			assert.Equal(t, priority.High, <-highOrder, "expected the high priority checkOut to be served")
+		})
+		t.Run("limits the share of the pool of a priority", func(t *testing.T) {
+			t.Parallel()
+
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+				<-cleanup
+				_ = nc.Close()
+			})
+
+			tpm := eventtest.NewTestPoolMonitor()
+			p := newPool(poolConfig{
+				Address:        address.Address(addr.String()),
+				MaxPoolSize:    4,
+				PriorityShares: map[priority.Priority]float64{priority.Low: 0.25},
+				PoolMonitor:    tpm.PoolMonitor,
+			})
+			err := p.ready()
+			noerr(t, err)
+			defer p.close(context.Background())
+
+			lowCtx := driverutil.WithPriority(context.Background(), priority.Low)
+			c, err := p.checkOut(lowCtx)
+			noerr(t, err)
+
+			// The low priority checkOuts hold their share of 1 connection, so another one has to wait
+			// even though the pool has space for new connections.
+			ctx, cancel := context.WithTimeout(lowCtx, 50*time.Millisecond)
+			defer cancel()
+			_, err = p.checkOut(ctx)
+			assert.IsTypef(t, WaitQueueTimeoutError{}, err, "expected a WaitQueueTimeoutError")
+
+			normal, err := p.checkOut(context.Background())
+			noerr(t, err)
+			err = p.checkIn(normal)
+			noerr(t, err)
+
+			// Once the connection is checked in, another low priority checkOut gets a connection.
+			order := startPriorityCheckOuts(t, p, priority.Low)
+			err = p.checkIn(c)
+			noerr(t, err)
+			assert.Equal(t, priority.Low, <-order, "expected the low priority checkOut to be served")
+
+			failed := tpm.Events(func(evt *event.PoolEvent) bool {
+				return evt.Type == event.GetFailed
+			})
+			require.Len(t, failed, 1, "expected a ConnectionCheckOutFailed event")
+			assert.Equal(t, int(priority.Low), failed[0].Priority, "expected the event to have the priority of the checkOut")
+		})
+		t.Run("rejects checkOuts over the concurrency limit", func(t *testing.T) {
+			t.Parallel()
+
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+				<-cleanup
+				_ = nc.Close()
+			})
+
+			tpm := eventtest.NewTestPoolMonitor()
+			p := newPool(poolConfig{
+				Address:      address.Address(addr.String()),
+				MaxPoolSize:  4,
+				LoadShedding: &LoadSheddingConfig{InitialLimit: 1, MinLimit: 1},
+				PoolMonitor:  tpm.PoolMonitor,
+			})
+			err := p.ready()
+			noerr(t, err)
+			defer p.close(context.Background())
+
+			c, err := p.checkOut(context.Background())
+			noerr(t, err)
+
+			// The checked-out connection uses the concurrency limit of 1, so another checkOut is
+			// rejected immediately even though the pool has space for new connections.
+			_, err = p.checkOut(context.Background())
+			assert.ErrorIs(t, err, driver.ErrOverloaded)
+			var derr driver.Error
+			require.True(t, errors.As(err, &derr), "expected a driver.Error, got %T", err)
+			assert.True(t, derr.HasErrorLabel(driver.SystemOverloadedError), "expected the SystemOverloadedError label")
+			assert.True(t, derr.HasErrorLabel(driver.RetryableError), "expected the RetryableError label")
+
+			failed := tpm.Events(func(evt *event.PoolEvent) bool {
+				return evt.Type == event.GetFailed
+			})
+			require.Len(t, failed, 1, "expected a ConnectionCheckOutFailed event")
+			assert.Equal(t, event.ReasonOverloaded, failed[0].Reason, "expected the overloaded reason")
+			assert.Equal(t, uint64(1), failed[0].ConcurrencyLimit, "expected the event to have the concurrency limit")
+
+			// Once the connection is checked in, its slot can be used by another checkOut.
+			err = p.checkIn(c)
+			noerr(t, err)
+			c, err = p.checkOut(context.Background())
+			noerr(t, err)
+			err = p.checkIn(c)
+			noerr(t, err)
+		})
 	})
 	t.Run("checkIn", func(t *testing.T) {
 		t.Parallel()
@@ -947,6 +1111,49 @@ func TestPool(t *testing.T) {
 			assert.Equalf(t, 1, p.availableConnectionCount(), "should have 1 idle connections in pool")
 			assert.Equalf(t, 1, p.totalConnectionCount(), "should have 1 total connection in pool")
 		})
+		t.Run("closes connections that exceeded their max lifetime", func(t *testing.T) {
+			t.Parallel()
+
+			cleanup := make(chan struct{})
+			defer close(cleanup)
+			addr := bootstrapConnections(t, 1, func(nc net.Conn) {
+				<-cleanup
+				_ = nc.Close()
+			})
+
+			d := newdialer(&net.Dialer{})
+			tpm := eventtest.NewTestPoolMonitor()
+			p := newPool(poolConfig{
+				Address:        address.Address(addr.String()),
+				MaxLifeTime:    100 * time.Millisecond,
+				LifeTimeJitter: 10 * time.Millisecond,
+				PoolMonitor:    tpm.PoolMonitor,
+			}, WithDialer(func(Dialer) Dialer { return d }))
+			err := p.ready()
+			noerr(t, err)
+			defer p.close(context.Background())
+
+			c, err := p.checkOut(context.Background())
+			noerr(t, err)
+			lifeTime := time.Until(c.lifeTimeDeadline)
+			assert.True(t, lifeTime > 0 && lifeTime <= 110*time.Millisecond,
+				"expected a lifetime between 0 and 110ms, got %v", lifeTime)
+
+			// Sleep for 120ms, which will exceed the connection lifetime including the jitter. Then check
+			// the connection back in and expect that it is closed even though it was never idle.
+			time.Sleep(120 * time.Millisecond)
+			err = p.checkIn(c)
+			noerr(t, err)
+
+			assertConnectionsClosed(t, d, 1)
+			assert.Equalf(t, 0, p.totalConnectionCount(), "should have 0 total connections in pool")
+
+			closed := tpm.Events(func(evt *event.PoolEvent) bool {
+				return evt.Type == event.ConnectionClosed
+			})
+			require.Len(t, closed, 1, "expected a ConnectionClosed event")
+			assert.Equal(t, event.ReasonLifeTimeExpired, closed[0].Reason, "unexpected ConnectionClosed reason")
+		})
 		t.Run("sets minPoolSize connection idle deadline", func(t *testing.T) {
 			t.Parallel()
 
@@ -1124,6 +1331,38 @@ func TestPool(t *testing.T) {
 	})
 }
 
+// startPriorityCheckOuts starts a checkOut for each of the given priorities, one after the other,
+// and waits until all of them are waiting for a connection. Each checkOut sends its priority to the
+// returned channel when it gets a connection and then checks the connection back in.
+func startPriorityCheckOuts(t *testing.T, p *pool, prios ...priority.Priority) <-chan priority.Priority {
+	t.Helper()
+
+	waiting := func() int {
+		p.idleMu.Lock()
+		defer p.idleMu.Unlock()
+
+		p.idleConnWait.cleanFront()
+		return p.idleConnWait.len()
+	}
+
+	order := make(chan priority.Priority, len(prios))
+	for _, prio := range prios {
+		n := waiting()
+		prio := prio
+		go func() {
+			c, err := p.checkOut(driverutil.WithPriority(context.Background(), prio))
+			if !assert.NoError(t, err, "checkOut error") {
+				return
+			}
+			order <- prio
+			_ = p.checkIn(c)
+		}()
+		assert.Eventually(t, func() bool { return waiting() > n }, time.Second, time.Millisecond,
+			"expected the checkOut with priority %v to wait for a connection", prio)
+	}
+	return order
+}
+
 func assertConnectionsClosed(t *testing.T, dialer *dialer, count int) {
 	t.Helper()
 
@@ -1165,6 +1404,44 @@ func assertConnectionsOpened(t *testing.T, dialer *dialer, count int) {
 func TestPool_PoolMonitor(t *testing.T) {
 	t.Parallel()
 
+	t.Run("drain", func(t *testing.T) {
+		t.Parallel()
+
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+		addr := bootstrapConnections(t, 2, func(nc net.Conn) {
+			<-cleanup
+			_ = nc.Close()
+		})
+
+		tpm := eventtest.NewTestPoolMonitor()
+		p := newPool(poolConfig{
+			Address:     address.Address(addr.String()),
+			PoolMonitor: tpm.PoolMonitor,
+		})
+		err := p.ready()
+		require.NoError(t, err, "ready error")
+		defer p.close(context.Background())
+
+		conn, err := p.checkOut(context.Background())
+		require.NoError(t, err, "checkOut error")
+
+		p.drain(ErrServerExcluded)
+
+		assert.Equal(t, poolReady, p.getState(), "expected the pool not to be paused")
+		drained := tpm.Events(func(evt *event.PoolEvent) bool { return evt.Type == event.PoolDrained })
+		require.Len(t, drained, 1, "expected a ConnectionPoolDrained event")
+		assert.Equal(t, ErrServerExcluded, drained[0].Error, "expected the event to have the error")
+		assert.False(t, tpm.IsPoolCleared(), "expected no ConnectionPoolCleared event")
+
+		err = p.checkIn(conn)
+		require.NoError(t, err, "checkIn error")
+		assert.Equal(t, 0, p.totalConnectionCount(), "expected the stale connection to be closed on checkIn")
+
+		conn, err = p.checkOut(context.Background())
+		require.NoError(t, err, "expected a new connection to be checked out after draining")
+		_ = p.checkIn(conn)
+	})
 	t.Run("records durations", func(t *testing.T) {
 		t.Parallel()
 
@@ -36,6 +36,9 @@ type rttConfig struct {
 	minRTTWindow       time.Duration
 	createConnectionFn func() *connection
 	createOperationFn  func(driver.Connection) *operation.Hello
+
+	// sampleFn is called with each RTT sample after it's recorded, if it's not nil.
+	sampleFn func(time.Duration)
 }
 
 type rttMonitor struct {
@@ -49,6 +52,7 @@ type rttMonitor struct {
 	offset        int
 	minRTT        time.Duration
 	rtt90         time.Duration
+	rtt95         time.Duration
 	averageRTT    time.Duration
 	averageRTTSet bool
 
@@ -197,11 +201,17 @@ func (r *rttMonitor) reset() {
 	r.offset = 0
 	r.minRTT = 0
 	r.rtt90 = 0
+	r.rtt95 = 0
 	r.averageRTT = 0
 	r.averageRTTSet = false
 }
 
 func (r *rttMonitor) addSample(rtt time.Duration) {
+	// Deferred before the unlock below, so sampleFn is called without holding the lock.
+	if r.cfg.sampleFn != nil {
+		defer r.cfg.sampleFn(rtt)
+	}
+
 	// Lock for the duration of this method. We're doing compuationally inexpensive work very infrequently, so lock
 	// contention isn't expected.
 	r.mu.Lock()
@@ -209,11 +219,12 @@ func (r *rttMonitor) addSample(rtt time.Duration) {
 
 	r.samples[r.offset] = rtt
 	r.offset = (r.offset + 1) % len(r.samples)
-	// Set the minRTT and 90th percentile RTT of all collected samples. Require at least 10 samples before
-	// setting these to prevent noisy samples on startup from artificially increasing RTT and to allow the
-	// calculation of a 90th percentile.
+	// Set the minRTT and 90th and 95th percentile RTT of all collected samples. Require at least 10 samples
+	// before setting these to prevent noisy samples on startup from artificially increasing RTT and to allow
+	// the calculation of the percentiles.
 	r.minRTT = min(r.samples, minSamples)
 	r.rtt90 = percentile(90.0, r.samples, minSamples)
+	r.rtt95 = percentile(95.0, r.samples, minSamples)
 
 	if !r.averageRTTSet {
 		r.averageRTT = rtt
@@ -290,6 +301,14 @@ func (r *rttMonitor) P90() time.Duration {
 	return r.rtt90
 }
 
+// P95 returns the 95th percentile observed round-trip time over the window period.
+func (r *rttMonitor) P95() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.rtt95
+}
+
 // Stats returns stringified stats of the current state of the monitor.
 func (r *rttMonitor) Stats() string {
 	r.mu.RLock()
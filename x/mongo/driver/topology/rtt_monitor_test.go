@@ -122,6 +122,12 @@ func TestRTTMonitor(t *testing.T) {
 			rtt.P90() > 0,
 			"expected P90() to return a positive duration, got %v",
 			rtt.P90())
+		assert.True(
+			t,
+			rtt.P95() >= rtt.P90(),
+			"expected P95() to return a duration of at least P90() %v, got %v",
+			rtt.P90(),
+			rtt.P95())
 	})
 
 	t.Run("creates the correct size samples slice", func(t *testing.T) {
@@ -326,6 +332,25 @@ func TestRTTMonitor(t *testing.T) {
 		l.Close()
 		wg.Wait()
 	})
+	t.Run("reports each sample", func(t *testing.T) {
+		t.Parallel()
+
+		var samples []time.Duration
+		var rtt *rttMonitor
+		rtt = newRTTMonitor(&rttConfig{
+			interval: 10 * time.Second,
+			sampleFn: func(sample time.Duration) {
+				// The samples must be reported without holding the lock.
+				_ = rtt.EWMA()
+				samples = append(samples, sample)
+			},
+		})
+		rtt.addSample(time.Millisecond)
+		rtt.addSample(2 * time.Millisecond)
+
+		want := []time.Duration{time.Millisecond, 2 * time.Millisecond}
+		assert.Equal(t, want, samples, "expected samples %v, got %v", want, samples)
+	})
 }
 
 func TestMin(t *testing.T) {
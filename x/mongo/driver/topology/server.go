@@ -22,6 +22,7 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
 	"github.com/hongyuyang/mongo-go-driver/mongo/address"
 	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/connstring"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/operation"
@@ -104,6 +105,7 @@ type Server struct {
 	// description related fields
 	desc                   atomic.Value // holds a description.Server
 	updateTopologyCallback atomic.Value
+	updateCircuitCallback  atomic.Value // holds a func()
 	topologyID             primitive.ObjectID
 
 	// subscriber related fields
@@ -126,6 +128,14 @@ type Server struct {
 	processErrorLock sync.Mutex
 	rttMonitor       *rttMonitor
 	monitorOnce      sync.Once
+
+	// overloadRetryBudget limits the retries of the commands rejected because the server is
+	// overloaded, or is nil if load shedding is disabled.
+	overloadRetryBudget *overloadRetryBudget
+
+	// circuitBreaker removes the server from read selection while its commands keep failing, or is
+	// nil if the circuit breaker is disabled.
+	circuitBreaker *circuitBreaker
 }
 
 // updateTopologyCallback is a callback used to create a server that should be called when the parent Topology instance
@@ -176,6 +186,7 @@ func NewServer(addr address.Address, topologyID primitive.ObjectID, opts ...Serv
 		minRTTWindow:       5 * time.Minute,
 		createConnectionFn: s.createConnection,
 		createOperationFn:  s.createBaseOperation,
+		sampleFn:           s.publishServerRTTSampledEvent,
 	}
 	s.rttMonitor = newRTTMonitor(rttCfg)
 
@@ -185,6 +196,12 @@ func NewServer(addr address.Address, topologyID primitive.ObjectID, opts ...Serv
 		MaxPoolSize:      cfg.maxConns,
 		MaxConnecting:    cfg.maxConnecting,
 		MaxIdleTime:      cfg.poolMaxIdleTime,
+		MaxLifeTime:      cfg.poolMaxLifeTime,
+		LifeTimeJitter:   cfg.poolLifeTimeJitter,
+		PriorityShares:   cfg.poolPriorityShares,
+		PriorityAging:    cfg.poolPriorityAging,
+		LoadShedding:     cfg.loadShedding,
+		RTT:              func() (time.Duration, time.Duration) { return s.rttMonitor.Min(), s.rttMonitor.EWMA() },
 		MaintainInterval: cfg.poolMaintainInterval,
 		LoadBalanced:     cfg.loadBalanced,
 		PoolMonitor:      cfg.poolMonitor,
@@ -194,6 +211,13 @@ func NewServer(addr address.Address, topologyID primitive.ObjectID, opts ...Serv
 
 	connectionOpts := copyConnectionOpts(cfg.connectionOpts)
 	s.pool = newPool(pc, connectionOpts...)
+	if cfg.loadShedding != nil {
+		s.overloadRetryBudget = newOverloadRetryBudget(cfg.loadShedding.withDefaults(cfg.maxConns))
+	}
+	// The load balancer is the only server in load balanced mode, so it must stay selectable.
+	if cfg.circuitBreaker != nil && !cfg.loadBalanced {
+		s.circuitBreaker = newCircuitBreaker(cfg.circuitBreaker.withDefaults(), s.circuitTransition)
+	}
 	s.publishServerOpeningEvent(s.address)
 
 	return s
@@ -277,6 +301,7 @@ func (s *Server) Disconnect(ctx context.Context) error {
 	}
 
 	s.updateTopologyCallback.Store((updateTopologyCallback)(nil))
+	s.updateCircuitCallback.Store((func())(nil))
 
 	// Cancel the global context so any new contexts created from it will be automatically cancelled. Close the done
 	// channel so the update() routine will know that it can stop. Cancel any in-progress monitoring checks at the end.
@@ -287,6 +312,7 @@ func (s *Server) Disconnect(ctx context.Context) error {
 	s.cancelCheck()
 
 	s.pool.close(ctx)
+	s.circuitBreaker.stop()
 
 	s.closewg.Wait()
 	s.rttMonitor.disconnect()
@@ -308,6 +334,9 @@ func (s *Server) Connection(ctx context.Context) (driver.Connection, error) {
 	conn, err := s.pool.checkOut(ctx)
 	if err != nil {
 		atomic.AddInt64(&s.operationCount, -1)
+		if errors.Is(err, driver.ErrOverloaded) {
+			s.overloadRetryBudget.rejected()
+		}
 		return nil, err
 	}
 
@@ -431,6 +460,16 @@ func getWriteConcernErrorForProcessing(err error) (*driver.WriteConcernError, bo
 
 // ProcessError handles SDAM error handling and implements driver.ErrorProcessor.
 func (s *Server) ProcessError(err error, conn driver.Connection) driver.ProcessErrorResult {
+	// Commands rejected because the server is overloaded decrease the concurrency limit of the pool
+	// and use up the retry budget, which successful commands fill up again.
+	var le interface{ HasErrorLabel(string) bool }
+	if err == nil {
+		s.overloadRetryBudget.succeeded()
+	} else if errors.As(err, &le) && le.HasErrorLabel(driver.SystemOverloadedError) {
+		s.overloadRetryBudget.rejected()
+		s.pool.limiter.overloaded()
+	}
+
 	// Ignore nil errors.
 	if err == nil {
 		return driver.NoChange
@@ -821,9 +860,20 @@ func (s *Server) check() (description.Server, error) {
 
 	start := time.Now()
 
-	// Create a new connection if this is the first check, the connection was closed after an error during the previous
-	// check, or the previous check was cancelled.
-	if s.conn == nil || s.conn.closed() || s.checkWasCancelled() {
+	if injectedErr := s.cfg.faultInjector.HeartbeatFault(s.address); injectedErr != nil {
+		// Fail the check as if the monitoring connection was broken, which also closes the connection so the next
+		// check creates a new one.
+		connID := "0"
+		if s.conn != nil {
+			connID = s.conn.ID()
+			_ = s.conn.close()
+		}
+		s.publishServerHeartbeatStartedEvent(connID, false)
+		err = ConnectionError{ConnectionID: connID, Wrapped: injectedErr, message: "heartbeat failed"}
+		s.publishServerHeartbeatFailedEvent(connID, time.Since(start), injectedErr, false)
+	} else if s.conn == nil || s.conn.closed() || s.checkWasCancelled() {
+		// Create a new connection if this is the first check, the connection was closed after an error during the
+		// previous check, or the previous check was cancelled.
 		connID := "0"
 		if s.conn != nil {
 			connID = s.conn.ID()
@@ -914,6 +964,8 @@ func (s *Server) check() (description.Server, error) {
 		// The check was successful. Set the average RTT and the 90th percentile RTT and return.
 		desc := *descPtr
 		desc = desc.SetAverageRTT(s.rttMonitor.EWMA())
+		desc.MinRTT = s.rttMonitor.Min()
+		desc.P90RTT = s.rttMonitor.P90()
 		desc.HeartbeatInterval = s.cfg.heartbeatInterval
 		return desc, nil
 	}
@@ -953,6 +1005,19 @@ func (s *Server) RTTMonitor() driver.RTTMonitor {
 	return s.rttMonitor
 }
 
+// OverloadRetryBackoff returns how long to wait before retrying a command that the server rejected
+// the given number of consecutive times because it is overloaded, or false if the command must not
+// be retried because load shedding is disabled, the command was retried too many times or the
+// retry budget of the server is used up. It implements driver.OverloadRetrier.
+func (s *Server) OverloadRetryBackoff(rejections int) (time.Duration, bool) {
+	return s.overloadRetryBudget.retryBackoff(rejections)
+}
+
+// FaultInjector returns the fault injector of the server's commands and heartbeats, or nil if there is none.
+func (s *Server) FaultInjector() *faultinject.Injector {
+	return s.cfg.faultInjector
+}
+
 // OperationCount returns the current number of in-progress operations for this server.
 func (s *Server) OperationCount() int64 {
 	return atomic.LoadInt64(&s.operationCount)
@@ -1005,6 +1070,57 @@ func (ss *ServerSubscription) Unsubscribe() error {
 	return nil
 }
 
+// StartRead implements the driver.ReadTracker interface. The read is a probe of the circuit breaker
+// if it is half-open.
+func (s *Server) StartRead() {
+	s.circuitBreaker.startOperation()
+}
+
+// FinishRead implements the driver.ReadTracker interface. It records the outcome of the read in the
+// circuit breaker.
+func (s *Server) FinishRead(err error) {
+	s.circuitBreaker.record(err)
+}
+
+// circuitTransition asks the parent Topology to update the server description after the state of
+// the circuit breaker changed and publishes the change to the server monitor.
+func (s *Server) circuitTransition(transition circuitTransition) {
+	if callback, ok := s.updateCircuitCallback.Load().(func()); ok && callback != nil {
+		callback()
+	}
+
+	if !transition.changed || s.cfg.serverMonitor == nil {
+		return
+	}
+	switch transition.to {
+	case circuitOpen:
+		if s.cfg.serverMonitor.CircuitBreakerOpened != nil {
+			s.cfg.serverMonitor.CircuitBreakerOpened(&event.CircuitBreakerOpenedEvent{
+				Address:     s.address,
+				TopologyID:  s.topologyID,
+				FailureRate: transition.failureRate,
+				Commands:    transition.commands,
+				CoolDown:    s.circuitBreaker.cfg.CoolDown,
+			})
+		}
+	case circuitHalfOpen:
+		if s.cfg.serverMonitor.CircuitBreakerHalfOpened != nil {
+			s.cfg.serverMonitor.CircuitBreakerHalfOpened(&event.CircuitBreakerHalfOpenedEvent{
+				Address:    s.address,
+				TopologyID: s.topologyID,
+				Probes:     s.circuitBreaker.cfg.Probes,
+			})
+		}
+	case circuitClosed:
+		if s.cfg.serverMonitor.CircuitBreakerClosed != nil {
+			s.cfg.serverMonitor.CircuitBreakerClosed(&event.CircuitBreakerClosedEvent{
+				Address:    s.address,
+				TopologyID: s.topologyID,
+			})
+		}
+	}
+}
+
 // publishes a ServerOpeningEvent to indicate the server is being initialized
 func (s *Server) publishServerOpeningEvent(addr address.Address) {
 	if s == nil {
@@ -1108,6 +1224,19 @@ func (s *Server) publishServerHeartbeatFailedEvent(connectionID string,
 	}
 }
 
+// publishes a ServerRTTSampledEvent to indicate an RTT sample was recorded
+func (s *Server) publishServerRTTSampledEvent(rtt time.Duration) {
+	if s.cfg.serverMonitor == nil || s.cfg.serverMonitor.ServerRTTSampled == nil {
+		return
+	}
+
+	s.cfg.serverMonitor.ServerRTTSampled(&event.ServerRTTSampledEvent{
+		Address:    s.address,
+		TopologyID: s.topologyID,
+		RTT:        rtt,
+	})
+}
+
 // unwrapConnectionError returns the connection error wrapped by err, or nil if err does not wrap a connection error.
 func unwrapConnectionError(err error) error {
 	// This is essentially an implementation of errors.As to unwrap this error until we get a ConnectionError and then
@@ -13,6 +13,8 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/bson/bsoncodec"
 	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/connstring"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/session"
@@ -33,6 +35,9 @@ type serverConfig struct {
 	monitoringDisabled   bool
 	serverAPI            *driver.ServerAPIOptions
 	loadBalanced         bool
+	faultInjector        *faultinject.Injector
+	loadShedding         *LoadSheddingConfig
+	circuitBreaker       *CircuitBreakerConfig
 
 	// Connection pool options.
 	maxConns             uint64
@@ -41,6 +46,10 @@ type serverConfig struct {
 	poolMonitor          *event.PoolMonitor
 	logger               *logger.Logger
 	poolMaxIdleTime      time.Duration
+	poolMaxLifeTime      time.Duration
+	poolLifeTimeJitter   time.Duration
+	poolPriorityShares   map[priority.Priority]float64
+	poolPriorityAging    time.Duration
 	poolMaintainInterval time.Duration
 }
 
@@ -147,6 +156,33 @@ func WithConnectionPoolMaxIdleTime(fn func(time.Duration) time.Duration) ServerO
 	}
 }
 
+// WithConnectionPoolMaxLifeTime configures the maximum time that a connection can exist before being removed from the
+// connection pool, and the maximum random jitter added to the lifetime of each connection. If the maximum lifetime is
+// 0, connections will not be removed because of their lifetime.
+func WithConnectionPoolMaxLifeTime(fn func(time.Duration, time.Duration) (time.Duration, time.Duration)) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.poolMaxLifeTime, cfg.poolLifeTimeJitter = fn(cfg.poolMaxLifeTime, cfg.poolLifeTimeJitter)
+	}
+}
+
+// WithConnectionPoolPriorityShares configures the maximum share of the connection pool that the checkOuts of each
+// operation priority can hold.
+func WithConnectionPoolPriorityShares(
+	fn func(map[priority.Priority]float64) map[priority.Priority]float64,
+) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.poolPriorityShares = fn(cfg.poolPriorityShares)
+	}
+}
+
+// WithConnectionPoolPriorityAging configures how long a checkOut waits before it's ranked like a checkOut of the next
+// higher operation priority.
+func WithConnectionPoolPriorityAging(fn func(time.Duration) time.Duration) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.poolPriorityAging = fn(cfg.poolPriorityAging)
+	}
+}
+
 // WithConnectionPoolMaintainInterval configures the interval that the background connection pool
 // maintenance goroutine runs.
 func WithConnectionPoolMaintainInterval(fn func(time.Duration) time.Duration) ServerOption {
@@ -198,6 +234,30 @@ func WithServerLoadBalanced(fn func(bool) bool) ServerOption {
 	}
 }
 
+// WithLoadShedding configures the adaptive concurrency limit of the operations in progress on the
+// server and the retries of the commands rejected because the server is overloaded. Load shedding
+// is disabled if the configuration is nil.
+func WithLoadShedding(fn func(*LoadSheddingConfig) *LoadSheddingConfig) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.loadShedding = fn(cfg.loadShedding)
+	}
+}
+
+// WithCircuitBreaker configures the circuit breaker that removes the server from read selection
+// while its commands keep failing. The circuit breaker is disabled if the configuration is nil.
+func WithCircuitBreaker(fn func(*CircuitBreakerConfig) *CircuitBreakerConfig) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.circuitBreaker = fn(cfg.circuitBreaker)
+	}
+}
+
+// WithFaultInjector configures the fault injector of the server's commands and heartbeats.
+func WithFaultInjector(fn func(*faultinject.Injector) *faultinject.Injector) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.faultInjector = fn(cfg.faultInjector)
+	}
+}
+
 // withLogger configures the logger for the server to use.
 func withLogger(fn func() *logger.Logger) ServerOption {
 	return func(cfg *serverConfig) {
@@ -23,6 +23,7 @@ import (
 
 	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
 	"github.com/hongyuyang/mongo-go-driver/event"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
 	"github.com/hongyuyang/mongo-go-driver/internal/randutil"
 	"github.com/hongyuyang/mongo-go-driver/mongo/address"
@@ -57,6 +58,10 @@ var ErrTopologyConnected = errors.New("topology is connected or connecting")
 // selection process took longer than allowed by the timeout.
 var ErrServerSelectionTimeout = errors.New("server selection timeout")
 
+// ErrServerExcluded is reported in the PoolDrained event published when the connection pool of a server is drained
+// because the server is excluded from server selection.
+var ErrServerExcluded = errors.New("server excluded from selection")
+
 // MonitorMode represents the way in which a server is monitored.
 type MonitorMode uint8
 
@@ -108,6 +113,13 @@ type Topology struct {
 	serversClosed bool
 	servers       map[address.Address]*Server
 
+	// excluded contains the addresses of the servers excluded from server selection. It is protected by
+	// serversLock.
+	excluded map[address.Address]bool
+
+	// hedgeBudget limits the hedged reads of the topology. It is nil if hedged reads are not enabled.
+	hedgeBudget *hedgeBudget
+
 	id primitive.ObjectID
 }
 
@@ -146,10 +158,14 @@ func New(cfg *Config) (*Topology, error) {
 		fsm:               newFSM(),
 		subscribers:       make(map[uint64]chan description.Topology),
 		servers:           make(map[address.Address]*Server),
+		excluded:          make(map[address.Address]bool),
 		dnsResolver:       dns.DefaultResolver,
 		id:                primitive.NewObjectID(),
 	}
 	t.desc.Store(description.Topology{})
+	if cfg.Hedging != nil {
+		t.hedgeBudget = newHedgeBudget(cfg.Hedging.withDefaults())
+	}
 	t.updateCallback = func(desc description.Server) description.Server {
 		return t.apply(context.TODO(), desc)
 	}
@@ -527,6 +543,99 @@ func (t *Topology) RequestImmediateCheck() {
 // server selection spec, and will time out after serverSelectionTimeout or when the
 // parent context is done.
 func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelector) (driver.Server, error) {
+	monitor := t.cfg.ServerSelectionMonitor
+	if monitor == nil {
+		return t.selectServer(ctx, ss)
+	}
+
+	operationName, _ := logger.OperationName(ctx)
+	operationID, _ := logger.OperationID(ctx)
+	if monitor.Started != nil {
+		monitor.Started(ctx, &event.ServerSelectionStartedEvent{
+			Selector:            ss,
+			Operation:           operationName,
+			OperationID:         operationID,
+			TopologyDescription: t.Description(),
+		})
+	}
+
+	start := time.Now()
+	server, err := t.selectServer(ctx, ss)
+	duration := time.Since(start)
+	if err != nil {
+		if monitor.Failed != nil {
+			desc := t.Description()
+			var ssErr ServerSelectionError
+			if errors.As(err, &ssErr) {
+				desc = ssErr.Desc
+			}
+			monitor.Failed(ctx, &event.ServerSelectionFailedEvent{
+				Selector:            ss,
+				Operation:           operationName,
+				OperationID:         operationID,
+				Duration:            duration,
+				Failure:             err,
+				TopologyDescription: desc,
+			})
+		}
+		return nil, err
+	}
+
+	if monitor.Succeeded != nil {
+		evt := &event.ServerSelectionSucceededEvent{
+			Selector:            ss,
+			Operation:           operationName,
+			OperationID:         operationID,
+			Duration:            duration,
+			TopologyDescription: t.Description(),
+		}
+		if selected, ok := server.(*SelectedServer); ok {
+			evt.Address = selected.address
+		}
+		monitor.Succeeded(ctx, evt)
+	}
+	return server, nil
+}
+
+// publishServerSelectionWaiting publishes a ServerSelectionWaitingEvent to the server selection monitor, if any.
+func (t *Topology) publishServerSelectionWaiting(ctx context.Context, ss description.ServerSelector, startTime time.Time) {
+	monitor := t.cfg.ServerSelectionMonitor
+	if monitor == nil || monitor.Waiting == nil {
+		return
+	}
+
+	var remaining time.Duration
+	if t.cfg.ServerSelectionTimeout > 0 {
+		remaining = t.cfg.ServerSelectionTimeout - time.Since(startTime)
+	}
+
+	operationName, _ := logger.OperationName(ctx)
+	operationID, _ := logger.OperationID(ctx)
+	monitor.Waiting(ctx, &event.ServerSelectionWaitingEvent{
+		Selector:            ss,
+		Operation:           operationName,
+		OperationID:         operationID,
+		RemainingTime:       remaining,
+		TopologyDescription: t.Description(),
+	})
+}
+
+// applicationSelector returns the selector that selects the servers for an operation. If the operation or the client
+// has an application server selector, it runs after the given selector.
+func (t *Topology) applicationSelector(ctx context.Context, ss description.ServerSelector) description.ServerSelector {
+	appSelector := t.cfg.ServerSelector
+	if selector, ok := driverutil.ServerSelectorFromContext(ctx); ok {
+		appSelector = selector
+	}
+	if appSelector == nil {
+		return ss
+	}
+
+	return description.CompositeSelector([]description.ServerSelector{ss, appSelector})
+}
+
+// selectServer implements SelectServer without publishing server selection events.
+func (t *Topology) selectServer(ctx context.Context, ss description.ServerSelector) (driver.Server, error) {
 	if atomic.LoadInt64(&t.state) != topologyConnected {
 		if mustLogServerSelection(t, logger.LevelDebug) {
 			logServerSelectionFailed(ctx, t, ss, ErrTopologyClosed)
@@ -544,7 +653,7 @@ func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelect
 
 	var doneOnce bool
 	var sub *driver.Subscription
-	selectionState := newServerSelectionState(ss, ssTimeoutCh)
+	selectionState := newServerSelectionState(t.applicationSelector(ctx, ss), ssTimeoutCh)
 
 	// Record the start time.
 	startTime := time.Now()
@@ -596,6 +705,7 @@ func (t *Topology) SelectServer(ctx context.Context, ss description.ServerSelect
 				logServerSelection(ctx, t, logger.LevelInfo, logger.ServerSelectionWaiting, ss,
 					logger.KeyRemainingTimeMS, remainingTimeMS.Milliseconds())
 			}
+			t.publishServerSelectionWaiting(ctx, ss, startTime)
 
 			continue
 		}
@@ -731,9 +841,17 @@ func (t *Topology) selectServerFromSubscription(ctx context.Context, subscriptio
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, ServerSelectionError{Wrapped: ctx.Err(), Desc: current}
+			return nil, ServerSelectionError{
+				Wrapped:    ctx.Err(),
+				Desc:       current,
+				Rejections: t.selectionRejections(current, selectionState.selector),
+			}
 		case <-selectionState.timeoutChan:
-			return nil, ServerSelectionError{Wrapped: ErrServerSelectionTimeout, Desc: current}
+			return nil, ServerSelectionError{
+				Wrapped:    ErrServerSelectionTimeout,
+				Desc:       current,
+				Rejections: t.selectionRejections(current, selectionState.selector),
+			}
 		case current = <-subscriptionCh:
 		}
 
@@ -769,7 +887,7 @@ func (t *Topology) selectServerFromDescription(desc description.Topology,
 
 	allowedIndexes := make([]int, 0, len(desc.Servers))
 	for i, s := range desc.Servers {
-		if s.Kind != description.Unknown {
+		if s.Kind != description.Unknown && !s.Excluded {
 			allowedIndexes = append(allowedIndexes, i)
 		}
 	}
@@ -781,11 +899,142 @@ func (t *Topology) selectServerFromDescription(desc description.Topology,
 
 	suitable, err := selectionState.selector.SelectServer(desc, allowed)
 	if err != nil {
-		return nil, ServerSelectionError{Wrapped: err, Desc: desc}
+		return nil, ServerSelectionError{
+			Wrapped:    err,
+			Desc:       desc,
+			Rejections: t.selectionRejections(desc, selectionState.selector),
+		}
 	}
 	return suitable, nil
 }
 
+// SelectionDiagnostics describes the outcome of a server selection dry run.
+type SelectionDiagnostics struct {
+	// Topology is the topology description the server was selected from.
+	Topology description.Topology
+
+	// Suitable contains the servers that match the server selector.
+	Suitable []description.Server
+
+	// Selected is the server an operation would be sent to, or nil if no server is suitable.
+	Selected *description.Server
+
+	// Rejections contains the reason each server other than Selected was rejected.
+	Rejections []description.ServerRejection
+}
+
+// DiagnoseSelection selects a server from the current topology description in the same way as SelectServer, without
+// waiting for a suitable server or running an operation, and explains why each other server was rejected. Because the
+// final choice between suitable servers is random, repeated dry runs may select different servers.
+func (t *Topology) DiagnoseSelection(ctx context.Context, ss description.ServerSelector) (SelectionDiagnostics, error) {
+	if atomic.LoadInt64(&t.state) != topologyConnected {
+		return SelectionDiagnostics{}, ErrTopologyClosed
+	}
+
+	desc := t.Description()
+	suitable, rejections, err := t.diagnoseSelection(desc, t.applicationSelector(ctx, ss))
+	diag := SelectionDiagnostics{
+		Topology:   desc,
+		Suitable:   suitable,
+		Rejections: rejections,
+	}
+	if err != nil {
+		return diag, ServerSelectionError{Wrapped: err, Desc: desc, Rejections: rejections}
+	}
+
+	switch len(suitable) {
+	case 0:
+	case 1:
+		diag.Selected = &suitable[0]
+	default:
+		// Mirror the two-choice step of selectServer. pick2 reorders its input, so it is given a copy.
+		desc1, desc2 := pick2(append([]description.Server(nil), suitable...))
+		count1, count2 := t.operationCount(desc1.Addr), t.operationCount(desc2.Addr)
+
+		selected, rejected := desc2, desc1
+		selectedCount, rejectedCount := count2, count1
+		if count1 < count2 {
+			selected, rejected = desc1, desc2
+			selectedCount, rejectedCount = count1, count2
+		}
+
+		diag.Selected = &selected
+		diag.Rejections = append(diag.Rejections, description.ServerRejection{
+			Addr: rejected.Addr,
+			Reason: fmt.Sprintf("lost the two-choice step to %s: %d in-progress operations, compared to %d",
+				selected.Addr, rejectedCount, selectedCount),
+		})
+	}
+
+	return diag, nil
+}
+
+// selectionRejections returns the reason each server in the description is rejected by the selector.
+func (t *Topology) selectionRejections(desc description.Topology, ss description.ServerSelector) []description.ServerRejection {
+	_, rejections, _ := t.diagnoseSelection(desc, ss)
+	return rejections
+}
+
+// diagnoseSelection runs the selector against the description in the same way as selectServerFromDescription and
+// additionally returns the reason each server that is not suitable was rejected.
+func (t *Topology) diagnoseSelection(desc description.Topology,
+	ss description.ServerSelector) ([]description.Server, []description.ServerRejection, error) {
+
+	if desc.CompatibilityErr != nil {
+		return nil, nil, desc.CompatibilityErr
+	}
+	if desc.Kind == description.LoadBalanced {
+		return desc.Servers, nil, nil
+	}
+
+	var rejections []description.ServerRejection
+	allowed := make([]description.Server, 0, len(desc.Servers))
+	for _, s := range desc.Servers {
+		switch {
+		case s.Excluded:
+			rejections = append(rejections, description.ServerRejection{Addr: s.Addr, Reason: "excluded from selection"})
+		case s.Kind == description.Unknown:
+			rejections = append(rejections, description.ServerRejection{Addr: s.Addr, Reason: t.unknownServerReason(s)})
+		default:
+			allowed = append(allowed, s)
+		}
+	}
+
+	suitable, selectorRejections, err := description.DiagnoseSelection(ss, desc, allowed)
+	return suitable, append(rejections, selectorRejections...), err
+}
+
+// unknownServerReason explains why a server of kind Unknown can't be selected.
+func (t *Topology) unknownServerReason(desc description.Server) string {
+	reasons := []string{"unknown server"}
+
+	t.serversLock.Lock()
+	server := t.servers[desc.Addr]
+	t.serversLock.Unlock()
+	if server != nil && server.pool.getState() == poolPaused {
+		reasons = append(reasons, "pool paused")
+	}
+
+	if desc.LastError != nil {
+		reasons = append(reasons, fmt.Sprintf("heartbeat failing with error: %v", desc.LastError))
+	} else {
+		reasons = append(reasons, "no successful heartbeat yet")
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
+// operationCount returns the number of in-progress operations of the server with the given address.
+func (t *Topology) operationCount(addr address.Address) int64 {
+	t.serversLock.Lock()
+	defer t.serversLock.Unlock()
+
+	if server, ok := t.servers[addr]; ok {
+		return server.OperationCount()
+	}
+	return 0
+}
+
 func (t *Topology) pollSRVRecords(hosts string) {
 	defer t.pollingwg.Done()
 
@@ -855,6 +1104,7 @@ func (t *Topology) processSRVResults(parsedHosts []string) bool {
 
 	for _, r := range diff.Removed {
 		addr := address.Address(r).Canonicalize()
+		delete(t.excluded, addr)
 		s, ok := t.servers[addr]
 		if !ok {
 			continue
@@ -933,6 +1183,10 @@ func (t *Topology) apply(ctx context.Context, desc description.Server) descripti
 	if oldDesc.TopologyVersion.CompareToIncoming(desc.TopologyVersion) > 0 {
 		return oldDesc
 	}
+	desc.Excluded = t.excluded[desc.Addr]
+	if s := t.servers[desc.Addr]; s != nil {
+		desc.CircuitOpen = s.circuitBreaker.blocksReads()
+	}
 
 	var current description.Topology
 	current, desc = t.fsm.apply(desc)
@@ -944,6 +1198,7 @@ func (t *Topology) apply(ctx context.Context, desc description.Server) descripti
 	diff := diffTopology(prev, current)
 
 	for _, removed := range diff.Removed {
+		delete(t.excluded, removed.Addr)
 		if s, ok := t.servers[removed.Addr]; ok {
 			go func() {
 				cancelCtx, cancel := context.WithCancel(ctx)
@@ -963,8 +1218,17 @@ func (t *Topology) apply(ctx context.Context, desc description.Server) descripti
 	if !prev.Equal(current) {
 		t.publishTopologyDescriptionChangedEvent(prev, current)
 	}
+	t.notifySubscribers(current)
 
+	return desc
+}
+
+// notifySubscribers sends the topology description to all subscribers, replacing any description they haven't
+// received yet.
+func (t *Topology) notifySubscribers(current description.Topology) {
 	t.subLock.Lock()
+	defer t.subLock.Unlock()
+
 	for _, ch := range t.subscribers {
 		// We drain the description if there's one in the channel
 		select {
@@ -973,9 +1237,87 @@ func (t *Topology) apply(ctx context.Context, desc description.Server) descripti
 		}
 		ch <- current
 	}
-	t.subLock.Unlock()
+}
 
-	return desc
+// SetServerExcluded excludes the server with the given address from server selection or includes it again. While a
+// server is excluded, operations are not sent to it, its connection pool is drained so that idle connections are
+// closed and in-use connections are closed when they are checked back in, and its description reports it as
+// excluded. The server is still monitored, so it becomes selectable as soon as it is included again. Excluding
+// servers is not supported in load balanced mode.
+func (t *Topology) SetServerExcluded(addr address.Address, excluded bool) error {
+	if atomic.LoadInt64(&t.state) != topologyConnected {
+		return ErrTopologyClosed
+	}
+	if t.cfg.LoadBalanced {
+		return errors.New("servers cannot be excluded from selection in load balanced mode")
+	}
+
+	addr = addr.Canonicalize()
+
+	t.serversLock.Lock()
+	ind, ok := t.fsm.findServer(addr)
+	if t.serversClosed || !ok {
+		t.serversLock.Unlock()
+		return fmt.Errorf("server %s is not part of the topology", addr)
+	}
+
+	if excluded {
+		t.excluded[addr] = true
+	} else {
+		delete(t.excluded, addr)
+	}
+
+	desc := t.fsm.Servers[ind]
+	if desc.Excluded == excluded {
+		t.serversLock.Unlock()
+		return nil
+	}
+	desc.Excluded = excluded
+	t.replaceServerDescription(ind, desc)
+
+	server := t.servers[addr]
+	t.serversLock.Unlock()
+
+	if excluded && server != nil {
+		server.pool.drain(ErrServerExcluded)
+	}
+	return nil
+}
+
+// updateCircuitOpen updates the description of the server with the given address after its circuit breaker started or
+// stopped blocking reads.
+func (t *Topology) updateCircuitOpen(addr address.Address) {
+	t.serversLock.Lock()
+	defer t.serversLock.Unlock()
+
+	server, ok := t.servers[addr]
+	ind, found := t.fsm.findServer(addr)
+	if t.serversClosed || !ok || !found {
+		return
+	}
+
+	desc := t.fsm.Servers[ind]
+	if open := server.circuitBreaker.blocksReads(); desc.CircuitOpen != open {
+		desc.CircuitOpen = open
+		t.replaceServerDescription(ind, desc)
+	}
+}
+
+// replaceServerDescription replaces the description of the server at the given index of the FSM without a new check of
+// the server, publishes the change and notifies the subscribers. It must be called while holding serversLock.
+func (t *Topology) replaceServerDescription(ind int, desc description.Server) {
+	prev := t.fsm.Topology
+	oldDesc := t.fsm.Servers[ind]
+	servers := make([]description.Server, len(t.fsm.Servers))
+	copy(servers, t.fsm.Servers)
+	servers[ind] = desc
+	t.fsm.Servers = servers
+	current := t.fsm.Topology
+
+	t.publishServerDescriptionChangedEvent(oldDesc, desc)
+	t.desc.Store(current)
+	t.publishTopologyDescriptionChangedEvent(prev, current)
+	t.notifySubscribers(current)
 }
 
 func (t *Topology) addServer(addr address.Address) error {
@@ -987,6 +1329,7 @@ func (t *Topology) addServer(addr address.Address) error {
 	if err != nil {
 		return err
 	}
+	svr.updateCircuitCallback.Store(func() { t.updateCircuitOpen(addr) })
 
 	t.servers[addr] = svr
 
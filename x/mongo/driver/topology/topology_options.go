@@ -16,7 +16,9 @@ import (
 	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
 	"github.com/hongyuyang/mongo-go-driver/mongo/description"
+	"github.com/hongyuyang/mongo-go-driver/mongo/faultinject"
 	"github.com/hongyuyang/mongo-go-driver/mongo/options"
+	"github.com/hongyuyang/mongo-go-driver/mongo/priority"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/auth"
 	"github.com/hongyuyang/mongo-go-driver/x/mongo/driver/ocsp"
@@ -35,9 +37,12 @@ type Config struct {
 	URI                    string
 	ServerSelectionTimeout time.Duration
 	ServerMonitor          *event.ServerMonitor
+	ServerSelectionMonitor *event.ServerSelectionMonitor
+	ServerSelector         description.ServerSelector
 	SRVMaxHosts            int
 	SRVServiceName         string
 	LoadBalanced           bool
+	Hedging                *HedgingConfig
 	logger                 *logger.Logger
 }
 
@@ -67,6 +72,7 @@ func newLogger(opts *options.LoggerOptions) (*logger.Logger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error creating logger: %w", err)
 	}
+	log.Redaction = opts.Redaction
 
 	return log, nil
 }
@@ -249,6 +255,29 @@ func NewConfig(co *options.ClientOptions, clock *session.ClusterClock) (*Config,
 			func(time.Duration) time.Duration { return *co.MaxConnIdleTime },
 		))
 	}
+	// MaxConnLifeTime
+	if co.MaxConnLifeTime != nil {
+		serverOpts = append(serverOpts, WithConnectionPoolMaxLifeTime(
+			func(_, jitter time.Duration) (time.Duration, time.Duration) {
+				if co.MaxConnLifeTimeJitter != nil {
+					jitter = *co.MaxConnLifeTimeJitter
+				}
+				return *co.MaxConnLifeTime, jitter
+			},
+		))
+	}
+	// PoolPriorityShares
+	if co.PoolPriorityShares != nil {
+		serverOpts = append(serverOpts, WithConnectionPoolPriorityShares(
+			func(map[priority.Priority]float64) map[priority.Priority]float64 { return co.PoolPriorityShares },
+		))
+	}
+	// PoolPriorityAging
+	if co.PoolPriorityAging != nil {
+		serverOpts = append(serverOpts, WithConnectionPoolPriorityAging(
+			func(time.Duration) time.Duration { return *co.PoolPriorityAging },
+		))
+	}
 	// MaxPoolSize
 	if co.MaxPoolSize != nil {
 		serverOpts = append(
@@ -277,6 +306,68 @@ func NewConfig(co *options.ClientOptions, clock *session.ClusterClock) (*Config,
 			WithConnectionPoolMonitor(func(*event.PoolMonitor) *event.PoolMonitor { return co.PoolMonitor }),
 		)
 	}
+	// LoadShedding
+	if lso := co.LoadShedding; lso != nil {
+		cfg := &LoadSheddingConfig{}
+		if lso.InitialLimit != nil {
+			cfg.InitialLimit = *lso.InitialLimit
+		}
+		if lso.MinLimit != nil {
+			cfg.MinLimit = *lso.MinLimit
+		}
+		if lso.MaxLimit != nil {
+			cfg.MaxLimit = *lso.MaxLimit
+		}
+		if lso.LatencyTolerance != nil {
+			cfg.LatencyTolerance = *lso.LatencyTolerance
+		}
+		if lso.MaxRetries != nil {
+			cfg.MaxRetries = *lso.MaxRetries
+			if cfg.MaxRetries == 0 {
+				cfg.MaxRetries = -1
+			}
+		}
+		if lso.RetryBackoff != nil {
+			cfg.RetryBackoff = *lso.RetryBackoff
+		}
+		if lso.RetryBudget != nil {
+			cfg.RetryBudget = *lso.RetryBudget
+		}
+		serverOpts = append(
+			serverOpts,
+			WithLoadShedding(func(*LoadSheddingConfig) *LoadSheddingConfig { return cfg }),
+		)
+	}
+	// CircuitBreaker
+	if cbo := co.CircuitBreaker; cbo != nil {
+		cfg := &CircuitBreakerConfig{}
+		if cbo.FailureRate != nil {
+			cfg.FailureRate = *cbo.FailureRate
+		}
+		if cbo.MinCommands != nil {
+			cfg.MinCommands = *cbo.MinCommands
+		}
+		if cbo.Window != nil {
+			cfg.Window = *cbo.Window
+		}
+		if cbo.CoolDown != nil {
+			cfg.CoolDown = *cbo.CoolDown
+		}
+		if cbo.Probes != nil {
+			cfg.Probes = *cbo.Probes
+		}
+		serverOpts = append(
+			serverOpts,
+			WithCircuitBreaker(func(*CircuitBreakerConfig) *CircuitBreakerConfig { return cfg }),
+		)
+	}
+	// FaultInjector
+	if co.FaultInjector != nil {
+		serverOpts = append(
+			serverOpts,
+			WithFaultInjector(func(*faultinject.Injector) *faultinject.Injector { return co.FaultInjector }),
+		)
+	}
 	// Monitor
 	if co.Monitor != nil {
 		connOpts = append(connOpts, WithMonitor(
@@ -291,6 +382,20 @@ func NewConfig(co *options.ClientOptions, clock *session.ClusterClock) (*Config,
 		)
 		cfgp.ServerMonitor = co.ServerMonitor
 	}
+	// ServerSelectionMonitor
+	cfgp.ServerSelectionMonitor = co.ServerSelectionMonitor
+	// ServerSelector
+	cfgp.ServerSelector = co.ServerSelector
+	// Hedging
+	if ho := co.Hedging; ho != nil {
+		cfgp.Hedging = &HedgingConfig{}
+		if ho.Delay != nil {
+			cfgp.Hedging.Delay = *ho.Delay
+		}
+		if ho.Budget != nil {
+			cfgp.Hedging.Budget = *ho.Budget
+		}
+	}
 	// ReplicaSet
 	if co.ReplicaSet != nil {
 		cfgp.ReplicaSetName = *co.ReplicaSet
@@ -103,4 +103,9 @@ func TestTopologyNewConfig(t *testing.T) {
 		assert.Nil(t, err, "error constructing topology config: %v", err)
 		assert.Equal(t, []string{"localhost:27018"}, cfg.SeedList)
 	})
+	t.Run("PoolPriorityAging", func(t *testing.T) {
+		cfg, err := NewConfig(options.Client().SetPoolPriorityAging(time.Second), nil)
+		assert.Nil(t, err, "error constructing topology config: %v", err)
+		assert.Equal(t, time.Second, newServerConfig(cfg.ServerOpts...).poolPriorityAging)
+	})
 }
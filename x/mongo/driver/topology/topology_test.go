@@ -20,7 +20,9 @@ import (
 	"time"
 
 	"github.com/hongyuyang/mongo-go-driver/bson/primitive"
+	"github.com/hongyuyang/mongo-go-driver/event"
 	"github.com/hongyuyang/mongo-go-driver/internal/assert"
+	"github.com/hongyuyang/mongo-go-driver/internal/driverutil"
 	"github.com/hongyuyang/mongo-go-driver/internal/logger"
 	"github.com/hongyuyang/mongo-go-driver/internal/require"
 	"github.com/hongyuyang/mongo-go-driver/internal/spectest"
@@ -217,7 +219,15 @@ func TestServerSelection(t *testing.T) {
 			t.Errorf("Timed out while trying to retrieve selected servers")
 		}
 
-		want := ServerSelectionError{Wrapped: context.Canceled, Desc: desc}
+		want := ServerSelectionError{
+			Wrapped: context.Canceled,
+			Desc:    desc,
+			Rejections: []description.ServerRejection{
+				{Addr: address.Address("one"), Reason: "rejected by server selector"},
+				{Addr: address.Address("two"), Reason: "rejected by server selector"},
+				{Addr: address.Address("three"), Reason: "rejected by server selector"},
+			},
+		}
 		assert.Equal(t, err, want, "Incorrect error received. got %v; want %v", err, want)
 	})
 	t.Run("Timeout", func(t *testing.T) {
@@ -413,6 +423,342 @@ func TestServerSelection(t *testing.T) {
 		_, err = topo.SelectServer(context.Background(), description.WriteSelector())
 		assert.Equal(t, ErrSubscribeAfterClosed, err, "expected error %v, got %v", ErrSubscribeAfterClosed, err)
 	})
+	t.Run("server selection monitor", func(t *testing.T) {
+		var started []*event.ServerSelectionStartedEvent
+		var succeeded []*event.ServerSelectionSucceededEvent
+		var failed []*event.ServerSelectionFailedEvent
+		monitor := &event.ServerSelectionMonitor{
+			Started: func(_ context.Context, evt *event.ServerSelectionStartedEvent) {
+				started = append(started, evt)
+			},
+			Succeeded: func(_ context.Context, evt *event.ServerSelectionSucceededEvent) {
+				succeeded = append(succeeded, evt)
+			},
+			Failed: func(_ context.Context, evt *event.ServerSelectionFailedEvent) {
+				failed = append(failed, evt)
+			},
+		}
+
+		topo, err := New(nil)
+		noerr(t, err)
+		topo.cfg.ServerSelectionMonitor = monitor
+		atomic.StoreInt64(&topo.state, topologyConnected)
+
+		primaryAddr := address.Address("one")
+		desc := description.Topology{
+			Servers: []description.Server{
+				{Addr: primaryAddr, Kind: description.RSPrimary},
+			},
+		}
+		topo.desc.Store(desc)
+		s, err := ConnectServer(primaryAddr, topo.updateCallback, topo.id)
+		noerr(t, err)
+		topo.servers[primaryAddr] = s
+
+		ctx := logger.WithOperationName(context.Background(), "insert")
+		_, err = topo.SelectServer(ctx, description.WriteSelector())
+		noerr(t, err)
+		assert.Equal(t, 1, len(started), "expected 1 started event, got %d", len(started))
+		assert.Equal(t, "insert", started[0].Operation, "expected operation %q, got %q", "insert", started[0].Operation)
+		assert.Equal(t, 1, len(succeeded), "expected 1 succeeded event, got %d", len(succeeded))
+		assert.Equal(t, primaryAddr, succeeded[0].Address, "expected address %v, got %v", primaryAddr,
+			succeeded[0].Address)
+
+		topo.subscriptionsClosed = true
+		_, err = topo.SelectServer(ctx, selectNone)
+		assert.Equal(t, ErrSubscribeAfterClosed, err, "expected error %v, got %v", ErrSubscribeAfterClosed, err)
+		assert.Equal(t, 2, len(started), "expected 2 started events, got %d", len(started))
+		assert.Equal(t, 1, len(failed), "expected 1 failed event, got %d", len(failed))
+		assert.Equal(t, ErrSubscribeAfterClosed, failed[0].Failure, "expected failure %v, got %v",
+			ErrSubscribeAfterClosed, failed[0].Failure)
+	})
+	t.Run("application server selector", func(t *testing.T) {
+		topo, err := New(nil)
+		noerr(t, err)
+		atomic.StoreInt64(&topo.state, topologyConnected)
+
+		desc := description.Topology{
+			Servers: []description.Server{
+				{Addr: address.Address("one"), Kind: description.Mongos, P90RTT: 10 * time.Millisecond},
+				{Addr: address.Address("two"), Kind: description.Mongos, P90RTT: 2 * time.Millisecond},
+			},
+		}
+		topo.desc.Store(desc)
+		for _, srv := range desc.Servers {
+			s, err := ConnectServer(srv.Addr, topo.updateCallback, topo.id)
+			noerr(t, err)
+			topo.servers[srv.Addr] = s
+		}
+
+		var selectAll description.ServerSelectorFunc = func(_ description.Topology, candidates []description.Server) ([]description.Server, error) {
+			return candidates, nil
+		}
+		// selectFastest selects the server with the lowest 90th percentile RTT.
+		var selectFastest description.ServerSelectorFunc = func(_ description.Topology, candidates []description.Server) ([]description.Server, error) {
+			var fastest []description.Server
+			for _, candidate := range candidates {
+				if len(fastest) == 0 || candidate.P90RTT < fastest[0].P90RTT {
+					fastest = []description.Server{candidate}
+				}
+			}
+			return fastest, nil
+		}
+		topo.cfg.ServerSelector = selectFastest
+
+		srv, err := topo.SelectServer(context.Background(), selectAll)
+		noerr(t, err)
+		assert.Equal(t, address.Address("two"), srv.(*SelectedServer).address, "expected the client selector to be used")
+
+		ctx := driverutil.WithServerSelector(context.Background(), selectFirst)
+		srv, err = topo.SelectServer(ctx, selectAll)
+		noerr(t, err)
+		assert.Equal(t, address.Address("one"), srv.(*SelectedServer).address, "expected the operation selector to be used")
+
+		selector := topo.applicationSelector(driverutil.WithServerSelector(context.Background(), nil), selectAll)
+		srvs, err := selector.SelectServer(desc, desc.Servers)
+		noerr(t, err)
+		assert.Equal(t, 2, len(srvs), "expected the client selector to be disabled, got %v", srvs)
+	})
+	t.Run("monitor", func(t *testing.T) {
+		topo, err := New(nil)
+		noerr(t, err)
+		atomic.StoreInt64(&topo.state, topologyConnected)
+
+		desc := description.Topology{
+			Servers: []description.Server{
+				{Addr: address.Address("one"), Kind: description.Standalone},
+			},
+		}
+		topo.desc.Store(desc)
+
+		var waiting []*event.ServerSelectionWaitingEvent
+		var failed []*event.ServerSelectionFailedEvent
+		topo.cfg.ServerSelectionTimeout = 50 * time.Millisecond
+		topo.cfg.ServerSelectionMonitor = &event.ServerSelectionMonitor{
+			Waiting: func(_ context.Context, evt *event.ServerSelectionWaitingEvent) {
+				waiting = append(waiting, evt)
+			},
+			Failed: func(_ context.Context, evt *event.ServerSelectionFailedEvent) {
+				failed = append(failed, evt)
+			},
+		}
+
+		ctx := logger.WithOperationName(context.Background(), "find")
+		_, err = topo.SelectServer(ctx, selectNone)
+		assert.True(t, errors.Is(err, ErrServerSelectionTimeout), "expected a server selection timeout, got %v", err)
+
+		require.True(t, len(waiting) > 0, "expected a waiting event")
+		assert.Equal(t, "find", waiting[0].Operation, "unexpected operation name")
+		assert.True(t, waiting[0].RemainingTime > 0, "expected remaining time, got %v", waiting[0].RemainingTime)
+		assert.True(t, desc.Equal(waiting[0].TopologyDescription), "expected the topology description")
+
+		require.Equal(t, 1, len(failed), "expected 1 failed event, got %d", len(failed))
+		assert.True(t, desc.Equal(failed[0].TopologyDescription), "expected the topology description")
+	})
+	t.Run("diagnostics", func(t *testing.T) {
+		topo, err := New(nil)
+		noerr(t, err)
+		atomic.StoreInt64(&topo.state, topologyConnected)
+
+		heartbeatErr := errors.New("connection refused")
+		desc := description.Topology{
+			Kind: description.ReplicaSetWithPrimary,
+			Servers: []description.Server{
+				{Addr: address.Address("primary"), Kind: description.RSPrimary, AverageRTT: time.Millisecond, AverageRTTSet: true},
+				{Addr: address.Address("near"), Kind: description.RSSecondary, AverageRTT: 2 * time.Millisecond, AverageRTTSet: true},
+				{Addr: address.Address("far"), Kind: description.RSSecondary, AverageRTT: time.Second, AverageRTTSet: true},
+				{Addr: address.Address("arbiter"), Kind: description.RSArbiter},
+				{Addr: address.Address("down"), Kind: description.Unknown, LastError: heartbeatErr},
+			},
+		}
+		topo.desc.Store(desc)
+		for _, srv := range desc.Servers {
+			s, err := ConnectServer(srv.Addr, topo.updateCallback, topo.id)
+			noerr(t, err)
+			topo.servers[srv.Addr] = s
+		}
+		down := topo.servers[address.Address("down")]
+		down.pool.stateMu.Lock()
+		down.pool.state = poolPaused
+		down.pool.stateMu.Unlock()
+
+		selector := description.CompositeSelector([]description.ServerSelector{
+			description.ReadPrefSelector(readpref.Nearest()),
+			description.LatencySelector(15 * time.Millisecond),
+		})
+		diag, err := topo.DiagnoseSelection(context.Background(), selector)
+		noerr(t, err)
+
+		assert.Equal(t, 2, len(diag.Suitable), "expected 2 suitable servers, got %v", diag.Suitable)
+		require.NotNil(t, diag.Selected, "expected a server to be selected")
+
+		reasons := make(map[address.Address]string)
+		for _, rejection := range diag.Rejections {
+			reasons[rejection.Addr] = rejection.Reason
+		}
+		assert.Equal(t, "unknown server, pool paused, heartbeat failing with error: connection refused",
+			reasons["down"], "unexpected reason for the unknown server")
+		assert.Equal(t, "wrong kind RSArbiter, want RSPrimary or RSSecondary", reasons["arbiter"],
+			"unexpected reason for the arbiter")
+		assert.Equal(t, "outside latency window: average RTT 1s exceeds 16ms", reasons["far"],
+			"unexpected reason for the distant secondary")
+
+		var other address.Address = "primary"
+		if diag.Selected.Addr == other {
+			other = "near"
+		}
+		assert.Contains(t, reasons[other], "lost the two-choice step", "unexpected reason for the unselected server")
+
+		state := newServerSelectionState(description.ReadPrefSelector(readpref.Secondary()), nil)
+		ssErr := ServerSelectionError{
+			Wrapped:    ErrServerSelectionTimeout,
+			Desc:       desc,
+			Rejections: topo.selectionRejections(desc, state.selector),
+		}
+		assert.Contains(t, ssErr.Error(), "primary:27017: wrong kind RSPrimary, want RSSecondary",
+			"expected the error to contain the rejection reasons")
+	})
+	t.Run("excluded servers", func(t *testing.T) {
+		topo, err := New(nil)
+		noerr(t, err)
+		atomic.StoreInt64(&topo.state, topologyConnected)
+
+		var changed []*event.TopologyDescriptionChangedEvent
+		topo.cfg.ServerMonitor = &event.ServerMonitor{
+			TopologyDescriptionChanged: func(evt *event.TopologyDescriptionChangedEvent) {
+				changed = append(changed, evt)
+			},
+		}
+
+		desc := description.Topology{
+			Kind:    description.ReplicaSetWithPrimary,
+			SetName: "rs",
+			Servers: []description.Server{
+				{Addr: address.Address("primary:27017"), Kind: description.RSPrimary, SetName: "rs"},
+				{Addr: address.Address("secondary:27017"), Kind: description.RSSecondary, SetName: "rs"},
+			},
+		}
+		topo.fsm.Kind = desc.Kind
+		topo.fsm.SetName = desc.SetName
+		topo.fsm.Servers = desc.Servers
+		topo.desc.Store(desc)
+		for _, srv := range desc.Servers {
+			s, err := ConnectServer(srv.Addr, topo.updateCallback, topo.id)
+			noerr(t, err)
+			topo.servers[srv.Addr] = s
+		}
+
+		err = topo.SetServerExcluded("secondary", true)
+		noerr(t, err)
+
+		require.Len(t, changed, 1, "expected a TopologyDescriptionChangedEvent")
+		newDesc := changed[0].NewDescription
+		assert.True(t, newDesc.Servers[1].Excluded, "expected the secondary to be excluded in the new description")
+		assert.False(t, changed[0].PreviousDescription.Servers[1].Excluded,
+			"expected the secondary not to be excluded in the previous description")
+
+		state := newServerSelectionState(description.ReadPrefSelector(readpref.Nearest()), nil)
+		srvs, err := topo.selectServerFromDescription(topo.Description(), state)
+		noerr(t, err)
+		require.Len(t, srvs, 1, "expected only the primary to be selectable, got %v", srvs)
+		assert.Equal(t, address.Address("primary:27017"), srvs[0].Addr, "expected the primary to be selectable")
+
+		diag, err := topo.DiagnoseSelection(context.Background(), description.ReadPrefSelector(readpref.Nearest()))
+		noerr(t, err)
+		require.Len(t, diag.Rejections, 1, "expected a rejection, got %v", diag.Rejections)
+		assert.Equal(t, "excluded from selection", diag.Rejections[0].Reason, "unexpected rejection reason")
+
+		// A heartbeat must not include the server again.
+		updated := topo.apply(context.Background(), description.Server{
+			Addr:          address.Address("secondary:27017"),
+			CanonicalAddr: address.Address("secondary:27017"),
+			Kind:          description.RSSecondary,
+			SetName:       "rs",
+		})
+		assert.True(t, updated.Excluded, "expected the secondary to stay excluded after a heartbeat")
+
+		err = topo.SetServerExcluded("secondary:27017", false)
+		noerr(t, err)
+		srvs, err = topo.selectServerFromDescription(topo.Description(), state)
+		noerr(t, err)
+		assert.Len(t, srvs, 2, "expected both servers to be selectable, got %v", srvs)
+
+		err = topo.SetServerExcluded("unknown:27017", true)
+		assert.Error(t, err, "expected an error excluding a server that is not part of the topology")
+
+		// Removing a server from the topology forgets that it was excluded.
+		err = topo.SetServerExcluded("secondary:27017", true)
+		noerr(t, err)
+		topo.apply(context.Background(), description.Server{
+			Addr:          address.Address("primary:27017"),
+			CanonicalAddr: address.Address("primary:27017"),
+			Kind:          description.RSPrimary,
+			SetName:       "rs",
+			Hosts:         []string{"primary:27017"},
+		})
+		_, ok := topo.servers["secondary:27017"]
+		require.False(t, ok, "expected the secondary to be removed from the topology")
+		assert.False(t, topo.excluded["secondary:27017"], "expected the removed secondary not to be excluded")
+	})
+	t.Run("servers whose circuit breaker is open", func(t *testing.T) {
+		topo, err := New(nil)
+		noerr(t, err)
+		atomic.StoreInt64(&topo.state, topologyConnected)
+		topo.cfg.ServerOpts = []ServerOption{
+			withMonitoringDisabled(func(bool) bool { return true }),
+			WithCircuitBreaker(func(*CircuitBreakerConfig) *CircuitBreakerConfig {
+				return &CircuitBreakerConfig{MinCommands: 2, CoolDown: time.Hour}
+			}),
+		}
+
+		var changed []*event.TopologyDescriptionChangedEvent
+		topo.cfg.ServerMonitor = &event.ServerMonitor{
+			TopologyDescriptionChanged: func(evt *event.TopologyDescriptionChangedEvent) {
+				changed = append(changed, evt)
+			},
+		}
+
+		desc := description.Topology{
+			Kind:    description.ReplicaSetWithPrimary,
+			SetName: "rs",
+			Servers: []description.Server{
+				{Addr: address.Address("primary:27017"), Kind: description.RSPrimary, SetName: "rs"},
+				{Addr: address.Address("secondary:27017"), Kind: description.RSSecondary, SetName: "rs"},
+			},
+		}
+		topo.fsm.Kind = desc.Kind
+		topo.fsm.SetName = desc.SetName
+		topo.fsm.Servers = desc.Servers
+		topo.desc.Store(desc)
+		for _, srv := range desc.Servers {
+			noerr(t, topo.addServer(srv.Addr))
+			defer func(s *Server) { _ = s.Disconnect(context.Background()) }(topo.servers[srv.Addr])
+		}
+		secondary := topo.servers["secondary:27017"]
+
+		networkErr := driver.Error{Labels: []string{driver.NetworkError}}
+		secondary.FinishRead(networkErr)
+		secondary.FinishRead(networkErr)
+
+		require.Len(t, changed, 1, "expected a TopologyDescriptionChangedEvent")
+		assert.True(t, changed[0].NewDescription.Servers[1].CircuitOpen,
+			"expected the circuit breaker of the secondary to be open in the new description")
+
+		state := newServerSelectionState(description.ReadPrefSelector(readpref.Nearest()), nil)
+		srvs, err := topo.selectServerFromDescription(topo.Description(), state)
+		noerr(t, err)
+		require.Len(t, srvs, 1, "expected only the primary to be selectable, got %v", srvs)
+		assert.Equal(t, address.Address("primary:27017"), srvs[0].Addr, "expected the primary to be selectable")
+
+		// A heartbeat must not close the circuit breaker in the description.
+		updated := topo.apply(context.Background(), description.Server{
+			Addr:          address.Address("secondary:27017"),
+			CanonicalAddr: address.Address("secondary:27017"),
+			Kind:          description.RSSecondary,
+			SetName:       "rs",
+		})
+		assert.True(t, updated.CircuitOpen, "expected the circuit breaker to stay open after a heartbeat")
+	})
 }
 
 func TestSessionTimeout(t *testing.T) {